@@ -1,15 +1,26 @@
 package main
 
 import (
+	"context"
+	"forum/internal/filter"
 	"forum/internal/handlers"
 	"forum/internal/handlers/middleware"
 	"forum/internal/managers"
+	"forum/internal/metrics"
+	"forum/internal/models"
+	"forum/internal/storage/local"
 	"forum/internal/storage/mongo"
 	"forum/internal/storage/mysql"
 	"forum/internal/storage/redis"
+	"forum/internal/storage/s3"
+	"forum/internal/tracing"
+	"forum/internal/unfurl"
+	"io"
 	"log/slog"
 	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	handls "github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
@@ -22,20 +33,78 @@ const (
 	mysqlAddr       = "mysql:3306"
 	mysqlDBName     = "forum"
 
+	// транзакции outbox требуют набор реплик
 	mongoDBName = "forum"
-	mongoAddr   = "mongodb://mongodb"
+	mongoAddr   = "mongodb://mongodb/?replicaSet=rs0"
 
 	redisAddr     = "redis:6379"
 	redisPassword = ""
 	redisDBName   = 0
 
-	userTable      = "users"
-	postCollection = "post"
+	userTable              = "users"
+	identityTable          = "user_identities"
+	followTable            = "follows"
+	categoryTable          = "category_subscriptions"
+	blockTable             = "blocks"
+	muteTable              = "category_mutes"
+	categoryModeratorTable = "category_moderators"
+	categoryRuleTable      = "category_rules"
+
+	postCollection         = "post"
+	savedCollection        = "saved"
+	notificationCollection = "notifications"
+	preferenceCollection   = "notification_preferences"
+	attachmentCollection   = "attachments"
+	reportCollection       = "reports"
+	decisionCollection     = "report_decisions"
+	auditCollection        = "audit_log"
+	webhookCollection      = "webhooks"
+	deliveryCollection     = "webhook_deliveries"
+	outboxCollection       = "outbox"
+
+	oidcProviderName = "corp"
+
+	unfurlWorkers = 4
+
+	// как часто проверяются отложенные посты
+	schedulerInterval = 30 * time.Second
+
+	// сколько хранятся удаленные посты и комментарии и как часто они вычищаются
+	deletedRetention = 30 * 24 * time.Hour
+	purgeInterval    = time.Hour
+
+	// через сколько месяцев пост архивируется и перестает принимать комментарии и голоса
+	archiveAfterMonths = 6
+
+	// ограничения фильтров содержимого: больше ссылок - пост на проверку модератору,
+	// повтор своего текста за окно - отказ, новые аккаунты пишут не чаще лимита
+	filterMaxLinks        = 3
+	filterDuplicateWindow = 24 * time.Hour
+	newAccountAge         = 72 * time.Hour
+	newAccountWindow      = 24 * time.Hour
+	newAccountPosts       = 5
+
+	// как часто отправляются доставки вебхуков и сколько ждать ответа получателя
+	webhookInterval = 10 * time.Second
+	webhookTimeout  = 10 * time.Second
+
+	// как часто relay публикует события из outbox
+	relayInterval = 500 * time.Millisecond
+
+	uploadDir = "./uploads"
 )
 
 func main() {
 	logger := slog.Default()
 
+	// экспорт спанов по OTLP включается адресом коллектора, без него спаны не записываются
+	shutdownTracing, err := tracing.Setup(os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"), "forum")
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+	defer shutdownTracing(context.Background())
+
 	dbMySQL, err := mysql.GetConnection(mysqlDBUser, mysqlDBPassword, mysqlAddr, mysqlDBName)
 	if err != nil {
 		logger.Error(err.Error())
@@ -55,11 +124,76 @@ func main() {
 	}
 
 	sessionStorage := redis.NewRedisStorage(redisClient)
+	eventStorage := redis.NewEventStorage(redisClient)
 	userStorage := mysql.NewUserStorage(dbMySQL, userTable)
-	postStorage := mongo.NewPostStorage(dbMongo, postCollection)
+	relationStorage := mysql.NewRelationStorage(dbMySQL, followTable, categoryTable, blockTable, muteTable)
+	postStorage := mongo.NewPostStorage(dbMongo, postCollection, outboxCollection, reportCollection)
+	savedStorage := mongo.NewSavedStorage(dbMongo, savedCollection)
+	attachmentStorage := mongo.NewAttachmentStorage(dbMongo, attachmentCollection)
+	notificationStorage := mongo.NewNotificationStorage(dbMongo, notificationCollection, preferenceCollection)
+	reportStorage := mongo.NewReportStorage(dbMongo, reportCollection, decisionCollection)
+	auditStorage := mongo.NewAuditStorage(dbMongo, auditCollection)
+	categoryStorage := mysql.NewCategoryStorage(dbMySQL, userTable, categoryModeratorTable, categoryRuleTable)
+	webhookStorage := mongo.NewWebhookStorage(dbMongo, webhookCollection, deliveryCollection)
+	outboxStorage := mongo.NewOutboxStorage(dbMongo, outboxCollection)
+
+	// файлы хранятся в S3-совместимом хранилище, если задан S3_ENDPOINT, иначе на диске
+	var blobStorage interface {
+		Put(string, io.Reader, int64, string) error
+		Get(string) (io.ReadCloser, error)
+		Delete(string) error
+	}
+	if endpoint := os.Getenv("S3_ENDPOINT"); endpoint != "" {
+		bucket := os.Getenv("S3_BUCKET")
+		s3Client, err := s3.GetConnection(endpoint, os.Getenv("S3_ACCESS_KEY"), os.Getenv("S3_SECRET_KEY"), bucket, os.Getenv("S3_USE_SSL") == "true")
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+		blobStorage = s3.NewBlobStorage(s3Client, bucket)
+	} else {
+		blobStorage, err = local.NewBlobStorage(uploadDir)
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+	}
 
 	authManager := managers.NewSeesionManager(userStorage, sessionStorage)
-	postManager := managers.NewPostManager(postStorage)
+	eventManager := managers.NewEventManager(eventStorage)
+	outboxManager := managers.NewOutboxManager(outboxStorage, eventManager)
+	unfurler := unfurl.NewUnfurler(splitList(os.Getenv("UNFURL_ALLOW")), splitList(os.Getenv("UNFURL_DENY")))
+	unfurlManager := managers.NewUnfurlManager(unfurler, postStorage, eventManager)
+	notificationManager := managers.NewNotificationManager(notificationStorage, relationStorage, eventManager)
+	// запрещенные слова - регулярные выражения по одному на строку в файле FILTER_WORDS_FILE
+	bannedWords := make([]string, 0)
+	if path := os.Getenv("FILTER_WORDS_FILE"); path != "" {
+		bannedWords, err = filter.LoadPatterns(path)
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+	}
+	wordFilter, err := filter.NewWordFilter(bannedWords, models.FilterReject)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+	contentFilter := filter.NewPipeline(
+		wordFilter,
+		filter.NewLinkFilter(filterMaxLinks, models.FilterHold),
+		filter.NewDuplicateFilter(postStorage, filterDuplicateWindow, models.FilterReject),
+		filter.NewAccountAgeFilter(postStorage, newAccountAge, newAccountWindow, newAccountPosts, models.FilterHold),
+	)
+	postManager := managers.NewPostManager(postStorage, userStorage, relationStorage, savedStorage, attachmentStorage, eventManager, notificationManager, unfurlManager, auditStorage, contentFilter, categoryStorage, archiveAfterMonths)
+	attachmentManager := managers.NewAttachmentManager(blobStorage, attachmentStorage)
+	relationManager := managers.NewRelationManager(userStorage, relationStorage)
+	accountManager := managers.NewAccountManager(userStorage, sessionStorage, postStorage)
+	reportManager := managers.NewReportManager(reportStorage, postStorage, postManager, userStorage, sessionStorage, auditStorage, categoryStorage)
+	auditManager := managers.NewAuditManager(auditStorage, userStorage)
+	suspensionManager := managers.NewSuspensionManager(userStorage, auditStorage)
+	categoryManager := managers.NewCategoryManager(categoryStorage, userStorage, auditStorage)
+	webhookManager := managers.NewWebhookManager(webhookStorage, userStorage, &http.Client{Timeout: webhookTimeout})
 
 	userHandler := handlers.UserHandler{
 		Logger:      logger,
@@ -71,30 +205,206 @@ func main() {
 		PostManager: postManager,
 	}
 
-	authMiddleware := middleware.NewAuthMiddleware(authManager, logger).GetHandler
+	eventHandler := handlers.EventHandler{
+		Logger:       logger,
+		EventManager: eventManager,
+	}
+
+	wsHandler := handlers.WebSocketHandler{
+		Logger:       logger,
+		AuthManager:  authManager,
+		EventManager: eventManager,
+		Origin:       os.Getenv("ORIGIN_ALLOWED"),
+	}
+
+	relationHandler := handlers.RelationHandler{
+		Logger:          logger,
+		RelationManager: relationManager,
+	}
+
+	attachmentHandler := handlers.AttachmentHandler{
+		Logger:            logger,
+		AttachmentManager: attachmentManager,
+	}
+
+	notificationHandler := handlers.NotificationHandler{
+		Logger:              logger,
+		NotificationManager: notificationManager,
+	}
+
+	reportHandler := handlers.ReportHandler{
+		Logger:        logger,
+		ReportManager: reportManager,
+	}
+
+	auditHandler := handlers.AuditHandler{
+		Logger:       logger,
+		AuditManager: auditManager,
+	}
+
+	suspensionHandler := handlers.SuspensionHandler{
+		Logger:            logger,
+		SuspensionManager: suspensionManager,
+	}
+
+	categoryHandler := handlers.CategoryHandler{
+		Logger:          logger,
+		CategoryManager: categoryManager,
+	}
+
+	webhookHandler := handlers.WebhookHandler{
+		Logger:         logger,
+		WebhookManager: webhookManager,
+	}
+
+	accountHandler := handlers.AccountHandler{
+		Logger:         logger,
+		AccountManager: accountManager,
+	}
+
+	// вход через OIDC включается, только если задан issuer
+	var oauthHandler *handlers.OAuthHandler
+	if issuer := os.Getenv("OIDC_ISSUER"); issuer != "" {
+		provider, err := managers.NewOIDCProvider(
+			oidcProviderName,
+			issuer,
+			os.Getenv("OIDC_CLIENT_ID"),
+			os.Getenv("OIDC_CLIENT_SECRET"),
+			os.Getenv("OIDC_REDIRECT_URL"),
+		)
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+
+		stateStorage := redis.NewOAuthStateStorage(redisClient)
+		identityStorage := mysql.NewIdentityStorage(dbMySQL, identityTable, userTable)
+
+		oauthHandler = &handlers.OAuthHandler{
+			Logger:       logger,
+			OAuthManager: managers.NewOAuthManager(authManager, provider, stateStorage, identityStorage),
+		}
+	}
+
+	authMiddlewares := middleware.NewAuthMiddleware(authManager, logger)
+	authMiddleware := authMiddlewares.GetHandler
+	optionalAuthMiddleware := authMiddlewares.GetOptionalHandler
+
+	go func() {
+		err := eventManager.Run()
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+	}()
+
+	for i := 0; i < unfurlWorkers; i++ {
+		go unfurlManager.Run(logger)
+	}
+
+	go postManager.RunScheduler(schedulerInterval, logger)
+	go postManager.RunPurge(purgeInterval, deletedRetention, logger)
+	go outboxManager.RunRelay(relayInterval, logger)
+
+	go func() {
+		err := webhookManager.Run(eventManager, logger)
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+	}()
+	go webhookManager.RunDelivery(webhookInterval, logger)
 
 	router := mux.NewRouter()
 
+	router.Handle("/metrics", metrics.Handler()).Methods(http.MethodGet)
+	metrics.RegisterSessions(sessionStorage.Count)
+
+	// события регистрируются первыми, чтобы их не перехватили пути с переменными
+	router.HandleFunc("/api/posts/events", eventHandler.AllEvents).Methods(http.MethodGet)
+	router.HandleFunc("/api/post/{postID}/events", eventHandler.PostEvents).Methods(http.MethodGet)
+	router.HandleFunc("/api/ws", wsHandler.Serve).Methods(http.MethodGet)
+
 	router.HandleFunc("/api/register", userHandler.Register).Methods(http.MethodPost)
 	router.HandleFunc("/api/login", userHandler.Login).Methods(http.MethodPost)
+	if oauthHandler != nil {
+		router.HandleFunc("/api/oauth/login", oauthHandler.Login).Methods(http.MethodGet)
+		router.HandleFunc("/api/oauth/callback", oauthHandler.Callback).Methods(http.MethodGet)
+	}
 
 	// пути, требующие аутентификацию
 	router.HandleFunc("/api/posts", authMiddleware(postHandler.Create)).Methods(http.MethodPost)
 	router.HandleFunc("/api/post/{postID}", authMiddleware(postHandler.Delete)).Methods(http.MethodDelete)
 	router.HandleFunc("/api/post/{postID}/{action}", authMiddleware(postHandler.UpdateVotes)).Methods(http.MethodGet)
 	router.HandleFunc("/api/post/{postID}", authMiddleware(postHandler.AddComment)).Methods(http.MethodPost)
+	router.HandleFunc("/api/post/{postID}/poll", authMiddleware(postHandler.Vote)).Methods(http.MethodPost)
+	router.HandleFunc("/api/post/{postID}/publish", authMiddleware(postHandler.Publish)).Methods(http.MethodPost)
+	router.HandleFunc("/api/post/{postID}/restore", authMiddleware(postHandler.Restore)).Methods(http.MethodPost)
+	router.HandleFunc("/api/post/{postID}/{state:pin|unpin|lock|unlock}", authMiddleware(postHandler.SetState)).Methods(http.MethodPost)
+	router.HandleFunc("/api/post/{postID}/report", authMiddleware(reportHandler.Report)).Methods(http.MethodPost)
+	router.HandleFunc("/api/post/{postID}/{commentID}/report", authMiddleware(reportHandler.Report)).Methods(http.MethodPost)
+	router.HandleFunc("/api/post/{postID}/{commentID}/restore", authMiddleware(postHandler.RestoreComment)).Methods(http.MethodPost)
+	router.HandleFunc("/api/post/{postID}/save", authMiddleware(postHandler.Save)).Methods(http.MethodPost)
+	router.HandleFunc("/api/post/{postID}/save", authMiddleware(postHandler.Unsave)).Methods(http.MethodDelete)
 	router.HandleFunc("/api/post/{postID}/{commentID}", authMiddleware(postHandler.DeleteComment)).Methods(http.MethodDelete)
 
-	router.HandleFunc("/api/posts/", postHandler.GetAll).Methods(http.MethodGet)
-	router.HandleFunc("/api/posts/{category}", postHandler.GetAllByCategory).Methods(http.MethodGet)
-	router.HandleFunc("/api/post/{postID}", postHandler.GetByID).Methods(http.MethodGet)
-	router.HandleFunc("/api/user/{username}", postHandler.GetAllByUser).Methods(http.MethodGet)
+	router.HandleFunc("/api/attachments", authMiddleware(attachmentHandler.Upload)).Methods(http.MethodPost)
+	router.HandleFunc("/api/attachments/{key}", attachmentHandler.Get).Methods(http.MethodGet)
+
+	router.HandleFunc("/api/feed", authMiddleware(postHandler.GetFeed)).Methods(http.MethodGet)
+	router.HandleFunc("/api/user/{username}/follow", authMiddleware(relationHandler.Follow)).Methods(http.MethodPost)
+	router.HandleFunc("/api/user/{username}/follow", authMiddleware(relationHandler.Unfollow)).Methods(http.MethodDelete)
+	router.HandleFunc("/api/category/{category}/subscribe", authMiddleware(relationHandler.Subscribe)).Methods(http.MethodPost)
+	router.HandleFunc("/api/category/{category}/subscribe", authMiddleware(relationHandler.Unsubscribe)).Methods(http.MethodDelete)
+	router.HandleFunc("/api/user/{username}/block", authMiddleware(relationHandler.Block)).Methods(http.MethodPost)
+	router.HandleFunc("/api/user/{username}/block", authMiddleware(relationHandler.Unblock)).Methods(http.MethodDelete)
+	router.HandleFunc("/api/category/{category}/mute", authMiddleware(relationHandler.Mute)).Methods(http.MethodPost)
+	router.HandleFunc("/api/category/{category}/mute", authMiddleware(relationHandler.Unmute)).Methods(http.MethodDelete)
+	router.HandleFunc("/api/category/{category}/moderators", categoryHandler.Moderators).Methods(http.MethodGet)
+	router.HandleFunc("/api/category/{category}/rules", categoryHandler.Rules).Methods(http.MethodGet)
+	router.HandleFunc("/api/me", authMiddleware(accountHandler.Delete)).Methods(http.MethodDelete)
+	router.HandleFunc("/api/me/export", authMiddleware(accountHandler.Export)).Methods(http.MethodGet)
+	router.HandleFunc("/api/me/saved", authMiddleware(postHandler.GetSaved)).Methods(http.MethodGet)
+	router.HandleFunc("/api/me/drafts", authMiddleware(postHandler.GetDrafts)).Methods(http.MethodGet)
+	router.HandleFunc("/api/mod/queue", authMiddleware(reportHandler.Queue)).Methods(http.MethodGet)
+	router.HandleFunc("/api/mod/queue/resolve", authMiddleware(reportHandler.Resolve)).Methods(http.MethodPost)
+	router.HandleFunc("/api/mod/decisions", authMiddleware(reportHandler.Decisions)).Methods(http.MethodGet)
+	router.HandleFunc("/api/mod/trash", authMiddleware(postHandler.GetDeleted)).Methods(http.MethodGet)
+	router.HandleFunc("/api/mod/audit", authMiddleware(auditHandler.Find)).Methods(http.MethodGet)
+	router.HandleFunc("/api/mod/audit/export", authMiddleware(auditHandler.Export)).Methods(http.MethodGet)
+	router.HandleFunc("/api/admin/users/{username}/suspend", authMiddleware(suspensionHandler.Suspend)).Methods(http.MethodPost)
+	router.HandleFunc("/api/admin/users/{username}/unsuspend", authMiddleware(suspensionHandler.Unsuspend)).Methods(http.MethodPost)
+	router.HandleFunc("/api/admin/users/{username}/{action:shadowban|unshadowban}", authMiddleware(suspensionHandler.Shadowban)).Methods(http.MethodPost)
+	router.HandleFunc("/api/admin/categories/{category}/moderators/{username}", authMiddleware(categoryHandler.AddModerator)).Methods(http.MethodPost)
+	router.HandleFunc("/api/admin/categories/{category}/moderators/{username}", authMiddleware(categoryHandler.RemoveModerator)).Methods(http.MethodDelete)
+	router.HandleFunc("/api/admin/categories/{category}/rules", authMiddleware(categoryHandler.SetRules)).Methods(http.MethodPost)
+	router.HandleFunc("/api/admin/webhooks", authMiddleware(webhookHandler.Create)).Methods(http.MethodPost)
+	router.HandleFunc("/api/admin/webhooks", authMiddleware(webhookHandler.Find)).Methods(http.MethodGet)
+	router.HandleFunc("/api/admin/webhooks/{webhookID}", authMiddleware(webhookHandler.Delete)).Methods(http.MethodDelete)
+	router.HandleFunc("/api/admin/webhooks/{webhookID}/{action:enable|disable}", authMiddleware(webhookHandler.SetActive)).Methods(http.MethodPost)
+	router.HandleFunc("/api/admin/webhooks/{webhookID}/deliveries", authMiddleware(webhookHandler.Deliveries)).Methods(http.MethodGet)
+	router.HandleFunc("/api/admin/webhooks/{webhookID}/deliveries/{deliveryID}/redeliver", authMiddleware(webhookHandler.Redeliver)).Methods(http.MethodPost)
+	router.HandleFunc("/api/notifications", authMiddleware(notificationHandler.List)).Methods(http.MethodGet)
+	router.HandleFunc("/api/notifications/read", authMiddleware(notificationHandler.MarkAllRead)).Methods(http.MethodPost)
+	router.HandleFunc("/api/notifications/preferences", authMiddleware(notificationHandler.GetPreferences)).Methods(http.MethodGet)
+	router.HandleFunc("/api/notifications/preferences", authMiddleware(notificationHandler.SetPreferences)).Methods(http.MethodPost)
+	router.HandleFunc("/api/notifications/{notificationID}/read", authMiddleware(notificationHandler.MarkRead)).Methods(http.MethodPost)
+
+	// публичные пути, пользователь нужен только для скрытия заблокированных
+	router.HandleFunc("/api/posts/", optionalAuthMiddleware(postHandler.GetAll)).Methods(http.MethodGet)
+	router.HandleFunc("/api/posts/{category}", optionalAuthMiddleware(postHandler.GetAllByCategory)).Methods(http.MethodGet)
+	router.HandleFunc("/api/post/{postID}", optionalAuthMiddleware(postHandler.GetByID)).Methods(http.MethodGet)
+	router.HandleFunc("/api/user/{username}", optionalAuthMiddleware(postHandler.GetAllByUser)).Methods(http.MethodGet)
 
 	panicMiddleware := middleware.Panic(logger, router)
+	// метрики снаружи panic, чтобы учитывались и ответы 500 после паники
+	// трассировка снаружи panic, чтобы спан запроса закрывался и после паники
+	tracingMiddleware := middleware.Tracing(router, panicMiddleware)
+	metricsMiddleware := middleware.Metrics(router, tracingMiddleware)
 
 	origin := os.Getenv("ORIGIN_ALLOWED")
 
-	headersOk := handls.AllowedHeaders([]string{"Authorization","Content-Type", "application/json"})
+	headersOk := handls.AllowedHeaders([]string{"Authorization", "Content-Type", "application/json", "traceparent", "tracestate"})
 	originsOk := handls.AllowedOrigins([]string{origin})
 	methodsOk := handls.AllowedMethods([]string{"GET", "POST", "DELETE"})
 
@@ -103,8 +413,20 @@ func main() {
 		"starting server",
 		"address", addr,
 	)
-	err = http.ListenAndServe(addr, handls.CORS(headersOk, originsOk, methodsOk)(panicMiddleware))
+	err = http.ListenAndServe(addr, handls.CORS(headersOk, originsOk, methodsOk)(metricsMiddleware))
 	if err != nil {
 		logger.Error(err.Error())
 	}
 }
+
+// Разбирает список через запятую из переменной окружения, пустая строка - пустой список
+func splitList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,48 @@
+package filter
+
+import (
+	"forum/internal/models"
+)
+
+// Проверка содержимого, nil - фильтр содержимое пропускает
+type Filter interface {
+	Check(*models.Content) (*models.FilterVerdict, error)
+}
+
+/*
+Прогоняет содержимое через фильтры по порядку. Первый отказ прерывает проверку,
+задержка запоминается, но следующие фильтры все равно выполняются, т.к. могут отклонить содержимое.
+*/
+type Pipeline struct {
+	filters []Filter
+}
+
+func NewPipeline(filters ...Filter) *Pipeline {
+	return &Pipeline{
+		filters: filters,
+	}
+}
+
+// Возвращает итоговое решение, без сработавших фильтров - allow
+func (p *Pipeline) Check(content *models.Content) (*models.FilterVerdict, error) {
+	var held *models.FilterVerdict
+	for _, filter := range p.filters {
+		verdict, err := filter.Check(content)
+		if err != nil {
+			return nil, err
+		}
+		if verdict == nil {
+			continue
+		}
+		if verdict.Action == models.FilterReject {
+			return verdict, nil
+		}
+		if held == nil {
+			held = verdict
+		}
+	}
+	if held != nil {
+		return held, nil
+	}
+	return &models.FilterVerdict{Action: models.FilterAllow}, nil
+}
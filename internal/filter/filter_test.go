@@ -0,0 +1,172 @@
+package filter
+
+import (
+	"errors"
+	"forum/internal/models"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryHistory struct {
+	contents []*models.Content
+	since    time.Time
+	err      error
+}
+
+func (mh *memoryHistory) Recent(authorID primitive.ObjectID, since time.Time) ([]*models.Content, error) {
+	mh.since = since
+	return mh.contents, mh.err
+}
+
+// Фильтр с заранее заданным решением
+type fixedFilter struct {
+	verdict *models.FilterVerdict
+	calls   int
+}
+
+func (ff *fixedFilter) Check(content *models.Content) (*models.FilterVerdict, error) {
+	ff.calls++
+	return ff.verdict, nil
+}
+
+func TestPipeline(t *testing.T) {
+	content := &models.Content{Text: "text"}
+
+	verdict, err := NewPipeline().Check(content)
+	if err != nil || verdict.Action != models.FilterAllow {
+		t.Errorf("want allow, have %+v, %v", verdict, err)
+	}
+
+	hold := &fixedFilter{verdict: &models.FilterVerdict{Action: models.FilterHold, Reason: "hold"}}
+	secondHold := &fixedFilter{verdict: &models.FilterVerdict{Action: models.FilterHold, Reason: "second"}}
+	verdict, err = NewPipeline(&fixedFilter{}, hold, secondHold).Check(content)
+	if err != nil || verdict.Action != models.FilterHold || verdict.Reason != "hold" {
+		t.Errorf("want first hold, have %+v, %v", verdict, err)
+	}
+
+	// отказ после задержки важнее и прерывает проверку
+	reject := &fixedFilter{verdict: &models.FilterVerdict{Action: models.FilterReject, Reason: "reject"}}
+	last := &fixedFilter{}
+	verdict, err = NewPipeline(hold, reject, last).Check(content)
+	if err != nil || verdict.Action != models.FilterReject || last.calls != 0 {
+		t.Errorf("want reject without next filters, have %+v, %v, calls %d", verdict, err, last.calls)
+	}
+
+	history := &memoryHistory{err: errors.New("storage error")}
+	_, err = NewPipeline(NewDuplicateFilter(history, time.Hour, models.FilterReject)).Check(content)
+	if err != history.err {
+		t.Errorf("want storage error, have %v", err)
+	}
+}
+
+func TestWordFilter(t *testing.T) {
+	_, err := NewWordFilter([]string{"("}, models.FilterReject)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+
+	wordFilter, err := NewWordFilter([]string{`\bcasino\b`, `fr[e3]{2} money`}, models.FilterReject)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		text    string
+		matched bool
+	}{
+		{"Best CASINO in town", true},
+		{"get FR33 money now", true},
+		{"casinos are not matched as a whole word", false},
+		{"nothing to see here", false},
+	}
+	for _, item := range cases {
+		verdict, err := wordFilter.Check(&models.Content{Text: item.text})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (verdict != nil) != item.matched {
+			t.Errorf("%q: want matched %v, have %+v", item.text, item.matched, verdict)
+		}
+	}
+}
+
+func TestLoadPatterns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.txt")
+	err := os.WriteFile(path, []byte("# spam\ncasino\n\n  viagra  \n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	patterns, err := LoadPatterns(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(patterns) != 2 || patterns[0] != "casino" || patterns[1] != "viagra" {
+		t.Errorf("unexpected patterns %q", patterns)
+	}
+}
+
+func TestLinkFilter(t *testing.T) {
+	linkFilter := NewLinkFilter(2, models.FilterHold)
+
+	verdict, _ := linkFilter.Check(&models.Content{Text: "see https://a.com and http://b.com"})
+	if verdict != nil {
+		t.Errorf("want allowed, have %+v", verdict)
+	}
+	verdict, _ = linkFilter.Check(&models.Content{Text: "https://a.com https://b.com HTTPS://c.com"})
+	if verdict == nil || verdict.Action != models.FilterHold {
+		t.Errorf("want hold, have %+v", verdict)
+	}
+}
+
+func TestDuplicateFilter(t *testing.T) {
+	now := time.Now()
+	history := &memoryHistory{contents: []*models.Content{{Text: "Buy   NOW\ncheap"}}}
+	duplicateFilter := NewDuplicateFilter(history, time.Hour, models.FilterReject)
+
+	verdict, err := duplicateFilter.Check(&models.Content{Text: "buy now cheap", Created: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verdict == nil || verdict.Action != models.FilterReject {
+		t.Errorf("want reject, have %+v", verdict)
+	}
+	if !history.since.Equal(now.Add(-time.Hour)) {
+		t.Errorf("want history since %v, have %v", now.Add(-time.Hour), history.since)
+	}
+
+	verdict, _ = duplicateFilter.Check(&models.Content{Text: "something else", Created: now})
+	if verdict != nil {
+		t.Errorf("want allowed, have %+v", verdict)
+	}
+}
+
+func TestAccountAgeFilter(t *testing.T) {
+	now := time.Now()
+	history := &memoryHistory{contents: []*models.Content{{Text: "one"}, {Text: "two"}}}
+	accountFilter := NewAccountAgeFilter(history, 72*time.Hour, 24*time.Hour, 2, models.FilterHold)
+
+	newAuthor := primitive.NewObjectIDFromTimestamp(now.Add(-time.Hour))
+	verdict, err := accountFilter.Check(&models.Content{AuthorID: newAuthor, Created: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verdict == nil || verdict.Action != models.FilterHold {
+		t.Errorf("want hold for new account, have %+v", verdict)
+	}
+
+	history.contents = history.contents[:1]
+	verdict, _ = accountFilter.Check(&models.Content{AuthorID: newAuthor, Created: now})
+	if verdict != nil {
+		t.Errorf("want allowed under limit, have %+v", verdict)
+	}
+
+	history.contents = append(history.contents, &models.Content{Text: "two"})
+	oldAuthor := primitive.NewObjectIDFromTimestamp(now.Add(-96 * time.Hour))
+	verdict, _ = accountFilter.Check(&models.Content{AuthorID: oldAuthor, Created: now})
+	if verdict != nil {
+		t.Errorf("want allowed for old account, have %+v", verdict)
+	}
+}
@@ -0,0 +1,91 @@
+package filter
+
+import (
+	"fmt"
+	"forum/internal/models"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type historyRepo interface {
+	// посты и комментарии автора, созданные не раньше since
+	Recent(primitive.ObjectID, time.Time) ([]*models.Content, error)
+}
+
+// Приводит текст к виду, в котором не различаются регистр и пробелы
+func normalize(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
+
+// Срабатывает на текст, повторяющий пост или комментарий автора за последние window
+type DuplicateFilter struct {
+	history historyRepo
+	window  time.Duration
+	action  string
+}
+
+func NewDuplicateFilter(history historyRepo, window time.Duration, action string) *DuplicateFilter {
+	return &DuplicateFilter{
+		history: history,
+		window:  window,
+		action:  action,
+	}
+}
+
+func (df *DuplicateFilter) Check(content *models.Content) (*models.FilterVerdict, error) {
+	recent, err := df.history.Recent(content.AuthorID, content.Created.Add(-df.window))
+	if err != nil {
+		return nil, err
+	}
+	text := normalize(content.Text)
+	for _, item := range recent {
+		if normalize(item.Text) == text {
+			return &models.FilterVerdict{
+				Action: df.action,
+				Reason: "duplicates your recent post or comment",
+			}, nil
+		}
+	}
+	return nil, nil
+}
+
+/*
+Ограничивает число постов и комментариев за window для аккаунтов моложе age.
+Возраст аккаунта берется из id пользователя: ObjectID содержит время создания.
+*/
+type AccountAgeFilter struct {
+	history historyRepo
+	age     time.Duration
+	window  time.Duration
+	limit   int
+	action  string
+}
+
+func NewAccountAgeFilter(history historyRepo, age, window time.Duration, limit int, action string) *AccountAgeFilter {
+	return &AccountAgeFilter{
+		history: history,
+		age:     age,
+		window:  window,
+		limit:   limit,
+		action:  action,
+	}
+}
+
+func (af *AccountAgeFilter) Check(content *models.Content) (*models.FilterVerdict, error) {
+	if content.Created.Sub(content.AuthorID.Timestamp()) >= af.age {
+		return nil, nil
+	}
+	recent, err := af.history.Recent(content.AuthorID, content.Created.Add(-af.window))
+	if err != nil {
+		return nil, err
+	}
+	if len(recent) < af.limit {
+		return nil, nil
+	}
+	return &models.FilterVerdict{
+		Action: af.action,
+		Reason: fmt.Sprintf("new accounts can post at most %d times per %s", af.limit, af.window),
+	}, nil
+}
@@ -0,0 +1,33 @@
+package filter
+
+import (
+	"fmt"
+	"forum/internal/models"
+	"regexp"
+)
+
+var linkPattern = regexp.MustCompile(`(?i)\bhttps?://\S+`)
+
+// Срабатывает, если ссылок в тексте больше max
+type LinkFilter struct {
+	max    int
+	action string
+}
+
+func NewLinkFilter(max int, action string) *LinkFilter {
+	return &LinkFilter{
+		max:    max,
+		action: action,
+	}
+}
+
+func (lf *LinkFilter) Check(content *models.Content) (*models.FilterVerdict, error) {
+	count := len(linkPattern.FindAllStringIndex(content.Text, -1))
+	if count <= lf.max {
+		return nil, nil
+	}
+	return &models.FilterVerdict{
+		Action: lf.action,
+		Reason: fmt.Sprintf("too many links: %d, at most %d allowed", count, lf.max),
+	}, nil
+}
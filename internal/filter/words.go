@@ -0,0 +1,67 @@
+package filter
+
+import (
+	"bufio"
+	"fmt"
+	"forum/internal/models"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Срабатывает на текст, совпавший с одним из запрещенных шаблонов
+type WordFilter struct {
+	patterns []*regexp.Regexp
+	action   string
+}
+
+/*
+patterns - регулярные выражения, регистр не учитывается.
+Обычное слово тоже шаблон, для совпадения только целым словом его нужно обернуть в \b.
+*/
+func NewWordFilter(patterns []string, action string) (*WordFilter, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, fmt.Errorf("bad banned word pattern %q: %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return &WordFilter{
+		patterns: compiled,
+		action:   action,
+	}, nil
+}
+
+func (wf *WordFilter) Check(content *models.Content) (*models.FilterVerdict, error) {
+	for _, re := range wf.patterns {
+		if match := re.FindString(content.Text); match != "" {
+			return &models.FilterVerdict{
+				Action: wf.action,
+				Reason: fmt.Sprintf("contains banned word %q", match),
+			}, nil
+		}
+	}
+	return nil, nil
+}
+
+// Читает шаблоны из файла по одному на строку, пустые строки и строки с # пропускаются
+func LoadPatterns(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	patterns := make([]string, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns, scanner.Err()
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"forum/internal/handlers/utils"
+	"forum/internal/models"
+	"log/slog"
+	"net/http"
+)
+
+type accountManager interface {
+	Delete(*models.Author) error
+	Export(*models.Author) (*models.UserExport, error)
+}
+
+type AccountHandler struct {
+	Logger         *slog.Logger
+	AccountManager accountManager
+}
+
+// Хендлер удаления аккаунта текущего пользователя
+func (ah *AccountHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(ah.Logger, r.URL.Path, r.Method)
+
+	author, ok := r.Context().Value(models.CtxKey("user")).(*models.Author)
+	if !ok {
+		msg.Set("bad context value by key user", http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	err := ah.AccountManager.Delete(author)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	msg.Set("success", http.StatusOK)
+	utils.WriteData(w, msg, map[string]interface{}{
+		"message": "success",
+	})
+}
+
+/*
+Хендлер выгрузки данных текущего пользователя.
+По умолчанию отдает zip-архив, с параметром format=json - один JSON документ.
+*/
+func (ah *AccountHandler) Export(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(ah.Logger, r.URL.Path, r.Method)
+
+	author, ok := r.Context().Value(models.CtxKey("user")).(*models.Author)
+	if !ok {
+		msg.Set("bad context value by key user", http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	export, err := ah.AccountManager.Export(author)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	msg.Set("success", http.StatusOK)
+	if r.URL.Query().Get("format") == "json" {
+		utils.WriteData(w, msg, export)
+		return
+	}
+	utils.WriteZip(w, msg, "export.zip", map[string]any{
+		"user.json":     export.User,
+		"posts.json":    export.Posts,
+		"comments.json": export.Comments,
+		"votes.json":    export.Votes,
+	})
+}
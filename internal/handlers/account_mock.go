@@ -0,0 +1,64 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/handlers/account.go
+
+// Package handlers is a generated GoMock package.
+package handlers
+
+import (
+	models "forum/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockaccountManager is a mock of accountManager interface.
+type MockaccountManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockaccountManagerMockRecorder
+}
+
+// MockaccountManagerMockRecorder is the mock recorder for MockaccountManager.
+type MockaccountManagerMockRecorder struct {
+	mock *MockaccountManager
+}
+
+// NewMockaccountManager creates a new mock instance.
+func NewMockaccountManager(ctrl *gomock.Controller) *MockaccountManager {
+	mock := &MockaccountManager{ctrl: ctrl}
+	mock.recorder = &MockaccountManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockaccountManager) EXPECT() *MockaccountManagerMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockaccountManager) Delete(arg0 *models.Author) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockaccountManagerMockRecorder) Delete(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockaccountManager)(nil).Delete), arg0)
+}
+
+// Export mocks base method.
+func (m *MockaccountManager) Export(arg0 *models.Author) (*models.UserExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", arg0)
+	ret0, _ := ret[0].(*models.UserExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Export indicates an expected call of Export.
+func (mr *MockaccountManagerMockRecorder) Export(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockaccountManager)(nil).Export), arg0)
+}
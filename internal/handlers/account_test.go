@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"forum/internal/handlers/utils"
+	"forum/internal/models"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestAccountDelete(t *testing.T) {
+	logger := slog.New(utils.DummyLogger{})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	accountManager := NewMockaccountManager(ctrl)
+
+	accountHandler := &AccountHandler{
+		Logger:         logger,
+		AccountManager: accountManager,
+	}
+
+	path := "/api/me"
+	method := http.MethodDelete
+	handler := accountHandler.Delete
+
+	author := getDefaultAuthor()
+
+	// good response
+	accountManager.EXPECT().Delete(author).Return(nil)
+
+	request := httptest.NewRequest(method, path, nil)
+	ctx := context.WithValue(request.Context(), models.CtxKey("user"), author)
+
+	response := map[string]interface{}{}
+
+	test := utils.TestRequest{
+		Handler:        handler,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusOK,
+		ResponsePtr:    &response,
+	}
+
+	err := utils.SendTestRequest(test)
+	if err != nil {
+		t.Fatalf("expected nil, but was %v", err)
+	}
+
+	// Context error
+	test = utils.TestRequest{
+		Handler:        handler,
+		Request:        httptest.NewRequest(method, path, nil),
+		ExpectedStatus: http.StatusUnprocessableEntity,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+
+	// Delete error
+	accountManager.EXPECT().Delete(author).Return(fmt.Errorf("no user found"))
+
+	request = httptest.NewRequest(method, path, nil)
+	ctx = context.WithValue(request.Context(), models.CtxKey("user"), author)
+
+	test = utils.TestRequest{
+		Handler:        handler,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusInternalServerError,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+}
+
+func TestAccountExport(t *testing.T) {
+	logger := slog.New(utils.DummyLogger{})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	accountManager := NewMockaccountManager(ctrl)
+
+	accountHandler := &AccountHandler{
+		Logger:         logger,
+		AccountManager: accountManager,
+	}
+
+	path := "/api/me/export"
+	method := http.MethodGet
+	handler := accountHandler.Export
+
+	author := getDefaultAuthor()
+	post := getDefaultPost(author)
+	export := &models.UserExport{
+		User:     *author,
+		Posts:    []*models.Post{post},
+		Comments: []models.ExportComment{},
+		Votes:    []models.ExportVote{{PostID: post.ID, Vote: 1}},
+	}
+
+	// json response
+	accountManager.EXPECT().Export(author).Return(export, nil)
+
+	request := httptest.NewRequest(method, path+"?format=json", nil)
+	ctx := context.WithValue(request.Context(), models.CtxKey("user"), author)
+
+	response := &models.UserExport{}
+
+	test := utils.TestRequest{
+		Handler:        handler,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusOK,
+		ResponsePtr:    response,
+	}
+
+	err := utils.SendTestRequest(test)
+	if err != nil {
+		t.Fatalf("expected nil, but was %v", err)
+	}
+	if !reflect.DeepEqual(response, export) {
+		t.Errorf("\nwant: %v\nhave: %v", export, response)
+	}
+
+	// zip response
+	accountManager.EXPECT().Export(author).Return(export, nil)
+
+	request = httptest.NewRequest(method, path, nil)
+	ctx = context.WithValue(request.Context(), models.CtxKey("user"), author)
+
+	w := httptest.NewRecorder()
+	handler(w, request.WithContext(ctx))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	body, err := io.ReadAll(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0)
+	for _, file := range archive.File {
+		names = append(names, file.Name)
+	}
+	expectedNames := []string{"comments.json", "posts.json", "user.json", "votes.json"}
+	if !reflect.DeepEqual(names, expectedNames) {
+		t.Errorf("\nwant: %v\nhave: %v", expectedNames, names)
+	}
+
+	// Export error
+	accountManager.EXPECT().Export(author).Return(nil, fmt.Errorf("some error when try to export"))
+
+	request = httptest.NewRequest(method, path, nil)
+	ctx = context.WithValue(request.Context(), models.CtxKey("user"), author)
+
+	test = utils.TestRequest{
+		Handler:        handler,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusInternalServerError,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+}
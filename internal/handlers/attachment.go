@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"errors"
+	"forum/internal/handlers/utils"
+	"forum/internal/models"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+var (
+	// тело запроса больше файла на размер заголовков multipart
+	maxUploadBody int64 = 11 << 20
+	uploadField         = "file"
+)
+
+type attachmentManager interface {
+	Upload(*models.Author, io.Reader) (*models.Attachment, error)
+	Open(string) (io.ReadCloser, string, error)
+}
+
+type AttachmentHandler struct {
+	Logger            *slog.Logger
+	AttachmentManager attachmentManager
+}
+
+// Хендлер загрузки изображения, файл передается в поле file формы multipart/form-data
+func (ah *AttachmentHandler) Upload(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(ah.Logger, r.URL.Path, r.Method)
+
+	author, ok := r.Context().Value(models.CtxKey("user")).(*models.Author)
+	if !ok {
+		msg.Set("bad context value by key user", http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadBody)
+	file, _, err := r.FormFile(uploadField)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			msg.Set(err.Error(), http.StatusRequestEntityTooLarge)
+		} else {
+			msg.Set(err.Error(), http.StatusBadRequest)
+		}
+		utils.WriteError(w, msg)
+		return
+	}
+	defer file.Close()
+
+	attachment, err := ah.AttachmentManager.Upload(author, file)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	msg.Set("success", http.StatusOK)
+	utils.WriteData(w, msg, attachment.Image())
+}
+
+// Хендлер, отдающий загруженный файл с ключом key
+func (ah *AttachmentHandler) Get(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(ah.Logger, r.URL.Path, r.Method)
+
+	file, contentType, err := ah.AttachmentManager.Open(mux.Vars(r)["key"])
+	if err != nil {
+		msg.Set(err.Error(), http.StatusNotFound)
+		utils.WriteError(w, msg)
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	// содержимое по ключу никогда не меняется
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	_, err = io.Copy(w, file)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusInternalServerError)
+		msg.Error()
+		return
+	}
+	msg.Set("success", http.StatusOK)
+	msg.Info()
+}
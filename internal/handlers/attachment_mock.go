@@ -0,0 +1,67 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/handlers/attachment.go
+
+// Package handlers is a generated GoMock package.
+package handlers
+
+import (
+	models "forum/internal/models"
+	io "io"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockattachmentManager is a mock of attachmentManager interface.
+type MockattachmentManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockattachmentManagerMockRecorder
+}
+
+// MockattachmentManagerMockRecorder is the mock recorder for MockattachmentManager.
+type MockattachmentManagerMockRecorder struct {
+	mock *MockattachmentManager
+}
+
+// NewMockattachmentManager creates a new mock instance.
+func NewMockattachmentManager(ctrl *gomock.Controller) *MockattachmentManager {
+	mock := &MockattachmentManager{ctrl: ctrl}
+	mock.recorder = &MockattachmentManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockattachmentManager) EXPECT() *MockattachmentManagerMockRecorder {
+	return m.recorder
+}
+
+// Open mocks base method.
+func (m *MockattachmentManager) Open(arg0 string) (io.ReadCloser, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Open", arg0)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Open indicates an expected call of Open.
+func (mr *MockattachmentManagerMockRecorder) Open(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Open", reflect.TypeOf((*MockattachmentManager)(nil).Open), arg0)
+}
+
+// Upload mocks base method.
+func (m *MockattachmentManager) Upload(arg0 *models.Author, arg1 io.Reader) (*models.Attachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upload", arg0, arg1)
+	ret0, _ := ret[0].(*models.Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Upload indicates an expected call of Upload.
+func (mr *MockattachmentManagerMockRecorder) Upload(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upload", reflect.TypeOf((*MockattachmentManager)(nil).Upload), arg0, arg1)
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"forum/internal/handlers/utils"
+	"forum/internal/models"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newUploadRequest(t *testing.T, field string, content []byte) *http.Request {
+	t.Helper()
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile(field, "image.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(content)
+	writer.Close()
+
+	request := httptest.NewRequest(http.MethodPost, "/api/attachments", body)
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+	return request
+}
+
+func TestUpload(t *testing.T) {
+	logger := slog.New(utils.DummyLogger{})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	attachmentManager := NewMockattachmentManager(ctrl)
+
+	attachmentHandler := &AttachmentHandler{
+		Logger:            logger,
+		AttachmentManager: attachmentManager,
+	}
+
+	author := getDefaultAuthor()
+	attachment := &models.Attachment{
+		ID:       primitive.NewObjectID(),
+		Key:      "image.png",
+		ThumbKey: "image_thumb.png",
+		Width:    10,
+		Height:   20,
+	}
+
+	// good response
+	attachmentManager.EXPECT().Upload(author, gomock.Any()).DoAndReturn(
+		func(author *models.Author, data io.Reader) (*models.Attachment, error) {
+			content, _ := io.ReadAll(data)
+			if string(content) != "png data" {
+				t.Errorf("unexpected upload content %q", content)
+			}
+			return attachment, nil
+		},
+	)
+
+	request := newUploadRequest(t, uploadField, []byte("png data"))
+	ctx := context.WithValue(request.Context(), models.CtxKey("user"), author)
+
+	response := &models.Image{}
+	test := utils.TestRequest{
+		Handler:        attachmentHandler.Upload,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusOK,
+		ResponsePtr:    response,
+	}
+
+	err := utils.SendTestRequest(test)
+	if err != nil {
+		t.Fatalf("expected nil, but was %v", err)
+	}
+	if *response != *attachment.Image() {
+		t.Errorf("\nwant: %v\nhave: %v", attachment.Image(), response)
+	}
+
+	// Manager error
+	attachmentManager.EXPECT().Upload(author, gomock.Any()).Return(nil, fmt.Errorf("unsupported image type"))
+
+	request = newUploadRequest(t, uploadField, []byte("text"))
+	test = utils.TestRequest{
+		Handler:        attachmentHandler.Upload,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusUnprocessableEntity,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+
+	// No file
+	request = newUploadRequest(t, "other", []byte("png data"))
+	test = utils.TestRequest{
+		Handler:        attachmentHandler.Upload,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusBadRequest,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+
+	// Too large
+	limit := maxUploadBody
+	maxUploadBody = 16
+	defer func() { maxUploadBody = limit }()
+
+	request = newUploadRequest(t, uploadField, []byte(strings.Repeat("x", 64)))
+	test = utils.TestRequest{
+		Handler:        attachmentHandler.Upload,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusRequestEntityTooLarge,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+}
+
+func TestGetAttachment(t *testing.T) {
+	logger := slog.New(utils.DummyLogger{})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	attachmentManager := NewMockattachmentManager(ctrl)
+
+	attachmentHandler := &AttachmentHandler{
+		Logger:            logger,
+		AttachmentManager: attachmentManager,
+	}
+
+	// good response
+	attachmentManager.EXPECT().Open("image.png").Return(io.NopCloser(strings.NewReader("png data")), "image/png", nil)
+
+	request := httptest.NewRequest(http.MethodGet, "/api/attachments/image.png", nil)
+	request = mux.SetURLVars(request, map[string]string{"key": "image.png"})
+
+	w := httptest.NewRecorder()
+	attachmentHandler.Get(w, request)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if w.Header().Get("Content-Type") != "image/png" || w.Header().Get("X-Content-Type-Options") != "nosniff" {
+		t.Errorf("unexpected headers %v", w.Header())
+	}
+	if w.Body.String() != "png data" {
+		t.Errorf("unexpected body %q", w.Body.String())
+	}
+
+	// Not found
+	attachmentManager.EXPECT().Open("missing.png").Return(nil, "", fmt.Errorf("no blob found"))
+
+	request = httptest.NewRequest(http.MethodGet, "/api/attachments/missing.png", nil)
+	request = mux.SetURLVars(request, map[string]string{"key": "missing.png"})
+
+	w = httptest.NewRecorder()
+	attachmentHandler.Get(w, request)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
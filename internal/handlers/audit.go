@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"forum/internal/handlers/utils"
+	"forum/internal/models"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	errBadAuditFilter = errors.New("bad audit filter")
+
+	auditCSVHeader = []string{"id", "created", "actorID", "actorName", "action", "targetType", "targetID", "reason", "before"}
+)
+
+type auditManager interface {
+	Find(*models.Author, models.AuditFilter, models.Page) ([]*models.AuditEntry, error)
+	Export(*models.Author, models.AuditFilter, func(*models.AuditEntry) error) error
+}
+
+type AuditHandler struct {
+	Logger       *slog.Logger
+	AuditManager auditManager
+}
+
+/*
+Читает фильтр журнала из query: actor - id модератора, action, targetType, targetID,
+from и to - границы периода в RFC3339.
+*/
+func readAuditFilter(r *http.Request) (models.AuditFilter, error) {
+	query := r.URL.Query()
+	filter := models.AuditFilter{
+		Action:     query.Get("action"),
+		TargetType: query.Get("targetType"),
+		TargetID:   query.Get("targetID"),
+	}
+
+	if value := query.Get("actor"); value != "" {
+		actorID, err := primitive.ObjectIDFromHex(value)
+		if err != nil {
+			return filter, errBadAuditFilter
+		}
+		filter.ActorID = &actorID
+	}
+	for key, bound := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		value := query.Get(key)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return filter, errBadAuditFilter
+		}
+		*bound = &t
+	}
+	return filter, nil
+}
+
+// Хендлер просмотра журнала модерации с фильтрами
+func (ah *AuditHandler) Find(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(ah.Logger, r.URL.Path, r.Method)
+
+	page, err := utils.ReadPage(r)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusBadRequest)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	filter, err := readAuditFilter(r)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusBadRequest)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	moderator, ok := r.Context().Value(models.CtxKey("user")).(*models.Author)
+	if !ok {
+		msg.Set("bad context value by key user", http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	entries, err := ah.AuditManager.Find(moderator, filter, page)
+	if err != nil {
+		msg.Set(err.Error(), postErrorStatus(err, http.StatusInternalServerError))
+		utils.WriteError(w, msg)
+		return
+	}
+
+	msg.Set("success", http.StatusOK)
+	utils.WriteData(w, msg, entries)
+}
+
+// Хендлер выгрузки журнала модерации в CSV, фильтры те же, что и у просмотра
+func (ah *AuditHandler) Export(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(ah.Logger, r.URL.Path, r.Method)
+
+	filter, err := readAuditFilter(r)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusBadRequest)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	moderator, ok := r.Context().Value(models.CtxKey("user")).(*models.Author)
+	if !ok {
+		msg.Set("bad context value by key user", http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	// записи пишутся в ответ по одной, ответ начинается с первой записью, чтобы ошибку доступа вернуть статусом
+	var writer *csv.Writer
+	start := func() error {
+		writer = utils.NewCSVWriter(w, "audit.csv")
+		return writer.Write(auditCSVHeader)
+	}
+	err = ah.AuditManager.Export(moderator, filter, func(entry *models.AuditEntry) error {
+		if writer == nil {
+			err := start()
+			if err != nil {
+				return err
+			}
+		}
+		return writer.Write([]string{
+			entry.ID.Hex(),
+			entry.Created.UTC().Format(time.RFC3339),
+			entry.ActorID.Hex(),
+			utils.CSVSafe(entry.ActorName),
+			entry.Action,
+			entry.TargetType,
+			entry.TargetID,
+			utils.CSVSafe(entry.Reason),
+			utils.CSVSafe(entry.Before),
+		})
+	})
+	if err != nil && writer == nil {
+		msg.Set(err.Error(), postErrorStatus(err, http.StatusInternalServerError))
+		utils.WriteError(w, msg)
+		return
+	}
+	// пустой журнал - только заголовок
+	if writer == nil {
+		err = start()
+	}
+	writer.Flush()
+	err = errors.Join(err, writer.Error())
+	// ответ уже начат, ошибку остается только записать в лог, выгрузка оборвется
+	if err != nil {
+		msg.Set(err.Error(), http.StatusInternalServerError)
+		msg.Error()
+		return
+	}
+	msg.Set("success", http.StatusOK)
+	msg.Info()
+}
@@ -0,0 +1,64 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/handlers/audit.go
+
+// Package handlers is a generated GoMock package.
+package handlers
+
+import (
+	models "forum/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockauditManager is a mock of auditManager interface.
+type MockauditManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockauditManagerMockRecorder
+}
+
+// MockauditManagerMockRecorder is the mock recorder for MockauditManager.
+type MockauditManagerMockRecorder struct {
+	mock *MockauditManager
+}
+
+// NewMockauditManager creates a new mock instance.
+func NewMockauditManager(ctrl *gomock.Controller) *MockauditManager {
+	mock := &MockauditManager{ctrl: ctrl}
+	mock.recorder = &MockauditManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockauditManager) EXPECT() *MockauditManagerMockRecorder {
+	return m.recorder
+}
+
+// Export mocks base method.
+func (m *MockauditManager) Export(arg0 *models.Author, arg1 models.AuditFilter, arg2 func(*models.AuditEntry) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Export indicates an expected call of Export.
+func (mr *MockauditManagerMockRecorder) Export(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockauditManager)(nil).Export), arg0, arg1, arg2)
+}
+
+// Find mocks base method.
+func (m *MockauditManager) Find(arg0 *models.Author, arg1 models.AuditFilter, arg2 models.Page) ([]*models.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*models.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockauditManagerMockRecorder) Find(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockauditManager)(nil).Find), arg0, arg1, arg2)
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"context"
+	"forum/internal/handlers/utils"
+	"forum/internal/models"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	gomock "github.com/golang/mock/gomock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func getDefaultAuditEntry(actor *models.Author) *models.AuditEntry {
+	return &models.AuditEntry{
+		ID:         primitive.NewObjectID(),
+		ActorID:    actor.ID,
+		ActorName:  actor.Username,
+		Action:     models.AuditPostDelete,
+		TargetType: models.AuditTargetPost,
+		TargetID:   primitive.NewObjectID().Hex(),
+		Reason:     "spam",
+		Before:     `{"title":"spam, again"}`,
+		Created:    time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestAuditFind(t *testing.T) {
+	logger := slog.New(utils.DummyLogger{})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	auditManager := NewMockauditManager(ctrl)
+
+	auditHandler := &AuditHandler{
+		Logger:       logger,
+		AuditManager: auditManager,
+	}
+
+	moderator := getDefaultAuthor()
+	entries := []*models.AuditEntry{getDefaultAuditEntry(moderator)}
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	filter := models.AuditFilter{ActorID: &moderator.ID, Action: models.AuditPostDelete, From: &from}
+	page := models.Page{Number: 1, Size: 20}
+	path := "/api/mod/audit?action=post.delete&from=2024-05-01T00:00:00Z&actor=" + moderator.ID.Hex()
+
+	newRequest := func(path string) *http.Request {
+		request := httptest.NewRequest(http.MethodGet, path, nil)
+		ctx := context.WithValue(request.Context(), models.CtxKey("user"), moderator)
+		return request.WithContext(ctx)
+	}
+
+	// good response
+	auditManager.EXPECT().Find(moderator, filter, page).Return(entries, nil)
+
+	response := []*models.AuditEntry{}
+
+	test := utils.TestRequest{
+		Handler:        auditHandler.Find,
+		Request:        newRequest(path),
+		ExpectedStatus: http.StatusOK,
+		ResponsePtr:    &response,
+	}
+
+	err := utils.SendTestRequest(test)
+	if err != nil {
+		t.Fatalf("expected nil, but was %v", err)
+	}
+	if !reflect.DeepEqual(response, entries) {
+		t.Errorf("\nwant: %v\nhave: %v", entries, response)
+	}
+
+	// bad filter
+	test = utils.TestRequest{
+		Handler:        auditHandler.Find,
+		Request:        newRequest("/api/mod/audit?from=yesterday"),
+		ExpectedStatus: http.StatusBadRequest,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+
+	// not a moderator
+	auditManager.EXPECT().Find(moderator, filter, page).Return(nil, models.ErrForbidden)
+
+	test = utils.TestRequest{
+		Handler:        auditHandler.Find,
+		Request:        newRequest(path),
+		ExpectedStatus: http.StatusForbidden,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+}
+
+func TestAuditExport(t *testing.T) {
+	logger := slog.New(utils.DummyLogger{})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	auditManager := NewMockauditManager(ctrl)
+
+	auditHandler := &AuditHandler{
+		Logger:       logger,
+		AuditManager: auditManager,
+	}
+
+	moderator := getDefaultAuthor()
+	entry := getDefaultAuditEntry(moderator)
+	filter := models.AuditFilter{TargetType: models.AuditTargetPost}
+
+	newRequest := func() *http.Request {
+		request := httptest.NewRequest(http.MethodGet, "/api/mod/audit/export?targetType=post", nil)
+		ctx := context.WithValue(request.Context(), models.CtxKey("user"), moderator)
+		return request.WithContext(ctx)
+	}
+
+	// good response, текст, похожий на формулу, экранируется
+	formula := getDefaultAuditEntry(moderator)
+	formula.ActorName, formula.Reason, formula.Before = "@admin", `=HYPERLINK("http://evil.example")`, "-1+1"
+	auditManager.EXPECT().Export(moderator, filter, gomock.Any()).DoAndReturn(
+		func(_ *models.Author, _ models.AuditFilter, fn func(*models.AuditEntry) error) error {
+			err := fn(entry)
+			if err != nil {
+				return err
+			}
+			return fn(formula)
+		},
+	)
+
+	recorder := httptest.NewRecorder()
+	auditHandler.Export(recorder, newRequest())
+	result := recorder.Result()
+	defer result.Body.Close()
+
+	if result.StatusCode != http.StatusOK {
+		t.Fatalf("want status 200, have %d", result.StatusCode)
+	}
+	if !strings.HasPrefix(result.Header.Get("Content-Type"), "text/csv") {
+		t.Errorf("want text/csv, have %s", result.Header.Get("Content-Type"))
+	}
+	body, err := io.ReadAll(result.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "id,created,actorID,actorName,action,targetType,targetID,reason,before\n" +
+		entry.ID.Hex() + ",2024-05-01T12:00:00Z," + moderator.ID.Hex() + "," + moderator.Username +
+		",post.delete,post," + entry.TargetID + `,spam,"{""title"":""spam, again""}"` + "\n" +
+		formula.ID.Hex() + ",2024-05-01T12:00:00Z," + moderator.ID.Hex() + ",'@admin" +
+		",post.delete,post," + formula.TargetID + `,"'=HYPERLINK(""http://evil.example"")",'-1+1` + "\n"
+	if string(body) != expected {
+		t.Errorf("\nwant: %s\nhave: %s", expected, body)
+	}
+
+	// not a moderator
+	auditManager.EXPECT().Export(moderator, filter, gomock.Any()).Return(models.ErrForbidden)
+
+	recorder = httptest.NewRecorder()
+	auditHandler.Export(recorder, newRequest())
+	if recorder.Code != http.StatusForbidden {
+		t.Errorf("want status 403, have %d", recorder.Code)
+	}
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 	"forum/internal/handlers/utils"
@@ -13,6 +14,15 @@ type AuthManager interface {
 	Register(*models.User) (string, error)
 }
 
+// Возвращает статус ответа для ошибки входа: 403 - пользователь заблокирован, иначе 401
+func loginErrorStatus(err error) int {
+	suspended := &models.SuspendedError{}
+	if errors.As(err, &suspended) {
+		return http.StatusForbidden
+	}
+	return http.StatusUnauthorized
+}
+
 type UserHandler struct {
 	Logger      *slog.Logger
 	AuthManager AuthManager
@@ -46,7 +56,7 @@ func (uh *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 
 	token, err := uh.AuthManager.Login(loginInput)
 	if err != nil {
-		msg.Set(err.Error(), http.StatusUnauthorized)
+		msg.Set(err.Error(), loginErrorStatus(err))
 		utils.WriteError(w, msg)
 		return
 	}
@@ -9,6 +9,7 @@ import (
 	"net/http/httptest"
 	"forum/internal/handlers/utils"
 	"forum/internal/models"
+	"strings"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -161,6 +162,18 @@ func TestLogin(t *testing.T) {
 		t.Fatal("expected error, but was nil")
 	}
 
+	// suspended user
+	authManager.EXPECT().Login(user).Return("", &models.SuspendedError{Reason: "spam"})
+
+	request = httptest.NewRequest(method, path, bytes.NewBuffer(body))
+	request.Header.Set("Content-Type", "application/json")
+
+	recorder := httptest.NewRecorder()
+	handler(recorder, request)
+	if recorder.Code != http.StatusForbidden || !strings.Contains(recorder.Body.String(), "spam") {
+		t.Errorf("want 403 with reason, have %d %q", recorder.Code, recorder.Body.String())
+	}
+
 	// Validate, marshall, read request
 	notValidItem := &models.User{
 		Password: "bad",
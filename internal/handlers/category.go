@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"encoding/json"
+	"forum/internal/handlers/utils"
+	"forum/internal/models"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type categoryManager interface {
+	Moderators(string) ([]models.Author, error)
+	AddModerator(string, string, *models.Author) error
+	RemoveModerator(string, string, *models.Author) error
+	Rules(string) (*models.CategoryRules, error)
+	SetRules(string, *models.CategoryRulesInput, *models.Author) (*models.CategoryRules, error)
+}
+
+type CategoryHandler struct {
+	Logger          *slog.Logger
+	CategoryManager categoryManager
+}
+
+// Хендлер, возвращающий модераторов категории
+func (ch *CategoryHandler) Moderators(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(ch.Logger, r.URL.Path, r.Method)
+
+	moderators, err := ch.CategoryManager.Moderators(mux.Vars(r)["category"])
+	if err != nil {
+		msg.Set(err.Error(), http.StatusNotFound)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	msg.Set("success", http.StatusOK)
+	utils.WriteData(w, msg, moderators)
+}
+
+// Хендлер назначения пользователя username модератором категории
+func (ch *CategoryHandler) AddModerator(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(ch.Logger, r.URL.Path, r.Method)
+
+	admin, ok := r.Context().Value(models.CtxKey("user")).(*models.Author)
+	if !ok {
+		msg.Set("bad context value by key user", http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	vars := mux.Vars(r)
+	err := ch.CategoryManager.AddModerator(vars["category"], vars["username"], admin)
+	if err != nil {
+		msg.Set(err.Error(), postErrorStatus(err, http.StatusUnprocessableEntity))
+		utils.WriteError(w, msg)
+		return
+	}
+
+	msg.Set("success", http.StatusOK)
+	utils.WriteData(w, msg, map[string]interface{}{
+		"message": "success",
+	})
+}
+
+// Хендлер снятия пользователя username с модерации категории
+func (ch *CategoryHandler) RemoveModerator(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(ch.Logger, r.URL.Path, r.Method)
+
+	admin, ok := r.Context().Value(models.CtxKey("user")).(*models.Author)
+	if !ok {
+		msg.Set("bad context value by key user", http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	vars := mux.Vars(r)
+	err := ch.CategoryManager.RemoveModerator(vars["category"], vars["username"], admin)
+	if err != nil {
+		msg.Set(err.Error(), postErrorStatus(err, http.StatusUnprocessableEntity))
+		utils.WriteError(w, msg)
+		return
+	}
+
+	msg.Set("success", http.StatusOK)
+	utils.WriteData(w, msg, map[string]interface{}{
+		"message": "success",
+	})
+}
+
+// Хендлер, возвращающий правила публикации в категории
+func (ch *CategoryHandler) Rules(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(ch.Logger, r.URL.Path, r.Method)
+
+	rules, err := ch.CategoryManager.Rules(mux.Vars(r)["category"])
+	if err != nil {
+		msg.Set(err.Error(), http.StatusNotFound)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	msg.Set("success", http.StatusOK)
+	utils.WriteData(w, msg, rules)
+}
+
+// Хендлер изменения правил публикации в категории, новые правила передаются в теле
+func (ch *CategoryHandler) SetRules(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(ch.Logger, r.URL.Path, r.Method)
+
+	data, err := utils.ReadRequestBody(r)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusBadRequest)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	input := &models.CategoryRulesInput{}
+	err = json.Unmarshal(data, input)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	err = utils.ValidateStruct(input)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	admin, ok := r.Context().Value(models.CtxKey("user")).(*models.Author)
+	if !ok {
+		msg.Set("bad context value by key user", http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	rules, err := ch.CategoryManager.SetRules(mux.Vars(r)["category"], input, admin)
+	if err != nil {
+		msg.Set(err.Error(), postErrorStatus(err, http.StatusUnprocessableEntity))
+		utils.WriteError(w, msg)
+		return
+	}
+
+	msg.Set("success", http.StatusOK)
+	utils.WriteData(w, msg, rules)
+}
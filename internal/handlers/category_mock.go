@@ -0,0 +1,108 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/handlers/category.go
+
+// Package handlers is a generated GoMock package.
+package handlers
+
+import (
+	models "forum/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockcategoryManager is a mock of categoryManager interface.
+type MockcategoryManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockcategoryManagerMockRecorder
+}
+
+// MockcategoryManagerMockRecorder is the mock recorder for MockcategoryManager.
+type MockcategoryManagerMockRecorder struct {
+	mock *MockcategoryManager
+}
+
+// NewMockcategoryManager creates a new mock instance.
+func NewMockcategoryManager(ctrl *gomock.Controller) *MockcategoryManager {
+	mock := &MockcategoryManager{ctrl: ctrl}
+	mock.recorder = &MockcategoryManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockcategoryManager) EXPECT() *MockcategoryManagerMockRecorder {
+	return m.recorder
+}
+
+// AddModerator mocks base method.
+func (m *MockcategoryManager) AddModerator(arg0, arg1 string, arg2 *models.Author) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddModerator", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddModerator indicates an expected call of AddModerator.
+func (mr *MockcategoryManagerMockRecorder) AddModerator(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddModerator", reflect.TypeOf((*MockcategoryManager)(nil).AddModerator), arg0, arg1, arg2)
+}
+
+// Moderators mocks base method.
+func (m *MockcategoryManager) Moderators(arg0 string) ([]models.Author, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Moderators", arg0)
+	ret0, _ := ret[0].([]models.Author)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Moderators indicates an expected call of Moderators.
+func (mr *MockcategoryManagerMockRecorder) Moderators(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Moderators", reflect.TypeOf((*MockcategoryManager)(nil).Moderators), arg0)
+}
+
+// RemoveModerator mocks base method.
+func (m *MockcategoryManager) RemoveModerator(arg0, arg1 string, arg2 *models.Author) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveModerator", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveModerator indicates an expected call of RemoveModerator.
+func (mr *MockcategoryManagerMockRecorder) RemoveModerator(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveModerator", reflect.TypeOf((*MockcategoryManager)(nil).RemoveModerator), arg0, arg1, arg2)
+}
+
+// Rules mocks base method.
+func (m *MockcategoryManager) Rules(arg0 string) (*models.CategoryRules, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rules", arg0)
+	ret0, _ := ret[0].(*models.CategoryRules)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Rules indicates an expected call of Rules.
+func (mr *MockcategoryManagerMockRecorder) Rules(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rules", reflect.TypeOf((*MockcategoryManager)(nil).Rules), arg0)
+}
+
+// SetRules mocks base method.
+func (m *MockcategoryManager) SetRules(arg0 string, arg1 *models.CategoryRulesInput, arg2 *models.Author) (*models.CategoryRules, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRules", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.CategoryRules)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetRules indicates an expected call of SetRules.
+func (mr *MockcategoryManagerMockRecorder) SetRules(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRules", reflect.TypeOf((*MockcategoryManager)(nil).SetRules), arg0, arg1, arg2)
+}
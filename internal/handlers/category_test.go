@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"forum/internal/handlers/utils"
+	"forum/internal/models"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	gomock "github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+)
+
+func TestCategoryModerators(t *testing.T) {
+	logger := slog.New(utils.DummyLogger{})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	categoryManager := NewMockcategoryManager(ctrl)
+
+	categoryHandler := &CategoryHandler{
+		Logger:          logger,
+		CategoryManager: categoryManager,
+	}
+
+	admin := getDefaultAuthor()
+
+	newRequest := func(method string) *http.Request {
+		request := httptest.NewRequest(method, "/api/admin/categories/music/moderators/user", nil)
+		request = mux.SetURLVars(request, map[string]string{"category": "music", "username": "user"})
+		ctx := context.WithValue(request.Context(), models.CtxKey("user"), admin)
+		return request.WithContext(ctx)
+	}
+
+	// good response
+	categoryManager.EXPECT().AddModerator("music", "user", admin).Return(nil)
+
+	response := map[string]interface{}{}
+
+	test := utils.TestRequest{
+		Handler:        categoryHandler.AddModerator,
+		Request:        newRequest(http.MethodPost),
+		ExpectedStatus: http.StatusOK,
+		ResponsePtr:    &response,
+	}
+
+	err := utils.SendTestRequest(test)
+	if err != nil {
+		t.Fatalf("expected nil, but was %v", err)
+	}
+
+	moderators := []models.Author{*admin}
+	categoryManager.EXPECT().Moderators("music").Return(moderators, nil)
+
+	list := []models.Author{}
+
+	test = utils.TestRequest{
+		Handler:        categoryHandler.Moderators,
+		Request:        newRequest(http.MethodGet),
+		ExpectedStatus: http.StatusOK,
+		ResponsePtr:    &list,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err != nil {
+		t.Fatalf("expected nil, but was %v", err)
+	}
+	if !reflect.DeepEqual(list, moderators) {
+		t.Errorf("\nwant: %v\nhave: %v", moderators, list)
+	}
+
+	// not an admin
+	categoryManager.EXPECT().RemoveModerator("music", "user", admin).Return(models.ErrForbidden)
+
+	recorder := httptest.NewRecorder()
+	categoryHandler.RemoveModerator(recorder, newRequest(http.MethodDelete))
+	if recorder.Code != http.StatusForbidden {
+		t.Errorf("want %d, have %d", http.StatusForbidden, recorder.Code)
+	}
+}
+
+func TestCategoryRules(t *testing.T) {
+	logger := slog.New(utils.DummyLogger{})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	categoryManager := NewMockcategoryManager(ctrl)
+
+	categoryHandler := &CategoryHandler{
+		Logger:          logger,
+		CategoryManager: categoryManager,
+	}
+
+	admin := getDefaultAuthor()
+	input := &models.CategoryRulesInput{PostTypes: []string{"text"}, MinAccountDays: 7}
+	rules := &models.CategoryRules{Category: "music", PostTypes: []string{"text"}, MinAccountDays: 7}
+
+	newRequest := func(body string) *http.Request {
+		request := httptest.NewRequest(http.MethodPost, "/api/admin/categories/music/rules", strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		request = mux.SetURLVars(request, map[string]string{"category": "music"})
+		ctx := context.WithValue(request.Context(), models.CtxKey("user"), admin)
+		return request.WithContext(ctx)
+	}
+
+	// good response
+	categoryManager.EXPECT().SetRules("music", input, admin).Return(rules, nil)
+
+	response := &models.CategoryRules{}
+
+	test := utils.TestRequest{
+		Handler:        categoryHandler.SetRules,
+		Request:        newRequest(`{"postTypes":["text"],"minAccountDays":7}`),
+		ExpectedStatus: http.StatusOK,
+		ResponsePtr:    response,
+	}
+
+	err := utils.SendTestRequest(test)
+	if err != nil {
+		t.Fatalf("expected nil, but was %v", err)
+	}
+	if !reflect.DeepEqual(response, rules) {
+		t.Errorf("\nwant: %v\nhave: %v", rules, response)
+	}
+
+	// no minimum account age
+	noAge := &models.CategoryRulesInput{PostTypes: []string{"text"}}
+	categoryManager.EXPECT().SetRules("music", noAge, admin).Return(&models.CategoryRules{Category: "music", PostTypes: []string{"text"}}, nil)
+
+	test = utils.TestRequest{
+		Handler:        categoryHandler.SetRules,
+		Request:        newRequest(`{"postTypes":["text"],"minAccountDays":0}`),
+		ExpectedStatus: http.StatusOK,
+		ResponsePtr:    &models.CategoryRules{},
+	}
+
+	err = utils.SendTestRequest(test)
+	if err != nil {
+		t.Fatalf("expected nil, but was %v", err)
+	}
+
+	// account age out of range
+	test = utils.TestRequest{
+		Handler:        categoryHandler.SetRules,
+		Request:        newRequest(`{"postTypes":["text"],"minAccountDays":-1}`),
+		ExpectedStatus: http.StatusUnprocessableEntity,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+
+	// unknown category
+	categoryManager.EXPECT().Rules("unknown").Return(nil, fmt.Errorf("no such category"))
+
+	request := httptest.NewRequest(http.MethodGet, "/api/category/unknown/rules", nil)
+	request = mux.SetURLVars(request, map[string]string{"category": "unknown"})
+	recorder := httptest.NewRecorder()
+	categoryHandler.Rules(recorder, request)
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("want %d, have %d", http.StatusNotFound, recorder.Code)
+	}
+}
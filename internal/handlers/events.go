@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"forum/internal/handlers/utils"
+	"forum/internal/models"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	heartbeatInterval = 15 * time.Second
+)
+
+type eventManager interface {
+	Subscribe(filter func(*models.Event) bool, lastEventID string) (<-chan *models.Event, func(), error)
+}
+
+type EventHandler struct {
+	Logger       *slog.Logger
+	EventManager eventManager
+}
+
+// Хендлер, отдающий события поста с postID как Server-Sent Events
+func (eh *EventHandler) PostEvents(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(eh.Logger, r.URL.Path, r.Method)
+
+	postID, err := primitive.ObjectIDFromHex(mux.Vars(r)["postID"])
+	if err != nil {
+		msg.Set(err.Error(), http.StatusNotFound)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	eh.stream(w, r, func(event *models.Event) bool {
+		return event.Public() && event.PostID == postID
+	})
+}
+
+// Хендлер, отдающий события всех постов как Server-Sent Events
+func (eh *EventHandler) AllEvents(w http.ResponseWriter, r *http.Request) {
+	// уведомления адресованы одному пользователю и доступны только в его теме WebSocket
+	eh.stream(w, r, func(event *models.Event) bool {
+		return event.Public()
+	})
+}
+
+/*
+Пишет события в w, пока клиент не отключится. Раз в heartbeatInterval отправляется комментарий,
+чтобы соединение не закрывали прокси. Продолжение после переподключения - по заголовку Last-Event-ID.
+*/
+func (eh *EventHandler) stream(w http.ResponseWriter, r *http.Request, filter func(*models.Event) bool) {
+	msg := utils.NewLogMsg(eh.Logger, r.URL.Path, r.Method)
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		msg.Set("streaming unsupported", http.StatusInternalServerError)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	events, cancel, err := eh.EventManager.Subscribe(filter, lastEventID)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, msg)
+		return
+	}
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	msg.Set("stream opened", http.StatusOK)
+	msg.Info()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			_, err = fmt.Fprint(w, ": heartbeat\n\n")
+		case event, ok := <-events:
+			if !ok {
+				// не успевали отдавать события, клиент переподключится с Last-Event-ID
+				msg.Set("slow consumer dropped", http.StatusOK)
+				msg.Info()
+				return
+			}
+			var data []byte
+			data, err = json.Marshal(event)
+			if err != nil {
+				return
+			}
+			_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+		}
+		if err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/handlers/events.go
+
+// Package handlers is a generated GoMock package.
+package handlers
+
+import (
+	models "forum/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockeventManager is a mock of eventManager interface.
+type MockeventManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockeventManagerMockRecorder
+}
+
+// MockeventManagerMockRecorder is the mock recorder for MockeventManager.
+type MockeventManagerMockRecorder struct {
+	mock *MockeventManager
+}
+
+// NewMockeventManager creates a new mock instance.
+func NewMockeventManager(ctrl *gomock.Controller) *MockeventManager {
+	mock := &MockeventManager{ctrl: ctrl}
+	mock.recorder = &MockeventManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockeventManager) EXPECT() *MockeventManagerMockRecorder {
+	return m.recorder
+}
+
+// Subscribe mocks base method.
+func (m *MockeventManager) Subscribe(filter func(*models.Event) bool, lastEventID string) (<-chan *models.Event, func(), error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", filter, lastEventID)
+	ret0, _ := ret[0].(<-chan *models.Event)
+	ret1, _ := ret[1].(func())
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockeventManagerMockRecorder) Subscribe(filter, lastEventID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockeventManager)(nil).Subscribe), filter, lastEventID)
+}
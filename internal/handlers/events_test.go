@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"forum/internal/handlers/utils"
+	"forum/internal/models"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPostEvents(t *testing.T) {
+	logger := slog.New(utils.DummyLogger{})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eventManager := NewMockeventManager(ctrl)
+
+	eventHandler := &EventHandler{
+		Logger:       logger,
+		EventManager: eventManager,
+	}
+
+	postID := primitive.NewObjectID()
+	event := &models.Event{
+		ID:     "1-1",
+		Type:   models.EventCommentCreated,
+		PostID: postID,
+	}
+
+	// good response, stream ends when manager closes channel
+	events := make(chan *models.Event, 1)
+	events <- event
+	close(events)
+
+	var filter func(*models.Event) bool
+	eventManager.EXPECT().Subscribe(gomock.Any(), "1-0").DoAndReturn(
+		func(f func(*models.Event) bool, lastEventID string) (<-chan *models.Event, func(), error) {
+			filter = f
+			return events, func() {}, nil
+		},
+	)
+
+	request := httptest.NewRequest(http.MethodGet, "/api/post/"+postID.Hex()+"/events", nil)
+	request.Header.Set("Last-Event-ID", "1-0")
+	request = mux.SetURLVars(request, map[string]string{"postID": postID.Hex()})
+
+	w := httptest.NewRecorder()
+	eventHandler.PostEvents(w, request)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if contentType := w.Header().Get("Content-Type"); contentType != "text/event-stream" {
+		t.Errorf("\nwant: text/event-stream\nhave: %v", contentType)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, "id: 1-1\nevent: comment.created\ndata: {") {
+		t.Errorf("unexpected stream body %q", body)
+	}
+	if !filter(event) || filter(&models.Event{PostID: primitive.NewObjectID()}) {
+		t.Error("filter must pass only events of the post")
+	}
+	// уведомление о посте адресовано одному пользователю и в поток поста не попадает
+	if filter(&models.Event{Type: models.EventNotification, PostID: postID, UserID: primitive.NewObjectID().Hex()}) {
+		t.Error("filter must not pass notifications")
+	}
+
+	// Bad post id
+	request = httptest.NewRequest(http.MethodGet, "/api/post/bad/events", nil)
+	request = mux.SetURLVars(request, map[string]string{"postID": "bad"})
+
+	w = httptest.NewRecorder()
+	eventHandler.PostEvents(w, request)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestAllEventsHeartbeat(t *testing.T) {
+	logger := slog.New(utils.DummyLogger{})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eventManager := NewMockeventManager(ctrl)
+
+	eventHandler := &EventHandler{
+		Logger:       logger,
+		EventManager: eventManager,
+	}
+
+	interval := heartbeatInterval
+	heartbeatInterval = 10 * time.Millisecond
+	defer func() { heartbeatInterval = interval }()
+
+	events := make(chan *models.Event)
+	var filter func(*models.Event) bool
+	eventManager.EXPECT().Subscribe(gomock.Any(), "").DoAndReturn(
+		func(f func(*models.Event) bool, lastEventID string) (<-chan *models.Event, func(), error) {
+			filter = f
+			return events, func() {}, nil
+		},
+	)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(events)
+	}()
+
+	w := httptest.NewRecorder()
+	eventHandler.AllEvents(w, httptest.NewRequest(http.MethodGet, "/api/posts/events", nil))
+
+	if !strings.Contains(w.Body.String(), ": heartbeat\n\n") {
+		t.Errorf("expected heartbeat in stream, got %q", w.Body.String())
+	}
+	if !filter(&models.Event{Type: models.EventPostCreated}) || filter(&models.Event{Type: models.EventNotification}) {
+		t.Error("filter must pass all events except notifications")
+	}
+}
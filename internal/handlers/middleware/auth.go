@@ -4,13 +4,14 @@ import (
 	"context"
 	"log/slog"
 	"net/http"
+	"forum/internal/metrics"
 	"forum/internal/models"
 	"strings"
 )
 
 // Check - проверка токена
 type authManager interface {
-	Check(string) (*models.Author, error)
+	Check(context.Context, string) (*models.Author, error)
 }
 
 type authMiddleware struct {
@@ -29,8 +30,9 @@ func NewAuthMiddleware(auth authManager, logger *slog.Logger) *authMiddleware {
 func (am *authMiddleware) GetHandler(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		tokenIn := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
-		author, err := am.auth.Check(tokenIn)
+		author, err := am.auth.Check(r.Context(), tokenIn)
 		if err != nil {
+			metrics.AuthFailure(metrics.AuthToken)
 			http.Error(w, err.Error(), http.StatusUnauthorized)
 			am.logger.Error(
 				"auth middleware",
@@ -45,3 +47,31 @@ func (am *authMiddleware) GetHandler(next http.HandlerFunc) http.HandlerFunc {
 		next.ServeHTTP(w, r.WithContext(ctx))
 	}
 }
+
+/*
+Возвращает хендлер для публичных путей: если токен передан и валиден, то пользователь кладется в контекст,
+иначе запрос обрабатывается как анонимный.
+*/
+func (am *authMiddleware) GetOptionalHandler(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tokenIn := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if tokenIn == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		author, err := am.auth.Check(r.Context(), tokenIn)
+		if err != nil {
+			metrics.AuthFailure(metrics.AuthToken)
+			am.logger.Info(
+				"optional auth middleware",
+				"err", err.Error(),
+				"url", r.URL.Path,
+				"method", r.Method,
+			)
+			next.ServeHTTP(w, r)
+			return
+		}
+		ctx := context.WithValue(r.Context(), models.CtxKey("user"), author)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"forum/internal/metrics"
+	"net/http"
+
+	"github.com/felixge/httpsnoop"
+	"github.com/gorilla/mux"
+)
+
+// Метка для запросов, не подошедших ни к одному пути, чтобы произвольные url не плодили метки
+const unmatchedRoute = "unmatched"
+
+/*
+Учитывает в метриках запросы к next по шаблону пути router, методу и статусу ответа.
+Оборачивает весь router, поэтому учитываются и запросы без подходящего пути.
+*/
+func Metrics(router *mux.Router, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := routeTemplate(router, r)
+		// httpsnoop сохраняет Flusher и Hijacker для SSE и websocket
+		result := httpsnoop.CaptureMetrics(next, w, r)
+		metrics.ObserveRequest(route, r.Method, result.Code, result.Duration)
+	})
+}
+
+// Возвращает шаблон пути router, к которому относится r, или unmatchedRoute
+func routeTemplate(router *mux.Router, r *http.Request) string {
+	match := &mux.RouteMatch{}
+	if !router.Match(r, match) || match.Route == nil {
+		return unmatchedRoute
+	}
+	template, err := match.Route.GetPathTemplate()
+	if err != nil {
+		return unmatchedRoute
+	}
+	return template
+}
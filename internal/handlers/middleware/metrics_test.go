@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"forum/internal/metrics"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestMetrics(t *testing.T) {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/post/{postID}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}).Methods(http.MethodGet)
+	handler := Metrics(router, router)
+
+	for _, target := range []string{"/api/post/1", "/api/post/2", "/unknown/path"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	recorder := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, err := io.ReadAll(recorder.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// запросы учитываются по шаблону пути, а не по url
+	expected := []string{
+		`forum_http_requests_total{method="GET",route="/api/post/{postID}",status="404"} 2`,
+		`forum_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`forum_http_request_duration_seconds_count{method="GET",route="/api/post/{postID}",status="404"} 2`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(string(body), line) {
+			t.Errorf("metrics have no %s", line)
+		}
+	}
+}
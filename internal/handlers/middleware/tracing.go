@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"forum/internal/tracing"
+	"net/http"
+
+	"github.com/felixge/httpsnoop"
+	"github.com/gorilla/mux"
+)
+
+/*
+Начинает серверный спан для каждого запроса к next, названный по методу и шаблону пути router.
+Спан кладется в контекст запроса, от него строятся спаны менеджеров и хранилищ.
+*/
+func Tracing(router *mux.Router, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracing.StartHTTP(r, routeTemplate(router, r))
+		result := httpsnoop.CaptureMetrics(next, w, r.WithContext(ctx))
+		tracing.EndHTTP(span, result.Code)
+	})
+}
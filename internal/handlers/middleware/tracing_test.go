@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"forum/internal/tracing/tracingtest"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	recorder := tracingtest.NewRecorder()
+
+	var handlerTraceID trace.TraceID
+	router := mux.NewRouter()
+	router.HandleFunc("/api/post/{postID}", func(w http.ResponseWriter, r *http.Request) {
+		handlerTraceID = trace.SpanContextFromContext(r.Context()).TraceID()
+		w.WriteHeader(http.StatusInternalServerError)
+	}).Methods(http.MethodGet)
+	handler := Tracing(router, router)
+
+	request := httptest.NewRequest(http.MethodGet, "/api/post/1", nil)
+	request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("want 1 span, have %d", len(spans))
+	}
+	span := spans[0]
+	// спан назван по шаблону пути и продолжает трассировку клиента
+	if span.Name() != "GET /api/post/{postID}" || span.SpanKind() != trace.SpanKindServer {
+		t.Errorf("unexpected span %s of kind %v", span.Name(), span.SpanKind())
+	}
+	if span.SpanContext().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" || span.Parent().SpanID().String() != "00f067aa0ba902b7" {
+		t.Errorf("trace context not propagated: trace %s, parent %s", span.SpanContext().TraceID(), span.Parent().SpanID())
+	}
+	if handlerTraceID != span.SpanContext().TraceID() {
+		t.Errorf("handler context has trace %s", handlerTraceID)
+	}
+	if span.Status().Code != codes.Error {
+		t.Errorf("want error status for 500, have %v", span.Status())
+	}
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"encoding/json"
+	"forum/internal/handlers/utils"
+	"forum/internal/models"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type notificationManager interface {
+	List(*models.Author, bool, models.Page) ([]*models.Notification, int, error)
+	MarkRead(*models.Author, string) error
+	MarkAllRead(*models.Author) error
+	Preferences(*models.Author) (*models.NotificationPreferences, error)
+	SetPreferences(*models.Author, *models.NotificationPreferences) error
+}
+
+type NotificationHandler struct {
+	Logger              *slog.Logger
+	NotificationManager notificationManager
+}
+
+// Хендлер, возвращающий уведомления пользователя и количество непрочитанных. unread=true - только непрочитанные.
+func (nh *NotificationHandler) List(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(nh.Logger, r.URL.Path, r.Method)
+
+	page, err := utils.ReadPage(r)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusBadRequest)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	user, ok := r.Context().Value(models.CtxKey("user")).(*models.Author)
+	if !ok {
+		msg.Set("bad context value by key user", http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	unreadOnly := r.URL.Query().Get("unread") == "true"
+	notifications, unread, err := nh.NotificationManager.List(user, unreadOnly, page)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	msg.Set("success", http.StatusOK)
+	utils.WriteData(w, msg, map[string]interface{}{
+		"notifications": notifications,
+		"unread":        unread,
+	})
+}
+
+// Хендлер, отмечающий прочитанным уведомление notificationID
+func (nh *NotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(nh.Logger, r.URL.Path, r.Method)
+
+	user, ok := r.Context().Value(models.CtxKey("user")).(*models.Author)
+	if !ok {
+		msg.Set("bad context value by key user", http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	err := nh.NotificationManager.MarkRead(user, mux.Vars(r)["notificationID"])
+	if err != nil {
+		msg.Set(err.Error(), http.StatusNotFound)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	msg.Set("success", http.StatusOK)
+	utils.WriteData(w, msg, map[string]interface{}{
+		"message": "success",
+	})
+}
+
+// Хендлер, отмечающий прочитанными все уведомления
+func (nh *NotificationHandler) MarkAllRead(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(nh.Logger, r.URL.Path, r.Method)
+
+	user, ok := r.Context().Value(models.CtxKey("user")).(*models.Author)
+	if !ok {
+		msg.Set("bad context value by key user", http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	err := nh.NotificationManager.MarkAllRead(user)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	msg.Set("success", http.StatusOK)
+	utils.WriteData(w, msg, map[string]interface{}{
+		"message": "success",
+	})
+}
+
+// Хендлер, возвращающий настройки уведомлений
+func (nh *NotificationHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(nh.Logger, r.URL.Path, r.Method)
+
+	user, ok := r.Context().Value(models.CtxKey("user")).(*models.Author)
+	if !ok {
+		msg.Set("bad context value by key user", http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	preferences, err := nh.NotificationManager.Preferences(user)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	msg.Set("success", http.StatusOK)
+	utils.WriteData(w, msg, preferences)
+}
+
+// Хендлер, изменяющий настройки уведомлений. Не переданные поля остаются прежними.
+func (nh *NotificationHandler) SetPreferences(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(nh.Logger, r.URL.Path, r.Method)
+
+	data, err := utils.ReadRequestBody(r)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusBadRequest)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	user, ok := r.Context().Value(models.CtxKey("user")).(*models.Author)
+	if !ok {
+		msg.Set("bad context value by key user", http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	preferences, err := nh.NotificationManager.Preferences(user)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	err = json.Unmarshal(data, preferences)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	err = nh.NotificationManager.SetPreferences(user, preferences)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	msg.Set("success", http.StatusOK)
+	utils.WriteData(w, msg, preferences)
+}
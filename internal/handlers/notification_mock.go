@@ -0,0 +1,108 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/handlers/notification.go
+
+// Package handlers is a generated GoMock package.
+package handlers
+
+import (
+	models "forum/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MocknotificationManager is a mock of notificationManager interface.
+type MocknotificationManager struct {
+	ctrl     *gomock.Controller
+	recorder *MocknotificationManagerMockRecorder
+}
+
+// MocknotificationManagerMockRecorder is the mock recorder for MocknotificationManager.
+type MocknotificationManagerMockRecorder struct {
+	mock *MocknotificationManager
+}
+
+// NewMocknotificationManager creates a new mock instance.
+func NewMocknotificationManager(ctrl *gomock.Controller) *MocknotificationManager {
+	mock := &MocknotificationManager{ctrl: ctrl}
+	mock.recorder = &MocknotificationManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocknotificationManager) EXPECT() *MocknotificationManagerMockRecorder {
+	return m.recorder
+}
+
+// List mocks base method.
+func (m *MocknotificationManager) List(arg0 *models.Author, arg1 bool, arg2 models.Page) ([]*models.Notification, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*models.Notification)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// List indicates an expected call of List.
+func (mr *MocknotificationManagerMockRecorder) List(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MocknotificationManager)(nil).List), arg0, arg1, arg2)
+}
+
+// MarkAllRead mocks base method.
+func (m *MocknotificationManager) MarkAllRead(arg0 *models.Author) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkAllRead", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkAllRead indicates an expected call of MarkAllRead.
+func (mr *MocknotificationManagerMockRecorder) MarkAllRead(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAllRead", reflect.TypeOf((*MocknotificationManager)(nil).MarkAllRead), arg0)
+}
+
+// MarkRead mocks base method.
+func (m *MocknotificationManager) MarkRead(arg0 *models.Author, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkRead", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkRead indicates an expected call of MarkRead.
+func (mr *MocknotificationManagerMockRecorder) MarkRead(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkRead", reflect.TypeOf((*MocknotificationManager)(nil).MarkRead), arg0, arg1)
+}
+
+// Preferences mocks base method.
+func (m *MocknotificationManager) Preferences(arg0 *models.Author) (*models.NotificationPreferences, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Preferences", arg0)
+	ret0, _ := ret[0].(*models.NotificationPreferences)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Preferences indicates an expected call of Preferences.
+func (mr *MocknotificationManagerMockRecorder) Preferences(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Preferences", reflect.TypeOf((*MocknotificationManager)(nil).Preferences), arg0)
+}
+
+// SetPreferences mocks base method.
+func (m *MocknotificationManager) SetPreferences(arg0 *models.Author, arg1 *models.NotificationPreferences) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPreferences", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPreferences indicates an expected call of SetPreferences.
+func (mr *MocknotificationManagerMockRecorder) SetPreferences(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPreferences", reflect.TypeOf((*MocknotificationManager)(nil).SetPreferences), arg0, arg1)
+}
@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"forum/internal/handlers/utils"
+	"forum/internal/models"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNotificationList(t *testing.T) {
+	logger := slog.New(utils.DummyLogger{})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	notificationManager := NewMocknotificationManager(ctrl)
+
+	notificationHandler := &NotificationHandler{
+		Logger:              logger,
+		NotificationManager: notificationManager,
+	}
+
+	author := getDefaultAuthor()
+	notifications := []*models.Notification{
+		{
+			ID:     primitive.NewObjectID(),
+			UserID: author.ID,
+			Type:   models.NotificationComment,
+			PostID: primitive.NewObjectID(),
+		},
+	}
+
+	// good response
+	notificationManager.EXPECT().List(author, true, models.Page{Number: 2, Size: 5}).Return(notifications, 3, nil)
+
+	request := httptest.NewRequest(http.MethodGet, "/api/notifications?unread=true&page=2&limit=5", nil)
+	ctx := context.WithValue(request.Context(), models.CtxKey("user"), author)
+
+	response := struct {
+		Notifications []*models.Notification `json:"notifications"`
+		Unread        int                    `json:"unread"`
+	}{}
+
+	test := utils.TestRequest{
+		Handler:        notificationHandler.List,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusOK,
+		ResponsePtr:    &response,
+	}
+
+	err := utils.SendTestRequest(test)
+	if err != nil {
+		t.Fatalf("expected nil, but was %v", err)
+	}
+	if response.Unread != 3 || len(response.Notifications) != 1 || response.Notifications[0].ID != notifications[0].ID {
+		t.Errorf("unexpected response %v", response)
+	}
+
+	// Bad page
+	request = httptest.NewRequest(http.MethodGet, "/api/notifications?page=0", nil)
+
+	test = utils.TestRequest{
+		Handler:        notificationHandler.List,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusBadRequest,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+
+	// Manager error
+	notificationManager.EXPECT().List(author, false, models.Page{Number: 1, Size: 20}).Return(nil, 0, fmt.Errorf("db error"))
+
+	request = httptest.NewRequest(http.MethodGet, "/api/notifications", nil)
+
+	test = utils.TestRequest{
+		Handler:        notificationHandler.List,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusInternalServerError,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+}
+
+func TestNotificationMarkRead(t *testing.T) {
+	logger := slog.New(utils.DummyLogger{})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	notificationManager := NewMocknotificationManager(ctrl)
+
+	notificationHandler := &NotificationHandler{
+		Logger:              logger,
+		NotificationManager: notificationManager,
+	}
+
+	author := getDefaultAuthor()
+	notificationID := primitive.NewObjectID().Hex()
+	vars := map[string]string{
+		"notificationID": notificationID,
+	}
+
+	// good response
+	notificationManager.EXPECT().MarkRead(author, notificationID).Return(nil)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/notifications/"+notificationID+"/read", nil)
+	request = mux.SetURLVars(request, vars)
+	ctx := context.WithValue(request.Context(), models.CtxKey("user"), author)
+
+	test := utils.TestRequest{
+		Handler:        notificationHandler.MarkRead,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusOK,
+		ResponsePtr:    &map[string]interface{}{},
+	}
+
+	err := utils.SendTestRequest(test)
+	if err != nil {
+		t.Fatalf("expected nil, but was %v", err)
+	}
+
+	// Manager error
+	notificationManager.EXPECT().MarkRead(author, notificationID).Return(fmt.Errorf("no notification found"))
+
+	test = utils.TestRequest{
+		Handler:        notificationHandler.MarkRead,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusNotFound,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+
+	// all read
+	notificationManager.EXPECT().MarkAllRead(author).Return(nil)
+
+	request = httptest.NewRequest(http.MethodPost, "/api/notifications/read", nil)
+
+	test = utils.TestRequest{
+		Handler:        notificationHandler.MarkAllRead,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusOK,
+		ResponsePtr:    &map[string]interface{}{},
+	}
+
+	err = utils.SendTestRequest(test)
+	if err != nil {
+		t.Fatalf("expected nil, but was %v", err)
+	}
+
+	// Context error
+	test = utils.TestRequest{
+		Handler:        notificationHandler.MarkAllRead,
+		Request:        httptest.NewRequest(http.MethodPost, "/api/notifications/read", nil),
+		ExpectedStatus: http.StatusUnprocessableEntity,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+}
+
+func TestNotificationPreferences(t *testing.T) {
+	logger := slog.New(utils.DummyLogger{})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	notificationManager := NewMocknotificationManager(ctrl)
+
+	notificationHandler := &NotificationHandler{
+		Logger:              logger,
+		NotificationManager: notificationManager,
+	}
+
+	author := getDefaultAuthor()
+
+	// partial update keeps other fields
+	notificationManager.EXPECT().Preferences(author).Return(models.DefaultNotificationPreferences(author.ID), nil)
+	expected := models.DefaultNotificationPreferences(author.ID)
+	expected.Milestone = false
+	notificationManager.EXPECT().SetPreferences(author, expected).Return(nil)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/notifications/preferences", bytes.NewBufferString(`{"milestone": false}`))
+	request.Header.Set("Content-Type", "application/json")
+	ctx := context.WithValue(request.Context(), models.CtxKey("user"), author)
+
+	response := &models.NotificationPreferences{}
+
+	test := utils.TestRequest{
+		Handler:        notificationHandler.SetPreferences,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusOK,
+		ResponsePtr:    response,
+	}
+
+	err := utils.SendTestRequest(test)
+	if err != nil {
+		t.Fatalf("expected nil, but was %v", err)
+	}
+	if response.Milestone || !response.Comment {
+		t.Errorf("unexpected preferences %v", response)
+	}
+
+	// Bad json
+	notificationManager.EXPECT().Preferences(author).Return(models.DefaultNotificationPreferences(author.ID), nil)
+
+	request = httptest.NewRequest(http.MethodPost, "/api/notifications/preferences", bytes.NewBufferString(`{"milestone": 1}`))
+	request.Header.Set("Content-Type", "application/json")
+
+	test = utils.TestRequest{
+		Handler:        notificationHandler.SetPreferences,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusUnprocessableEntity,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+
+	// get
+	notificationManager.EXPECT().Preferences(author).Return(nil, fmt.Errorf("db error"))
+
+	request = httptest.NewRequest(http.MethodGet, "/api/notifications/preferences", nil)
+
+	test = utils.TestRequest{
+		Handler:        notificationHandler.GetPreferences,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusInternalServerError,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+}
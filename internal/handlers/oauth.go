@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"forum/internal/handlers/utils"
+	"log/slog"
+	"net/http"
+)
+
+type oauthManager interface {
+	Begin() (string, error)
+	Complete(state, code string) (string, error)
+}
+
+type OAuthHandler struct {
+	Logger       *slog.Logger
+	OAuthManager oauthManager
+}
+
+// Хендлер, перенаправляющий пользователя на страницу входа внешнего провайдера
+func (oh *OAuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(oh.Logger, r.URL.Path, r.Method)
+
+	redirectURL, err := oh.OAuthManager.Begin()
+	if err != nil {
+		msg.Set(err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	msg.Set("redirect", http.StatusFound)
+	msg.Info()
+	http.Redirect(w, r, redirectURL, http.StatusFound)
+}
+
+// Хендлер, на который провайдер возвращает пользователя после входа
+func (oh *OAuthHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(oh.Logger, r.URL.Path, r.Method)
+
+	query := r.URL.Query()
+	if providerErr := query.Get("error"); providerErr != "" {
+		msg.Set(providerErr, http.StatusUnauthorized)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	state := query.Get("state")
+	code := query.Get("code")
+	if state == "" || code == "" {
+		msg.Set("missing state or code", http.StatusBadRequest)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	token, err := oh.OAuthManager.Complete(state, code)
+	if err != nil {
+		msg.Set(err.Error(), loginErrorStatus(err))
+		utils.WriteError(w, msg)
+		return
+	}
+
+	msg.Set("success", http.StatusOK)
+	utils.WriteData(w, msg, map[string]interface{}{
+		"token": token,
+	})
+}
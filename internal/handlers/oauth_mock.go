@@ -0,0 +1,64 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/handlers/oauth.go
+
+// Package handlers is a generated GoMock package.
+package handlers
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockoauthManager is a mock of oauthManager interface.
+type MockoauthManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockoauthManagerMockRecorder
+}
+
+// MockoauthManagerMockRecorder is the mock recorder for MockoauthManager.
+type MockoauthManagerMockRecorder struct {
+	mock *MockoauthManager
+}
+
+// NewMockoauthManager creates a new mock instance.
+func NewMockoauthManager(ctrl *gomock.Controller) *MockoauthManager {
+	mock := &MockoauthManager{ctrl: ctrl}
+	mock.recorder = &MockoauthManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockoauthManager) EXPECT() *MockoauthManagerMockRecorder {
+	return m.recorder
+}
+
+// Begin mocks base method.
+func (m *MockoauthManager) Begin() (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Begin")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Begin indicates an expected call of Begin.
+func (mr *MockoauthManagerMockRecorder) Begin() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Begin", reflect.TypeOf((*MockoauthManager)(nil).Begin))
+}
+
+// Complete mocks base method.
+func (m *MockoauthManager) Complete(state, code string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Complete", state, code)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Complete indicates an expected call of Complete.
+func (mr *MockoauthManagerMockRecorder) Complete(state, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Complete", reflect.TypeOf((*MockoauthManager)(nil).Complete), state, code)
+}
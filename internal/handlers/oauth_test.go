@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"fmt"
+	"forum/internal/handlers/utils"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestOAuthLogin(t *testing.T) {
+	logger := slog.New(utils.DummyLogger{})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	oauthManager := NewMockoauthManager(ctrl)
+
+	oauthHandler := &OAuthHandler{
+		Logger:       logger,
+		OAuthManager: oauthManager,
+	}
+
+	path := "/api/oauth/login"
+	method := http.MethodGet
+
+	// good response
+	redirectURL := "https://idp.example.com/authorize?state=state"
+	oauthManager.EXPECT().Begin().Return(redirectURL, nil)
+
+	w := httptest.NewRecorder()
+	oauthHandler.Login(w, httptest.NewRequest(method, path, nil))
+
+	if w.Code != http.StatusFound {
+		t.Fatalf("expected status %d, got %d", http.StatusFound, w.Code)
+	}
+	if location := w.Header().Get("Location"); location != redirectURL {
+		t.Errorf("\nwant: %v\nhave: %v", redirectURL, location)
+	}
+
+	// Begin error
+	oauthManager.EXPECT().Begin().Return("", fmt.Errorf("cant save state"))
+
+	w = httptest.NewRecorder()
+	oauthHandler.Login(w, httptest.NewRequest(method, path, nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+}
+
+func TestOAuthCallback(t *testing.T) {
+	logger := slog.New(utils.DummyLogger{})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	oauthManager := NewMockoauthManager(ctrl)
+
+	oauthHandler := &OAuthHandler{
+		Logger:       logger,
+		OAuthManager: oauthManager,
+	}
+
+	path := "/api/oauth/callback"
+	method := http.MethodGet
+	handler := oauthHandler.Callback
+
+	// good response
+	expectedToken := "test_token"
+	oauthManager.EXPECT().Complete("state", "code").Return(expectedToken, nil)
+
+	response := &authResponse{}
+
+	test := utils.TestRequest{
+		Handler:        handler,
+		Request:        httptest.NewRequest(method, path+"?state=state&code=code", nil),
+		ExpectedStatus: http.StatusOK,
+		ResponsePtr:    response,
+	}
+
+	err := utils.SendTestRequest(test)
+	if err != nil {
+		t.Fatalf("expected nil, but was %v", err)
+	}
+	if response.Token != expectedToken {
+		t.Errorf("\nwant: %v\nhave: %v", expectedToken, response.Token)
+	}
+
+	// Complete error
+	oauthManager.EXPECT().Complete("state", "code").Return("", fmt.Errorf("bad nonce"))
+
+	test = utils.TestRequest{
+		Handler:        handler,
+		Request:        httptest.NewRequest(method, path+"?state=state&code=code", nil),
+		ExpectedStatus: http.StatusUnauthorized,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+
+	// Provider error
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(method, path+"?error=access_denied", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+
+	// Missing code
+	w = httptest.NewRecorder()
+	handler(w, httptest.NewRequest(method, path+"?state=state", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
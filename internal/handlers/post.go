@@ -1,26 +1,59 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 	"forum/internal/handlers/utils"
 	"forum/internal/models"
+	"forum/internal/tracing"
 
 	"github.com/gorilla/mux"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type postManager interface {
-	GetAll() ([]*models.Post, error)
-	GetAllByCategory(string) ([]*models.Post, error)
-	GetAllByUser(string) ([]*models.Post, error)
-	FindOne(postID string) (*models.Post, error)
-	Delete(postID string) error
+	GetAll(*models.Author) ([]*models.Post, error)
+	GetAllByCategory(string, *models.Author) ([]*models.Post, error)
+	GetAllByUser(string, *models.Author) ([]*models.Post, error)
+	GetFeed(*models.Author, models.Page) ([]*models.Post, error)
+	FindOne(ctx context.Context, postID string, viewer *models.Author) (*models.Post, error)
+	Delete(postID string, actor *models.Author, reason string) error
 	UpdateVotes(string, string, primitive.ObjectID) (*models.Post, error)
 	Create(*models.PostInput, *models.Author) (*models.Post, error)
-	DeleteComment(string, string) (*models.Post, error)
+	DeleteComment(string, string, *models.Author, string) (*models.Post, error)
 	AddComment(string, *models.CommentInput, *models.Author) (*models.Post, error)
+	Vote(string, *models.BallotInput, *models.Author) (*models.Post, error)
+	Save(string, string, *models.Author) error
+	Unsave(string, *models.Author) error
+	GetSaved(*models.Author, string, models.Page) ([]*models.SavedItem, error)
+	GetDrafts(*models.Author) ([]*models.Post, error)
+	Publish(string, *models.Author) (*models.Post, error)
+	SetState(string, string, *models.Author) (*models.Post, error)
+	Restore(string, *models.Author, string) (*models.Post, error)
+	RestoreComment(string, string, *models.Author, string) (*models.Post, error)
+	GetDeleted(*models.Author, models.Page) ([]*models.Post, error)
+}
+
+var maxFolderLength = 64
+
+/*
+Возвращает статус ответа для ошибки менеджера постов: 422 - содержимое отклонено фильтрами,
+403 - нет прав или пост закрыт модератором, 409 - пост в архиве, для остальных ошибок - fallback.
+*/
+func postErrorStatus(err error, fallback int) int {
+	rejected := &models.RejectedError{}
+	switch {
+	case errors.As(err, &rejected):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, models.ErrForbidden), errors.Is(err, models.ErrPostLocked):
+		return http.StatusForbidden
+	case errors.Is(err, models.ErrPostArchived):
+		return http.StatusConflict
+	}
+	return fallback
 }
 
 type PostHandler struct {
@@ -32,7 +65,10 @@ type PostHandler struct {
 func (ph *PostHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 	msg := utils.NewLogMsg(ph.Logger, r.URL.Path, r.Method)
 
-	posts, err := ph.PostManager.GetAll()
+	// пользователь есть в контексте, только если передан токен
+	viewer, _ := r.Context().Value(models.CtxKey("user")).(*models.Author)
+
+	posts, err := ph.PostManager.GetAll(viewer)
 	if err != nil {
 		msg.Set(err.Error(), http.StatusBadRequest)
 		utils.WriteError(w, msg)
@@ -78,7 +114,7 @@ func (ph *PostHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 	createdPost, err := ph.PostManager.Create(post, author)
 	if err != nil {
-		msg.Set(err.Error(), http.StatusUnprocessableEntity)
+		msg.Set(err.Error(), postErrorStatus(err, http.StatusUnprocessableEntity))
 		utils.WriteError(w, msg)
 		return
 	}
@@ -91,8 +127,10 @@ func (ph *PostHandler) Create(w http.ResponseWriter, r *http.Request) {
 func (ph *PostHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	msg := utils.NewLogMsg(ph.Logger, r.URL.Path, r.Method)
 
+	viewer, _ := r.Context().Value(models.CtxKey("user")).(*models.Author)
+
 	postID := mux.Vars(r)["postID"]
-	post, err := ph.PostManager.FindOne(postID)
+	post, err := ph.PostManager.FindOne(r.Context(), postID, viewer)
 	if err != nil {
 		msg.Set(err.Error(), http.StatusNotFound)
 		utils.WriteError(w, msg)
@@ -100,15 +138,19 @@ func (ph *PostHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	}
 
 	msg.Set("success", http.StatusOK)
+	_, span := tracing.Start(r.Context(), "PostHandler.GetByID serialize")
 	utils.WriteData(w, msg, post)
+	span.End()
 }
 
 // Хендлер, возвращающий все посты по category
 func (ph *PostHandler) GetAllByCategory(w http.ResponseWriter, r *http.Request) {
 	msg := utils.NewLogMsg(ph.Logger, r.URL.Path, r.Method)
 
+	viewer, _ := r.Context().Value(models.CtxKey("user")).(*models.Author)
+
 	category := mux.Vars(r)["category"]
-	posts, err := ph.PostManager.GetAllByCategory(category)
+	posts, err := ph.PostManager.GetAllByCategory(category, viewer)
 	if err != nil {
 		msg.Set(err.Error(), http.StatusNotFound)
 		utils.WriteError(w, msg)
@@ -123,8 +165,10 @@ func (ph *PostHandler) GetAllByCategory(w http.ResponseWriter, r *http.Request)
 func (ph *PostHandler) GetAllByUser(w http.ResponseWriter, r *http.Request) {
 	msg := utils.NewLogMsg(ph.Logger, r.URL.Path, r.Method)
 
+	viewer, _ := r.Context().Value(models.CtxKey("user")).(*models.Author)
+
 	username := mux.Vars(r)["username"]
-	posts, err := ph.PostManager.GetAllByUser(username)
+	posts, err := ph.PostManager.GetAllByUser(username, viewer)
 	if err != nil {
 		msg.Set(err.Error(), http.StatusNotFound)
 		utils.WriteError(w, msg)
@@ -135,14 +179,50 @@ func (ph *PostHandler) GetAllByUser(w http.ResponseWriter, r *http.Request) {
 	utils.WriteData(w, msg, posts)
 }
 
-// Хендлер удаления поста с postID
+// Хендлер, возвращающий ленту текущего пользователя постранично
+func (ph *PostHandler) GetFeed(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(ph.Logger, r.URL.Path, r.Method)
+
+	page, err := utils.ReadPage(r)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusBadRequest)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	author, ok := r.Context().Value(models.CtxKey("user")).(*models.Author)
+	if !ok {
+		msg.Set("bad context value by key user", http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	posts, err := ph.PostManager.GetFeed(author, page)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	msg.Set("success", http.StatusOK)
+	utils.WriteData(w, msg, posts)
+}
+
+// Хендлер удаления поста с postID, причина для журнала модерации передается в query reason
 func (ph *PostHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	msg := utils.NewLogMsg(ph.Logger, r.URL.Path, r.Method)
 
+	actor, ok := r.Context().Value(models.CtxKey("user")).(*models.Author)
+	if !ok {
+		msg.Set("bad context value by key user", http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
 	postID := mux.Vars(r)["postID"]
-	err := ph.PostManager.Delete(postID)
+	err := ph.PostManager.Delete(postID, actor, r.URL.Query().Get("reason"))
 	if err != nil {
-		msg.Set(err.Error(), http.StatusNotFound)
+		msg.Set(err.Error(), postErrorStatus(err, http.StatusNotFound))
 		utils.WriteError(w, msg)
 		return
 	}
@@ -170,7 +250,7 @@ func (ph *PostHandler) UpdateVotes(w http.ResponseWriter, r *http.Request) {
 
 	post, err := ph.PostManager.UpdateVotes(postID, action, author.ID)
 	if err != nil {
-		msg.Set(err.Error(), http.StatusNotFound)
+		msg.Set(err.Error(), postErrorStatus(err, http.StatusNotFound))
 		utils.WriteError(w, msg)
 		return
 	}
@@ -216,7 +296,7 @@ func (ph *PostHandler) AddComment(w http.ResponseWriter, r *http.Request) {
 
 	post, err := ph.PostManager.AddComment(postID, comment, author)
 	if err != nil {
-		msg.Set(err.Error(), http.StatusNotFound)
+		msg.Set(err.Error(), postErrorStatus(err, http.StatusNotFound))
 		utils.WriteError(w, msg)
 		return
 	}
@@ -225,14 +305,190 @@ func (ph *PostHandler) AddComment(w http.ResponseWriter, r *http.Request) {
 	utils.WriteData(w, msg, post)
 }
 
-// Хендлер удаления комментариев
+// Хендлер удаления комментариев, причина для журнала модерации передается в query reason
 func (ph *PostHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
 	msg := utils.NewLogMsg(ph.Logger, r.URL.Path, r.Method)
 
+	actor, ok := r.Context().Value(models.CtxKey("user")).(*models.Author)
+	if !ok {
+		msg.Set("bad context value by key user", http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
 	vars := mux.Vars(r)
 	postID := vars["postID"]
 	commentID := vars["commentID"]
-	post, err := ph.PostManager.DeleteComment(postID, commentID)
+	post, err := ph.PostManager.DeleteComment(postID, commentID, actor, r.URL.Query().Get("reason"))
+	if err != nil {
+		msg.Set(err.Error(), postErrorStatus(err, http.StatusNotFound))
+		utils.WriteError(w, msg)
+		return
+	}
+
+	msg.Set("success", http.StatusOK)
+	utils.WriteData(w, msg, post)
+}
+
+// Хендлер голосования в опросе, в теле передаются id выбранных вариантов
+func (ph *PostHandler) Vote(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(ph.Logger, r.URL.Path, r.Method)
+
+	data, err := utils.ReadRequestBody(r)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusBadRequest)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	ballot := &models.BallotInput{}
+	err = json.Unmarshal(data, ballot)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	author, ok := r.Context().Value(models.CtxKey("user")).(*models.Author)
+	if !ok {
+		msg.Set("bad context value by key user", http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	postID := mux.Vars(r)["postID"]
+	post, err := ph.PostManager.Vote(postID, ballot, author)
+	if err != nil {
+		msg.Set(err.Error(), postErrorStatus(err, http.StatusUnprocessableEntity))
+		utils.WriteError(w, msg)
+		return
+	}
+
+	msg.Set("success", http.StatusOK)
+	utils.WriteData(w, msg, post)
+}
+
+// Хендлер добавления поста в закладки, папка передается параметром folder
+func (ph *PostHandler) Save(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(ph.Logger, r.URL.Path, r.Method)
+
+	author, ok := r.Context().Value(models.CtxKey("user")).(*models.Author)
+	if !ok {
+		msg.Set("bad context value by key user", http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	folder := r.URL.Query().Get("folder")
+	if len(folder) > maxFolderLength {
+		msg.Set("folder name is too long", http.StatusBadRequest)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	postID := mux.Vars(r)["postID"]
+	err := ph.PostManager.Save(postID, folder, author)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusNotFound)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	msg.Set("success", http.StatusOK)
+	utils.WriteData(w, msg, map[string]interface{}{
+		"message": "success",
+	})
+}
+
+// Хендлер удаления поста из закладок
+func (ph *PostHandler) Unsave(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(ph.Logger, r.URL.Path, r.Method)
+
+	author, ok := r.Context().Value(models.CtxKey("user")).(*models.Author)
+	if !ok {
+		msg.Set("bad context value by key user", http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	postID := mux.Vars(r)["postID"]
+	err := ph.PostManager.Unsave(postID, author)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusNotFound)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	msg.Set("success", http.StatusOK)
+	utils.WriteData(w, msg, map[string]interface{}{
+		"message": "success",
+	})
+}
+
+// Хендлер, возвращающий закладки текущего пользователя постранично, с фильтром по папке folder
+func (ph *PostHandler) GetSaved(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(ph.Logger, r.URL.Path, r.Method)
+
+	page, err := utils.ReadPage(r)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusBadRequest)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	author, ok := r.Context().Value(models.CtxKey("user")).(*models.Author)
+	if !ok {
+		msg.Set("bad context value by key user", http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	items, err := ph.PostManager.GetSaved(author, r.URL.Query().Get("folder"), page)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	msg.Set("success", http.StatusOK)
+	utils.WriteData(w, msg, items)
+}
+
+// Хендлер, возвращающий черновики и отложенные посты пользователя
+func (ph *PostHandler) GetDrafts(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(ph.Logger, r.URL.Path, r.Method)
+
+	author, ok := r.Context().Value(models.CtxKey("user")).(*models.Author)
+	if !ok {
+		msg.Set("bad context value by key user", http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	posts, err := ph.PostManager.GetDrafts(author)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	msg.Set("success", http.StatusOK)
+	utils.WriteData(w, msg, posts)
+}
+
+// Хендлер немедленной публикации черновика или отложенного поста c id - postID
+func (ph *PostHandler) Publish(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(ph.Logger, r.URL.Path, r.Method)
+
+	author, ok := r.Context().Value(models.CtxKey("user")).(*models.Author)
+	if !ok {
+		msg.Set("bad context value by key user", http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	postID := mux.Vars(r)["postID"]
+	post, err := ph.PostManager.Publish(postID, author)
 	if err != nil {
 		msg.Set(err.Error(), http.StatusNotFound)
 		utils.WriteError(w, msg)
@@ -242,3 +498,100 @@ func (ph *PostHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
 	msg.Set("success", http.StatusOK)
 	utils.WriteData(w, msg, post)
 }
+
+// Хендлер модерации поста c id - postID, state - pin, unpin, lock или unlock
+func (ph *PostHandler) SetState(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(ph.Logger, r.URL.Path, r.Method)
+
+	moderator, ok := r.Context().Value(models.CtxKey("user")).(*models.Author)
+	if !ok {
+		msg.Set("bad context value by key user", http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	vars := mux.Vars(r)
+	post, err := ph.PostManager.SetState(vars["postID"], vars["state"], moderator)
+	if err != nil {
+		msg.Set(err.Error(), postErrorStatus(err, http.StatusNotFound))
+		utils.WriteError(w, msg)
+		return
+	}
+
+	msg.Set("success", http.StatusOK)
+	utils.WriteData(w, msg, post)
+}
+
+// Хендлер восстановления удаленного поста c id - postID, причина передается в query reason
+func (ph *PostHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(ph.Logger, r.URL.Path, r.Method)
+
+	moderator, ok := r.Context().Value(models.CtxKey("user")).(*models.Author)
+	if !ok {
+		msg.Set("bad context value by key user", http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	post, err := ph.PostManager.Restore(mux.Vars(r)["postID"], moderator, r.URL.Query().Get("reason"))
+	if err != nil {
+		msg.Set(err.Error(), postErrorStatus(err, http.StatusNotFound))
+		utils.WriteError(w, msg)
+		return
+	}
+
+	msg.Set("success", http.StatusOK)
+	utils.WriteData(w, msg, post)
+}
+
+// Хендлер восстановления удаленного комментария commentID к посту postID
+func (ph *PostHandler) RestoreComment(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(ph.Logger, r.URL.Path, r.Method)
+
+	moderator, ok := r.Context().Value(models.CtxKey("user")).(*models.Author)
+	if !ok {
+		msg.Set("bad context value by key user", http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	vars := mux.Vars(r)
+	post, err := ph.PostManager.RestoreComment(vars["postID"], vars["commentID"], moderator, r.URL.Query().Get("reason"))
+	if err != nil {
+		msg.Set(err.Error(), postErrorStatus(err, http.StatusNotFound))
+		utils.WriteError(w, msg)
+		return
+	}
+
+	msg.Set("success", http.StatusOK)
+	utils.WriteData(w, msg, post)
+}
+
+// Хендлер корзины модератора: удаленные посты и посты с удаленными комментариями
+func (ph *PostHandler) GetDeleted(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(ph.Logger, r.URL.Path, r.Method)
+
+	page, err := utils.ReadPage(r)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusBadRequest)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	moderator, ok := r.Context().Value(models.CtxKey("user")).(*models.Author)
+	if !ok {
+		msg.Set("bad context value by key user", http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	posts, err := ph.PostManager.GetDeleted(moderator, page)
+	if err != nil {
+		msg.Set(err.Error(), postErrorStatus(err, http.StatusInternalServerError))
+		utils.WriteError(w, msg)
+		return
+	}
+
+	msg.Set("success", http.StatusOK)
+	utils.WriteData(w, msg, posts)
+}
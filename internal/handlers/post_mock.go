@@ -5,6 +5,7 @@
 package handlers
 
 import (
+	context "context"
 	models "forum/internal/models"
 	reflect "reflect"
 
@@ -66,92 +67,240 @@ func (mr *MockpostManagerMockRecorder) Create(arg0, arg1 interface{}) *gomock.Ca
 }
 
 // Delete mocks base method.
-func (m *MockpostManager) Delete(postID string) error {
+func (m *MockpostManager) Delete(postID string, actor *models.Author, reason string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", postID)
+	ret := m.ctrl.Call(m, "Delete", postID, actor, reason)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockpostManagerMockRecorder) Delete(postID interface{}) *gomock.Call {
+func (mr *MockpostManagerMockRecorder) Delete(postID, actor, reason interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockpostManager)(nil).Delete), postID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockpostManager)(nil).Delete), postID, actor, reason)
 }
 
 // DeleteComment mocks base method.
-func (m *MockpostManager) DeleteComment(arg0, arg1 string) (*models.Post, error) {
+func (m *MockpostManager) DeleteComment(arg0, arg1 string, arg2 *models.Author, arg3 string) (*models.Post, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteComment", arg0, arg1)
+	ret := m.ctrl.Call(m, "DeleteComment", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(*models.Post)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // DeleteComment indicates an expected call of DeleteComment.
-func (mr *MockpostManagerMockRecorder) DeleteComment(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockpostManagerMockRecorder) DeleteComment(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteComment", reflect.TypeOf((*MockpostManager)(nil).DeleteComment), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteComment", reflect.TypeOf((*MockpostManager)(nil).DeleteComment), arg0, arg1, arg2, arg3)
 }
 
 // FindOne mocks base method.
-func (m *MockpostManager) FindOne(postID string) (*models.Post, error) {
+func (m *MockpostManager) FindOne(ctx context.Context, postID string, viewer *models.Author) (*models.Post, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindOne", postID)
+	ret := m.ctrl.Call(m, "FindOne", ctx, postID, viewer)
 	ret0, _ := ret[0].(*models.Post)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // FindOne indicates an expected call of FindOne.
-func (mr *MockpostManagerMockRecorder) FindOne(postID interface{}) *gomock.Call {
+func (mr *MockpostManagerMockRecorder) FindOne(ctx, postID, viewer interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOne", reflect.TypeOf((*MockpostManager)(nil).FindOne), postID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOne", reflect.TypeOf((*MockpostManager)(nil).FindOne), ctx, postID, viewer)
 }
 
 // GetAll mocks base method.
-func (m *MockpostManager) GetAll() ([]*models.Post, error) {
+func (m *MockpostManager) GetAll(arg0 *models.Author) ([]*models.Post, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAll")
+	ret := m.ctrl.Call(m, "GetAll", arg0)
 	ret0, _ := ret[0].([]*models.Post)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAll indicates an expected call of GetAll.
-func (mr *MockpostManagerMockRecorder) GetAll() *gomock.Call {
+func (mr *MockpostManagerMockRecorder) GetAll(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockpostManager)(nil).GetAll))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockpostManager)(nil).GetAll), arg0)
 }
 
 // GetAllByCategory mocks base method.
-func (m *MockpostManager) GetAllByCategory(arg0 string) ([]*models.Post, error) {
+func (m *MockpostManager) GetAllByCategory(arg0 string, arg1 *models.Author) ([]*models.Post, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllByCategory", arg0)
+	ret := m.ctrl.Call(m, "GetAllByCategory", arg0, arg1)
 	ret0, _ := ret[0].([]*models.Post)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAllByCategory indicates an expected call of GetAllByCategory.
-func (mr *MockpostManagerMockRecorder) GetAllByCategory(arg0 interface{}) *gomock.Call {
+func (mr *MockpostManagerMockRecorder) GetAllByCategory(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByCategory", reflect.TypeOf((*MockpostManager)(nil).GetAllByCategory), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByCategory", reflect.TypeOf((*MockpostManager)(nil).GetAllByCategory), arg0, arg1)
 }
 
 // GetAllByUser mocks base method.
-func (m *MockpostManager) GetAllByUser(arg0 string) ([]*models.Post, error) {
+func (m *MockpostManager) GetAllByUser(arg0 string, arg1 *models.Author) ([]*models.Post, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllByUser", arg0)
+	ret := m.ctrl.Call(m, "GetAllByUser", arg0, arg1)
 	ret0, _ := ret[0].([]*models.Post)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAllByUser indicates an expected call of GetAllByUser.
-func (mr *MockpostManagerMockRecorder) GetAllByUser(arg0 interface{}) *gomock.Call {
+func (mr *MockpostManagerMockRecorder) GetAllByUser(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByUser", reflect.TypeOf((*MockpostManager)(nil).GetAllByUser), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByUser", reflect.TypeOf((*MockpostManager)(nil).GetAllByUser), arg0, arg1)
+}
+
+// GetDeleted mocks base method.
+func (m *MockpostManager) GetDeleted(arg0 *models.Author, arg1 models.Page) ([]*models.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeleted", arg0, arg1)
+	ret0, _ := ret[0].([]*models.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeleted indicates an expected call of GetDeleted.
+func (mr *MockpostManagerMockRecorder) GetDeleted(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeleted", reflect.TypeOf((*MockpostManager)(nil).GetDeleted), arg0, arg1)
+}
+
+// GetDrafts mocks base method.
+func (m *MockpostManager) GetDrafts(arg0 *models.Author) ([]*models.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDrafts", arg0)
+	ret0, _ := ret[0].([]*models.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDrafts indicates an expected call of GetDrafts.
+func (mr *MockpostManagerMockRecorder) GetDrafts(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDrafts", reflect.TypeOf((*MockpostManager)(nil).GetDrafts), arg0)
+}
+
+// GetFeed mocks base method.
+func (m *MockpostManager) GetFeed(arg0 *models.Author, arg1 models.Page) ([]*models.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFeed", arg0, arg1)
+	ret0, _ := ret[0].([]*models.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFeed indicates an expected call of GetFeed.
+func (mr *MockpostManagerMockRecorder) GetFeed(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFeed", reflect.TypeOf((*MockpostManager)(nil).GetFeed), arg0, arg1)
+}
+
+// GetSaved mocks base method.
+func (m *MockpostManager) GetSaved(arg0 *models.Author, arg1 string, arg2 models.Page) ([]*models.SavedItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSaved", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*models.SavedItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSaved indicates an expected call of GetSaved.
+func (mr *MockpostManagerMockRecorder) GetSaved(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSaved", reflect.TypeOf((*MockpostManager)(nil).GetSaved), arg0, arg1, arg2)
+}
+
+// Publish mocks base method.
+func (m *MockpostManager) Publish(arg0 string, arg1 *models.Author) (*models.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", arg0, arg1)
+	ret0, _ := ret[0].(*models.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockpostManagerMockRecorder) Publish(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockpostManager)(nil).Publish), arg0, arg1)
+}
+
+// Restore mocks base method.
+func (m *MockpostManager) Restore(arg0 string, arg1 *models.Author, arg2 string) (*models.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockpostManagerMockRecorder) Restore(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockpostManager)(nil).Restore), arg0, arg1, arg2)
+}
+
+// RestoreComment mocks base method.
+func (m *MockpostManager) RestoreComment(arg0, arg1 string, arg2 *models.Author, arg3 string) (*models.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreComment", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreComment indicates an expected call of RestoreComment.
+func (mr *MockpostManagerMockRecorder) RestoreComment(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreComment", reflect.TypeOf((*MockpostManager)(nil).RestoreComment), arg0, arg1, arg2, arg3)
+}
+
+// Save mocks base method.
+func (m *MockpostManager) Save(arg0, arg1 string, arg2 *models.Author) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockpostManagerMockRecorder) Save(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockpostManager)(nil).Save), arg0, arg1, arg2)
+}
+
+// SetState mocks base method.
+func (m *MockpostManager) SetState(arg0, arg1 string, arg2 *models.Author) (*models.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetState", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetState indicates an expected call of SetState.
+func (mr *MockpostManagerMockRecorder) SetState(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetState", reflect.TypeOf((*MockpostManager)(nil).SetState), arg0, arg1, arg2)
+}
+
+// Unsave mocks base method.
+func (m *MockpostManager) Unsave(arg0 string, arg1 *models.Author) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unsave", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unsave indicates an expected call of Unsave.
+func (mr *MockpostManagerMockRecorder) Unsave(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unsave", reflect.TypeOf((*MockpostManager)(nil).Unsave), arg0, arg1)
 }
 
 // UpdateVotes mocks base method.
@@ -168,3 +317,18 @@ func (mr *MockpostManagerMockRecorder) UpdateVotes(arg0, arg1, arg2 interface{})
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateVotes", reflect.TypeOf((*MockpostManager)(nil).UpdateVotes), arg0, arg1, arg2)
 }
+
+// Vote mocks base method.
+func (m *MockpostManager) Vote(arg0 string, arg1 *models.BallotInput, arg2 *models.Author) (*models.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Vote", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Vote indicates an expected call of Vote.
+func (mr *MockpostManagerMockRecorder) Vote(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Vote", reflect.TypeOf((*MockpostManager)(nil).Vote), arg0, arg1, arg2)
+}
@@ -11,6 +11,7 @@ import (
 	"forum/internal/handlers/utils"
 	"forum/internal/models"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -239,6 +240,21 @@ func TestAddComment(t *testing.T) {
 		t.Fatal("expected error, but was nil")
 	}
 
+	// rejected by filters, reason is returned to the client
+	postManager.EXPECT().AddComment(post.ID.Hex(), commentInput, author).Return(nil, &models.RejectedError{Reason: "too many links"})
+
+	request = httptest.NewRequest(method, path, bytes.NewBuffer(body))
+	request.Header.Set("Content-Type", "application/json")
+	request = mux.SetURLVars(request, vars)
+
+	ctx = context.WithValue(request.Context(), models.CtxKey("user"), author)
+
+	recorder := httptest.NewRecorder()
+	handler(recorder, request.WithContext(ctx))
+	if recorder.Code != http.StatusUnprocessableEntity || !strings.Contains(recorder.Body.String(), "too many links") {
+		t.Errorf("want 422 with reason, have %d %q", recorder.Code, recorder.Body.String())
+	}
+
 	// Validate, marshall, read request
 	badValidItem := &models.PostInput{
 		Type:     "audio",
@@ -383,15 +399,17 @@ func TestDeleteComment(t *testing.T) {
 	handler := postHandler.DeleteComment
 
 	// good response
-	postManager.EXPECT().DeleteComment(post.ID.Hex(), comment.ID.Hex()).Return(post, nil)
+	postManager.EXPECT().DeleteComment(post.ID.Hex(), comment.ID.Hex(), author, "spam").Return(post, nil)
 
-	request := httptest.NewRequest(method, path, nil)
+	request := httptest.NewRequest(method, path+"?reason=spam", nil)
 
 	vars := map[string]string{
 		"postID":    post.ID.Hex(),
 		"commentID": comment.ID.Hex(),
 	}
 	request = mux.SetURLVars(request, vars)
+	ctx := context.WithValue(request.Context(), models.CtxKey("user"), author)
+	request = request.WithContext(ctx)
 
 	response := &models.Post{}
 
@@ -411,10 +429,12 @@ func TestDeleteComment(t *testing.T) {
 	}
 
 	// Delete comment error
-	postManager.EXPECT().DeleteComment(post.ID.Hex(), comment.ID.Hex()).Return(nil, fmt.Errorf("some error with comment"))
+	postManager.EXPECT().DeleteComment(post.ID.Hex(), comment.ID.Hex(), author, "").Return(nil, fmt.Errorf("some error with comment"))
 
 	request = httptest.NewRequest(method, path, nil)
 	request = mux.SetURLVars(request, vars)
+	ctx = context.WithValue(request.Context(), models.CtxKey("user"), author)
+	request = request.WithContext(ctx)
 
 	test = utils.TestRequest{
 		Handler:        handler,
@@ -453,14 +473,16 @@ func TestDelete(t *testing.T) {
 	handler := postHandler.Delete
 
 	// good response
-	postManager.EXPECT().Delete(post.ID.Hex()).Return(nil)
+	postManager.EXPECT().Delete(post.ID.Hex(), author, "spam").Return(nil)
 
-	request := httptest.NewRequest(method, path, nil)
+	request := httptest.NewRequest(method, path+"?reason=spam", nil)
 
 	vars := map[string]string{
 		"postID": post.ID.Hex(),
 	}
 	request = mux.SetURLVars(request, vars)
+	ctx := context.WithValue(request.Context(), models.CtxKey("user"), author)
+	request = request.WithContext(ctx)
 
 	response := &successResponse{}
 
@@ -480,11 +502,13 @@ func TestDelete(t *testing.T) {
 	}
 
 	// Delete comment error
-	postManager.EXPECT().Delete(post.ID.Hex()).Return(fmt.Errorf("some error when try to delete"))
+	postManager.EXPECT().Delete(post.ID.Hex(), author, "").Return(fmt.Errorf("some error when try to delete"))
 
 	request = httptest.NewRequest(method, path, nil)
 
 	request = mux.SetURLVars(request, vars)
+	ctx = context.WithValue(request.Context(), models.CtxKey("user"), author)
+	request = request.WithContext(ctx)
 
 	test = utils.TestRequest{
 		Handler:        handler,
@@ -521,7 +545,7 @@ func TestGetAllByUser(t *testing.T) {
 
 	// good response
 	expected := []*models.Post{post, post2}
-	postManager.EXPECT().GetAllByUser(author.Username).Return(expected, nil)
+	postManager.EXPECT().GetAllByUser(author.Username, nil).Return(expected, nil)
 
 	request := httptest.NewRequest(method, path, nil)
 
@@ -550,7 +574,7 @@ func TestGetAllByUser(t *testing.T) {
 	}
 
 	// Get all by user error
-	postManager.EXPECT().GetAllByUser(author.Username).Return(nil, fmt.Errorf("some error when try to filter by username"))
+	postManager.EXPECT().GetAllByUser(author.Username, nil).Return(nil, fmt.Errorf("some error when try to filter by username"))
 
 	request = httptest.NewRequest(method, path, nil)
 
@@ -591,7 +615,7 @@ func TestGetAllByCategory(t *testing.T) {
 
 	// good response
 	expected := []*models.Post{post, post2}
-	postManager.EXPECT().GetAllByCategory(post.Category).Return(expected, nil)
+	postManager.EXPECT().GetAllByCategory(post.Category, nil).Return(expected, nil)
 
 	request := httptest.NewRequest(method, path, nil)
 
@@ -620,7 +644,7 @@ func TestGetAllByCategory(t *testing.T) {
 	}
 
 	// Get all by category error
-	postManager.EXPECT().GetAllByCategory(post.Category).Return(nil, fmt.Errorf("some error when try to filter by category"))
+	postManager.EXPECT().GetAllByCategory(post.Category, nil).Return(nil, fmt.Errorf("some error when try to filter by category"))
 
 	request = httptest.NewRequest(method, path, nil)
 
@@ -659,7 +683,7 @@ func TestGetByID(t *testing.T) {
 	handler := postHandler.GetByID
 
 	// good response
-	postManager.EXPECT().FindOne(post.ID.Hex()).Return(post, nil)
+	postManager.EXPECT().FindOne(gomock.Any(), post.ID.Hex(), nil).Return(post, nil)
 
 	request := httptest.NewRequest(method, path, nil)
 
@@ -686,7 +710,7 @@ func TestGetByID(t *testing.T) {
 	}
 
 	// FindOne error
-	postManager.EXPECT().FindOne(post.ID.Hex()).Return(nil, fmt.Errorf("some error when try to find by id"))
+	postManager.EXPECT().FindOne(gomock.Any(), post.ID.Hex(), nil).Return(nil, fmt.Errorf("some error when try to find by id"))
 
 	request = httptest.NewRequest(method, path, nil)
 
@@ -727,7 +751,7 @@ func TestGetAll(t *testing.T) {
 
 	// good response
 	expected := []*models.Post{post, post2}
-	postManager.EXPECT().GetAll().Return(expected, nil)
+	postManager.EXPECT().GetAll(nil).Return(expected, nil)
 
 	request := httptest.NewRequest(method, path, nil)
 
@@ -751,7 +775,7 @@ func TestGetAll(t *testing.T) {
 	}
 
 	// Get all by category error
-	postManager.EXPECT().GetAll().Return(nil, fmt.Errorf("some error when try to get all"))
+	postManager.EXPECT().GetAll(nil).Return(nil, fmt.Errorf("some error when try to get all"))
 
 	request = httptest.NewRequest(method, path, nil)
 
@@ -765,6 +789,24 @@ func TestGetAll(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error, but was nil")
 	}
+
+	// Viewer from context
+	postManager.EXPECT().GetAll(author).Return(expected, nil)
+
+	request = httptest.NewRequest(method, path, nil)
+	ctx := context.WithValue(request.Context(), models.CtxKey("user"), author)
+
+	test = utils.TestRequest{
+		Handler:        handler,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusOK,
+		ResponsePtr:    response,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err != nil {
+		t.Fatalf("expected nil, but was %v", err)
+	}
 }
 
 func getDefaultPost(author *models.Author) *models.Post {
@@ -796,3 +838,775 @@ func getDefaultAuthor() *models.Author {
 	}
 	return author
 }
+
+func TestGetFeed(t *testing.T) {
+	logger := slog.New(utils.DummyLogger{})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	postManager := NewMockpostManager(ctrl)
+
+	postHandler := &PostHandler{
+		Logger:      logger,
+		PostManager: postManager,
+	}
+
+	path := "/api/feed"
+	method := http.MethodGet
+	handler := postHandler.GetFeed
+
+	author := getDefaultAuthor()
+	post := getDefaultPost(author)
+	expected := []*models.Post{post}
+
+	// good response
+	page := models.Page{Number: 2, Size: 10}
+	postManager.EXPECT().GetFeed(author, page).Return(expected, nil)
+
+	request := httptest.NewRequest(method, path+"?page=2&limit=10", nil)
+	ctx := context.WithValue(request.Context(), models.CtxKey("user"), author)
+
+	response := &[]*models.Post{}
+
+	test := utils.TestRequest{
+		Handler:        handler,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusOK,
+		ResponsePtr:    response,
+	}
+
+	err := utils.SendTestRequest(test)
+	if err != nil {
+		t.Fatalf("expected nil, but was %v", err)
+	}
+	if !reflect.DeepEqual(*response, expected) {
+		t.Errorf("\nwant: %v\nhave: %v", expected, *response)
+	}
+
+	// Bad page
+	request = httptest.NewRequest(method, path+"?page=0", nil)
+	ctx = context.WithValue(request.Context(), models.CtxKey("user"), author)
+
+	test = utils.TestRequest{
+		Handler:        handler,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusBadRequest,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+
+	// Context error
+	test = utils.TestRequest{
+		Handler:        handler,
+		Request:        httptest.NewRequest(method, path, nil),
+		ExpectedStatus: http.StatusUnprocessableEntity,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+
+	// Get feed error
+	postManager.EXPECT().GetFeed(author, models.Page{Number: 1, Size: 20}).Return(nil, fmt.Errorf("some error when try to get feed"))
+
+	request = httptest.NewRequest(method, path, nil)
+	ctx = context.WithValue(request.Context(), models.CtxKey("user"), author)
+
+	test = utils.TestRequest{
+		Handler:        handler,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusInternalServerError,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+}
+
+func TestVote(t *testing.T) {
+	logger := slog.New(utils.DummyLogger{})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	postManager := NewMockpostManager(ctrl)
+
+	postHandler := &PostHandler{
+		Logger:      logger,
+		PostManager: postManager,
+	}
+
+	author := getDefaultAuthor()
+	post := getDefaultPost(author)
+	post.Type = "poll"
+	post.Poll = &models.Poll{
+		Options:     []models.PollOption{{ID: 0, Text: "yes", Votes: 1}, {ID: 1, Text: "no"}},
+		TotalVoters: 1,
+		Voted:       true,
+	}
+
+	path := "/api/post/" + post.ID.Hex() + "/poll"
+	vars := map[string]string{
+		"postID": post.ID.Hex(),
+	}
+	ballot := &models.BallotInput{Options: []int{0}}
+
+	// good response
+	postManager.EXPECT().Vote(post.ID.Hex(), ballot, author).Return(post, nil)
+
+	request := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"options":[0]}`))
+	request.Header.Set("Content-Type", "application/json")
+	request = mux.SetURLVars(request, vars)
+	ctx := context.WithValue(request.Context(), models.CtxKey("user"), author)
+
+	response := &models.Post{}
+
+	test := utils.TestRequest{
+		Handler:        postHandler.Vote,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusOK,
+		ResponsePtr:    response,
+	}
+
+	err := utils.SendTestRequest(test)
+	if err != nil {
+		t.Fatalf("expected nil, but was %v", err)
+	}
+	if !reflect.DeepEqual(response.Poll, post.Poll) {
+		t.Errorf("\nwant: %v\nhave: %v", post.Poll, response.Poll)
+	}
+
+	// bad json
+	request = httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"options":"yes"}`))
+	request.Header.Set("Content-Type", "application/json")
+	request = mux.SetURLVars(request, vars)
+	ctx = context.WithValue(request.Context(), models.CtxKey("user"), author)
+
+	test = utils.TestRequest{
+		Handler:        postHandler.Vote,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusUnprocessableEntity,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+
+	// Vote error
+	postManager.EXPECT().Vote(post.ID.Hex(), ballot, author).Return(nil, fmt.Errorf("you have already voted"))
+
+	request = httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"options":[0]}`))
+	request.Header.Set("Content-Type", "application/json")
+	request = mux.SetURLVars(request, vars)
+	ctx = context.WithValue(request.Context(), models.CtxKey("user"), author)
+
+	test = utils.TestRequest{
+		Handler:        postHandler.Vote,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusUnprocessableEntity,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+}
+
+func TestSave(t *testing.T) {
+	logger := slog.New(utils.DummyLogger{})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	postManager := NewMockpostManager(ctrl)
+
+	postHandler := &PostHandler{
+		Logger:      logger,
+		PostManager: postManager,
+	}
+
+	author := getDefaultAuthor()
+	post := getDefaultPost(author)
+
+	path := "/api/post/" + post.ID.Hex() + "/save"
+	vars := map[string]string{
+		"postID": post.ID.Hex(),
+	}
+
+	// good response
+	postManager.EXPECT().Save(post.ID.Hex(), "later", author).Return(nil)
+
+	request := httptest.NewRequest(http.MethodPost, path+"?folder=later", nil)
+	request = mux.SetURLVars(request, vars)
+	ctx := context.WithValue(request.Context(), models.CtxKey("user"), author)
+
+	response := map[string]interface{}{}
+
+	test := utils.TestRequest{
+		Handler:        postHandler.Save,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusOK,
+		ResponsePtr:    &response,
+	}
+
+	err := utils.SendTestRequest(test)
+	if err != nil {
+		t.Fatalf("expected nil, but was %v", err)
+	}
+
+	// Too long folder
+	request = httptest.NewRequest(http.MethodPost, path+"?folder="+strings.Repeat("a", maxFolderLength+1), nil)
+	request = mux.SetURLVars(request, vars)
+	ctx = context.WithValue(request.Context(), models.CtxKey("user"), author)
+
+	test = utils.TestRequest{
+		Handler:        postHandler.Save,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusBadRequest,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+
+	// Save error
+	postManager.EXPECT().Save(post.ID.Hex(), "", author).Return(fmt.Errorf("no post found"))
+
+	request = httptest.NewRequest(http.MethodPost, path, nil)
+	request = mux.SetURLVars(request, vars)
+	ctx = context.WithValue(request.Context(), models.CtxKey("user"), author)
+
+	test = utils.TestRequest{
+		Handler:        postHandler.Save,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusNotFound,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+
+	// Unsave
+	postManager.EXPECT().Unsave(post.ID.Hex(), author).Return(nil)
+
+	request = httptest.NewRequest(http.MethodDelete, path, nil)
+	request = mux.SetURLVars(request, vars)
+	ctx = context.WithValue(request.Context(), models.CtxKey("user"), author)
+
+	test = utils.TestRequest{
+		Handler:        postHandler.Unsave,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusOK,
+		ResponsePtr:    &response,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err != nil {
+		t.Fatalf("expected nil, but was %v", err)
+	}
+
+	// Context error
+	request = httptest.NewRequest(http.MethodDelete, path, nil)
+
+	test = utils.TestRequest{
+		Handler:        postHandler.Unsave,
+		Request:        mux.SetURLVars(request, vars),
+		ExpectedStatus: http.StatusUnprocessableEntity,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+}
+
+func TestGetSaved(t *testing.T) {
+	logger := slog.New(utils.DummyLogger{})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	postManager := NewMockpostManager(ctrl)
+
+	postHandler := &PostHandler{
+		Logger:      logger,
+		PostManager: postManager,
+	}
+
+	path := "/api/me/saved"
+	method := http.MethodGet
+	handler := postHandler.GetSaved
+
+	author := getDefaultAuthor()
+	post := getDefaultPost(author)
+	expected := []*models.SavedItem{
+		{
+			PostID: post.ID,
+			Folder: "later",
+			Saved:  post.Created,
+			Post:   post,
+		},
+		{
+			PostID:  primitive.NewObjectID(),
+			Folder:  "later",
+			Saved:   post.Created,
+			Deleted: true,
+		},
+	}
+
+	// good response
+	postManager.EXPECT().GetSaved(author, "later", models.Page{Number: 1, Size: 20}).Return(expected, nil)
+
+	request := httptest.NewRequest(method, path+"?folder=later", nil)
+	ctx := context.WithValue(request.Context(), models.CtxKey("user"), author)
+
+	response := &[]*models.SavedItem{}
+
+	test := utils.TestRequest{
+		Handler:        handler,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusOK,
+		ResponsePtr:    response,
+	}
+
+	err := utils.SendTestRequest(test)
+	if err != nil {
+		t.Fatalf("expected nil, but was %v", err)
+	}
+	if !reflect.DeepEqual(*response, expected) {
+		t.Errorf("\nwant: %v\nhave: %v", expected, *response)
+	}
+
+	// Get saved error
+	postManager.EXPECT().GetSaved(author, "", models.Page{Number: 1, Size: 20}).Return(nil, fmt.Errorf("some error when try to get saved"))
+
+	request = httptest.NewRequest(method, path, nil)
+	ctx = context.WithValue(request.Context(), models.CtxKey("user"), author)
+
+	test = utils.TestRequest{
+		Handler:        handler,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusInternalServerError,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+}
+
+func TestPublish(t *testing.T) {
+	logger := slog.New(utils.DummyLogger{})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	postManager := NewMockpostManager(ctrl)
+
+	postHandler := &PostHandler{
+		Logger:      logger,
+		PostManager: postManager,
+	}
+
+	author := getDefaultAuthor()
+	post := getDefaultPost(author)
+	post.Status = models.PostPublished
+
+	path := "/api/post/" + post.ID.Hex() + "/publish"
+	vars := map[string]string{
+		"postID": post.ID.Hex(),
+	}
+
+	// good response
+	postManager.EXPECT().Publish(post.ID.Hex(), author).Return(post, nil)
+
+	request := httptest.NewRequest(http.MethodPost, path, nil)
+	request = mux.SetURLVars(request, vars)
+	ctx := context.WithValue(request.Context(), models.CtxKey("user"), author)
+
+	response := &models.Post{}
+
+	test := utils.TestRequest{
+		Handler:        postHandler.Publish,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusOK,
+		ResponsePtr:    response,
+	}
+
+	err := utils.SendTestRequest(test)
+	if err != nil {
+		t.Fatalf("expected nil, but was %v", err)
+	}
+	if !reflect.DeepEqual(response, post) {
+		t.Errorf("\nwant: %v\nhave: %v", post, response)
+	}
+
+	// Context error
+	request = httptest.NewRequest(http.MethodPost, path, nil)
+	request = mux.SetURLVars(request, vars)
+
+	test = utils.TestRequest{
+		Handler:        postHandler.Publish,
+		Request:        request,
+		ExpectedStatus: http.StatusUnprocessableEntity,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+
+	// Publish error
+	postManager.EXPECT().Publish(post.ID.Hex(), author).Return(nil, fmt.Errorf("post is not a draft"))
+
+	request = httptest.NewRequest(http.MethodPost, path, nil)
+	request = mux.SetURLVars(request, vars)
+	ctx = context.WithValue(request.Context(), models.CtxKey("user"), author)
+
+	test = utils.TestRequest{
+		Handler:        postHandler.Publish,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusNotFound,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+}
+
+func TestGetDrafts(t *testing.T) {
+	logger := slog.New(utils.DummyLogger{})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	postManager := NewMockpostManager(ctrl)
+
+	postHandler := &PostHandler{
+		Logger:      logger,
+		PostManager: postManager,
+	}
+
+	author := getDefaultAuthor()
+	post := getDefaultPost(author)
+	post.Status = models.PostDraft
+	posts := []*models.Post{post}
+
+	path := "/api/me/drafts"
+
+	// good response
+	postManager.EXPECT().GetDrafts(author).Return(posts, nil)
+
+	request := httptest.NewRequest(http.MethodGet, path, nil)
+	ctx := context.WithValue(request.Context(), models.CtxKey("user"), author)
+
+	response := []*models.Post{}
+
+	test := utils.TestRequest{
+		Handler:        postHandler.GetDrafts,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusOK,
+		ResponsePtr:    &response,
+	}
+
+	err := utils.SendTestRequest(test)
+	if err != nil {
+		t.Fatalf("expected nil, but was %v", err)
+	}
+	if !reflect.DeepEqual(response, posts) {
+		t.Errorf("\nwant: %v\nhave: %v", posts, response)
+	}
+
+	// GetDrafts error
+	postManager.EXPECT().GetDrafts(author).Return(nil, fmt.Errorf("storage error"))
+
+	request = httptest.NewRequest(http.MethodGet, path, nil)
+	ctx = context.WithValue(request.Context(), models.CtxKey("user"), author)
+
+	test = utils.TestRequest{
+		Handler:        postHandler.GetDrafts,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusInternalServerError,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+}
+
+func TestSetState(t *testing.T) {
+	logger := slog.New(utils.DummyLogger{})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	postManager := NewMockpostManager(ctrl)
+
+	postHandler := &PostHandler{
+		Logger:      logger,
+		PostManager: postManager,
+	}
+
+	author := getDefaultAuthor()
+	post := getDefaultPost(author)
+	post.Locked = true
+
+	path := "/api/post/" + post.ID.Hex() + "/lock"
+	vars := map[string]string{
+		"postID": post.ID.Hex(),
+		"state":  "lock",
+	}
+
+	// good response
+	postManager.EXPECT().SetState(post.ID.Hex(), "lock", author).Return(post, nil)
+
+	request := httptest.NewRequest(http.MethodPost, path, nil)
+	request = mux.SetURLVars(request, vars)
+	ctx := context.WithValue(request.Context(), models.CtxKey("user"), author)
+
+	response := &models.Post{}
+
+	test := utils.TestRequest{
+		Handler:        postHandler.SetState,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusOK,
+		ResponsePtr:    response,
+	}
+
+	err := utils.SendTestRequest(test)
+	if err != nil {
+		t.Fatalf("expected nil, but was %v", err)
+	}
+	if !reflect.DeepEqual(response, post) {
+		t.Errorf("\nwant: %v\nhave: %v", post, response)
+	}
+
+	// ошибки менеджера отображаются в статусы
+	cases := []struct {
+		err    error
+		status int
+	}{
+		{models.ErrForbidden, http.StatusForbidden},
+		{models.ErrPostLocked, http.StatusForbidden},
+		{models.ErrPostArchived, http.StatusConflict},
+		{fmt.Errorf("no post found"), http.StatusNotFound},
+	}
+	for _, item := range cases {
+		postManager.EXPECT().SetState(post.ID.Hex(), "lock", author).Return(nil, item.err)
+
+		request = httptest.NewRequest(http.MethodPost, path, nil)
+		request = mux.SetURLVars(request, vars)
+		ctx = context.WithValue(request.Context(), models.CtxKey("user"), author)
+
+		recorder := httptest.NewRecorder()
+		postHandler.SetState(recorder, request.WithContext(ctx))
+		if recorder.Code != item.status {
+			t.Errorf("%v: want status %d, have %d", item.err, item.status, recorder.Code)
+		}
+	}
+
+	// Context error
+	request = httptest.NewRequest(http.MethodPost, path, nil)
+	request = mux.SetURLVars(request, vars)
+
+	test = utils.TestRequest{
+		Handler:        postHandler.SetState,
+		Request:        request,
+		ExpectedStatus: http.StatusUnprocessableEntity,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+}
+
+func TestRestore(t *testing.T) {
+	logger := slog.New(utils.DummyLogger{})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	postManager := NewMockpostManager(ctrl)
+
+	postHandler := &PostHandler{
+		Logger:      logger,
+		PostManager: postManager,
+	}
+
+	moderator := getDefaultAuthor()
+	post := getDefaultPost(moderator)
+
+	path := "/api/post/" + post.ID.Hex() + "/restore?reason=mistake"
+	vars := map[string]string{
+		"postID": post.ID.Hex(),
+	}
+
+	// good response
+	postManager.EXPECT().Restore(post.ID.Hex(), moderator, "mistake").Return(post, nil)
+
+	request := httptest.NewRequest(http.MethodPost, path, nil)
+	request = mux.SetURLVars(request, vars)
+	ctx := context.WithValue(request.Context(), models.CtxKey("user"), moderator)
+
+	response := &models.Post{}
+
+	test := utils.TestRequest{
+		Handler:        postHandler.Restore,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusOK,
+		ResponsePtr:    response,
+	}
+
+	err := utils.SendTestRequest(test)
+	if err != nil {
+		t.Fatalf("expected nil, but was %v", err)
+	}
+	if !reflect.DeepEqual(response, post) {
+		t.Errorf("\nwant: %v\nhave: %v", post, response)
+	}
+
+	// ошибки менеджера отображаются в статусы
+	cases := []struct {
+		err    error
+		status int
+	}{
+		{models.ErrForbidden, http.StatusForbidden},
+		{fmt.Errorf("no post found"), http.StatusNotFound},
+	}
+	for _, item := range cases {
+		postManager.EXPECT().Restore(post.ID.Hex(), moderator, "mistake").Return(nil, item.err)
+
+		request = httptest.NewRequest(http.MethodPost, path, nil)
+		request = mux.SetURLVars(request, vars)
+		ctx = context.WithValue(request.Context(), models.CtxKey("user"), moderator)
+
+		recorder := httptest.NewRecorder()
+		postHandler.Restore(recorder, request.WithContext(ctx))
+		if recorder.Code != item.status {
+			t.Errorf("%v: want status %d, have %d", item.err, item.status, recorder.Code)
+		}
+	}
+
+	// RestoreComment
+	commentID := primitive.NewObjectID().Hex()
+	postManager.EXPECT().RestoreComment(post.ID.Hex(), commentID, moderator, "").Return(post, nil)
+
+	request = httptest.NewRequest(http.MethodPost, "/api/post/"+post.ID.Hex()+"/"+commentID+"/restore", nil)
+	request = mux.SetURLVars(request, map[string]string{"postID": post.ID.Hex(), "commentID": commentID})
+	ctx = context.WithValue(request.Context(), models.CtxKey("user"), moderator)
+
+	response = &models.Post{}
+
+	test = utils.TestRequest{
+		Handler:        postHandler.RestoreComment,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusOK,
+		ResponsePtr:    response,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err != nil {
+		t.Fatalf("expected nil, but was %v", err)
+	}
+
+	// Context error
+	request = httptest.NewRequest(http.MethodPost, path, nil)
+	request = mux.SetURLVars(request, vars)
+
+	test = utils.TestRequest{
+		Handler:        postHandler.Restore,
+		Request:        request,
+		ExpectedStatus: http.StatusUnprocessableEntity,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+}
+
+func TestGetDeleted(t *testing.T) {
+	logger := slog.New(utils.DummyLogger{})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	postManager := NewMockpostManager(ctrl)
+
+	postHandler := &PostHandler{
+		Logger:      logger,
+		PostManager: postManager,
+	}
+
+	moderator := getDefaultAuthor()
+	post := getDefaultPost(moderator)
+	deletedAt := post.Created
+	post.DeletedAt = &deletedAt
+	post.DeletedBy = &moderator.ID
+	posts := []*models.Post{post}
+
+	path := "/api/mod/trash?page=2&limit=10"
+	page := models.Page{Number: 2, Size: 10}
+
+	// good response
+	postManager.EXPECT().GetDeleted(moderator, page).Return(posts, nil)
+
+	request := httptest.NewRequest(http.MethodGet, path, nil)
+	ctx := context.WithValue(request.Context(), models.CtxKey("user"), moderator)
+
+	response := []*models.Post{}
+
+	test := utils.TestRequest{
+		Handler:        postHandler.GetDeleted,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusOK,
+		ResponsePtr:    &response,
+	}
+
+	err := utils.SendTestRequest(test)
+	if err != nil {
+		t.Fatalf("expected nil, but was %v", err)
+	}
+	if !reflect.DeepEqual(response, posts) {
+		t.Errorf("\nwant: %v\nhave: %v", posts, response)
+	}
+
+	// not a moderator
+	postManager.EXPECT().GetDeleted(moderator, page).Return(nil, models.ErrForbidden)
+
+	request = httptest.NewRequest(http.MethodGet, path, nil)
+	ctx = context.WithValue(request.Context(), models.CtxKey("user"), moderator)
+
+	recorder := httptest.NewRecorder()
+	postHandler.GetDeleted(recorder, request.WithContext(ctx))
+	if recorder.Code != http.StatusForbidden {
+		t.Errorf("want status %d, have %d", http.StatusForbidden, recorder.Code)
+	}
+
+	// bad page
+	request = httptest.NewRequest(http.MethodGet, "/api/mod/trash?page=0", nil)
+	ctx = context.WithValue(request.Context(), models.CtxKey("user"), moderator)
+
+	test = utils.TestRequest{
+		Handler:        postHandler.GetDeleted,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusBadRequest,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+}
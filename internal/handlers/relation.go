@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"forum/internal/handlers/utils"
+	"forum/internal/models"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type relationManager interface {
+	Follow(*models.Author, string) error
+	Unfollow(*models.Author, string) error
+	Subscribe(*models.Author, string) error
+	Unsubscribe(*models.Author, string) error
+	Block(*models.Author, string) error
+	Unblock(*models.Author, string) error
+	Mute(*models.Author, string) error
+	Unmute(*models.Author, string) error
+}
+
+type RelationHandler struct {
+	Logger          *slog.Logger
+	RelationManager relationManager
+}
+
+// Хендлер подписки на пользователя username
+func (rh *RelationHandler) Follow(w http.ResponseWriter, r *http.Request) {
+	rh.handle(w, r, "username", rh.RelationManager.Follow)
+}
+
+// Хендлер отписки от пользователя username
+func (rh *RelationHandler) Unfollow(w http.ResponseWriter, r *http.Request) {
+	rh.handle(w, r, "username", rh.RelationManager.Unfollow)
+}
+
+// Хендлер подписки на категорию category
+func (rh *RelationHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
+	rh.handle(w, r, "category", rh.RelationManager.Subscribe)
+}
+
+// Хендлер отписки от категории category
+func (rh *RelationHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	rh.handle(w, r, "category", rh.RelationManager.Unsubscribe)
+}
+
+// Хендлер блокировки пользователя username
+func (rh *RelationHandler) Block(w http.ResponseWriter, r *http.Request) {
+	rh.handle(w, r, "username", rh.RelationManager.Block)
+}
+
+// Хендлер снятия блокировки пользователя username
+func (rh *RelationHandler) Unblock(w http.ResponseWriter, r *http.Request) {
+	rh.handle(w, r, "username", rh.RelationManager.Unblock)
+}
+
+// Хендлер скрытия категории category из общей выдачи
+func (rh *RelationHandler) Mute(w http.ResponseWriter, r *http.Request) {
+	rh.handle(w, r, "category", rh.RelationManager.Mute)
+}
+
+// Хендлер возврата категории category в общую выдачу
+func (rh *RelationHandler) Unmute(w http.ResponseWriter, r *http.Request) {
+	rh.handle(w, r, "category", rh.RelationManager.Unmute)
+}
+
+// Достает текущего пользователя и переменную пути key и выполняет action
+func (rh *RelationHandler) handle(w http.ResponseWriter, r *http.Request, key string, action func(*models.Author, string) error) {
+	msg := utils.NewLogMsg(rh.Logger, r.URL.Path, r.Method)
+
+	author, ok := r.Context().Value(models.CtxKey("user")).(*models.Author)
+	if !ok {
+		msg.Set("bad context value by key user", http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	err := action(author, mux.Vars(r)[key])
+	if err != nil {
+		msg.Set(err.Error(), http.StatusNotFound)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	msg.Set("success", http.StatusOK)
+	utils.WriteData(w, msg, map[string]interface{}{
+		"message": "success",
+	})
+}
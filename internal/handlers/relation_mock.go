@@ -0,0 +1,147 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/handlers/relation.go
+
+// Package handlers is a generated GoMock package.
+package handlers
+
+import (
+	models "forum/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockrelationManager is a mock of relationManager interface.
+type MockrelationManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockrelationManagerMockRecorder
+}
+
+// MockrelationManagerMockRecorder is the mock recorder for MockrelationManager.
+type MockrelationManagerMockRecorder struct {
+	mock *MockrelationManager
+}
+
+// NewMockrelationManager creates a new mock instance.
+func NewMockrelationManager(ctrl *gomock.Controller) *MockrelationManager {
+	mock := &MockrelationManager{ctrl: ctrl}
+	mock.recorder = &MockrelationManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockrelationManager) EXPECT() *MockrelationManagerMockRecorder {
+	return m.recorder
+}
+
+// Block mocks base method.
+func (m *MockrelationManager) Block(arg0 *models.Author, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Block", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Block indicates an expected call of Block.
+func (mr *MockrelationManagerMockRecorder) Block(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Block", reflect.TypeOf((*MockrelationManager)(nil).Block), arg0, arg1)
+}
+
+// Follow mocks base method.
+func (m *MockrelationManager) Follow(arg0 *models.Author, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Follow", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Follow indicates an expected call of Follow.
+func (mr *MockrelationManagerMockRecorder) Follow(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Follow", reflect.TypeOf((*MockrelationManager)(nil).Follow), arg0, arg1)
+}
+
+// Mute mocks base method.
+func (m *MockrelationManager) Mute(arg0 *models.Author, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Mute", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Mute indicates an expected call of Mute.
+func (mr *MockrelationManagerMockRecorder) Mute(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Mute", reflect.TypeOf((*MockrelationManager)(nil).Mute), arg0, arg1)
+}
+
+// Subscribe mocks base method.
+func (m *MockrelationManager) Subscribe(arg0 *models.Author, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockrelationManagerMockRecorder) Subscribe(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockrelationManager)(nil).Subscribe), arg0, arg1)
+}
+
+// Unblock mocks base method.
+func (m *MockrelationManager) Unblock(arg0 *models.Author, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unblock", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unblock indicates an expected call of Unblock.
+func (mr *MockrelationManagerMockRecorder) Unblock(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unblock", reflect.TypeOf((*MockrelationManager)(nil).Unblock), arg0, arg1)
+}
+
+// Unfollow mocks base method.
+func (m *MockrelationManager) Unfollow(arg0 *models.Author, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unfollow", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unfollow indicates an expected call of Unfollow.
+func (mr *MockrelationManagerMockRecorder) Unfollow(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unfollow", reflect.TypeOf((*MockrelationManager)(nil).Unfollow), arg0, arg1)
+}
+
+// Unmute mocks base method.
+func (m *MockrelationManager) Unmute(arg0 *models.Author, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unmute", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unmute indicates an expected call of Unmute.
+func (mr *MockrelationManagerMockRecorder) Unmute(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unmute", reflect.TypeOf((*MockrelationManager)(nil).Unmute), arg0, arg1)
+}
+
+// Unsubscribe mocks base method.
+func (m *MockrelationManager) Unsubscribe(arg0 *models.Author, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unsubscribe", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unsubscribe indicates an expected call of Unsubscribe.
+func (mr *MockrelationManagerMockRecorder) Unsubscribe(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unsubscribe", reflect.TypeOf((*MockrelationManager)(nil).Unsubscribe), arg0, arg1)
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"forum/internal/handlers/utils"
+	"forum/internal/models"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+)
+
+func TestFollowAndBlock(t *testing.T) {
+	logger := slog.New(utils.DummyLogger{})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	relationManager := NewMockrelationManager(ctrl)
+
+	relationHandler := &RelationHandler{
+		Logger:          logger,
+		RelationManager: relationManager,
+	}
+
+	author := getDefaultAuthor()
+	vars := map[string]string{
+		"username": "other",
+	}
+
+	cases := []struct {
+		name    string
+		handler http.HandlerFunc
+		method  string
+		expect  func(error) *gomock.Call
+	}{
+		{
+			name:    "follow",
+			handler: relationHandler.Follow,
+			method:  http.MethodPost,
+			expect: func(err error) *gomock.Call {
+				return relationManager.EXPECT().Follow(author, "other").Return(err)
+			},
+		},
+		{
+			name:    "unfollow",
+			handler: relationHandler.Unfollow,
+			method:  http.MethodDelete,
+			expect: func(err error) *gomock.Call {
+				return relationManager.EXPECT().Unfollow(author, "other").Return(err)
+			},
+		},
+		{
+			name:    "block",
+			handler: relationHandler.Block,
+			method:  http.MethodPost,
+			expect: func(err error) *gomock.Call {
+				return relationManager.EXPECT().Block(author, "other").Return(err)
+			},
+		},
+		{
+			name:    "unblock",
+			handler: relationHandler.Unblock,
+			method:  http.MethodDelete,
+			expect: func(err error) *gomock.Call {
+				return relationManager.EXPECT().Unblock(author, "other").Return(err)
+			},
+		},
+	}
+
+	for _, item := range cases {
+		// good response
+		item.expect(nil)
+
+		request := httptest.NewRequest(item.method, "/api/user/other/follow", nil)
+		request = mux.SetURLVars(request, vars)
+		ctx := context.WithValue(request.Context(), models.CtxKey("user"), author)
+
+		response := map[string]interface{}{}
+
+		test := utils.TestRequest{
+			Handler:        item.handler,
+			Request:        request.WithContext(ctx),
+			ExpectedStatus: http.StatusOK,
+			ResponsePtr:    &response,
+		}
+
+		err := utils.SendTestRequest(test)
+		if err != nil {
+			t.Fatalf("%s: expected nil, but was %v", item.name, err)
+		}
+
+		// Context error
+		request = httptest.NewRequest(item.method, "/api/user/other/follow", nil)
+
+		test = utils.TestRequest{
+			Handler:        item.handler,
+			Request:        mux.SetURLVars(request, vars),
+			ExpectedStatus: http.StatusUnprocessableEntity,
+		}
+
+		err = utils.SendTestRequest(test)
+		if err == nil {
+			t.Fatalf("%s: expected error, but was nil", item.name)
+		}
+
+		// Manager error
+		item.expect(fmt.Errorf("no user found"))
+
+		request = httptest.NewRequest(item.method, "/api/user/other/follow", nil)
+		request = mux.SetURLVars(request, vars)
+		ctx = context.WithValue(request.Context(), models.CtxKey("user"), author)
+
+		test = utils.TestRequest{
+			Handler:        item.handler,
+			Request:        request.WithContext(ctx),
+			ExpectedStatus: http.StatusNotFound,
+		}
+
+		err = utils.SendTestRequest(test)
+		if err == nil {
+			t.Fatalf("%s: expected error, but was nil", item.name)
+		}
+	}
+}
+
+func TestSubscribeAndMute(t *testing.T) {
+	logger := slog.New(utils.DummyLogger{})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	relationManager := NewMockrelationManager(ctrl)
+
+	relationHandler := &RelationHandler{
+		Logger:          logger,
+		RelationManager: relationManager,
+	}
+
+	author := getDefaultAuthor()
+	vars := map[string]string{
+		"category": "music",
+	}
+
+	// good response
+	relationManager.EXPECT().Subscribe(author, "music").Return(nil)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/category/music/subscribe", nil)
+	request = mux.SetURLVars(request, vars)
+	ctx := context.WithValue(request.Context(), models.CtxKey("user"), author)
+
+	response := map[string]interface{}{}
+
+	test := utils.TestRequest{
+		Handler:        relationHandler.Subscribe,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusOK,
+		ResponsePtr:    &response,
+	}
+
+	err := utils.SendTestRequest(test)
+	if err != nil {
+		t.Fatalf("expected nil, but was %v", err)
+	}
+
+	// Unsubscribe error
+	relationManager.EXPECT().Unsubscribe(author, "music").Return(fmt.Errorf("db error"))
+
+	request = httptest.NewRequest(http.MethodDelete, "/api/category/music/subscribe", nil)
+	request = mux.SetURLVars(request, vars)
+	ctx = context.WithValue(request.Context(), models.CtxKey("user"), author)
+
+	test = utils.TestRequest{
+		Handler:        relationHandler.Unsubscribe,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusNotFound,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+
+	// Mute
+	relationManager.EXPECT().Mute(author, "music").Return(nil)
+
+	request = httptest.NewRequest(http.MethodPost, "/api/category/music/mute", nil)
+	request = mux.SetURLVars(request, vars)
+	ctx = context.WithValue(request.Context(), models.CtxKey("user"), author)
+
+	test = utils.TestRequest{
+		Handler:        relationHandler.Mute,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusOK,
+		ResponsePtr:    &response,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err != nil {
+		t.Fatalf("expected nil, but was %v", err)
+	}
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"encoding/json"
+	"forum/internal/handlers/utils"
+	"forum/internal/models"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type reportManager interface {
+	Report(string, string, *models.ReportInput, *models.Author) error
+	Queue(*models.Author, models.Page) ([]*models.ReportGroup, error)
+	Resolve(*models.ResolveInput, *models.Author) (*models.ReportDecision, error)
+	Decisions(*models.Author, models.Page) ([]*models.ReportDecision, error)
+}
+
+type ReportHandler struct {
+	Logger        *slog.Logger
+	ReportManager reportManager
+}
+
+// Хендлер жалобы на пост c id - postID или на его комментарий commentID
+func (rh *ReportHandler) Report(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(rh.Logger, r.URL.Path, r.Method)
+
+	data, err := utils.ReadRequestBody(r)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusBadRequest)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	report := &models.ReportInput{}
+	err = json.Unmarshal(data, report)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	err = utils.ValidateStruct(report)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	reporter, ok := r.Context().Value(models.CtxKey("user")).(*models.Author)
+	if !ok {
+		msg.Set("bad context value by key user", http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	vars := mux.Vars(r)
+	err = rh.ReportManager.Report(vars["postID"], vars["commentID"], report, reporter)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	msg.Set("success", http.StatusOK)
+	utils.WriteData(w, msg, map[string]interface{}{
+		"message": "success",
+	})
+}
+
+// Хендлер очереди модерации
+func (rh *ReportHandler) Queue(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(rh.Logger, r.URL.Path, r.Method)
+
+	page, err := utils.ReadPage(r)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusBadRequest)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	moderator, ok := r.Context().Value(models.CtxKey("user")).(*models.Author)
+	if !ok {
+		msg.Set("bad context value by key user", http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	groups, err := rh.ReportManager.Queue(moderator, page)
+	if err != nil {
+		msg.Set(err.Error(), postErrorStatus(err, http.StatusInternalServerError))
+		utils.WriteError(w, msg)
+		return
+	}
+
+	msg.Set("success", http.StatusOK)
+	utils.WriteData(w, msg, groups)
+}
+
+// Хендлер решения модератора по жалобам на пост или комментарий
+func (rh *ReportHandler) Resolve(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(rh.Logger, r.URL.Path, r.Method)
+
+	data, err := utils.ReadRequestBody(r)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusBadRequest)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	input := &models.ResolveInput{}
+	err = json.Unmarshal(data, input)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	err = utils.ValidateStruct(input)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	moderator, ok := r.Context().Value(models.CtxKey("user")).(*models.Author)
+	if !ok {
+		msg.Set("bad context value by key user", http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	decision, err := rh.ReportManager.Resolve(input, moderator)
+	if err != nil {
+		msg.Set(err.Error(), postErrorStatus(err, http.StatusUnprocessableEntity))
+		utils.WriteError(w, msg)
+		return
+	}
+
+	msg.Set("success", http.StatusOK)
+	utils.WriteData(w, msg, decision)
+}
+
+// Хендлер истории решений по жалобам
+func (rh *ReportHandler) Decisions(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(rh.Logger, r.URL.Path, r.Method)
+
+	page, err := utils.ReadPage(r)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusBadRequest)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	moderator, ok := r.Context().Value(models.CtxKey("user")).(*models.Author)
+	if !ok {
+		msg.Set("bad context value by key user", http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	decisions, err := rh.ReportManager.Decisions(moderator, page)
+	if err != nil {
+		msg.Set(err.Error(), postErrorStatus(err, http.StatusInternalServerError))
+		utils.WriteError(w, msg)
+		return
+	}
+
+	msg.Set("success", http.StatusOK)
+	utils.WriteData(w, msg, decisions)
+}
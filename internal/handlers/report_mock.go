@@ -0,0 +1,94 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/handlers/report.go
+
+// Package handlers is a generated GoMock package.
+package handlers
+
+import (
+	models "forum/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockreportManager is a mock of reportManager interface.
+type MockreportManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockreportManagerMockRecorder
+}
+
+// MockreportManagerMockRecorder is the mock recorder for MockreportManager.
+type MockreportManagerMockRecorder struct {
+	mock *MockreportManager
+}
+
+// NewMockreportManager creates a new mock instance.
+func NewMockreportManager(ctrl *gomock.Controller) *MockreportManager {
+	mock := &MockreportManager{ctrl: ctrl}
+	mock.recorder = &MockreportManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockreportManager) EXPECT() *MockreportManagerMockRecorder {
+	return m.recorder
+}
+
+// Decisions mocks base method.
+func (m *MockreportManager) Decisions(arg0 *models.Author, arg1 models.Page) ([]*models.ReportDecision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Decisions", arg0, arg1)
+	ret0, _ := ret[0].([]*models.ReportDecision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Decisions indicates an expected call of Decisions.
+func (mr *MockreportManagerMockRecorder) Decisions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Decisions", reflect.TypeOf((*MockreportManager)(nil).Decisions), arg0, arg1)
+}
+
+// Queue mocks base method.
+func (m *MockreportManager) Queue(arg0 *models.Author, arg1 models.Page) ([]*models.ReportGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Queue", arg0, arg1)
+	ret0, _ := ret[0].([]*models.ReportGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Queue indicates an expected call of Queue.
+func (mr *MockreportManagerMockRecorder) Queue(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Queue", reflect.TypeOf((*MockreportManager)(nil).Queue), arg0, arg1)
+}
+
+// Report mocks base method.
+func (m *MockreportManager) Report(arg0, arg1 string, arg2 *models.ReportInput, arg3 *models.Author) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Report", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Report indicates an expected call of Report.
+func (mr *MockreportManagerMockRecorder) Report(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Report", reflect.TypeOf((*MockreportManager)(nil).Report), arg0, arg1, arg2, arg3)
+}
+
+// Resolve mocks base method.
+func (m *MockreportManager) Resolve(arg0 *models.ResolveInput, arg1 *models.Author) (*models.ReportDecision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Resolve", arg0, arg1)
+	ret0, _ := ret[0].(*models.ReportDecision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Resolve indicates an expected call of Resolve.
+func (mr *MockreportManagerMockRecorder) Resolve(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resolve", reflect.TypeOf((*MockreportManager)(nil).Resolve), arg0, arg1)
+}
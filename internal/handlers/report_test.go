@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"forum/internal/handlers/utils"
+	"forum/internal/models"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	gomock "github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestReportContent(t *testing.T) {
+	logger := slog.New(utils.DummyLogger{})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	reportManager := NewMockreportManager(ctrl)
+
+	reportHandler := &ReportHandler{
+		Logger:        logger,
+		ReportManager: reportManager,
+	}
+
+	author := getDefaultAuthor()
+	postID := primitive.NewObjectID().Hex()
+	commentID := primitive.NewObjectID().Hex()
+	path := "/api/post/" + postID + "/" + commentID + "/report"
+	vars := map[string]string{
+		"postID":    postID,
+		"commentID": commentID,
+	}
+	input := &models.ReportInput{Reason: "spam"}
+
+	newRequest := func(body string) *http.Request {
+		request := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		request = mux.SetURLVars(request, vars)
+		ctx := context.WithValue(request.Context(), models.CtxKey("user"), author)
+		return request.WithContext(ctx)
+	}
+
+	// good response
+	reportManager.EXPECT().Report(postID, commentID, input, author).Return(nil)
+
+	response := map[string]interface{}{}
+
+	test := utils.TestRequest{
+		Handler:        reportHandler.Report,
+		Request:        newRequest(`{"reason":"spam"}`),
+		ExpectedStatus: http.StatusOK,
+		ResponsePtr:    &response,
+	}
+
+	err := utils.SendTestRequest(test)
+	if err != nil {
+		t.Fatalf("expected nil, but was %v", err)
+	}
+
+	// bad reason
+	test = utils.TestRequest{
+		Handler:        reportHandler.Report,
+		Request:        newRequest(`{"reason":"boring"}`),
+		ExpectedStatus: http.StatusUnprocessableEntity,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+
+	// Report error
+	reportManager.EXPECT().Report(postID, commentID, input, author).Return(fmt.Errorf("you have already reported this"))
+
+	test = utils.TestRequest{
+		Handler:        reportHandler.Report,
+		Request:        newRequest(`{"reason":"spam"}`),
+		ExpectedStatus: http.StatusUnprocessableEntity,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+}
+
+func TestQueue(t *testing.T) {
+	logger := slog.New(utils.DummyLogger{})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	reportManager := NewMockreportManager(ctrl)
+
+	reportHandler := &ReportHandler{
+		Logger:        logger,
+		ReportManager: reportManager,
+	}
+
+	moderator := getDefaultAuthor()
+	page := models.Page{Number: 1, Size: 20}
+	groups := []*models.ReportGroup{
+		{
+			PostID:   primitive.NewObjectID(),
+			AuthorID: primitive.NewObjectID(),
+			Count:    3,
+			Reasons:  []string{"spam"},
+			First:    time.Now().In(time.UTC).Round(time.Millisecond),
+			Last:     time.Now().In(time.UTC).Round(time.Millisecond),
+		},
+	}
+
+	// good response
+	reportManager.EXPECT().Queue(moderator, page).Return(groups, nil)
+
+	request := httptest.NewRequest(http.MethodGet, "/api/mod/queue", nil)
+	ctx := context.WithValue(request.Context(), models.CtxKey("user"), moderator)
+
+	response := []*models.ReportGroup{}
+
+	test := utils.TestRequest{
+		Handler:        reportHandler.Queue,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusOK,
+		ResponsePtr:    &response,
+	}
+
+	err := utils.SendTestRequest(test)
+	if err != nil {
+		t.Fatalf("expected nil, but was %v", err)
+	}
+	if !reflect.DeepEqual(response, groups) {
+		t.Errorf("\nwant: %v\nhave: %v", groups, response)
+	}
+
+	// not a moderator
+	reportManager.EXPECT().Queue(moderator, page).Return(nil, models.ErrForbidden)
+
+	request = httptest.NewRequest(http.MethodGet, "/api/mod/queue", nil)
+	ctx = context.WithValue(request.Context(), models.CtxKey("user"), moderator)
+
+	test = utils.TestRequest{
+		Handler:        reportHandler.Queue,
+		Request:        request.WithContext(ctx),
+		ExpectedStatus: http.StatusForbidden,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+}
+
+func TestResolve(t *testing.T) {
+	logger := slog.New(utils.DummyLogger{})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	reportManager := NewMockreportManager(ctrl)
+
+	reportHandler := &ReportHandler{
+		Logger:        logger,
+		ReportManager: reportManager,
+	}
+
+	moderator := getDefaultAuthor()
+	postID := primitive.NewObjectID()
+	input := &models.ResolveInput{PostID: postID.Hex(), Action: models.ResolveRemove}
+	decision := &models.ReportDecision{
+		ID:          primitive.NewObjectID(),
+		PostID:      postID,
+		AuthorID:    primitive.NewObjectID(),
+		Action:      models.ResolveRemove,
+		ModeratorID: moderator.ID,
+		Reports:     2,
+		Created:     time.Now().In(time.UTC).Round(time.Millisecond),
+	}
+
+	newRequest := func(body string) *http.Request {
+		request := httptest.NewRequest(http.MethodPost, "/api/mod/queue/resolve", strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		ctx := context.WithValue(request.Context(), models.CtxKey("user"), moderator)
+		return request.WithContext(ctx)
+	}
+
+	// good response
+	reportManager.EXPECT().Resolve(input, moderator).Return(decision, nil)
+
+	response := &models.ReportDecision{}
+
+	test := utils.TestRequest{
+		Handler:        reportHandler.Resolve,
+		Request:        newRequest(`{"postID":"` + postID.Hex() + `","action":"remove"}`),
+		ExpectedStatus: http.StatusOK,
+		ResponsePtr:    response,
+	}
+
+	err := utils.SendTestRequest(test)
+	if err != nil {
+		t.Fatalf("expected nil, but was %v", err)
+	}
+	if !reflect.DeepEqual(response, decision) {
+		t.Errorf("\nwant: %v\nhave: %v", decision, response)
+	}
+
+	// bad action
+	test = utils.TestRequest{
+		Handler:        reportHandler.Resolve,
+		Request:        newRequest(`{"postID":"` + postID.Hex() + `","action":"ignore"}`),
+		ExpectedStatus: http.StatusUnprocessableEntity,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+
+	// Resolve error
+	reportManager.EXPECT().Resolve(input, moderator).Return(nil, fmt.Errorf("no open reports found"))
+
+	test = utils.TestRequest{
+		Handler:        reportHandler.Resolve,
+		Request:        newRequest(`{"postID":"` + postID.Hex() + `","action":"remove"}`),
+		ExpectedStatus: http.StatusUnprocessableEntity,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"encoding/json"
+	"forum/internal/handlers/utils"
+	"forum/internal/models"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+var shadowbanAction = "shadowban"
+
+type suspensionManager interface {
+	Suspend(string, *models.SuspendInput, *models.Author) error
+	Unsuspend(string, *models.Author) error
+	SetShadowban(string, bool, *models.Author) error
+}
+
+type SuspensionHandler struct {
+	Logger            *slog.Logger
+	SuspensionManager suspensionManager
+}
+
+// Хендлер блокировки пользователя username, срок и причина передаются в теле
+func (sh *SuspensionHandler) Suspend(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(sh.Logger, r.URL.Path, r.Method)
+
+	data, err := utils.ReadRequestBody(r)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusBadRequest)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	input := &models.SuspendInput{}
+	err = json.Unmarshal(data, input)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	err = utils.ValidateStruct(input)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	admin, ok := r.Context().Value(models.CtxKey("user")).(*models.Author)
+	if !ok {
+		msg.Set("bad context value by key user", http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	err = sh.SuspensionManager.Suspend(mux.Vars(r)["username"], input, admin)
+	if err != nil {
+		msg.Set(err.Error(), postErrorStatus(err, http.StatusUnprocessableEntity))
+		utils.WriteError(w, msg)
+		return
+	}
+
+	msg.Set("success", http.StatusOK)
+	utils.WriteData(w, msg, map[string]interface{}{
+		"message": "success",
+	})
+}
+
+// Хендлер снятия блокировки пользователя username
+func (sh *SuspensionHandler) Unsuspend(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(sh.Logger, r.URL.Path, r.Method)
+
+	admin, ok := r.Context().Value(models.CtxKey("user")).(*models.Author)
+	if !ok {
+		msg.Set("bad context value by key user", http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	err := sh.SuspensionManager.Unsuspend(mux.Vars(r)["username"], admin)
+	if err != nil {
+		msg.Set(err.Error(), postErrorStatus(err, http.StatusUnprocessableEntity))
+		utils.WriteError(w, msg)
+		return
+	}
+
+	msg.Set("success", http.StatusOK)
+	utils.WriteData(w, msg, map[string]interface{}{
+		"message": "success",
+	})
+}
+
+// Хендлер теневого бана пользователя username, action - shadowban или unshadowban
+func (sh *SuspensionHandler) Shadowban(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(sh.Logger, r.URL.Path, r.Method)
+
+	admin, ok := r.Context().Value(models.CtxKey("user")).(*models.Author)
+	if !ok {
+		msg.Set("bad context value by key user", http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	vars := mux.Vars(r)
+	err := sh.SuspensionManager.SetShadowban(vars["username"], vars["action"] == shadowbanAction, admin)
+	if err != nil {
+		msg.Set(err.Error(), postErrorStatus(err, http.StatusUnprocessableEntity))
+		utils.WriteError(w, msg)
+		return
+	}
+
+	msg.Set("success", http.StatusOK)
+	utils.WriteData(w, msg, map[string]interface{}{
+		"message": "success",
+	})
+}
@@ -0,0 +1,77 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/handlers/suspension.go
+
+// Package handlers is a generated GoMock package.
+package handlers
+
+import (
+	models "forum/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MocksuspensionManager is a mock of suspensionManager interface.
+type MocksuspensionManager struct {
+	ctrl     *gomock.Controller
+	recorder *MocksuspensionManagerMockRecorder
+}
+
+// MocksuspensionManagerMockRecorder is the mock recorder for MocksuspensionManager.
+type MocksuspensionManagerMockRecorder struct {
+	mock *MocksuspensionManager
+}
+
+// NewMocksuspensionManager creates a new mock instance.
+func NewMocksuspensionManager(ctrl *gomock.Controller) *MocksuspensionManager {
+	mock := &MocksuspensionManager{ctrl: ctrl}
+	mock.recorder = &MocksuspensionManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocksuspensionManager) EXPECT() *MocksuspensionManagerMockRecorder {
+	return m.recorder
+}
+
+// SetShadowban mocks base method.
+func (m *MocksuspensionManager) SetShadowban(arg0 string, arg1 bool, arg2 *models.Author) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetShadowban", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetShadowban indicates an expected call of SetShadowban.
+func (mr *MocksuspensionManagerMockRecorder) SetShadowban(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetShadowban", reflect.TypeOf((*MocksuspensionManager)(nil).SetShadowban), arg0, arg1, arg2)
+}
+
+// Suspend mocks base method.
+func (m *MocksuspensionManager) Suspend(arg0 string, arg1 *models.SuspendInput, arg2 *models.Author) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Suspend", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Suspend indicates an expected call of Suspend.
+func (mr *MocksuspensionManagerMockRecorder) Suspend(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Suspend", reflect.TypeOf((*MocksuspensionManager)(nil).Suspend), arg0, arg1, arg2)
+}
+
+// Unsuspend mocks base method.
+func (m *MocksuspensionManager) Unsuspend(arg0 string, arg1 *models.Author) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unsuspend", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unsuspend indicates an expected call of Unsuspend.
+func (mr *MocksuspensionManagerMockRecorder) Unsuspend(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unsuspend", reflect.TypeOf((*MocksuspensionManager)(nil).Unsuspend), arg0, arg1)
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"forum/internal/handlers/utils"
+	"forum/internal/models"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	gomock "github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+)
+
+func TestSuspend(t *testing.T) {
+	logger := slog.New(utils.DummyLogger{})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	suspensionManager := NewMocksuspensionManager(ctrl)
+
+	suspensionHandler := &SuspensionHandler{
+		Logger:            logger,
+		SuspensionManager: suspensionManager,
+	}
+
+	admin := getDefaultAuthor()
+	until := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	input := &models.SuspendInput{Until: &until, Reason: "spam"}
+
+	newRequest := func(body string) *http.Request {
+		request := httptest.NewRequest(http.MethodPost, "/api/admin/users/user/suspend", strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		request = mux.SetURLVars(request, map[string]string{"username": "user"})
+		ctx := context.WithValue(request.Context(), models.CtxKey("user"), admin)
+		return request.WithContext(ctx)
+	}
+
+	// good response
+	suspensionManager.EXPECT().Suspend("user", input, admin).Return(nil)
+
+	response := map[string]interface{}{}
+
+	test := utils.TestRequest{
+		Handler:        suspensionHandler.Suspend,
+		Request:        newRequest(`{"until":"2030-01-01T00:00:00Z","reason":"spam"}`),
+		ExpectedStatus: http.StatusOK,
+		ResponsePtr:    &response,
+	}
+
+	err := utils.SendTestRequest(test)
+	if err != nil {
+		t.Fatalf("expected nil, but was %v", err)
+	}
+
+	// no reason
+	test = utils.TestRequest{
+		Handler:        suspensionHandler.Suspend,
+		Request:        newRequest(`{"until":"2030-01-01T00:00:00Z"}`),
+		ExpectedStatus: http.StatusUnprocessableEntity,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+
+	// not an admin
+	suspensionManager.EXPECT().Suspend("user", input, admin).Return(models.ErrForbidden)
+
+	test = utils.TestRequest{
+		Handler:        suspensionHandler.Suspend,
+		Request:        newRequest(`{"until":"2030-01-01T00:00:00Z","reason":"spam"}`),
+		ExpectedStatus: http.StatusForbidden,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+}
+
+func TestShadowban(t *testing.T) {
+	logger := slog.New(utils.DummyLogger{})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	suspensionManager := NewMocksuspensionManager(ctrl)
+
+	suspensionHandler := &SuspensionHandler{
+		Logger:            logger,
+		SuspensionManager: suspensionManager,
+	}
+
+	admin := getDefaultAuthor()
+
+	newRequest := func(action string) *http.Request {
+		request := httptest.NewRequest(http.MethodPost, "/api/admin/users/user/"+action, nil)
+		request = mux.SetURLVars(request, map[string]string{"username": "user", "action": action})
+		ctx := context.WithValue(request.Context(), models.CtxKey("user"), admin)
+		return request.WithContext(ctx)
+	}
+
+	// good response
+	suspensionManager.EXPECT().SetShadowban("user", true, admin).Return(nil)
+
+	response := map[string]interface{}{}
+
+	test := utils.TestRequest{
+		Handler:        suspensionHandler.Shadowban,
+		Request:        newRequest("shadowban"),
+		ExpectedStatus: http.StatusOK,
+		ResponsePtr:    &response,
+	}
+
+	err := utils.SendTestRequest(test)
+	if err != nil {
+		t.Fatalf("expected nil, but was %v", err)
+	}
+
+	// SetShadowban error
+	suspensionManager.EXPECT().SetShadowban("user", false, admin).Return(fmt.Errorf("shadowban is already in this state"))
+
+	test = utils.TestRequest{
+		Handler:        suspensionHandler.Shadowban,
+		Request:        newRequest("unshadowban"),
+		ExpectedStatus: http.StatusUnprocessableEntity,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+}
@@ -2,12 +2,18 @@ package utils
 
 import (
 	"errors"
+	"forum/internal/models"
 	"io"
 	"net/http"
+	"strconv"
 )
 
 var (
+	defaultPageSize = 20
+	maxPageSize     = 100
+
 	errUnknownPayload = errors.New("unknown payload")
+	errBadPage        = errors.New("bad page parameters")
 )
 
 // Выполняет проверку заголовка Content-type и читает body.
@@ -24,3 +30,30 @@ func ReadRequestBody(r *http.Request) ([]byte, error) {
 
 	return body, nil
 }
+
+// Читает параметры page и limit из query, если их нет - возвращает первую страницу.
+func ReadPage(r *http.Request) (models.Page, error) {
+	page := models.Page{
+		Number: 1,
+		Size:   defaultPageSize,
+	}
+	query := r.URL.Query()
+
+	if value := query.Get("page"); value != "" {
+		number, err := strconv.Atoi(value)
+		if err != nil || number < 1 {
+			return page, errBadPage
+		}
+		page.Number = number
+	}
+
+	if value := query.Get("limit"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size < 1 || size > maxPageSize {
+			return page, errBadPage
+		}
+		page.Size = size
+	}
+
+	return page, nil
+}
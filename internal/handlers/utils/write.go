@@ -1,8 +1,13 @@
 package utils
 
 import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"net/http"
+	"sort"
+	"strings"
 )
 
 /*
@@ -37,3 +42,68 @@ func WriteData(w http.ResponseWriter, msg *logMsg, data any) {
 	}
 	msg.Info()
 }
+
+/*
+Упаковывает files в zip-архив, где ключ - имя файла, а значение сериализуется в JSON.
+Архив отдается как вложение с именем filename.
+*/
+func WriteZip(w http.ResponseWriter, msg *logMsg, filename string, files map[string]any) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buf := &bytes.Buffer{}
+	archive := zip.NewWriter(buf)
+	for _, name := range names {
+		file, err := archive.Create(name)
+		if err != nil {
+			msg.Set(err.Error(), http.StatusInternalServerError)
+			WriteError(w, msg)
+			return
+		}
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(files[name])
+		if err != nil {
+			msg.Set(err.Error(), http.StatusInternalServerError)
+			WriteError(w, msg)
+			return
+		}
+	}
+	err := archive.Close()
+	if err != nil {
+		msg.Set(err.Error(), http.StatusInternalServerError)
+		WriteError(w, msg)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	_, err = w.Write(buf.Bytes())
+	if err != nil {
+		msg.Set(err.Error(), http.StatusInternalServerError)
+		WriteError(w, msg)
+		return
+	}
+	msg.Info()
+}
+
+/*
+Начинает ответ CSV-вложением с именем filename и возвращает writer, который пишет строки сразу в w.
+После первой записи статус ответа уже не изменить, поэтому ошибки до нее нужно отдавать через WriteError.
+*/
+func NewCSVWriter(w http.ResponseWriter, filename string) *csv.Writer {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	return csv.NewWriter(w)
+}
+
+// Экранирует поле CSV, которое табличный редактор принял бы за формулу
+func CSVSafe(field string) string {
+	if field != "" && strings.ContainsRune("=+-@\t\r", rune(field[0])) {
+		return "'" + field
+	}
+	return field
+}
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"encoding/json"
+	"forum/internal/handlers/utils"
+	"forum/internal/models"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+var enableAction = "enable"
+
+type webhookManager interface {
+	Create(*models.WebhookInput, *models.Author) (*models.Webhook, error)
+	Find(*models.Author) ([]*models.Webhook, error)
+	Delete(string, *models.Author) error
+	SetActive(string, bool, *models.Author) error
+	Deliveries(string, *models.Author, models.Page) ([]*models.WebhookDelivery, error)
+	Redeliver(string, string, *models.Author) (*models.WebhookDelivery, error)
+}
+
+type WebhookHandler struct {
+	Logger         *slog.Logger
+	WebhookManager webhookManager
+}
+
+// Хендлер регистрации вебхука, в ответе возвращается ключ подписи
+func (wh *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(wh.Logger, r.URL.Path, r.Method)
+
+	data, err := utils.ReadRequestBody(r)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusBadRequest)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	input := &models.WebhookInput{}
+	err = json.Unmarshal(data, input)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	err = utils.ValidateStruct(input)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	admin, ok := r.Context().Value(models.CtxKey("user")).(*models.Author)
+	if !ok {
+		msg.Set("bad context value by key user", http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	webhook, err := wh.WebhookManager.Create(input, admin)
+	if err != nil {
+		msg.Set(err.Error(), postErrorStatus(err, http.StatusUnprocessableEntity))
+		utils.WriteError(w, msg)
+		return
+	}
+
+	msg.Set("success", http.StatusOK)
+	utils.WriteData(w, msg, webhook)
+}
+
+// Хендлер, возвращающий все вебхуки
+func (wh *WebhookHandler) Find(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(wh.Logger, r.URL.Path, r.Method)
+
+	admin, ok := r.Context().Value(models.CtxKey("user")).(*models.Author)
+	if !ok {
+		msg.Set("bad context value by key user", http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	webhooks, err := wh.WebhookManager.Find(admin)
+	if err != nil {
+		msg.Set(err.Error(), postErrorStatus(err, http.StatusInternalServerError))
+		utils.WriteError(w, msg)
+		return
+	}
+
+	msg.Set("success", http.StatusOK)
+	utils.WriteData(w, msg, webhooks)
+}
+
+// Хендлер удаления вебхука с webhookID
+func (wh *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(wh.Logger, r.URL.Path, r.Method)
+
+	admin, ok := r.Context().Value(models.CtxKey("user")).(*models.Author)
+	if !ok {
+		msg.Set("bad context value by key user", http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	err := wh.WebhookManager.Delete(mux.Vars(r)["webhookID"], admin)
+	if err != nil {
+		msg.Set(err.Error(), postErrorStatus(err, http.StatusNotFound))
+		utils.WriteError(w, msg)
+		return
+	}
+
+	msg.Set("success", http.StatusOK)
+	utils.WriteData(w, msg, map[string]interface{}{
+		"message": "success",
+	})
+}
+
+// Хендлер включения и выключения вебхука, action - enable или disable
+func (wh *WebhookHandler) SetActive(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(wh.Logger, r.URL.Path, r.Method)
+
+	admin, ok := r.Context().Value(models.CtxKey("user")).(*models.Author)
+	if !ok {
+		msg.Set("bad context value by key user", http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	vars := mux.Vars(r)
+	err := wh.WebhookManager.SetActive(vars["webhookID"], vars["action"] == enableAction, admin)
+	if err != nil {
+		msg.Set(err.Error(), postErrorStatus(err, http.StatusNotFound))
+		utils.WriteError(w, msg)
+		return
+	}
+
+	msg.Set("success", http.StatusOK)
+	utils.WriteData(w, msg, map[string]interface{}{
+		"message": "success",
+	})
+}
+
+// Хендлер журнала доставок вебхука постранично
+func (wh *WebhookHandler) Deliveries(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(wh.Logger, r.URL.Path, r.Method)
+
+	page, err := utils.ReadPage(r)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusBadRequest)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	admin, ok := r.Context().Value(models.CtxKey("user")).(*models.Author)
+	if !ok {
+		msg.Set("bad context value by key user", http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	deliveries, err := wh.WebhookManager.Deliveries(mux.Vars(r)["webhookID"], admin, page)
+	if err != nil {
+		msg.Set(err.Error(), postErrorStatus(err, http.StatusNotFound))
+		utils.WriteError(w, msg)
+		return
+	}
+
+	msg.Set("success", http.StatusOK)
+	utils.WriteData(w, msg, deliveries)
+}
+
+// Хендлер повторной отправки доставки с deliveryID
+func (wh *WebhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(wh.Logger, r.URL.Path, r.Method)
+
+	admin, ok := r.Context().Value(models.CtxKey("user")).(*models.Author)
+	if !ok {
+		msg.Set("bad context value by key user", http.StatusUnprocessableEntity)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	vars := mux.Vars(r)
+	delivery, err := wh.WebhookManager.Redeliver(vars["webhookID"], vars["deliveryID"], admin)
+	if err != nil {
+		msg.Set(err.Error(), postErrorStatus(err, http.StatusNotFound))
+		utils.WriteError(w, msg)
+		return
+	}
+
+	msg.Set("success", http.StatusOK)
+	utils.WriteData(w, msg, delivery)
+}
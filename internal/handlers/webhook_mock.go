@@ -0,0 +1,123 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/handlers/webhook.go
+
+// Package handlers is a generated GoMock package.
+package handlers
+
+import (
+	models "forum/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockwebhookManager is a mock of webhookManager interface.
+type MockwebhookManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockwebhookManagerMockRecorder
+}
+
+// MockwebhookManagerMockRecorder is the mock recorder for MockwebhookManager.
+type MockwebhookManagerMockRecorder struct {
+	mock *MockwebhookManager
+}
+
+// NewMockwebhookManager creates a new mock instance.
+func NewMockwebhookManager(ctrl *gomock.Controller) *MockwebhookManager {
+	mock := &MockwebhookManager{ctrl: ctrl}
+	mock.recorder = &MockwebhookManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockwebhookManager) EXPECT() *MockwebhookManagerMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockwebhookManager) Create(arg0 *models.WebhookInput, arg1 *models.Author) (*models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(*models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockwebhookManagerMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockwebhookManager)(nil).Create), arg0, arg1)
+}
+
+// Delete mocks base method.
+func (m *MockwebhookManager) Delete(arg0 string, arg1 *models.Author) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockwebhookManagerMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockwebhookManager)(nil).Delete), arg0, arg1)
+}
+
+// Deliveries mocks base method.
+func (m *MockwebhookManager) Deliveries(arg0 string, arg1 *models.Author, arg2 models.Page) ([]*models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Deliveries", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Deliveries indicates an expected call of Deliveries.
+func (mr *MockwebhookManagerMockRecorder) Deliveries(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deliveries", reflect.TypeOf((*MockwebhookManager)(nil).Deliveries), arg0, arg1, arg2)
+}
+
+// Find mocks base method.
+func (m *MockwebhookManager) Find(arg0 *models.Author) ([]*models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", arg0)
+	ret0, _ := ret[0].([]*models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockwebhookManagerMockRecorder) Find(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockwebhookManager)(nil).Find), arg0)
+}
+
+// Redeliver mocks base method.
+func (m *MockwebhookManager) Redeliver(arg0, arg1 string, arg2 *models.Author) (*models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Redeliver", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Redeliver indicates an expected call of Redeliver.
+func (mr *MockwebhookManagerMockRecorder) Redeliver(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Redeliver", reflect.TypeOf((*MockwebhookManager)(nil).Redeliver), arg0, arg1, arg2)
+}
+
+// SetActive mocks base method.
+func (m *MockwebhookManager) SetActive(arg0 string, arg1 bool, arg2 *models.Author) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetActive", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetActive indicates an expected call of SetActive.
+func (mr *MockwebhookManagerMockRecorder) SetActive(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetActive", reflect.TypeOf((*MockwebhookManager)(nil).SetActive), arg0, arg1, arg2)
+}
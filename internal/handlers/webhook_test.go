@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"forum/internal/handlers/utils"
+	"forum/internal/models"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	gomock "github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCreateWebhook(t *testing.T) {
+	logger := slog.New(utils.DummyLogger{})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	webhookManager := NewMockwebhookManager(ctrl)
+
+	webhookHandler := &WebhookHandler{
+		Logger:         logger,
+		WebhookManager: webhookManager,
+	}
+
+	admin := getDefaultAuthor()
+	input := &models.WebhookInput{URL: "https://example.com/hook", Events: []string{models.EventPostCreated}}
+	webhook := &models.Webhook{
+		ID:      primitive.NewObjectID(),
+		URL:     input.URL,
+		Secret:  "secret",
+		Events:  input.Events,
+		Active:  true,
+		Created: time.Now().In(time.UTC).Round(time.Millisecond),
+	}
+
+	newRequest := func(body string) *http.Request {
+		request := httptest.NewRequest(http.MethodPost, "/api/admin/webhooks", strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		ctx := context.WithValue(request.Context(), models.CtxKey("user"), admin)
+		return request.WithContext(ctx)
+	}
+
+	// good response
+	webhookManager.EXPECT().Create(input, admin).Return(webhook, nil)
+
+	response := &models.Webhook{}
+
+	test := utils.TestRequest{
+		Handler:        webhookHandler.Create,
+		Request:        newRequest(`{"url":"https://example.com/hook","events":["post.created"]}`),
+		ExpectedStatus: http.StatusOK,
+		ResponsePtr:    response,
+	}
+
+	err := utils.SendTestRequest(test)
+	if err != nil {
+		t.Fatalf("expected nil, but was %v", err)
+	}
+	if !reflect.DeepEqual(response, webhook) {
+		t.Errorf("\nwant: %v\nhave: %v", webhook, response)
+	}
+
+	// bad url
+	test = utils.TestRequest{
+		Handler:        webhookHandler.Create,
+		Request:        newRequest(`{"url":"not a url","events":["post.created"]}`),
+		ExpectedStatus: http.StatusUnprocessableEntity,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+
+	// not an admin
+	webhookManager.EXPECT().Create(input, admin).Return(nil, models.ErrForbidden)
+
+	recorder := httptest.NewRecorder()
+	webhookHandler.Create(recorder, newRequest(`{"url":"https://example.com/hook","events":["post.created"]}`))
+	if recorder.Code != http.StatusForbidden {
+		t.Errorf("want %d, have %d", http.StatusForbidden, recorder.Code)
+	}
+}
+
+func TestWebhookDeliveries(t *testing.T) {
+	logger := slog.New(utils.DummyLogger{})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	webhookManager := NewMockwebhookManager(ctrl)
+
+	webhookHandler := &WebhookHandler{
+		Logger:         logger,
+		WebhookManager: webhookManager,
+	}
+
+	admin := getDefaultAuthor()
+	webhookID := primitive.NewObjectID().Hex()
+	delivery := &models.WebhookDelivery{
+		ID:          primitive.NewObjectID(),
+		Key:         "1-0",
+		Event:       models.EventPostCreated,
+		Payload:     []byte(`{"type":"post.created"}`),
+		Status:      models.DeliveryPending,
+		NextAttempt: time.Now().In(time.UTC).Round(time.Millisecond),
+		Created:     time.Now().In(time.UTC).Round(time.Millisecond),
+	}
+
+	newRequest := func(method, target string, vars map[string]string) *http.Request {
+		request := httptest.NewRequest(method, target, nil)
+		request = mux.SetURLVars(request, vars)
+		ctx := context.WithValue(request.Context(), models.CtxKey("user"), admin)
+		return request.WithContext(ctx)
+	}
+
+	// good response
+	webhookManager.EXPECT().Deliveries(webhookID, admin, models.Page{Number: 1, Size: 20}).Return([]*models.WebhookDelivery{delivery}, nil)
+
+	deliveries := []*models.WebhookDelivery{}
+
+	test := utils.TestRequest{
+		Handler:        webhookHandler.Deliveries,
+		Request:        newRequest(http.MethodGet, "/api/admin/webhooks/"+webhookID+"/deliveries?page=1&size=20", map[string]string{"webhookID": webhookID}),
+		ExpectedStatus: http.StatusOK,
+		ResponsePtr:    &deliveries,
+	}
+
+	err := utils.SendTestRequest(test)
+	if err != nil {
+		t.Fatalf("expected nil, but was %v", err)
+	}
+	if len(deliveries) != 1 || !reflect.DeepEqual(deliveries[0], delivery) {
+		t.Errorf("\nwant: %v\nhave: %v", delivery, deliveries)
+	}
+
+	// redelivery
+	vars := map[string]string{"webhookID": webhookID, "deliveryID": delivery.ID.Hex()}
+	webhookManager.EXPECT().Redeliver(webhookID, delivery.ID.Hex(), admin).Return(delivery, nil)
+
+	response := &models.WebhookDelivery{}
+
+	test = utils.TestRequest{
+		Handler:        webhookHandler.Redeliver,
+		Request:        newRequest(http.MethodPost, "/api/admin/webhooks/"+webhookID+"/deliveries/"+delivery.ID.Hex()+"/redeliver", vars),
+		ExpectedStatus: http.StatusOK,
+		ResponsePtr:    response,
+	}
+
+	err = utils.SendTestRequest(test)
+	if err != nil {
+		t.Fatalf("expected nil, but was %v", err)
+	}
+
+	// enable
+	webhookManager.EXPECT().SetActive(webhookID, true, admin).Return(nil)
+
+	recorder := httptest.NewRecorder()
+	webhookHandler.SetActive(recorder, newRequest(http.MethodPost, "/api/admin/webhooks/"+webhookID+"/enable", map[string]string{"webhookID": webhookID, "action": "enable"}))
+	if recorder.Code != http.StatusOK {
+		t.Errorf("want %d, have %d", http.StatusOK, recorder.Code)
+	}
+}
@@ -0,0 +1,302 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"forum/internal/handlers/utils"
+	"forum/internal/models"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	// сколько тем может слушать одно соединение
+	maxSubscriptions = 20
+	// сколько ответов на команды может ждать отправки, иначе клиент считается медленным
+	replyBuffer = 16
+
+	wsWriteWait   = 10 * time.Second
+	wsPongWait    = 60 * time.Second
+	wsPingPeriod  = wsPongWait * 9 / 10
+	wsMaxReadSize = int64(4096)
+
+	errBadTopic          = errors.New("unknown topic")
+	errTooManyTopics     = errors.New("too many subscriptions")
+	errBadAction         = errors.New("unknown action")
+	errNotSubscribed     = errors.New("not subscribed")
+	errAlreadySubscribed = errors.New("already subscribed")
+)
+
+// Темы, на которые можно подписаться через WebSocket
+const (
+	topicPost          = "post:"
+	topicCategory      = "category:"
+	topicNotifications = "notifications"
+)
+
+// Check - проверка токена
+type tokenChecker interface {
+	Check(context.Context, string) (*models.Author, error)
+}
+
+type WebSocketHandler struct {
+	Logger       *slog.Logger
+	AuthManager  tokenChecker
+	EventManager eventManager
+	// разрешенный Origin, если пустой, то только тот же хост
+	Origin string
+}
+
+// Команда клиента: {"action": "subscribe", "topic": "post:<id>"}
+type wsCommand struct {
+	Action string `json:"action"`
+	Topic  string `json:"topic"`
+}
+
+/*
+Сообщение сервера. Type - event, subscribed, unsubscribed или error.
+Для event заполнены Topic и Event, для ошибок - Error.
+*/
+type wsMessage struct {
+	Type  string        `json:"type"`
+	Topic string        `json:"topic,omitempty"`
+	Event *models.Event `json:"event,omitempty"`
+	Error string        `json:"error,omitempty"`
+}
+
+// Темы, на которые подписано соединение. Читается из рассылки событий, поэтому под мьютексом.
+type wsTopics struct {
+	mu     sync.RWMutex
+	user   *models.Author
+	topics map[string]func(*models.Event) bool
+}
+
+func (t *wsTopics) add(topic string) error {
+	match, err := t.matcher(topic)
+	if err != nil {
+		return err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.topics[topic]; ok {
+		return errAlreadySubscribed
+	}
+	if len(t.topics) >= maxSubscriptions {
+		return errTooManyTopics
+	}
+	t.topics[topic] = match
+	return nil
+}
+
+func (t *wsTopics) remove(topic string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.topics[topic]; !ok {
+		return errNotSubscribed
+	}
+	delete(t.topics, topic)
+	return nil
+}
+
+// Возвращает тему, под которую подходит event, пустая строка - ни одна
+func (t *wsTopics) match(event *models.Event) string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for topic, match := range t.topics {
+		if match(event) {
+			return topic
+		}
+	}
+	return ""
+}
+
+func (t *wsTopics) matcher(topic string) (func(*models.Event) bool, error) {
+	switch {
+	case strings.HasPrefix(topic, topicPost):
+		postID, err := primitive.ObjectIDFromHex(strings.TrimPrefix(topic, topicPost))
+		if err != nil {
+			return nil, errBadTopic
+		}
+		return func(event *models.Event) bool {
+			return event.Public() && event.PostID == postID
+		}, nil
+	case strings.HasPrefix(topic, topicCategory):
+		category := strings.TrimPrefix(topic, topicCategory)
+		if !models.IsCategory(category) {
+			return nil, errBadTopic
+		}
+		return func(event *models.Event) bool {
+			return event.Public() && event.Category == category
+		}, nil
+	case topic == topicNotifications:
+		userID := t.user.ID.Hex()
+		// UserID есть и у событий постов пользователя, в тему попадают только его уведомления
+		return func(event *models.Event) bool {
+			return event.Type == models.EventNotification && event.UserID == userID
+		}, nil
+	}
+	return nil, errBadTopic
+}
+
+/*
+Хендлер WebSocket-соединения. Токен передается в заголовке Authorization или параметре token,
+так как браузер не дает задать заголовки при открытии WebSocket.
+*/
+func (wh *WebSocketHandler) Serve(w http.ResponseWriter, r *http.Request) {
+	msg := utils.NewLogMsg(wh.Logger, r.URL.Path, r.Method)
+
+	tokenIn := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if tokenIn == "" {
+		tokenIn = r.URL.Query().Get("token")
+	}
+	user, err := wh.AuthManager.Check(r.Context(), tokenIn)
+	if err != nil {
+		msg.Set(err.Error(), http.StatusUnauthorized)
+		utils.WriteError(w, msg)
+		return
+	}
+
+	upgrader := websocket.Upgrader{}
+	if wh.Origin != "" {
+		upgrader.CheckOrigin = func(r *http.Request) bool {
+			return r.Header.Get("Origin") == wh.Origin
+		}
+	}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade сам пишет ответ с ошибкой
+		msg.Set(err.Error(), http.StatusBadRequest)
+		msg.Error()
+		return
+	}
+	defer conn.Close()
+
+	topics := &wsTopics{
+		user:   user,
+		topics: make(map[string]func(*models.Event) bool),
+	}
+	events, cancel, err := wh.EventManager.Subscribe(func(event *models.Event) bool {
+		return topics.match(event) != ""
+	}, "")
+	if err != nil {
+		msg.Set(err.Error(), http.StatusInternalServerError)
+		msg.Error()
+		return
+	}
+	defer cancel()
+
+	msg.Set("websocket opened", http.StatusSwitchingProtocols)
+	msg.Info()
+
+	replies := make(chan *wsMessage, replyBuffer)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		wh.write(conn, topics, events, replies)
+	}()
+
+	wh.read(conn, topics, replies)
+	close(replies)
+	<-done
+}
+
+// Читает команды клиента, пока соединение не закроется или клиент не перестанет успевать читать ответы
+func (wh *WebSocketHandler) read(conn *websocket.Conn, topics *wsTopics, replies chan<- *wsMessage) {
+	conn.SetReadLimit(wsMaxReadSize)
+	conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		command := &wsCommand{}
+		err = json.Unmarshal(data, command)
+		if err != nil {
+			if !reply(replies, &wsMessage{Type: "error", Error: err.Error()}) {
+				return
+			}
+			continue
+		}
+
+		var response *wsMessage
+		switch command.Action {
+		case "subscribe":
+			err = topics.add(command.Topic)
+			response = &wsMessage{Type: "subscribed", Topic: command.Topic}
+		case "unsubscribe":
+			err = topics.remove(command.Topic)
+			response = &wsMessage{Type: "unsubscribed", Topic: command.Topic}
+		default:
+			err = errBadAction
+		}
+		if err != nil {
+			response = &wsMessage{Type: "error", Topic: command.Topic, Error: err.Error()}
+		}
+		if !reply(replies, response) {
+			return
+		}
+	}
+}
+
+/*
+Пишет события и ответы в conn. Если клиент не успевает читать события,
+менеджер событий закрывает канал и соединение закрывается с кодом 1013.
+*/
+func (wh *WebSocketHandler) write(conn *websocket.Conn, topics *wsTopics, events <-chan *models.Event, replies <-chan *wsMessage) {
+	ping := time.NewTicker(wsPingPeriod)
+	defer ping.Stop()
+
+	for {
+		var err error
+		select {
+		case response, ok := <-replies:
+			if !ok {
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			err = conn.WriteJSON(response)
+		case event, ok := <-events:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if !ok {
+				conn.WriteMessage(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "slow consumer"))
+				conn.Close()
+				return
+			}
+			topic := topics.match(event)
+			if topic == "" {
+				// отписались, пока событие было в очереди
+				continue
+			}
+			err = conn.WriteJSON(&wsMessage{Type: "event", Topic: topic, Event: event})
+		case <-ping.C:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			err = conn.WriteMessage(websocket.PingMessage, nil)
+		}
+		if err != nil {
+			conn.Close()
+			return
+		}
+	}
+}
+
+// Кладет ответ в очередь, false - очередь переполнена
+func reply(replies chan<- *wsMessage, response *wsMessage) bool {
+	select {
+	case replies <- response:
+		return true
+	default:
+		return false
+	}
+}
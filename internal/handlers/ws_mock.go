@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/handlers/ws.go
+
+// Package handlers is a generated GoMock package.
+package handlers
+
+import (
+	context "context"
+	models "forum/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MocktokenChecker is a mock of tokenChecker interface.
+type MocktokenChecker struct {
+	ctrl     *gomock.Controller
+	recorder *MocktokenCheckerMockRecorder
+}
+
+// MocktokenCheckerMockRecorder is the mock recorder for MocktokenChecker.
+type MocktokenCheckerMockRecorder struct {
+	mock *MocktokenChecker
+}
+
+// NewMocktokenChecker creates a new mock instance.
+func NewMocktokenChecker(ctrl *gomock.Controller) *MocktokenChecker {
+	mock := &MocktokenChecker{ctrl: ctrl}
+	mock.recorder = &MocktokenCheckerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocktokenChecker) EXPECT() *MocktokenCheckerMockRecorder {
+	return m.recorder
+}
+
+// Check mocks base method.
+func (m *MocktokenChecker) Check(arg0 context.Context, arg1 string) (*models.Author, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Check", arg0, arg1)
+	ret0, _ := ret[0].(*models.Author)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Check indicates an expected call of Check.
+func (mr *MocktokenCheckerMockRecorder) Check(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MocktokenChecker)(nil).Check), arg0, arg1)
+}
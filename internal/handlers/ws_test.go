@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"errors"
+	"forum/internal/handlers/utils"
+	"forum/internal/models"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/websocket"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func readWSMessage(t *testing.T, conn *websocket.Conn) *wsMessage {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	message := &wsMessage{}
+	err := conn.ReadJSON(message)
+	if err != nil {
+		t.Fatalf("unexpected read error: %v", err)
+	}
+	return message
+}
+
+func TestWebSocket(t *testing.T) {
+	logger := slog.New(utils.DummyLogger{})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	authManager := NewMocktokenChecker(ctrl)
+	eventManager := NewMockeventManager(ctrl)
+
+	wsHandler := &WebSocketHandler{
+		Logger:       logger,
+		AuthManager:  authManager,
+		EventManager: eventManager,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(wsHandler.Serve))
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	limit := maxSubscriptions
+	maxSubscriptions = 2
+	defer func() { maxSubscriptions = limit }()
+
+	user := &models.Author{ID: primitive.NewObjectID(), Username: "user"}
+
+	// Bad token
+	authManager.EXPECT().Check(gomock.Any(), "bad").Return(nil, errors.New("bad token"))
+
+	_, response, err := websocket.DefaultDialer.Dial(url+"?token=bad", nil)
+	if err == nil {
+		t.Fatal("expected dial error")
+	}
+	if response.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, response.StatusCode)
+	}
+
+	// subscriptions and events
+	events := make(chan *models.Event, 5)
+	authManager.EXPECT().Check(gomock.Any(), "token").Return(user, nil)
+	eventManager.EXPECT().Subscribe(gomock.Any(), "").Return(events, func() {}, nil)
+
+	conn, _, err := websocket.DefaultDialer.Dial(url+"?token=token", nil)
+	if err != nil {
+		t.Fatalf("unexpected dial error: %v", err)
+	}
+	defer conn.Close()
+
+	postID := primitive.NewObjectID()
+	commands := []struct {
+		command  wsCommand
+		expected wsMessage
+	}{
+		{
+			command:  wsCommand{Action: "subscribe", Topic: "post:" + postID.Hex()},
+			expected: wsMessage{Type: "subscribed", Topic: "post:" + postID.Hex()},
+		},
+		{
+			command:  wsCommand{Action: "subscribe", Topic: "post:" + postID.Hex()},
+			expected: wsMessage{Type: "error", Topic: "post:" + postID.Hex(), Error: errAlreadySubscribed.Error()},
+		},
+		{
+			command:  wsCommand{Action: "subscribe", Topic: "category:unknown"},
+			expected: wsMessage{Type: "error", Topic: "category:unknown", Error: errBadTopic.Error()},
+		},
+		{
+			command:  wsCommand{Action: "subscribe", Topic: topicNotifications},
+			expected: wsMessage{Type: "subscribed", Topic: topicNotifications},
+		},
+		{
+			command:  wsCommand{Action: "subscribe", Topic: "category:music"},
+			expected: wsMessage{Type: "error", Topic: "category:music", Error: errTooManyTopics.Error()},
+		},
+		{
+			command:  wsCommand{Action: "unsubscribe", Topic: "category:music"},
+			expected: wsMessage{Type: "error", Topic: "category:music", Error: errNotSubscribed.Error()},
+		},
+		{
+			command:  wsCommand{Action: "listen", Topic: "category:music"},
+			expected: wsMessage{Type: "error", Topic: "category:music", Error: errBadAction.Error()},
+		},
+	}
+	for _, item := range commands {
+		err = conn.WriteJSON(item.command)
+		if err != nil {
+			t.Fatal(err)
+		}
+		message := readWSMessage(t, conn)
+		if *message != item.expected {
+			t.Errorf("\nwant: %v\nhave: %v", item.expected, *message)
+		}
+	}
+
+	// событие чужого поста отбрасывается, событие подписанного поста доставляется
+	events <- &models.Event{ID: "1-1", Type: models.EventPostVotes, PostID: primitive.NewObjectID()}
+	events <- &models.Event{ID: "1-2", Type: models.EventCommentCreated, PostID: postID}
+
+	message := readWSMessage(t, conn)
+	if message.Type != "event" || message.Topic != "post:"+postID.Hex() || message.Event.ID != "1-2" {
+		t.Errorf("unexpected message %v", message)
+	}
+
+	// в тему уведомлений не попадают события постов пользователя, только его уведомления
+	// чужое уведомление о подписанном посте не попадает и в тему поста
+	events <- &models.Event{ID: "1-3", Type: models.EventPostVotes, PostID: primitive.NewObjectID(), UserID: user.ID.Hex()}
+	events <- &models.Event{ID: "1-4", Type: models.EventNotification, PostID: postID, UserID: primitive.NewObjectID().Hex()}
+	events <- &models.Event{ID: "1-5", Type: models.EventNotification, UserID: user.ID.Hex()}
+
+	message = readWSMessage(t, conn)
+	if message.Type != "event" || message.Topic != topicNotifications || message.Event.ID != "1-5" {
+		t.Errorf("unexpected message %v", message)
+	}
+
+	// slow consumer
+	close(events)
+
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, _, err = conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseTryAgainLater) {
+		t.Errorf("expected close %d, got %v", websocket.CloseTryAgainLater, err)
+	}
+}
@@ -0,0 +1,115 @@
+package images
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/draw"
+)
+
+// Возвращает значение тега Orientation из EXIF JPEG-файла, 1 - если его нет
+func jpegOrientation(data []byte) int {
+	reader := bytes.NewReader(data)
+	var marker [2]byte
+	if _, err := reader.Read(marker[:]); err != nil || marker != [2]byte{0xFF, 0xD8} {
+		return 1
+	}
+	for {
+		var header [4]byte
+		if _, err := reader.Read(header[:]); err != nil || header[0] != 0xFF {
+			return 1
+		}
+		length := int(binary.BigEndian.Uint16(header[2:])) - 2
+		if length < 0 || length > reader.Len() {
+			return 1
+		}
+		segment := make([]byte, length)
+		reader.Read(segment)
+		// APP1 с EXIF
+		if header[1] == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return tiffOrientation(segment[6:])
+		}
+		// начало данных изображения, дальше метаданных нет
+		if header[1] == 0xDA {
+			return 1
+		}
+	}
+}
+
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	offset := int(order.Uint32(tiff[4:]))
+	if offset+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[offset:]))
+	for i := 0; i < count; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			orientation := int(order.Uint16(tiff[entry+8:]))
+			if orientation < 1 || orientation > 8 {
+				return 1
+			}
+			return orientation
+		}
+	}
+	return 1
+}
+
+/*
+Поворачивает и отражает img согласно EXIF Orientation, т.к. после удаления EXIF
+браузер уже не сможет это сделать сам.
+*/
+func applyOrientation(img image.Image, orientation int) image.Image {
+	if orientation == 1 {
+		return img
+	}
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	// для 5-8 ширина и высота меняются местами
+	outWidth, outHeight := width, height
+	if orientation >= 5 {
+		outWidth, outHeight = height, width
+	}
+	src := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+	out := image.NewRGBA(image.Rect(0, 0, outWidth, outHeight))
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = width-1-x, y
+			case 3:
+				dx, dy = width-1-x, height-1-y
+			case 4:
+				dx, dy = x, height-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = height-1-y, x
+			case 7:
+				dx, dy = height-1-y, width-1-x
+			case 8:
+				dx, dy = y, width-1-x
+			}
+			out.Set(dx, dy, src.At(x, y))
+		}
+	}
+	return out
+}
@@ -0,0 +1,67 @@
+package images
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var errBadGIF = errors.New("bad gif")
+
+/*
+Считает кадры GIF и их суммарную площадь по дескрипторам изображений без распаковки LZW,
+чтобы отклонить анимацию, которая не поместится в память, до gif.DecodeAll.
+*/
+func gifFrames(data []byte) (int, int, error) {
+	if len(data) < 13 {
+		return 0, 0, errBadGIF
+	}
+	pos := 13 + colorTableSize(data[10])
+	frames, pixels := 0, 0
+	for pos < len(data) {
+		switch data[pos] {
+		// расширение: метка и блоки данных
+		case 0x21:
+			pos = skipSubBlocks(data, pos+2)
+		// дескриптор изображения: положение, размер, локальная палитра, LZW-данные
+		case 0x2C:
+			if pos+10 > len(data) {
+				return 0, 0, errBadGIF
+			}
+			width := int(binary.LittleEndian.Uint16(data[pos+5:]))
+			height := int(binary.LittleEndian.Uint16(data[pos+7:]))
+			frames++
+			pixels += width * height
+			pos = skipSubBlocks(data, pos+10+colorTableSize(data[pos+9])+1)
+		// конец файла
+		case 0x3B:
+			return frames, pixels, nil
+		default:
+			return 0, 0, errBadGIF
+		}
+		if pos < 0 {
+			return 0, 0, errBadGIF
+		}
+	}
+	return frames, pixels, nil
+}
+
+// Размер палитры в байтах по упакованному полю дескриптора
+func colorTableSize(packed byte) int {
+	if packed&0x80 == 0 {
+		return 0
+	}
+	return 3 << (packed&0x07 + 1)
+}
+
+// Пропускает последовательность блоков данных, заканчивающуюся нулевым блоком. -1 - данные оборваны.
+func skipSubBlocks(data []byte, pos int) int {
+	for pos < len(data) {
+		size := int(data[pos])
+		pos++
+		if size == 0 {
+			return pos
+		}
+		pos += size
+	}
+	return -1
+}
@@ -0,0 +1,170 @@
+package images
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"golang.org/x/image/draw"
+)
+
+var (
+	// ограничения проверяются по заголовку до полного декодирования, чтобы не раскрывать "бомбы"
+	maxSide   = 8000
+	maxPixels = 40_000_000
+	// у анимации ограничены число кадров и их суммарная площадь, каждый кадр декодируется в память
+	maxFrames          = 500
+	maxAnimationPixels = 200_000_000
+	// сторона квадрата, в который вписывается миниатюра
+	thumbnailSize = 320
+	jpegQuality   = 90
+
+	ErrUnsupportedType = errors.New("unsupported image type")
+	ErrBadDimensions   = errors.New("bad image dimensions")
+)
+
+// Расширения поддерживаемых типов
+var extensions = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/gif":  "gif",
+}
+
+/*
+Обработанное изображение. Original перекодирован заново, поэтому в нем нет EXIF и других метаданных,
+Thumbnail вписан в thumbnailSize x thumbnailSize.
+*/
+type Processed struct {
+	Original       []byte
+	Thumbnail      []byte
+	ContentType    string
+	Extension      string
+	ThumbExtension string
+	Width          int
+	Height         int
+}
+
+// Возвращает content type по расширению файла, пустая строка - неподдерживаемое
+func ContentType(extension string) string {
+	for contentType, ext := range extensions {
+		if ext == extension {
+			return contentType
+		}
+	}
+	return ""
+}
+
+// Проверяет тип и размеры data, удаляет метаданные и создает миниатюру
+func Process(data []byte) (*Processed, error) {
+	contentType := http.DetectContentType(data)
+	extension, ok := extensions[contentType]
+	if !ok {
+		return nil, ErrUnsupportedType
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedType
+	}
+	if config.Width < 1 || config.Height < 1 || config.Width > maxSide || config.Height > maxSide ||
+		config.Width*config.Height > maxPixels {
+		return nil, ErrBadDimensions
+	}
+
+	processed := &Processed{
+		ContentType: contentType,
+		Extension:   extension,
+	}
+	original := &bytes.Buffer{}
+	var img image.Image
+
+	switch contentType {
+	case "image/gif":
+		frames, pixels, err := gifFrames(data)
+		if err != nil {
+			return nil, ErrUnsupportedType
+		}
+		if frames > maxFrames || pixels > maxAnimationPixels {
+			return nil, ErrBadDimensions
+		}
+		// анимация сохраняется, перекодирование убирает комментарии и расширения приложений
+		animation, err := gif.DecodeAll(bytes.NewReader(data))
+		if err != nil {
+			return nil, ErrUnsupportedType
+		}
+		animation.Config = image.Config{}
+		err = gif.EncodeAll(original, &gif.GIF{
+			Image:     animation.Image,
+			Delay:     animation.Delay,
+			LoopCount: animation.LoopCount,
+			Disposal:  animation.Disposal,
+		})
+		if err != nil {
+			return nil, err
+		}
+		img = animation.Image[0]
+	case "image/jpeg":
+		img, err = jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, ErrUnsupportedType
+		}
+		img = applyOrientation(img, jpegOrientation(data))
+		err = jpeg.Encode(original, img, &jpeg.Options{Quality: jpegQuality})
+		if err != nil {
+			return nil, err
+		}
+	case "image/png":
+		img, err = png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, ErrUnsupportedType
+		}
+		err = png.Encode(original, img)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	processed.Original = original.Bytes()
+	processed.Width = img.Bounds().Dx()
+	processed.Height = img.Bounds().Dy()
+
+	thumbnail := &bytes.Buffer{}
+	thumb := resize(img, thumbnailSize)
+	// у JPEG нет прозрачности, остальное сохраняется в PNG
+	if contentType == "image/jpeg" {
+		processed.ThumbExtension = "jpg"
+		err = jpeg.Encode(thumbnail, thumb, &jpeg.Options{Quality: jpegQuality})
+	} else {
+		processed.ThumbExtension = "png"
+		err = png.Encode(thumbnail, thumb)
+	}
+	if err != nil {
+		return nil, err
+	}
+	processed.Thumbnail = thumbnail.Bytes()
+
+	return processed, nil
+}
+
+// Вписывает img в квадрат size x size с сохранением пропорций, меньшие изображения не увеличиваются
+func resize(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= size && height <= size {
+		return img
+	}
+	if width >= height {
+		height = max(1, height*size/width)
+		width = size
+	} else {
+		width = max(1, width*size/height)
+		height = size
+	}
+	thumb := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(thumb, thumb.Bounds(), img, bounds, draw.Src, nil)
+	return thumb
+}
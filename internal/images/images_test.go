@@ -0,0 +1,175 @@
+package images
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func newTestImage(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 0, 255})
+		}
+	}
+	return img
+}
+
+// Собирает JPEG с APP1 EXIF, в котором указана orientation
+func jpegWithOrientation(t *testing.T, img image.Image, orientation uint16) []byte {
+	t.Helper()
+	encoded := &bytes.Buffer{}
+	err := jpeg.Encode(encoded, img, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tiff := &bytes.Buffer{}
+	tiff.WriteString("MM")
+	binary.Write(tiff, binary.BigEndian, uint16(42))
+	binary.Write(tiff, binary.BigEndian, uint32(8))
+	binary.Write(tiff, binary.BigEndian, uint16(1))
+	binary.Write(tiff, binary.BigEndian, uint16(0x0112))
+	binary.Write(tiff, binary.BigEndian, uint16(3))
+	binary.Write(tiff, binary.BigEndian, uint32(1))
+	binary.Write(tiff, binary.BigEndian, orientation)
+	binary.Write(tiff, binary.BigEndian, uint16(0))
+	binary.Write(tiff, binary.BigEndian, uint32(0))
+
+	segment := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+	out := &bytes.Buffer{}
+	out.Write([]byte{0xFF, 0xD8, 0xFF, 0xE1})
+	binary.Write(out, binary.BigEndian, uint16(len(segment)+2))
+	out.Write(segment)
+	out.Write(encoded.Bytes()[2:])
+	return out.Bytes()
+}
+
+func TestProcessJPEG(t *testing.T) {
+	data := jpegWithOrientation(t, newTestImage(800, 400), 6)
+	if jpegOrientation(data) != 6 {
+		t.Fatalf("want orientation 6, have %d", jpegOrientation(data))
+	}
+
+	processed, err := Process(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// поворот на 90 градусов
+	if processed.Width != 400 || processed.Height != 800 {
+		t.Errorf("want 400x800, have %dx%d", processed.Width, processed.Height)
+	}
+	if processed.ContentType != "image/jpeg" || processed.Extension != "jpg" || processed.ThumbExtension != "jpg" {
+		t.Errorf("unexpected types %+v", processed)
+	}
+	if bytes.Contains(processed.Original, []byte("Exif")) {
+		t.Error("exif must be stripped")
+	}
+	if jpegOrientation(processed.Original) != 1 {
+		t.Error("orientation must be reset")
+	}
+
+	thumb, err := jpeg.DecodeConfig(bytes.NewReader(processed.Thumbnail))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if thumb.Width != 160 || thumb.Height != 320 {
+		t.Errorf("want thumbnail 160x320, have %dx%d", thumb.Width, thumb.Height)
+	}
+}
+
+func TestProcessPNGAndGIF(t *testing.T) {
+	encoded := &bytes.Buffer{}
+	png.Encode(encoded, newTestImage(100, 50))
+	processed, err := Process(encoded.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if processed.Extension != "png" || processed.Width != 100 || processed.Height != 50 {
+		t.Errorf("unexpected png %+v", processed)
+	}
+
+	palette := color.Palette{color.Black, color.White}
+	frame := image.NewPaletted(image.Rect(0, 0, 20, 10), palette)
+	encoded.Reset()
+	gif.EncodeAll(encoded, &gif.GIF{
+		Image: []*image.Paletted{frame, frame},
+		Delay: []int{10, 10},
+	})
+	processed, err = Process(encoded.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if processed.Extension != "gif" || processed.ThumbExtension != "png" {
+		t.Errorf("unexpected gif %+v", processed)
+	}
+	animation, err := gif.DecodeAll(bytes.NewReader(processed.Original))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(animation.Image) != 2 {
+		t.Errorf("animation must be kept, have %d frames", len(animation.Image))
+	}
+}
+
+func TestProcessErrors(t *testing.T) {
+	_, err := Process([]byte("<html>not an image</html>"))
+	if err != ErrUnsupportedType {
+		t.Errorf("want %v, have %v", ErrUnsupportedType, err)
+	}
+
+	side := maxSide
+	maxSide = 50
+	defer func() { maxSide = side }()
+
+	encoded := &bytes.Buffer{}
+	png.Encode(encoded, newTestImage(100, 10))
+	_, err = Process(encoded.Bytes())
+	if err != ErrBadDimensions {
+		t.Errorf("want %v, have %v", ErrBadDimensions, err)
+	}
+}
+
+func TestProcessGIFLimits(t *testing.T) {
+	palette := color.Palette{color.Black, color.White}
+	frame := image.NewPaletted(image.Rect(0, 0, 40, 30), palette)
+	animation := &gif.GIF{}
+	for i := 0; i < 100; i++ {
+		animation.Image = append(animation.Image, frame)
+		animation.Delay = append(animation.Delay, 10)
+	}
+	encoded := &bytes.Buffer{}
+	gif.EncodeAll(encoded, animation)
+
+	frames, pixels, err := gifFrames(encoded.Bytes())
+	if err != nil || frames != 100 || pixels != 100*40*30 {
+		t.Fatalf("want 100 frames of 1200 pixels, have %d, %d, %v", frames, pixels, err)
+	}
+
+	limit := maxFrames
+	maxFrames = 99
+	_, err = Process(encoded.Bytes())
+	maxFrames = limit
+	if err != ErrBadDimensions {
+		t.Errorf("too many frames: want %v, have %v", ErrBadDimensions, err)
+	}
+
+	limit = maxAnimationPixels
+	maxAnimationPixels = 100*40*30 - 1
+	_, err = Process(encoded.Bytes())
+	maxAnimationPixels = limit
+	if err != ErrBadDimensions {
+		t.Errorf("too many pixels: want %v, have %v", ErrBadDimensions, err)
+	}
+
+	_, err = Process(encoded.Bytes())
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package managers
+
+import (
+	"forum/internal/models"
+	"slices"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type accountUserRepo interface {
+	Delete(string) error
+}
+
+type accountSessionRepo interface {
+	DeleteAll(primitive.ObjectID) error
+}
+
+type accountPostRepo interface {
+	Find(bson.M) ([]*models.Post, error)
+	AnonymizeAuthor(primitive.ObjectID) error
+	DeleteUnpublished(primitive.ObjectID) error
+}
+
+type AccountManager struct {
+	users    accountUserRepo
+	sessions accountSessionRepo
+	posts    accountPostRepo
+}
+
+func NewAccountManager(users accountUserRepo, sessions accountSessionRepo, posts accountPostRepo) *AccountManager {
+	return &AccountManager{
+		users:    users,
+		sessions: sessions,
+		posts:    posts,
+	}
+}
+
+/*
+Удаляет аккаунт пользователя. Черновики и отложенные посты удаляются, чтобы не быть опубликованными позже,
+остальные посты и комментарии обезличиваются. Пользователь удаляется последним: шаги с постами
+можно повторить, и если один из них не удался, пользователь может войти и удалить аккаунт еще раз.
+*/
+func (am *AccountManager) Delete(author *models.Author) error {
+	err := am.sessions.DeleteAll(author.ID)
+	if err != nil {
+		return err
+	}
+
+	err = am.posts.DeleteUnpublished(author.ID)
+	if err != nil {
+		return err
+	}
+
+	err = am.posts.AnonymizeAuthor(author.ID)
+	if err != nil {
+		return err
+	}
+
+	return am.users.Delete(author.Username)
+}
+
+// Собирает посты, комментарии, голоса и участие в опросах пользователя для выгрузки
+func (am *AccountManager) Export(author *models.Author) (*models.UserExport, error) {
+	export := &models.UserExport{
+		User:     *author,
+		Comments: make([]models.ExportComment, 0),
+		Votes:    make([]models.ExportVote, 0),
+		Ballots:  make([]models.ExportBallot, 0),
+	}
+
+	posts, err := am.posts.Find(bson.M{"author.id": author.ID})
+	if err != nil {
+		return nil, err
+	}
+	export.Posts = posts
+
+	filter := bson.M{
+		"$or": []bson.M{
+			{"comments.author.id": author.ID},
+			{"votes.userID": author.ID},
+			{"poll.voters": author.ID},
+		},
+	}
+	related, err := am.posts.Find(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, post := range related {
+		for _, comment := range post.Comments {
+			if comment.Author.ID == author.ID {
+				export.Comments = append(export.Comments, models.ExportComment{
+					PostID:  post.ID,
+					Comment: comment,
+				})
+			}
+		}
+		for _, vote := range post.Votes {
+			if vote.UserID == author.ID {
+				export.Votes = append(export.Votes, models.ExportVote{
+					PostID: post.ID,
+					Vote:   vote.Vote,
+				})
+			}
+		}
+		if post.Poll != nil && slices.Contains(post.Poll.Voters, author.ID) {
+			export.Ballots = append(export.Ballots, models.ExportBallot{PostID: post.ID})
+		}
+	}
+
+	return export, nil
+}
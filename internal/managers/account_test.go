@@ -0,0 +1,94 @@
+package managers
+
+import (
+	"errors"
+	"forum/internal/models"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Записывает шаги удаления аккаунта, шаг fail завершается ошибкой. Find отдает posts.
+type accountSteps struct {
+	steps []string
+	fail  string
+	posts []*models.Post
+}
+
+func (as *accountSteps) step(name string) error {
+	as.steps = append(as.steps, name)
+	if name == as.fail {
+		return errors.New(name + " failed")
+	}
+	return nil
+}
+
+func (as *accountSteps) Find(filter bson.M) ([]*models.Post, error) {
+	return as.posts, nil
+}
+
+func (as *accountSteps) Delete(username string) error {
+	return as.step("users.Delete")
+}
+
+func (as *accountSteps) DeleteAll(userID primitive.ObjectID) error {
+	return as.step("sessions.DeleteAll")
+}
+
+func (as *accountSteps) DeleteUnpublished(authorID primitive.ObjectID) error {
+	return as.step("posts.DeleteUnpublished")
+}
+
+func (as *accountSteps) AnonymizeAuthor(authorID primitive.ObjectID) error {
+	return as.step("posts.AnonymizeAuthor")
+}
+
+func TestAccountDelete(t *testing.T) {
+	author := &models.Author{ID: primitive.NewObjectID(), Username: "user"}
+
+	steps := &accountSteps{}
+	err := NewAccountManager(steps, steps, steps).Delete(author)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// черновики удаляются до обезличивания, чтобы не быть опубликованными от удаленного автора
+	expected := []string{"sessions.DeleteAll", "posts.DeleteUnpublished", "posts.AnonymizeAuthor", "users.Delete"}
+	if !reflect.DeepEqual(steps.steps, expected) {
+		t.Errorf("want steps %v, have %v", expected, steps.steps)
+	}
+
+	// если посты не обезличены, пользователь остается и может повторить удаление
+	steps = &accountSteps{fail: "posts.AnonymizeAuthor"}
+	err = NewAccountManager(steps, steps, steps).Delete(author)
+	if err == nil {
+		t.Fatal("expected error, but was nil")
+	}
+	expected = []string{"sessions.DeleteAll", "posts.DeleteUnpublished", "posts.AnonymizeAuthor"}
+	if !reflect.DeepEqual(steps.steps, expected) {
+		t.Errorf("want steps %v, have %v", expected, steps.steps)
+	}
+}
+
+func TestAccountExport(t *testing.T) {
+	author := &models.Author{ID: primitive.NewObjectID(), Username: "user"}
+	poll := &models.Post{
+		ID:    primitive.NewObjectID(),
+		Votes: []models.Vote{{UserID: author.ID, Vote: 1}},
+		Poll:  &models.Poll{Voters: []primitive.ObjectID{primitive.NewObjectID(), author.ID}},
+	}
+	other := &models.Post{ID: primitive.NewObjectID(), Poll: &models.Poll{Voters: []primitive.ObjectID{primitive.NewObjectID()}}}
+
+	storage := &accountSteps{posts: []*models.Post{poll, other}}
+	export, err := NewAccountManager(storage, storage, storage).Export(author)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(export.Ballots, []models.ExportBallot{{PostID: poll.ID}}) {
+		t.Errorf("want ballot in %v, have %v", poll.ID, export.Ballots)
+	}
+	if len(export.Votes) != 1 || export.Votes[0].PostID != poll.ID {
+		t.Errorf("unexpected votes %v", export.Votes)
+	}
+}
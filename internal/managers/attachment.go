@@ -0,0 +1,107 @@
+package managers
+
+import (
+	"bytes"
+	"errors"
+	"forum/internal/images"
+	"forum/internal/models"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	maxUploadSize int64 = 10 << 20
+
+	errTooLarge      = errors.New("file is too large")
+	errBadAttachment = errors.New("bad attachment key")
+)
+
+type blobStore interface {
+	Put(string, io.Reader, int64, string) error
+	Get(string) (io.ReadCloser, error)
+	Delete(string) error
+}
+
+type attachmentRepo interface {
+	Create(*models.Attachment) error
+	FindOne(primitive.ObjectID) (*models.Attachment, error)
+}
+
+type AttachmentManager struct {
+	blobs   blobStore
+	storage attachmentRepo
+}
+
+func NewAttachmentManager(blobs blobStore, storage attachmentRepo) *AttachmentManager {
+	return &AttachmentManager{
+		blobs:   blobs,
+		storage: storage,
+	}
+}
+
+/*
+Загружает изображение author. Изображение перекодируется без метаданных,
+рядом сохраняется миниатюра. Возвращенный id указывается при создании поста типа image.
+*/
+func (am *AttachmentManager) Upload(author *models.Author, data io.Reader) (*models.Attachment, error) {
+	raw, err := io.ReadAll(io.LimitReader(data, maxUploadSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(raw)) > maxUploadSize {
+		return nil, errTooLarge
+	}
+
+	processed, err := images.Process(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	id := primitive.NewObjectID()
+	attachment := &models.Attachment{
+		ID:          id,
+		OwnerID:     author.ID,
+		Key:         id.Hex() + "." + processed.Extension,
+		ThumbKey:    id.Hex() + "_thumb." + processed.ThumbExtension,
+		ContentType: processed.ContentType,
+		Size:        len(processed.Original),
+		Width:       processed.Width,
+		Height:      processed.Height,
+		Created:     time.Now(),
+	}
+
+	err = am.blobs.Put(attachment.Key, bytes.NewReader(processed.Original), int64(len(processed.Original)), processed.ContentType)
+	if err != nil {
+		return nil, err
+	}
+	err = am.blobs.Put(attachment.ThumbKey, bytes.NewReader(processed.Thumbnail), int64(len(processed.Thumbnail)), images.ContentType(processed.ThumbExtension))
+	if err != nil {
+		am.blobs.Delete(attachment.Key)
+		return nil, err
+	}
+
+	err = am.storage.Create(attachment)
+	if err != nil {
+		am.blobs.Delete(attachment.Key)
+		am.blobs.Delete(attachment.ThumbKey)
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// Открывает файл с ключом key, возвращает его содержимое и content type
+func (am *AttachmentManager) Open(key string) (io.ReadCloser, string, error) {
+	contentType := images.ContentType(strings.TrimPrefix(path.Ext(key), "."))
+	if contentType == "" || path.Base(key) != key {
+		return nil, "", errBadAttachment
+	}
+	file, err := am.blobs.Get(key)
+	if err != nil {
+		return nil, "", err
+	}
+	return file, contentType, nil
+}
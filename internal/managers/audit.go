@@ -0,0 +1,59 @@
+package managers
+
+import (
+	"forum/internal/models"
+)
+
+// Запись в журнал модерации
+type auditRepo interface {
+	Append(*models.AuditEntry) error
+}
+
+type auditLogRepo interface {
+	auditRepo
+	Find(models.AuditFilter, models.Page) ([]*models.AuditEntry, error)
+	Each(models.AuditFilter, func(*models.AuditEntry) error) error
+}
+
+/*
+Добавляет в журнал запись о действии actor над объектом, before - состояние объекта до действия.
+Вызывается после того, как действие выполнено, поэтому ошибка записи возвращается вызывающему,
+чтобы действие без следа в журнале не выглядело успешным.
+*/
+func recordAudit(audit auditRepo, actor *models.Author, action, targetType, targetID, reason string, before any) error {
+	entry, err := models.NewAuditEntry(actor, action, targetType, targetID, reason, before)
+	if err != nil {
+		return err
+	}
+	return audit.Append(entry)
+}
+
+type AuditManager struct {
+	storage auditLogRepo
+	users   moderatorRepo
+}
+
+func NewAuditManager(storage auditLogRepo, users moderatorRepo) *AuditManager {
+	return &AuditManager{
+		storage: storage,
+		users:   users,
+	}
+}
+
+// Возвращает страницу журнала модерации, подходящую под filter, новые записи первыми
+func (am *AuditManager) Find(moderator *models.Author, filter models.AuditFilter, page models.Page) ([]*models.AuditEntry, error) {
+	err := checkModerator(am.users, moderator)
+	if err != nil {
+		return nil, err
+	}
+	return am.storage.Find(filter, page)
+}
+
+// Передает в fn все записи журнала, подходящие под filter, от старых к новым
+func (am *AuditManager) Export(moderator *models.Author, filter models.AuditFilter, fn func(*models.AuditEntry) error) error {
+	err := checkModerator(am.users, moderator)
+	if err != nil {
+		return err
+	}
+	return am.storage.Each(filter, fn)
+}
@@ -0,0 +1,162 @@
+package managers
+
+import (
+	"encoding/json"
+	"errors"
+	"forum/internal/models"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryAudit struct {
+	entries []*models.AuditEntry
+	err     error
+}
+
+func (ma *memoryAudit) Append(entry *models.AuditEntry) error {
+	if ma.err != nil {
+		return ma.err
+	}
+	ma.entries = append(ma.entries, entry)
+	return nil
+}
+
+func (ma *memoryAudit) Find(filter models.AuditFilter, page models.Page) ([]*models.AuditEntry, error) {
+	entries := make([]*models.AuditEntry, 0)
+	for _, entry := range ma.entries {
+		if filter.Action == "" || entry.Action == filter.Action {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func (ma *memoryAudit) Each(filter models.AuditFilter, fn func(*models.AuditEntry) error) error {
+	entries, _ := ma.Find(filter, models.Page{})
+	for _, entry := range entries {
+		err := fn(entry)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Пост, который можно удалить целиком или по комментарию
+type removablePost struct {
+	memoryPost
+	deleted bool
+}
+
+func (rp *removablePost) Delete(postID, deletedBy primitive.ObjectID, now time.Time, events models.OutboxEvents) error {
+	rp.deleted = true
+	return rp.outbox.save(rp.post, events)
+}
+
+func (rp *removablePost) DeleteComment(postID, commentID, deletedBy primitive.ObjectID, now time.Time, events models.OutboxEvents) (*models.Post, error) {
+	for i := range rp.post.Comments {
+		if rp.post.Comments[i].ID == commentID {
+			rp.post.Comments[i].DeletedAt = &now
+			rp.post.Comments[i].DeletedBy = &deletedBy
+		}
+	}
+	return rp.post, rp.outbox.save(rp.post, events)
+}
+
+func TestDeleteAudit(t *testing.T) {
+	moderator := &models.Author{ID: primitive.NewObjectID(), Username: "moderator"}
+	comment := models.Comment{ID: primitive.NewObjectID(), Body: "spam comment"}
+	post := &models.Post{
+		ID:       primitive.NewObjectID(),
+		Title:    "spam",
+		Created:  time.Now(),
+		Comments: []models.Comment{comment},
+	}
+	users := &memoryUsers{users: map[string]*models.User{
+		"moderator": {ID: moderator.ID.Hex(), Username: "moderator", Role: models.RoleModerator},
+	}}
+	storage := &removablePost{memoryPost: memoryPost{post: post}}
+	audit := &memoryAudit{}
+	postManager := NewPostManager(storage, users, nil, nil, nil, &memoryPublisher{}, nil, nil, audit, nil, &memoryCategories{}, testArchiveMonths)
+
+	_, err := postManager.DeleteComment(post.ID.Hex(), primitive.NewObjectID().Hex(), moderator, "spam")
+	if err != errNoComment {
+		t.Errorf("want errNoComment, have %v", err)
+	}
+
+	_, err = postManager.DeleteComment(post.ID.Hex(), comment.ID.Hex(), moderator, "spam")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = postManager.Delete(post.ID.Hex(), moderator, "spam")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(audit.entries) != 2 {
+		t.Fatalf("want 2 audit entries, have %d", len(audit.entries))
+	}
+	entry := audit.entries[0]
+	if entry.Action != models.AuditCommentDelete || entry.TargetID != comment.ID.Hex() || entry.ActorID != moderator.ID || entry.Reason != "spam" {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+	before := &models.Comment{}
+	err = json.Unmarshal([]byte(entry.Before), before)
+	if err != nil || before.Body != comment.Body {
+		t.Errorf("comment snapshot not saved: %v, %q", err, entry.Before)
+	}
+	entry = audit.entries[1]
+	if entry.Action != models.AuditPostDelete || entry.TargetType != models.AuditTargetPost || entry.TargetID != post.ID.Hex() {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+
+	// ошибка журнала не скрывается от вызывающего
+	audit.err = errors.New("audit unavailable")
+	err = postManager.Delete(post.ID.Hex(), moderator, "spam")
+	if err != audit.err {
+		t.Errorf("want audit error, have %v", err)
+	}
+}
+
+func TestAuditManager(t *testing.T) {
+	moderator := &models.Author{ID: primitive.NewObjectID(), Username: "moderator"}
+	user := &models.Author{ID: primitive.NewObjectID(), Username: "user"}
+	users := &memoryUsers{users: map[string]*models.User{
+		"moderator": {ID: moderator.ID.Hex(), Username: "moderator", Role: models.RoleModerator},
+		"user":      {ID: user.ID.Hex(), Username: "user", Role: models.RoleUser},
+	}}
+	audit := &memoryAudit{entries: []*models.AuditEntry{
+		{ID: primitive.NewObjectID(), Action: models.AuditPostDelete},
+		{ID: primitive.NewObjectID(), Action: models.AuditUserBan},
+	}}
+	auditManager := NewAuditManager(audit, users)
+	filter := models.AuditFilter{Action: models.AuditUserBan}
+
+	_, err := auditManager.Find(user, filter, models.Page{Number: 1, Size: 20})
+	if err != models.ErrForbidden {
+		t.Errorf("want ErrForbidden, have %v", err)
+	}
+	err = auditManager.Export(user, filter, func(*models.AuditEntry) error { return nil })
+	if err != models.ErrForbidden {
+		t.Errorf("want ErrForbidden, have %v", err)
+	}
+
+	entries, err := auditManager.Find(moderator, filter, models.Page{Number: 1, Size: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Action != models.AuditUserBan {
+		t.Errorf("unexpected entries %+v", entries)
+	}
+
+	exported := 0
+	err = auditManager.Export(moderator, models.AuditFilter{}, func(*models.AuditEntry) error {
+		exported++
+		return nil
+	})
+	if err != nil || exported != 2 {
+		t.Errorf("want 2 exported entries, have %d, %v", exported, err)
+	}
+}
@@ -1,8 +1,11 @@
 package managers
 
 import (
+	"context"
 	"errors"
+	"forum/internal/metrics"
 	"forum/internal/models"
+	"forum/internal/tracing"
 	"reflect"
 	"time"
 
@@ -23,7 +26,7 @@ var (
 
 type sessionRepo interface {
 	Set(string, *models.Author) error
-	Get(string) (*models.Author, error)
+	Get(context.Context, string) (*models.Author, error)
 }
 
 type userRepo interface {
@@ -47,6 +50,7 @@ func NewSeesionManager(users userRepo, sessions sessionRepo) *AuthManager {
 func (am *AuthManager) Login(loginInput *models.User) (string, error) {
 	user, err := am.users.FindOne(loginInput.Username)
 	if err != nil {
+		metrics.AuthFailure(metrics.AuthLogin)
 		return "", err
 	}
 
@@ -57,8 +61,13 @@ func (am *AuthManager) Login(loginInput *models.User) (string, error) {
 
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(loginInput.Password))
 	if err != nil {
+		metrics.AuthFailure(metrics.AuthLogin)
 		return "", errBadPass
 	}
+	err = user.Suspension(time.Now())
+	if err != nil {
+		return "", err
+	}
 
 	author := &models.Author{ID: userID, Username: user.Username}
 	token, err := am.generateToken(author)
@@ -114,12 +123,19 @@ func (am *AuthManager) generateToken(author *models.Author) (string, error) {
 }
 
 // Проверка токена. В горутине осуществляется запрос на получение автора по токену,
-// т.к. эта процедура не зависит от извлечения автора из токена
-func (am *AuthManager) Check(tokenIn string) (*models.Author, error) {
+// т.к. эта процедура не зависит от извлечения автора из токена.
+// Блокировка и теневой бан читаются из базы, чтобы действовать и на уже выданные сессии.
+func (am *AuthManager) Check(ctx context.Context, tokenIn string) (_ *models.Author, err error) {
+	ctx, span := tracing.Start(ctx, "AuthManager.Check")
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+
 	errChan := make(chan error)
 	authorChan := make(chan *models.Author)
 	go func(errChan chan error, authorChan chan *models.Author) {
-		author, err := am.sessions.Get(tokenIn)
+		author, err := am.sessions.Get(ctx, tokenIn)
 		if err != nil {
 			errChan <- err
 		} else {
@@ -180,6 +196,19 @@ func (am *AuthManager) Check(tokenIn string) (*models.Author, error) {
 		if !reflect.DeepEqual(authorFromToken, author) {
 			return nil, errBadToken
 		}
-		return author, nil
 	}
+
+	// хранилище пользователей не принимает контекст, обращение к нему отмечается отдельным спаном
+	_, userSpan := tracing.Start(ctx, "AuthManager.Check user")
+	user, err := am.users.FindOne(username)
+	userSpan.End()
+	if err != nil {
+		return nil, err
+	}
+	err = user.Suspension(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	authorFromToken.Shadowbanned = user.Shadowbanned
+	return authorFromToken, nil
 }
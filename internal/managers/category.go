@@ -0,0 +1,191 @@
+package managers
+
+import (
+	"errors"
+	"fmt"
+	"forum/internal/models"
+	"slices"
+	"time"
+)
+
+var (
+	errNoCategory   = errors.New("no such category")
+	errBadPostType  = errors.New("unknown post type")
+	errTypeDenied   = errors.New("this post type is not allowed in the category")
+	errNotModerator = errors.New("user is not a moderator of the category")
+)
+
+// Типы постов, должны совпадать с тегом valid у PostInput.Type
+var postTypes = map[string]bool{"link": true, "text": true, "image": true, "poll": true}
+
+type categoryRepo interface {
+	ModeratedCategories(string) ([]string, error)
+	Rules(string) (*models.CategoryRules, error)
+}
+
+type categoryAdminRepo interface {
+	categoryRepo
+	AddModerator(string, string) error
+	RemoveModerator(string, string) error
+	Moderators(string) ([]models.Author, error)
+	SetRules(*models.CategoryRules) error
+}
+
+/*
+Возвращает категории, которые может модерировать author: nil - все категории
+для модераторов и администраторов сайта, иначе категории, куда он назначен.
+Если таких нет, возвращает ErrForbidden. Права читаются из базы при каждом действии.
+*/
+func moderatedCategories(users moderatorRepo, categories categoryRepo, author *models.Author) ([]string, error) {
+	user, err := users.FindOne(author.Username)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsModerator() {
+		return nil, nil
+	}
+	moderated, err := categories.ModeratedCategories(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(moderated) == 0 {
+		return nil, models.ErrForbidden
+	}
+	return moderated, nil
+}
+
+// Проверяет, что author может модерировать посты категории category
+func checkCategoryModerator(users moderatorRepo, categories categoryRepo, author *models.Author, category string) error {
+	moderated, err := moderatedCategories(users, categories, author)
+	if err != nil {
+		return err
+	}
+	if moderated != nil && !slices.Contains(moderated, category) {
+		return models.ErrForbidden
+	}
+	return nil
+}
+
+// Проверяет правила категории для поста типа postType от author на момент now
+func checkRules(rules *models.CategoryRules, postType string, author *models.Author, now time.Time) error {
+	if !rules.AllowsType(postType) {
+		return errTypeDenied
+	}
+	allowedFrom := rules.AllowedFrom(author.ID.Timestamp())
+	if now.Before(allowedFrom) {
+		return fmt.Errorf("%w: accounts younger than %d days cant post in %s", models.ErrForbidden, rules.MinAccountDays, rules.Category)
+	}
+	return nil
+}
+
+// Назначение модераторов категорий и правила публикации в них. Изменять их может только администратор.
+type CategoryManager struct {
+	storage categoryAdminRepo
+	users   moderatorRepo
+	audit   auditRepo
+}
+
+func NewCategoryManager(storage categoryAdminRepo, users moderatorRepo, audit auditRepo) *CategoryManager {
+	return &CategoryManager{
+		storage: storage,
+		users:   users,
+		audit:   audit,
+	}
+}
+
+// Возвращает модераторов категории category
+func (cm *CategoryManager) Moderators(category string) ([]models.Author, error) {
+	if !models.IsCategory(category) {
+		return nil, errNoCategory
+	}
+	return cm.storage.Moderators(category)
+}
+
+// Назначает пользователя username модератором категории category
+func (cm *CategoryManager) AddModerator(category, username string, admin *models.Author) error {
+	user, err := cm.target(category, username, admin)
+	if err != nil {
+		return err
+	}
+	err = cm.storage.AddModerator(category, user.ID)
+	if err != nil {
+		return err
+	}
+	return recordAudit(cm.audit, admin, models.AuditCategoryModeratorAdd, models.AuditTargetCategory, category, "", map[string]string{"userID": user.ID})
+}
+
+// Снимает пользователя username с модерации категории category
+func (cm *CategoryManager) RemoveModerator(category, username string, admin *models.Author) error {
+	user, err := cm.target(category, username, admin)
+	if err != nil {
+		return err
+	}
+	moderated, err := cm.storage.ModeratedCategories(user.ID)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(moderated, category) {
+		return errNotModerator
+	}
+
+	err = cm.storage.RemoveModerator(category, user.ID)
+	if err != nil {
+		return err
+	}
+	return recordAudit(cm.audit, admin, models.AuditCategoryModeratorRemove, models.AuditTargetCategory, category, "", map[string]string{"userID": user.ID})
+}
+
+// Возвращает правила категории category
+func (cm *CategoryManager) Rules(category string) (*models.CategoryRules, error) {
+	if !models.IsCategory(category) {
+		return nil, errNoCategory
+	}
+	return cm.storage.Rules(category)
+}
+
+// Заменяет правила категории category, прежние правила сохраняются в журнал модерации
+func (cm *CategoryManager) SetRules(category string, input *models.CategoryRulesInput, admin *models.Author) (*models.CategoryRules, error) {
+	if !models.IsCategory(category) {
+		return nil, errNoCategory
+	}
+	for _, postType := range input.PostTypes {
+		if !postTypes[postType] {
+			return nil, errBadPostType
+		}
+	}
+	err := checkAdmin(cm.users, admin)
+	if err != nil {
+		return nil, err
+	}
+
+	before, err := cm.storage.Rules(category)
+	if err != nil {
+		return nil, err
+	}
+	rules := &models.CategoryRules{
+		Category:       category,
+		PostTypes:      input.PostTypes,
+		MinAccountDays: input.MinAccountDays,
+	}
+	err = cm.storage.SetRules(rules)
+	if err != nil {
+		return nil, err
+	}
+	err = recordAudit(cm.audit, admin, models.AuditCategoryRules, models.AuditTargetCategory, category, "", before)
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// Проверяет категорию и права admin, возвращает пользователя username
+func (cm *CategoryManager) target(category, username string, admin *models.Author) (*models.User, error) {
+	if !models.IsCategory(category) {
+		return nil, errNoCategory
+	}
+	err := checkAdmin(cm.users, admin)
+	if err != nil {
+		return nil, err
+	}
+	return cm.users.FindOne(username)
+}
@@ -0,0 +1,216 @@
+package managers
+
+import (
+	"errors"
+	"forum/internal/models"
+	"slices"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Назначения модераторов по id пользователя и правила категорий, без правил категория открыта для всех
+type memoryCategories struct {
+	moderators map[string][]string
+	rules      map[string]*models.CategoryRules
+}
+
+func (mc *memoryCategories) ModeratedCategories(userID string) ([]string, error) {
+	return mc.moderators[userID], nil
+}
+
+func (mc *memoryCategories) Rules(category string) (*models.CategoryRules, error) {
+	if rules, ok := mc.rules[category]; ok {
+		return rules, nil
+	}
+	return &models.CategoryRules{Category: category}, nil
+}
+
+func (mc *memoryCategories) AddModerator(category, userID string) error {
+	if !slices.Contains(mc.moderators[userID], category) {
+		mc.moderators[userID] = append(mc.moderators[userID], category)
+	}
+	return nil
+}
+
+func (mc *memoryCategories) RemoveModerator(category, userID string) error {
+	mc.moderators[userID] = slices.DeleteFunc(mc.moderators[userID], func(item string) bool {
+		return item == category
+	})
+	return nil
+}
+
+func (mc *memoryCategories) Moderators(category string) ([]models.Author, error) {
+	moderators := make([]models.Author, 0)
+	for userID, categories := range mc.moderators {
+		if slices.Contains(categories, category) {
+			id, _ := primitive.ObjectIDFromHex(userID)
+			moderators = append(moderators, models.Author{ID: id})
+		}
+	}
+	return moderators, nil
+}
+
+func (mc *memoryCategories) SetRules(rules *models.CategoryRules) error {
+	mc.rules[rules.Category] = rules
+	return nil
+}
+
+func TestCategoryModeration(t *testing.T) {
+	author := &models.Author{ID: primitive.NewObjectID(), Username: "author"}
+	musicModerator := &models.Author{ID: primitive.NewObjectID(), Username: "music"}
+	codeModerator := &models.Author{ID: primitive.NewObjectID(), Username: "code"}
+	moderator := &models.Author{ID: primitive.NewObjectID(), Username: "moderator"}
+	users := &memoryBans{
+		memoryUsers: &memoryUsers{users: map[string]*models.User{
+			"author":    {ID: author.ID.Hex(), Username: "author", Role: models.RoleUser},
+			"music":     {ID: musicModerator.ID.Hex(), Username: "music", Role: models.RoleUser},
+			"code":      {ID: codeModerator.ID.Hex(), Username: "code", Role: models.RoleUser},
+			"moderator": {ID: moderator.ID.Hex(), Username: "moderator", Role: models.RoleModerator},
+		}},
+		banned: make(map[string]bool),
+	}
+	categories := &memoryCategories{moderators: map[string][]string{
+		musicModerator.ID.Hex(): {"music"},
+		codeModerator.ID.Hex():  {"programming"},
+	}}
+	post := &models.Post{ID: primitive.NewObjectID(), Author: *author, Category: "music", Created: time.Now()}
+	storage := &removablePost{memoryPost: memoryPost{post: post}}
+	postManager := NewPostManager(storage, users, nil, nil, nil, &memoryPublisher{}, nil, nil, &memoryAudit{}, nil, categories, testArchiveMonths)
+
+	// модератор другой категории не может закрыть или удалить пост
+	_, err := postManager.SetState(post.ID.Hex(), lockState, codeModerator)
+	if err != models.ErrForbidden {
+		t.Errorf("want ErrForbidden, have %v", err)
+	}
+	err = postManager.Delete(post.ID.Hex(), codeModerator, "offtopic")
+	if err != models.ErrForbidden || storage.deleted {
+		t.Errorf("want ErrForbidden, have %v", err)
+	}
+
+	for _, actor := range []*models.Author{musicModerator, moderator} {
+		_, err = postManager.SetState(post.ID.Hex(), lockState, actor)
+		if err != nil {
+			t.Errorf("%s: %v", actor.Username, err)
+		}
+	}
+	err = postManager.Delete(post.ID.Hex(), author, "")
+	if err != nil || !storage.deleted {
+		t.Errorf("author must delete own post, have %v", err)
+	}
+
+	// очередь жалоб ограничена категориями модератора, блокировать может только модератор сайта
+	reports := &memoryReports{}
+	reportManager := NewReportManager(reports, storage, &memoryRemover{}, users, revokedSessions{}, &memoryAudit{}, categories)
+	_, err = reportManager.Queue(musicModerator, models.Page{Number: 1, Size: 20})
+	if err != nil || !slices.Equal(reports.categories, []string{"music"}) {
+		t.Errorf("want music queue, have %v, %v", reports.categories, err)
+	}
+	_, err = reportManager.Queue(moderator, models.Page{Number: 1, Size: 20})
+	if err != nil || reports.categories != nil {
+		t.Errorf("want full queue, have %v, %v", reports.categories, err)
+	}
+	_, err = reportManager.Queue(author, models.Page{Number: 1, Size: 20})
+	if err != models.ErrForbidden {
+		t.Errorf("want ErrForbidden, have %v", err)
+	}
+
+	resolve := &models.ResolveInput{PostID: post.ID.Hex(), Action: models.ResolveRemove}
+	_, err = reportManager.Resolve(resolve, codeModerator)
+	if err != models.ErrForbidden {
+		t.Errorf("want ErrForbidden, have %v", err)
+	}
+	resolve.Action = models.ResolveBan
+	_, err = reportManager.Resolve(resolve, musicModerator)
+	if err != models.ErrForbidden {
+		t.Errorf("want ErrForbidden, have %v", err)
+	}
+}
+
+func TestCheckRules(t *testing.T) {
+	now := time.Now()
+	newAuthor := &models.Author{ID: primitive.NewObjectIDFromTimestamp(now.Add(-24 * time.Hour))}
+	oldAuthor := &models.Author{ID: primitive.NewObjectIDFromTimestamp(now.AddDate(0, 0, -30))}
+	rules := &models.CategoryRules{Category: "music", PostTypes: []string{"text", "link"}, MinAccountDays: 7}
+
+	cases := []struct {
+		rules    *models.CategoryRules
+		postType string
+		author   *models.Author
+		err      error
+	}{
+		{&models.CategoryRules{Category: "music"}, "image", newAuthor, nil},
+		{rules, "text", oldAuthor, nil},
+		{rules, "image", oldAuthor, errTypeDenied},
+		{rules, "link", newAuthor, models.ErrForbidden},
+	}
+
+	for i, item := range cases {
+		err := checkRules(item.rules, item.postType, item.author, now)
+		if !errors.Is(err, item.err) {
+			t.Errorf("case %d: want %v, have %v", i, item.err, err)
+		}
+	}
+}
+
+func TestCategoryManager(t *testing.T) {
+	admin := &models.Author{ID: primitive.NewObjectID(), Username: "admin"}
+	moderator := &models.Author{ID: primitive.NewObjectID(), Username: "moderator"}
+	users := &memoryUsers{users: map[string]*models.User{
+		"admin":     {ID: admin.ID.Hex(), Username: "admin", Role: models.RoleAdmin},
+		"moderator": {ID: moderator.ID.Hex(), Username: "moderator", Role: models.RoleModerator},
+		"user":      {ID: primitive.NewObjectID().Hex(), Username: "user", Role: models.RoleUser},
+	}}
+	storage := &memoryCategories{moderators: map[string][]string{}, rules: map[string]*models.CategoryRules{}}
+	audit := &memoryAudit{}
+	categoryManager := NewCategoryManager(storage, users, audit)
+
+	err := categoryManager.AddModerator("music", "user", moderator)
+	if err != models.ErrForbidden {
+		t.Errorf("want ErrForbidden, have %v", err)
+	}
+	err = categoryManager.AddModerator("unknown", "user", admin)
+	if err != errNoCategory {
+		t.Errorf("want errNoCategory, have %v", err)
+	}
+	err = categoryManager.AddModerator("music", "user", admin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	moderators, err := categoryManager.Moderators("music")
+	if err != nil || len(moderators) != 1 {
+		t.Errorf("want one moderator, have %v, %v", moderators, err)
+	}
+	err = categoryManager.RemoveModerator("programming", "user", admin)
+	if err != errNotModerator {
+		t.Errorf("want errNotModerator, have %v", err)
+	}
+	err = categoryManager.RemoveModerator("music", "user", admin)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := &models.CategoryRulesInput{PostTypes: []string{"video"}}
+	_, err = categoryManager.SetRules("music", input, admin)
+	if err != errBadPostType {
+		t.Errorf("want errBadPostType, have %v", err)
+	}
+	input = &models.CategoryRulesInput{PostTypes: []string{"text"}, MinAccountDays: 7}
+	rules, err := categoryManager.SetRules("music", input, admin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rules.Category != "music" || rules.AllowsType("link") {
+		t.Errorf("unexpected rules %+v", rules)
+	}
+
+	actions := make([]string, 0)
+	for _, entry := range audit.entries {
+		actions = append(actions, entry.Action)
+	}
+	expected := []string{models.AuditCategoryModeratorAdd, models.AuditCategoryModeratorRemove, models.AuditCategoryRules}
+	if !slices.Equal(actions, expected) {
+		t.Errorf("want %v, have %v", expected, actions)
+	}
+}
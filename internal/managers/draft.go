@@ -0,0 +1,128 @@
+package managers
+
+import (
+	"errors"
+	"forum/internal/models"
+	"log/slog"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	errNoPost        = errors.New("no post found")
+	errNotPublished  = errors.New("post is not published")
+	errNotDraft      = errors.New("post is not a draft")
+	errBadPublishAt  = errors.New("scheduled post requires publishAt in the future")
+	errNotScheduled  = errors.New("only scheduled posts can have publishAt")
+	unpublishedPosts = bson.A{models.PostDraft, models.PostScheduled}
+)
+
+// Проверяет состояние и время публикации нового поста, пустое состояние - опубликовать сразу
+func postStatus(post *models.PostInput, now time.Time) (string, error) {
+	status := post.Status
+	if status == "" {
+		status = models.PostPublished
+	}
+	if status != models.PostScheduled {
+		if post.PublishAt != nil {
+			return "", errNotScheduled
+		}
+		return status, nil
+	}
+	if post.PublishAt == nil || !post.PublishAt.After(now) {
+		return "", errBadPublishAt
+	}
+	return status, nil
+}
+
+// Добавляет в filter условие, что пост опубликован. Старые посты без состояния тоже подходят.
+func publishedOnly(filter bson.M) bson.M {
+	filter["status"] = bson.M{"$nin": unpublishedPosts}
+	return filter
+}
+
+/*
+Сообщает о публикации поста: загрузка превью ссылки и уведомления об упоминаниях.
+Событие post.created сохраняется в outbox вместе с постом. Для черновиков откладывается до публикации,
+чтобы они никому не были видны, а для постов в теневом бане или задержанных фильтрами не выполняется вовсе.
+*/
+func (pm *PostManager) announce(post *models.Post) {
+	if post.AuthorOnly() {
+		return
+	}
+	if post.Type == linkPostType && post.URL != "" {
+		pm.unfurls.Enqueue(post)
+	}
+	pm.notifyMentions(post.Mentions, &post.Author, post.ID, nil, nil)
+}
+
+// Возвращает черновики и отложенные посты author
+func (pm *PostManager) GetDrafts(author *models.Author) ([]*models.Post, error) {
+	filter := bson.M{
+		"author.id": author.ID,
+		"status":    bson.M{"$in": unpublishedPosts},
+	}
+	posts, err := pm.storage.Find(notDeleted(filter))
+	if err != nil {
+		return nil, err
+	}
+	pm.preparePosts(posts, author.ID)
+	return posts, nil
+}
+
+// Сразу публикует черновик или отложенный пост author
+func (pm *PostManager) Publish(postIDStr string, author *models.Author) (*models.Post, error) {
+	postID, err := primitive.ObjectIDFromHex(postIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := pm.storage.Publish(postID, author.ID, time.Now(), postCreatedEvents)
+	if err != nil {
+		return nil, err
+	}
+	if post == nil {
+		return nil, errNotDraft
+	}
+
+	pm.announce(post)
+	pm.preparePost(post, author.ID)
+	return post, nil
+}
+
+/*
+Публикует все отложенные посты, время которых наступило к now, и возвращает их количество.
+Каждый пост забирается из хранилища атомарно, поэтому планировщики на разных
+экземплярах сервера не публикуют один пост дважды.
+*/
+func (pm *PostManager) PublishDue(now time.Time) (int, error) {
+	published := 0
+	for {
+		post, err := pm.storage.PublishDue(now, postCreatedEvents)
+		if err != nil {
+			return published, err
+		}
+		if post == nil {
+			return published, nil
+		}
+		pm.announce(post)
+		published++
+	}
+}
+
+/*
+Раз в interval публикует отложенные посты. Ошибки пишутся в logger и не прерывают работу,
+пост опубликуется в следующий раз.
+*/
+func (pm *PostManager) RunScheduler(interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		_, err := pm.PublishDue(time.Now())
+		if err != nil {
+			logger.Error("publish scheduled posts", "task", "post.PublishDue", "err", err)
+		}
+	}
+}
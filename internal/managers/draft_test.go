@@ -0,0 +1,137 @@
+package managers
+
+import (
+	"context"
+	"forum/internal/models"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPostStatus(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Hour)
+
+	cases := []struct {
+		post   *models.PostInput
+		status string
+		err    error
+	}{
+		{&models.PostInput{}, models.PostPublished, nil},
+		{&models.PostInput{Status: models.PostDraft}, models.PostDraft, nil},
+		{&models.PostInput{Status: models.PostScheduled, PublishAt: &future}, models.PostScheduled, nil},
+		{&models.PostInput{Status: models.PostScheduled}, "", errBadPublishAt},
+		{&models.PostInput{Status: models.PostScheduled, PublishAt: &past}, "", errBadPublishAt},
+		{&models.PostInput{Status: models.PostDraft, PublishAt: &future}, "", errNotScheduled},
+	}
+
+	for i, item := range cases {
+		status, err := postStatus(item.post, now)
+		if status != item.status || err != item.err {
+			t.Errorf("case %d: want %q, %v, have %q, %v", i, item.status, item.err, status, err)
+		}
+	}
+}
+
+// Хранилище отложенных постов, PublishDue забирает пост под мьютексом, как атомарное обновление mongo
+type memoryScheduled struct {
+	postRepo
+	mu     sync.Mutex
+	posts  []*models.Post
+	outbox memoryOutbox
+}
+
+func (ms *memoryScheduled) PublishDue(now time.Time, events models.OutboxEvents) (*models.Post, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	for _, post := range ms.posts {
+		if post.Status == models.PostScheduled && !post.PublishAt.After(now) {
+			post.Status = models.PostPublished
+			post.PublishAt = nil
+			post.Created = now
+			return post, ms.outbox.save(post, events)
+		}
+	}
+	return nil, nil
+}
+
+func (ms *memoryScheduled) View(ctx context.Context, postID primitive.ObjectID) (*models.Post, error) {
+	for _, post := range ms.posts {
+		if post.ID == postID {
+			return post, nil
+		}
+	}
+	return nil, errNoPost
+}
+
+// Публикатор событий для нескольких горутин
+type syncPublisher struct {
+	mu     sync.Mutex
+	events []*models.Event
+}
+
+func (sp *syncPublisher) Publish(event *models.Event) error {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	sp.events = append(sp.events, event)
+	return nil
+}
+
+func TestPublishDue(t *testing.T) {
+	now := time.Now()
+	due := now.Add(-time.Minute)
+	later := now.Add(time.Hour)
+
+	storage := &memoryScheduled{}
+	for i := 0; i < 20; i++ {
+		storage.posts = append(storage.posts, &models.Post{
+			ID:        primitive.NewObjectID(),
+			Status:    models.PostScheduled,
+			PublishAt: &due,
+		})
+	}
+	storage.posts = append(storage.posts, &models.Post{
+		ID:        primitive.NewObjectID(),
+		Status:    models.PostScheduled,
+		PublishAt: &later,
+	})
+
+	events := &syncPublisher{}
+	postManager := NewPostManager(storage, &memoryUsers{}, nil, nil, nil, events, nil, nil, nil, nil, nil, testArchiveMonths)
+
+	// несколько планировщиков одновременно публикуют каждый пост ровно один раз
+	var wg sync.WaitGroup
+	counts := make([]int, 4)
+	for i := range counts {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			counts[i], _ = postManager.PublishDue(now)
+		}(i)
+	}
+	wg.Wait()
+
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+	if total != 20 {
+		t.Errorf("want 20 published posts, have %d", total)
+	}
+	if saved := storage.outbox.events(); len(saved) != 20 {
+		t.Errorf("want 20 events, have %d", len(saved))
+	}
+	if storage.posts[20].Status != models.PostScheduled {
+		t.Error("post scheduled for later must not be published")
+	}
+
+	// черновик виден только автору
+	storage.posts[20].Author = models.Author{ID: primitive.NewObjectID()}
+	_, err := postManager.FindOne(context.Background(), storage.posts[20].ID.Hex(), nil)
+	if err != errNoPost {
+		t.Errorf("want errNoPost, have %v", err)
+	}
+}
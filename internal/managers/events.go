@@ -0,0 +1,202 @@
+package managers
+
+import (
+	"forum/internal/models"
+	"sync"
+)
+
+var (
+	// размер очереди событий одного подписчика, если он не успевает читать, то отключается
+	listenerBuffer = 64
+	// сколько последних ключей событий помнится, чтобы не рассылать повторные публикации из outbox
+	recentEventKeys = 1024
+)
+
+type eventRepo interface {
+	Publish(*models.Event) error
+	Since(string) ([]*models.Event, error)
+	Subscribe() (<-chan *models.Event, func() error, error)
+}
+
+type listener struct {
+	filter func(*models.Event) bool
+	events chan *models.Event
+}
+
+/*
+Рассылает события локальным подписчикам (SSE и т.п.).
+События между экземплярами сервера передаются через хранилище событий.
+*/
+type EventManager struct {
+	storage eventRepo
+
+	mu        sync.Mutex
+	listeners map[*listener]struct{}
+	seen      *recentKeys
+}
+
+func NewEventManager(storage eventRepo) *EventManager {
+	return &EventManager{
+		storage:   storage,
+		listeners: make(map[*listener]struct{}),
+		seen:      newRecentKeys(recentEventKeys),
+	}
+}
+
+// Помнит size последних ключей, более старые вытесняются
+type recentKeys struct {
+	keys  map[string]struct{}
+	order []string
+	next  int
+}
+
+func newRecentKeys(size int) *recentKeys {
+	return &recentKeys{
+		keys:  make(map[string]struct{}, size),
+		order: make([]string, size),
+	}
+}
+
+// Запоминает key, возвращает false, если он уже был среди последних
+func (rk *recentKeys) Add(key string) bool {
+	if _, ok := rk.keys[key]; ok {
+		return false
+	}
+	delete(rk.keys, rk.order[rk.next])
+	rk.order[rk.next] = key
+	rk.keys[key] = struct{}{}
+	rk.next = (rk.next + 1) % len(rk.order)
+	return true
+}
+
+// Публикует событие для всех экземпляров сервера
+func (em *EventManager) Publish(event *models.Event) error {
+	return em.storage.Publish(event)
+}
+
+// Получает события из хранилища и рассылает их локальным подписчикам. Блокирует, пока подписка не закроется.
+func (em *EventManager) Run() error {
+	events, closeSubscription, err := em.storage.Subscribe()
+	if err != nil {
+		return err
+	}
+	defer closeSubscription()
+
+	for event := range events {
+		em.broadcast(event)
+	}
+	return nil
+}
+
+func (em *EventManager) broadcast(event *models.Event) {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	// relay публикует события outbox хотя бы один раз, повторная публикация подписчикам не нужна
+	if event.Key != "" && !em.seen.Add(event.Key) {
+		return
+	}
+	for l := range em.listeners {
+		if !l.filter(event) {
+			continue
+		}
+		select {
+		case l.events <- event:
+		default:
+			// подписчик не успевает, отключаем его, он может переподключиться с Last-Event-ID
+			delete(em.listeners, l)
+			close(l.events)
+		}
+	}
+}
+
+/*
+Подписывает на события, удовлетворяющие filter. Если передан lastEventID,
+то сначала отдаются пропущенные события после него, затем новые.
+Канал закрывается, если подписчик не успевает читать события. Функция отменяет подписку.
+*/
+func (em *EventManager) Subscribe(filter func(*models.Event) bool, lastEventID string) (<-chan *models.Event, func(), error) {
+	l := &listener{
+		filter: filter,
+		events: make(chan *models.Event, listenerBuffer),
+	}
+	em.mu.Lock()
+	em.listeners[l] = struct{}{}
+	em.mu.Unlock()
+
+	// подписываемся до чтения истории, чтобы не потерять события между ними
+	replay := make([]*models.Event, 0)
+	if lastEventID != "" {
+		missed, err := em.storage.Since(lastEventID)
+		if err != nil {
+			em.unsubscribe(l)
+			return nil, nil, err
+		}
+		keys := make(map[string]struct{})
+		for _, event := range missed {
+			if event.Key != "" {
+				if _, ok := keys[event.Key]; ok {
+					continue
+				}
+				keys[event.Key] = struct{}{}
+			}
+			if filter(event) {
+				replay = append(replay, event)
+			}
+		}
+	}
+
+	out := make(chan *models.Event)
+	done := make(chan struct{})
+	go func() {
+		defer close(out)
+		lastSent := lastEventID
+		for _, event := range replay {
+			select {
+			case out <- event:
+				lastSent = event.ID
+			case <-done:
+				return
+			}
+		}
+		for {
+			select {
+			case event, ok := <-l.events:
+				if !ok {
+					return
+				}
+				// событие уже было отдано из истории
+				if lastSent != "" && !models.EventBefore(lastSent, event.ID) {
+					continue
+				}
+				select {
+				case out <- event:
+					lastSent = event.ID
+				case <-done:
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			close(done)
+			em.unsubscribe(l)
+		})
+	}
+	return out, cancel, nil
+}
+
+func (em *EventManager) unsubscribe(l *listener) {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	if _, ok := em.listeners[l]; ok {
+		delete(em.listeners, l)
+		close(l.events)
+	}
+}
@@ -0,0 +1,146 @@
+package managers
+
+import (
+	"forum/internal/models"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Хранилище событий в памяти, рассылает события подписчику без сети
+type memoryEvents struct {
+	mu     sync.Mutex
+	seq    int
+	events []*models.Event
+	live   chan *models.Event
+}
+
+func (me *memoryEvents) Publish(event *models.Event) error {
+	me.mu.Lock()
+	me.seq++
+	event.ID = "1-" + strconv.Itoa(me.seq)
+	me.events = append(me.events, event)
+	me.mu.Unlock()
+	me.live <- event
+	return nil
+}
+
+func (me *memoryEvents) Since(lastID string) ([]*models.Event, error) {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+	missed := make([]*models.Event, 0)
+	for _, event := range me.events {
+		if models.EventBefore(lastID, event.ID) {
+			missed = append(missed, event)
+		}
+	}
+	return missed, nil
+}
+
+func (me *memoryEvents) Subscribe() (<-chan *models.Event, func() error, error) {
+	return me.live, func() error { return nil }, nil
+}
+
+func TestEventManagerResume(t *testing.T) {
+	storage := &memoryEvents{live: make(chan *models.Event)}
+	eventManager := NewEventManager(storage)
+	go eventManager.Run()
+
+	all := func(*models.Event) bool { return true }
+
+	first, cancelFirst, err := eventManager.Subscribe(all, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancelFirst()
+
+	for i := 0; i < 3; i++ {
+		go eventManager.Publish(&models.Event{Type: models.EventPostVotes})
+		select {
+		case <-first:
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for event")
+		}
+	}
+
+	// reconnect after the first event gets the two missed ones exactly once
+	resumed, cancelResumed, err := eventManager.Subscribe(all, "1-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancelResumed()
+
+	go eventManager.Publish(&models.Event{Type: models.EventPostDeleted})
+
+	expected := []string{"1-2", "1-3", "1-4"}
+	for _, id := range expected {
+		select {
+		case event := <-resumed:
+			if event.ID != id {
+				t.Fatalf("want event %s, but have %s", id, event.ID)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for event %s", id)
+		}
+	}
+}
+
+func TestEventManagerSlowConsumer(t *testing.T) {
+	eventManager := NewEventManager(&memoryEvents{})
+
+	events, cancel, err := eventManager.Subscribe(func(*models.Event) bool { return true }, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel()
+
+	// никто не читает, буфер переполняется и подписчик отключается
+	for i := 0; i <= listenerBuffer+1; i++ {
+		eventManager.broadcast(&models.Event{ID: "1-" + strconv.Itoa(i+1)})
+	}
+
+	received := 0
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case _, ok := <-events:
+			if !ok {
+				if received > listenerBuffer+1 {
+					t.Errorf("want at most %d events, have %d", listenerBuffer+1, received)
+				}
+				return
+			}
+			received++
+		case <-timeout:
+			t.Fatal("expected closed channel for slow consumer")
+		}
+	}
+}
+
+func TestEventManagerDuplicates(t *testing.T) {
+	eventManager := NewEventManager(&memoryEvents{})
+
+	events, cancel, err := eventManager.Subscribe(func(*models.Event) bool { return true }, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel()
+
+	// relay опубликовал событие outbox дважды, подписчик получает его один раз
+	eventManager.broadcast(&models.Event{ID: "1-1", Key: "a"})
+	eventManager.broadcast(&models.Event{ID: "1-2", Key: "a"})
+	eventManager.broadcast(&models.Event{ID: "1-3", Key: "b"})
+
+	expected := []string{"1-1", "1-3"}
+	for _, id := range expected {
+		select {
+		case event := <-events:
+			if event.ID != id {
+				t.Fatalf("want event %s, but have %s", id, event.ID)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for event %s", id)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package managers
+
+import (
+	"forum/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type contentFilter interface {
+	Check(*models.Content) (*models.FilterVerdict, error)
+}
+
+// Убирает чужие комментарии, задержанные фильтрами до проверки модератором
+func hideHeld(comments []models.Comment, viewerID primitive.ObjectID) []models.Comment {
+	visible := make([]models.Comment, 0, len(comments))
+	for _, comment := range comments {
+		if comment.Held != "" && !isOwnedBy(comment.Author.ID, viewerID) {
+			continue
+		}
+		visible = append(visible, comment)
+	}
+	return visible
+}
+
+func hasHeldComments(post *models.Post) bool {
+	for _, comment := range post.Comments {
+		if comment.Held != "" {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+Проверяет content фильтрами. Отклоненное содержимое возвращает *models.RejectedError,
+для задержанного до проверки модератором возвращается причина задержки, иначе пустая строка.
+*/
+func (pm *PostManager) screen(content *models.Content) (string, error) {
+	verdict, err := pm.filter.Check(content)
+	if err != nil {
+		return "", err
+	}
+	switch verdict.Action {
+	case models.FilterReject:
+		return "", &models.RejectedError{Reason: verdict.Reason}
+	case models.FilterHold:
+		return verdict.Reason, nil
+	}
+	return "", nil
+}
+
+/*
+Системная жалоба без автора, которая ставит задержанный фильтрами по reason post или его комментарий comment
+(nil - сам пост) в очередь модерации. Хранилище сохраняет ее вместе с содержимым. Отклонение жалобы
+публикует содержимое, удаление - удаляет. Пустой reason - содержимое не задержано, жалобы нет.
+*/
+func filterReport(post *models.Post, comment *models.Comment, reason string) *models.Report {
+	if reason == "" {
+		return nil
+	}
+	report := &models.Report{
+		ID:         primitive.NewObjectID(),
+		ReporterID: primitive.NilObjectID,
+		PostID:     post.ID,
+		Category:   post.Category,
+		AuthorID:   post.Author.ID,
+		Reason:     models.ReportFilter,
+		Details:    reason,
+		Status:     models.ReportOpen,
+		Created:    time.Now(),
+	}
+	if comment != nil {
+		report.CommentID = &comment.ID
+		report.AuthorID = comment.Author.ID
+	}
+	return report
+}
+
+/*
+Снимает задержку фильтров с поста postID или его комментария commentID (пустой - с поста)
+и сообщает о содержимом так же, как при создании.
+*/
+func (pm *PostManager) Release(postIDStr, commentIDStr string) error {
+	postID, err := primitive.ObjectIDFromHex(postIDStr)
+	if err != nil {
+		return err
+	}
+
+	if commentIDStr == "" {
+		post, err := pm.storage.Release(postID, postCreatedEvents)
+		if err != nil {
+			return err
+		}
+		if post.IsPublished() && post.DeletedAt == nil {
+			pm.announce(post)
+		}
+		return nil
+	}
+
+	commentID, err := primitive.ObjectIDFromHex(commentIDStr)
+	if err != nil {
+		return err
+	}
+	post, err := pm.storage.ReleaseComment(postID, commentID, commentCreatedEvents(commentID))
+	if err != nil {
+		return err
+	}
+	comment := findComment(post, commentID)
+	if comment == nil || comment.AuthorOnly() || comment.DeletedAt != nil || post.DeletedAt != nil {
+		return nil
+	}
+	var parent *models.Comment
+	if comment.ParentID != nil {
+		parent = findComment(post, *comment.ParentID)
+	}
+	pm.announceComment(post, comment, parent)
+	return nil
+}
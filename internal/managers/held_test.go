@@ -0,0 +1,162 @@
+package managers
+
+import (
+	"errors"
+	"forum/internal/filter"
+	"forum/internal/models"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+/*
+Хранилище одного поста, отдает копии, чтобы подготовка к выдаче не меняла сохраненный пост.
+Жалобы фильтров сохраняются в reports вместе с содержимым.
+*/
+type heldPost struct {
+	memoryPost
+	reports *memoryReports
+}
+
+func (hp *heldPost) saveReport(report *models.Report) {
+	if report != nil {
+		hp.reports.Create(report)
+	}
+}
+
+func (hp *heldPost) copyPost() *models.Post {
+	post := *hp.post
+	post.Comments = append([]models.Comment(nil), hp.post.Comments...)
+	return &post
+}
+
+func (hp *heldPost) Create(post *models.Post, report *models.Report, events models.OutboxEvents) error {
+	hp.post = post
+	hp.saveReport(report)
+	return hp.outbox.save(post, events)
+}
+
+func (hp *heldPost) FindOne(postID primitive.ObjectID) (*models.Post, error) {
+	return hp.copyPost(), nil
+}
+
+func (hp *heldPost) AddComment(postID primitive.ObjectID, comment *models.Comment, report *models.Report, events models.OutboxEvents) (*models.Post, error) {
+	hp.post.Comments = append(hp.post.Comments, *comment)
+	hp.saveReport(report)
+	return hp.copyPost(), hp.outbox.save(hp.post, events)
+}
+
+func (hp *heldPost) Release(postID primitive.ObjectID, events models.OutboxEvents) (*models.Post, error) {
+	hp.post.Held = ""
+	return hp.copyPost(), hp.outbox.save(hp.post, events)
+}
+
+func (hp *heldPost) ReleaseComment(postID, commentID primitive.ObjectID, events models.OutboxEvents) (*models.Post, error) {
+	for i := range hp.post.Comments {
+		if hp.post.Comments[i].ID == commentID {
+			hp.post.Comments[i].Held = ""
+		}
+	}
+	return hp.copyPost(), hp.outbox.save(hp.post, events)
+}
+
+type sentNotifications []*models.Notification
+
+func (sn *sentNotifications) Notify(notification *models.Notification) error {
+	*sn = append(*sn, notification)
+	return nil
+}
+
+func TestHeldContent(t *testing.T) {
+	moderator := &models.Author{ID: primitive.NewObjectID(), Username: "moderator"}
+	author := &models.Author{ID: primitive.NewObjectID(), Username: "author"}
+	commenter := &models.Author{ID: primitive.NewObjectID(), Username: "commenter"}
+	users := &memoryBans{
+		memoryUsers: &memoryUsers{users: map[string]*models.User{
+			"moderator": {ID: moderator.ID.Hex(), Username: "moderator", Role: models.RoleModerator},
+		}},
+		banned: map[string]bool{},
+	}
+
+	wordFilter, err := filter.NewWordFilter([]string{`\bcasino\b`}, models.FilterReject)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pipeline := filter.NewPipeline(wordFilter, filter.NewLinkFilter(0, models.FilterHold))
+
+	reports := &memoryReports{}
+	storage := &heldPost{reports: reports}
+	events := &memoryPublisher{}
+	notifications := &sentNotifications{}
+	postManager := NewPostManager(storage, users, noBlocks{}, nil, nil, events, notifications, nil, &memoryAudit{}, pipeline, &memoryCategories{}, testArchiveMonths)
+	reportManager := NewReportManager(reports, storage, postManager, users, revokedSessions{}, &memoryAudit{}, &memoryCategories{})
+
+	_, err = postManager.Create(&models.PostInput{Title: "best casino", Type: "text", Category: "music"}, author)
+	rejected := &models.RejectedError{}
+	if !errors.As(err, &rejected) || rejected.Reason != `contains banned word "casino"` {
+		t.Errorf("want RejectedError, have %v", err)
+	}
+
+	// пост со ссылкой задерживается: виден только автору и попадает в очередь модерации
+	post, err := postManager.Create(&models.PostInput{Title: "look", Text: "https://example.com", Type: "text", Category: "music"}, author)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if post.Held == "" || len(storage.outbox.events()) != 0 {
+		t.Errorf("post not held: %+v, events %v", post, storage.outbox.events())
+	}
+	if len(reports.reports) != 1 || reports.reports[0].Reason != models.ReportFilter || reports.reports[0].Details != post.Held {
+		t.Fatalf("unexpected reports %+v", reports.reports)
+	}
+	if !isShadowedFrom(post, commenter.ID) || isShadowedFrom(post, author.ID) {
+		t.Error("held post must be visible only to its author")
+	}
+
+	_, err = reportManager.Resolve(&models.ResolveInput{PostID: post.ID.Hex(), Action: models.ResolveDismiss}, moderator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved := storage.outbox.events(); storage.post.Held != "" || len(saved) != 1 || saved[0].Type != models.EventPostCreated {
+		t.Errorf("post not released: %+v, events %v", storage.post, saved)
+	}
+
+	// комментарий задерживается так же, уведомление автору поста приходит после проверки
+	updated, err := postManager.AddComment(post.ID.Hex(), &models.CommentInput{Body: "see http://spam.example"}, commenter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	comment := updated.Comments[0]
+	if comment.Held == "" || len(storage.outbox.events()) != 1 || len(*notifications) != 0 {
+		t.Errorf("comment not held: %+v, events %v", comment, storage.outbox.events())
+	}
+	if visible := hideHeld(storage.post.Comments, author.ID); len(visible) != 0 {
+		t.Errorf("held comment visible to post author: %v", visible)
+	}
+
+	_, err = reportManager.Resolve(&models.ResolveInput{PostID: post.ID.Hex(), CommentID: comment.ID.Hex(), Action: models.ResolveDismiss}, moderator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved := storage.outbox.events(); storage.post.Comments[0].Held != "" || len(saved) != 2 || saved[1].Type != models.EventCommentCreated {
+		t.Errorf("comment not released: %+v, events %v", storage.post.Comments[0], saved)
+	}
+	if len(*notifications) != 1 || (*notifications)[0].UserID != author.ID {
+		t.Errorf("unexpected notifications %v", *notifications)
+	}
+}
+
+func TestVisibleToHeld(t *testing.T) {
+	viewer := primitive.NewObjectID()
+	filter := visibleTo(bson.M{}, viewer)
+	visible := filter["$or"].(bson.A)[0].(bson.M)
+	if held, ok := visible["held"].(bson.M); !ok || held["$exists"] != false {
+		t.Errorf("held posts are listed: %v", filter)
+	}
+
+	post := &models.Post{Author: models.Author{ID: viewer}, Held: "too many links", Created: time.Now()}
+	if isHiddenFrom(post, viewer) || !isHiddenFrom(post, primitive.NewObjectID()) {
+		t.Error("held post must be visible only to its author")
+	}
+}
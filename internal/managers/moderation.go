@@ -0,0 +1,118 @@
+package managers
+
+import (
+	"errors"
+	"forum/internal/models"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	pinState    = "pin"
+	unpinState  = "unpin"
+	lockState   = "lock"
+	unlockState = "unlock"
+
+	stateAuditActions = map[string]string{
+		pinState:    models.AuditPostPin,
+		unpinState:  models.AuditPostUnpin,
+		lockState:   models.AuditPostLock,
+		unlockState: models.AuditPostUnlock,
+	}
+
+	errBadState = errors.New("bad post state")
+)
+
+// Возвращает true, если к now пост старше archiveAfterMonths
+func (pm *PostManager) isArchived(post *models.Post, now time.Time) bool {
+	return post.Created.Before(now.AddDate(0, -pm.archiveAfterMonths, 0))
+}
+
+// Проверяет, что пост принимает новые комментарии и голоса
+func (pm *PostManager) checkOpen(post *models.Post, now time.Time) error {
+	if post.Locked {
+		return models.ErrPostLocked
+	}
+	if pm.isArchived(post, now) {
+		return models.ErrPostArchived
+	}
+	return nil
+}
+
+// Поднимает закрепленные посты наверх, остальной порядок сохраняется
+func pinnedFirst(posts []*models.Post) {
+	sort.SliceStable(posts, func(i, j int) bool {
+		return posts[i].Pinned && !posts[j].Pinned
+	})
+}
+
+type moderatorRepo interface {
+	FindOne(string) (*models.User, error)
+}
+
+// Проверяет по базе, что author - модератор. Роль не хранится в сессии, поэтому снятие прав действует сразу.
+func checkModerator(users moderatorRepo, author *models.Author) error {
+	user, err := users.FindOne(author.Username)
+	if err != nil {
+		return err
+	}
+	if !user.IsModerator() {
+		return models.ErrForbidden
+	}
+	return nil
+}
+
+// Закрепляет (pin|unpin) или закрывает (lock|unlock) пост. Доступно только модераторам категории поста.
+func (pm *PostManager) SetState(postIDStr, state string, moderator *models.Author) (*models.Post, error) {
+	postID, err := primitive.ObjectIDFromHex(postIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	var set bson.M
+	switch state {
+	case pinState, unpinState:
+		set = bson.M{"pinned": state == pinState}
+	case lockState, unlockState:
+		set = bson.M{"locked": state == lockState}
+	default:
+		return nil, errBadState
+	}
+
+	post, err := pm.storage.FindOne(postID)
+	if err != nil {
+		return nil, err
+	}
+	err = checkCategoryModerator(pm.users, pm.categories, moderator, post.Category)
+	if err != nil {
+		return nil, err
+	}
+	if post.DeletedAt != nil {
+		return nil, errNoPost
+	}
+	if !post.IsPublished() {
+		return nil, errNotPublished
+	}
+	before := models.PostStateData{Pinned: post.Pinned, Locked: post.Locked}
+
+	post, err = pm.storage.UpdateOne(postID, bson.M{"$set": set})
+	if err != nil {
+		return nil, err
+	}
+	pm.preparePost(post, moderator.ID)
+
+	err = recordAudit(pm.audit, moderator, stateAuditActions[state], models.AuditTargetPost, postIDStr, "", before)
+	if err != nil {
+		return nil, err
+	}
+
+	pm.publish(models.EventPostState, post, models.PostStateData{
+		Pinned:   post.Pinned,
+		Locked:   post.Locked,
+		Archived: post.Archived,
+	})
+	return post, nil
+}
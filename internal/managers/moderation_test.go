@@ -0,0 +1,123 @@
+package managers
+
+import (
+	"forum/internal/models"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// через сколько месяцев архивируются посты в тестах
+const testArchiveMonths = 6
+
+func TestCheckOpen(t *testing.T) {
+	now := time.Now()
+	postManager := &PostManager{archiveAfterMonths: testArchiveMonths}
+
+	cases := []struct {
+		post *models.Post
+		err  error
+	}{
+		{&models.Post{Created: now}, nil},
+		{&models.Post{Created: now, Locked: true}, models.ErrPostLocked},
+		{&models.Post{Created: now.AddDate(0, -testArchiveMonths, -1)}, models.ErrPostArchived},
+	}
+
+	for i, item := range cases {
+		err := postManager.checkOpen(item.post, now)
+		if err != item.err {
+			t.Errorf("case %d: want %v, have %v", i, item.err, err)
+		}
+	}
+}
+
+func TestPinnedFirst(t *testing.T) {
+	posts := []*models.Post{
+		{Title: "first"},
+		{Title: "pinned", Pinned: true},
+		{Title: "second"},
+	}
+	pinnedFirst(posts)
+	titles := []string{posts[0].Title, posts[1].Title, posts[2].Title}
+	if titles[0] != "pinned" || titles[1] != "first" || titles[2] != "second" {
+		t.Errorf("unexpected order %v", titles)
+	}
+}
+
+// Хранилище с одним постом, UpdateOne применяет только $set
+type memoryPost struct {
+	postRepo
+	post   *models.Post
+	outbox memoryOutbox
+}
+
+func (mp *memoryPost) FindOne(postID primitive.ObjectID) (*models.Post, error) {
+	return mp.post, nil
+}
+
+func (mp *memoryPost) UpdateOne(postID primitive.ObjectID, update bson.M) (*models.Post, error) {
+	for key, value := range update["$set"].(bson.M) {
+		switch key {
+		case "pinned":
+			mp.post.Pinned = value.(bool)
+		case "locked":
+			mp.post.Locked = value.(bool)
+		}
+	}
+	return mp.post, nil
+}
+
+func TestSetState(t *testing.T) {
+	moderator := &models.Author{ID: primitive.NewObjectID(), Username: "moderator"}
+	user := &models.Author{ID: primitive.NewObjectID(), Username: "user"}
+	users := &memoryUsers{users: map[string]*models.User{
+		"moderator": {ID: moderator.ID.Hex(), Username: "moderator", Role: models.RoleModerator},
+		"user":      {ID: user.ID.Hex(), Username: "user", Role: models.RoleUser},
+	}}
+	post := &models.Post{ID: primitive.NewObjectID(), Created: time.Now()}
+	events := &memoryPublisher{}
+	audit := &memoryAudit{}
+	postManager := NewPostManager(&memoryPost{post: post}, users, nil, nil, nil, events, nil, nil, audit, nil, &memoryCategories{}, testArchiveMonths)
+
+	_, err := postManager.SetState(post.ID.Hex(), lockState, user)
+	if err != models.ErrForbidden {
+		t.Errorf("want ErrForbidden, have %v", err)
+	}
+
+	_, err = postManager.SetState(post.ID.Hex(), "delete", moderator)
+	if err != errBadState {
+		t.Errorf("want errBadState, have %v", err)
+	}
+
+	updated, err := postManager.SetState(post.ID.Hex(), lockState, moderator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !updated.Locked || len(*events) != 1 || (*events)[0].Type != models.EventPostState {
+		t.Errorf("post not locked: %+v, events %v", updated, *events)
+	}
+	if len(audit.entries) != 1 || audit.entries[0].Action != models.AuditPostLock || audit.entries[0].Before != `{"pinned":false,"locked":false,"archived":false}` {
+		t.Errorf("unexpected audit log %+v", audit.entries)
+	}
+
+	_, err = postManager.AddComment(post.ID.Hex(), &models.CommentInput{Body: "text"}, user)
+	if err != models.ErrPostLocked {
+		t.Errorf("want ErrPostLocked, have %v", err)
+	}
+	_, err = postManager.UpdateVotes(post.ID.Hex(), upvoteAction, user.ID)
+	if err != models.ErrPostLocked {
+		t.Errorf("want ErrPostLocked, have %v", err)
+	}
+
+	// изменения поста в теневом бане не выдают его остальным
+	post.Shadowed = true
+	_, err = postManager.SetState(post.ID.Hex(), pinState, moderator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*events) != 1 {
+		t.Errorf("shadowed post announced: %v", *events)
+	}
+}
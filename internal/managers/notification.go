@@ -0,0 +1,147 @@
+package managers
+
+import (
+	"encoding/json"
+	"forum/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Рейтинги поста, при достижении которых автор получает уведомление
+var voteMilestones = []int{10, 50, 100, 500, 1000}
+
+type notificationRepo interface {
+	Create(*models.Notification) error
+	CreateOnce(*models.Notification) (bool, error)
+	Find(primitive.ObjectID, bool, models.Page) ([]*models.Notification, error)
+	CountUnread(primitive.ObjectID) (int, error)
+	MarkRead(primitive.ObjectID, primitive.ObjectID) error
+	MarkAllRead(primitive.ObjectID) error
+	Preferences(primitive.ObjectID) (*models.NotificationPreferences, error)
+	SetPreferences(*models.NotificationPreferences) error
+}
+
+type notificationRelationRepo interface {
+	IsBlocked(primitive.ObjectID, primitive.ObjectID) (bool, error)
+}
+
+type NotificationManager struct {
+	storage   notificationRepo
+	relations notificationRelationRepo
+	events    eventPublisher
+}
+
+func NewNotificationManager(storage notificationRepo, relations notificationRelationRepo, events eventPublisher) *NotificationManager {
+	return &NotificationManager{
+		storage:   storage,
+		relations: relations,
+		events:    events,
+	}
+}
+
+/*
+Создает уведомление и рассылает его как событие notification.created.
+Уведомление не создается, если пользователь сам его вызвал, отключил этот тип
+или заблокировал автора действия. Milestone для одного рейтинга создается один раз.
+*/
+func (nm *NotificationManager) Notify(notification *models.Notification) error {
+	if notification.Actor != nil && notification.Actor.ID == notification.UserID {
+		return nil
+	}
+
+	preferences, err := nm.storage.Preferences(notification.UserID)
+	if err != nil {
+		return err
+	}
+	if !preferences.Enabled(notification.Type) {
+		return nil
+	}
+
+	if notification.Actor != nil {
+		blocked, err := nm.relations.IsBlocked(notification.UserID, notification.Actor.ID)
+		if err != nil {
+			return err
+		}
+		if blocked {
+			return nil
+		}
+	}
+
+	notification.ID = primitive.NewObjectID()
+	notification.Created = time.Now()
+
+	if notification.Type == models.NotificationMilestone {
+		created, err := nm.storage.CreateOnce(notification)
+		if err != nil || !created {
+			return err
+		}
+	} else {
+		err = nm.storage.Create(notification)
+		if err != nil {
+			return err
+		}
+	}
+
+	data, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+	// событие не обязательно, уведомление уже сохранено
+	nm.events.Publish(&models.Event{
+		Type:   models.EventNotification,
+		PostID: notification.PostID,
+		UserID: notification.UserID.Hex(),
+		Data:   data,
+	})
+	return nil
+}
+
+// Возвращает страницу уведомлений user и общее количество непрочитанных
+func (nm *NotificationManager) List(user *models.Author, unreadOnly bool, page models.Page) ([]*models.Notification, int, error) {
+	notifications, err := nm.storage.Find(user.ID, unreadOnly, page)
+	if err != nil {
+		return nil, 0, err
+	}
+	unread, err := nm.storage.CountUnread(user.ID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return notifications, unread, nil
+}
+
+// Отмечает прочитанным уведомление с notificationID
+func (nm *NotificationManager) MarkRead(user *models.Author, notificationIDStr string) error {
+	notificationID, err := primitive.ObjectIDFromHex(notificationIDStr)
+	if err != nil {
+		return err
+	}
+	return nm.storage.MarkRead(user.ID, notificationID)
+}
+
+// Отмечает прочитанными все уведомления user
+func (nm *NotificationManager) MarkAllRead(user *models.Author) error {
+	return nm.storage.MarkAllRead(user.ID)
+}
+
+// Возвращает настройки уведомлений user
+func (nm *NotificationManager) Preferences(user *models.Author) (*models.NotificationPreferences, error) {
+	return nm.storage.Preferences(user.ID)
+}
+
+// Сохраняет настройки уведомлений user
+func (nm *NotificationManager) SetPreferences(user *models.Author, preferences *models.NotificationPreferences) error {
+	preferences.UserID = user.ID
+	return nm.storage.SetPreferences(preferences)
+}
+
+// Возвращает рейтинги из voteMilestones, которые пост впервые достиг при изменении с before до after
+func reachedMilestones(before, after int) []int {
+	reached := make([]int, 0)
+	for _, milestone := range voteMilestones {
+		if before < milestone && after >= milestone {
+			reached = append(reached, milestone)
+		}
+	}
+	return reached
+}
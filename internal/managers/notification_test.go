@@ -0,0 +1,132 @@
+package managers
+
+import (
+	"forum/internal/models"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Хранилище уведомлений в памяти
+type memoryNotifications struct {
+	notificationRepo
+	created     []*models.Notification
+	milestones  map[int]bool
+	preferences *models.NotificationPreferences
+}
+
+func (mn *memoryNotifications) Create(notification *models.Notification) error {
+	mn.created = append(mn.created, notification)
+	return nil
+}
+
+func (mn *memoryNotifications) CreateOnce(notification *models.Notification) (bool, error) {
+	if mn.milestones[notification.Score] {
+		return false, nil
+	}
+	mn.milestones[notification.Score] = true
+	mn.created = append(mn.created, notification)
+	return true, nil
+}
+
+func (mn *memoryNotifications) Preferences(userID primitive.ObjectID) (*models.NotificationPreferences, error) {
+	return mn.preferences, nil
+}
+
+type memoryBlocks map[primitive.ObjectID]primitive.ObjectID
+
+func (mb memoryBlocks) IsBlocked(blocker, blocked primitive.ObjectID) (bool, error) {
+	return mb[blocker] == blocked, nil
+}
+
+type memoryPublisher []*models.Event
+
+func (mp *memoryPublisher) Publish(event *models.Event) error {
+	*mp = append(*mp, event)
+	return nil
+}
+
+func TestNotify(t *testing.T) {
+	user := primitive.NewObjectID()
+	actor := &models.Author{ID: primitive.NewObjectID(), Username: "actor"}
+	blockedActor := &models.Author{ID: primitive.NewObjectID(), Username: "blocked"}
+
+	storage := &memoryNotifications{
+		milestones:  make(map[int]bool),
+		preferences: models.DefaultNotificationPreferences(user),
+	}
+	storage.preferences.Mention = false
+	events := &memoryPublisher{}
+	notificationManager := NewNotificationManager(storage, memoryBlocks{user: blockedActor.ID}, events)
+
+	cases := []struct {
+		name         string
+		notification *models.Notification
+		delivered    bool
+	}{
+		{
+			name:         "comment",
+			notification: &models.Notification{UserID: user, Type: models.NotificationComment, Actor: actor},
+			delivered:    true,
+		},
+		{
+			name:         "self",
+			notification: &models.Notification{UserID: user, Type: models.NotificationComment, Actor: &models.Author{ID: user}},
+		},
+		{
+			name:         "disabled",
+			notification: &models.Notification{UserID: user, Type: models.NotificationMention, Actor: actor},
+		},
+		{
+			name:         "blocked",
+			notification: &models.Notification{UserID: user, Type: models.NotificationReply, Actor: blockedActor},
+		},
+		{
+			name:         "milestone",
+			notification: &models.Notification{UserID: user, Type: models.NotificationMilestone, Score: 10},
+			delivered:    true,
+		},
+		{
+			name:         "milestone again",
+			notification: &models.Notification{UserID: user, Type: models.NotificationMilestone, Score: 10},
+		},
+	}
+
+	for _, item := range cases {
+		before := len(storage.created)
+		err := notificationManager.Notify(item.notification)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", item.name, err)
+		}
+		delivered := len(storage.created) > before
+		if delivered != item.delivered {
+			t.Errorf("%s: want delivered %v, have %v", item.name, item.delivered, delivered)
+		}
+	}
+
+	if len(*events) != 2 {
+		t.Fatalf("want 2 events, have %d", len(*events))
+	}
+	if (*events)[0].Type != models.EventNotification || (*events)[0].UserID != user.Hex() {
+		t.Errorf("unexpected event %v", (*events)[0])
+	}
+}
+
+func TestReachedMilestones(t *testing.T) {
+	cases := []struct {
+		before, after int
+		expected      []int
+	}{
+		{before: 9, after: 10, expected: []int{10}},
+		{before: 10, after: 11, expected: []int{}},
+		{before: 11, after: 10, expected: []int{}},
+		{before: 49, after: 100, expected: []int{50, 100}},
+	}
+	for _, item := range cases {
+		reached := reachedMilestones(item.before, item.after)
+		if !reflect.DeepEqual(reached, item.expected) {
+			t.Errorf("%d -> %d: want %v, have %v", item.before, item.after, item.expected, reached)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package managers
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"forum/internal/models"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/oauth2"
+)
+
+var (
+	maxUsernameAttempts = 5
+	defaultUsername     = "user"
+
+	errNoIDToken     = errors.New("no id_token in token response")
+	errBadNonce      = errors.New("bad nonce")
+	errNoSubject     = errors.New("no subject in id_token")
+	errUsernameTaken = errors.New("cant find free username")
+
+	usernameCleaner = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+)
+
+// Внешний провайдер идентификации. Выполняет authorization code flow с PKCE.
+type identityProvider interface {
+	Name() string
+	AuthCodeURL(state, nonce, verifier string) string
+	Exchange(code, nonce, verifier string) (*models.ExternalIdentity, error)
+}
+
+type oauthStateRepo interface {
+	Set(string, *models.OAuthState) error
+	Pop(string) (*models.OAuthState, error)
+}
+
+type identityRepo interface {
+	FindOne(provider, subject string) (*models.User, error)
+	Create(*models.ExternalIdentity, *models.User) error
+}
+
+type oidcProvider struct {
+	name     string
+	config   oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+// Выполняет discovery провайдера по issuer и возвращает провайдера с именем name
+func NewOIDCProvider(name, issuer, clientID, clientSecret, redirectURL string) (*oidcProvider, error) {
+	provider, err := oidc.NewProvider(context.Background(), issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	return &oidcProvider{
+		name: name,
+		config: oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			RedirectURL:  redirectURL,
+			Endpoint:     provider.Endpoint(),
+			Scopes:       []string{oidc.ScopeOpenID, "profile", "email"},
+		},
+		verifier: provider.Verifier(&oidc.Config{ClientID: clientID}),
+	}, nil
+}
+
+func (op *oidcProvider) Name() string {
+	return op.name
+}
+
+// Возвращает адрес страницы входа провайдера
+func (op *oidcProvider) AuthCodeURL(state, nonce, verifier string) string {
+	return op.config.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier))
+}
+
+// Обменивает code на токены и проверяет id_token
+func (op *oidcProvider) Exchange(code, nonce, verifier string) (*models.ExternalIdentity, error) {
+	ctx := context.Background()
+	token, err := op.config.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, err
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, errNoIDToken
+	}
+
+	idToken, err := op.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, err
+	}
+	if idToken.Nonce != nonce {
+		return nil, errBadNonce
+	}
+	if idToken.Subject == "" {
+		return nil, errNoSubject
+	}
+
+	claims := struct {
+		PreferredUsername string `json:"preferred_username"`
+		Email             string `json:"email"`
+	}{}
+	err = idToken.Claims(&claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ExternalIdentity{
+		Provider: op.name,
+		Subject:  idToken.Subject,
+		Username: claims.PreferredUsername,
+		Email:    claims.Email,
+	}, nil
+}
+
+type OAuthManager struct {
+	auth       *AuthManager
+	provider   identityProvider
+	states     oauthStateRepo
+	identities identityRepo
+}
+
+func NewOAuthManager(auth *AuthManager, provider identityProvider, states oauthStateRepo, identities identityRepo) *OAuthManager {
+	return &OAuthManager{
+		auth:       auth,
+		provider:   provider,
+		states:     states,
+		identities: identities,
+	}
+}
+
+// Начинает вход через провайдера, возвращает адрес, на который нужно перенаправить пользователя
+func (om *OAuthManager) Begin() (string, error) {
+	state, err := randomString()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := randomString()
+	if err != nil {
+		return "", err
+	}
+	oauthState := &models.OAuthState{
+		Verifier: oauth2.GenerateVerifier(),
+		Nonce:    nonce,
+	}
+
+	err = om.states.Set(state, oauthState)
+	if err != nil {
+		return "", err
+	}
+
+	return om.provider.AuthCodeURL(state, oauthState.Nonce, oauthState.Verifier), nil
+}
+
+/*
+Завершает вход через провайдера по state и code из callback.
+Если внешняя учетная запись еще не привязана, то создается новый пользователь.
+Заблокированный пользователь не получает сессию, как и при входе по паролю.
+Возвращает токен сессии.
+*/
+func (om *OAuthManager) Complete(state, code string) (string, error) {
+	oauthState, err := om.states.Pop(state)
+	if err != nil {
+		return "", err
+	}
+
+	identity, err := om.provider.Exchange(code, oauthState.Nonce, oauthState.Verifier)
+	if err != nil {
+		return "", err
+	}
+
+	user, err := om.identities.FindOne(identity.Provider, identity.Subject)
+	if errors.Is(err, sql.ErrNoRows) {
+		user, err = om.provision(identity)
+	}
+	if err != nil {
+		return "", err
+	}
+	err = user.Suspension(time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	userID, err := primitive.ObjectIDFromHex(user.ID)
+	if err != nil {
+		return "", err
+	}
+
+	author := &models.Author{ID: userID, Username: user.Username}
+	return om.auth.generateToken(author)
+}
+
+// Создает локального пользователя для внешней учетной записи
+func (om *OAuthManager) provision(identity *models.ExternalIdentity) (*models.User, error) {
+	username, err := om.freeUsername(identity)
+	if err != nil {
+		return nil, err
+	}
+
+	// пароль случайный, войти по нему нельзя, только через провайдера
+	password, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{
+		ID:       primitive.NewObjectID().Hex(),
+		Username: username,
+		Password: string(hashedPassword),
+	}
+	err = om.identities.Create(identity, user)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Подбирает свободный username на основе preferred_username или email
+func (om *OAuthManager) freeUsername(identity *models.ExternalIdentity) (string, error) {
+	base := identity.Username
+	if base == "" {
+		base, _, _ = strings.Cut(identity.Email, "@")
+	}
+	base = usernameCleaner.ReplaceAllString(base, "")
+	if base == "" {
+		base = defaultUsername
+	}
+
+	username := base
+	for i := 0; i < maxUsernameAttempts; i++ {
+		_, err := om.auth.users.FindOne(username)
+		if errors.Is(err, sql.ErrNoRows) {
+			return username, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		suffix, err := randomString()
+		if err != nil {
+			return "", err
+		}
+		username = base + "_" + suffix[:6]
+	}
+	return "", errUsernameTaken
+}
+
+func randomString() (string, error) {
+	buf := make([]byte, 16)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,358 @@
+package managers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"forum/internal/models"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+const (
+	testClientID     = "forum"
+	testClientSecret = "secret"
+	testKeyID        = "test-key"
+)
+
+// Заглушка OIDC провайдера: discovery, jwks и token endpoint с проверкой PKCE
+type stubOIDCServer struct {
+	*httptest.Server
+	key *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]stubGrant
+}
+
+type stubGrant struct {
+	challenge string
+	nonce     string
+	subject   string
+	username  string
+}
+
+func newStubOIDCServer(t *testing.T) *stubOIDCServer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stub := &stubOIDCServer{
+		key:   key,
+		codes: make(map[string]stubGrant),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", stub.discovery)
+	mux.HandleFunc("/jwks", stub.jwks)
+	mux.HandleFunc("/token", stub.token)
+	stub.Server = httptest.NewServer(mux)
+	return stub
+}
+
+// Запоминает выданный провайдером code для параметров из адреса авторизации
+func (s *stubOIDCServer) grant(t *testing.T, authURL, code, subject, username string) {
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := parsed.Query()
+	if query.Get("code_challenge_method") != "S256" {
+		t.Fatalf("expected S256 challenge method, got %q", query.Get("code_challenge_method"))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.codes[code] = stubGrant{
+		challenge: query.Get("code_challenge"),
+		nonce:     query.Get("nonce"),
+		subject:   subject,
+		username:  username,
+	}
+}
+
+func (s *stubOIDCServer) discovery(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"issuer":                                s.URL,
+		"authorization_endpoint":                s.URL + "/authorize",
+		"token_endpoint":                        s.URL + "/token",
+		"jwks_uri":                              s.URL + "/jwks",
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (s *stubOIDCServer) jwks(w http.ResponseWriter, r *http.Request) {
+	encode := base64.RawURLEncoding.EncodeToString
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"keys": []map[string]string{
+			{
+				"kty": "RSA",
+				"kid": testKeyID,
+				"use": "sig",
+				"alg": "RS256",
+				"n":   encode(s.key.N.Bytes()),
+				"e":   encode(big.NewInt(int64(s.key.E)).Bytes()),
+			},
+		},
+	})
+}
+
+func (s *stubOIDCServer) token(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	grant, ok := s.codes[r.PostForm.Get("code")]
+	delete(s.codes, r.PostForm.Get("code"))
+	s.mu.Unlock()
+	if !ok {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != grant.challenge {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":                s.URL,
+		"sub":                grant.subject,
+		"aud":                testClientID,
+		"iat":                now.Unix(),
+		"exp":                now.Add(time.Hour).Unix(),
+		"nonce":              grant.nonce,
+		"preferred_username": grant.username,
+	})
+	idToken.Header["kid"] = testKeyID
+	signed, err := idToken.SignedString(s.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token": "access",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     signed,
+	})
+}
+
+type memoryStates map[string]*models.OAuthState
+
+func (ms memoryStates) Set(state string, oauthState *models.OAuthState) error {
+	ms[state] = oauthState
+	return nil
+}
+
+func (ms memoryStates) Pop(state string) (*models.OAuthState, error) {
+	oauthState, ok := ms[state]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	delete(ms, state)
+	return oauthState, nil
+}
+
+type memoryUsers struct {
+	users      map[string]*models.User
+	identities map[string]*models.User
+}
+
+func (mu *memoryUsers) FindOne(username string) (*models.User, error) {
+	user, ok := mu.users[username]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return user, nil
+}
+
+func (mu *memoryUsers) Create(user *models.User) error {
+	mu.users[user.Username] = user
+	return nil
+}
+
+type memoryIdentities struct {
+	*memoryUsers
+}
+
+func (mi memoryIdentities) FindOne(provider, subject string) (*models.User, error) {
+	user, ok := mi.identities[provider+":"+subject]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return user, nil
+}
+
+func (mi memoryIdentities) Create(identity *models.ExternalIdentity, user *models.User) error {
+	mi.users[user.Username] = user
+	mi.identities[identity.Provider+":"+identity.Subject] = user
+	return nil
+}
+
+type memorySessions map[string]*models.Author
+
+func (ms memorySessions) Set(token string, author *models.Author) error {
+	ms[token] = author
+	return nil
+}
+
+func (ms memorySessions) Get(ctx context.Context, token string) (*models.Author, error) {
+	author, ok := ms[token]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return author, nil
+}
+
+func TestOIDCProvider(t *testing.T) {
+	stub := newStubOIDCServer(t)
+	defer stub.Close()
+
+	provider, err := NewOIDCProvider("corp", stub.URL, testClientID, testClientSecret, "http://forum/callback")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verifier := "verifier-verifier-verifier-verifier-verifier"
+	authURL := provider.AuthCodeURL("state", "nonce", verifier)
+	stub.grant(t, authURL, "code", "subject-1", "alice")
+
+	identity, err := provider.Exchange("code", "nonce", verifier)
+	if err != nil {
+		t.Fatalf("expected nil, but was %v", err)
+	}
+	if identity.Provider != "corp" || identity.Subject != "subject-1" || identity.Username != "alice" {
+		t.Errorf("unexpected identity %+v", identity)
+	}
+
+	// wrong verifier
+	stub.grant(t, authURL, "code2", "subject-1", "alice")
+	_, err = provider.Exchange("code2", "nonce", "another-verifier-another-verifier-another")
+	if err == nil {
+		t.Error("expected error, but was nil")
+	}
+
+	// wrong nonce
+	stub.grant(t, authURL, "code3", "subject-1", "alice")
+	_, err = provider.Exchange("code3", "other nonce", verifier)
+	if err != errBadNonce {
+		t.Errorf("expected errBadNonce, but was %v", err)
+	}
+}
+
+func TestOAuthManager(t *testing.T) {
+	stub := newStubOIDCServer(t)
+	defer stub.Close()
+
+	provider, err := NewOIDCProvider("corp", stub.URL, testClientID, testClientSecret, "http://forum/callback")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	users := &memoryUsers{
+		users:      make(map[string]*models.User),
+		identities: make(map[string]*models.User),
+	}
+	// username из провайдера уже занят локальным пользователем
+	users.users["alice"] = &models.User{ID: "000000000000000000000001", Username: "alice"}
+
+	sessions := memorySessions{}
+	states := memoryStates{}
+	auth := NewSeesionManager(users, sessions)
+	oauth := NewOAuthManager(auth, provider, states, memoryIdentities{users})
+
+	complete := func(subject, username string) (string, error) {
+		authURL, err := oauth.Begin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := url.Parse(authURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stub.grant(t, authURL, "code-"+subject, subject, username)
+		return oauth.Complete(parsed.Query().Get("state"), "code-"+subject)
+	}
+	login := func(subject, username string) string {
+		token, err := complete(subject, username)
+		if err != nil {
+			t.Fatalf("expected nil, but was %v", err)
+		}
+		return token
+	}
+
+	// first login provisions a new user
+	token := login("subject-1", "alice")
+	author, ok := sessions[token]
+	if !ok {
+		t.Fatal("expected session for token")
+	}
+	if author.Username == "alice" {
+		t.Error("expected free username, but got taken one")
+	}
+	if len(users.identities) != 1 {
+		t.Fatalf("expected 1 linked identity, got %d", len(users.identities))
+	}
+
+	// second login reuses linked user
+	token = login("subject-1", "alice")
+	if sessions[token].ID != author.ID {
+		t.Errorf("want user %v, have %v", author.ID, sessions[token].ID)
+	}
+	if len(users.identities) != 1 {
+		t.Errorf("expected 1 linked identity, got %d", len(users.identities))
+	}
+
+	// state can be used only once
+	_, err = oauth.Complete("unknown", "code")
+	if err == nil {
+		t.Error("expected error, but was nil")
+	}
+
+	// заблокированный пользователь не получает сессию
+	users.identities["corp:subject-1"].Banned = true
+	_, err = complete("subject-1", "alice")
+	suspended := &models.SuspendedError{}
+	if !errors.As(err, &suspended) {
+		t.Errorf("want SuspendedError, have %v", err)
+	}
+
+	// ошибка базы не считается отсутствием пользователя, дубликат не создается
+	oauth = NewOAuthManager(auth, provider, states, failingIdentities{memoryIdentities{users}})
+	_, err = complete("subject-2", "bob")
+	if err != errConnection {
+		t.Errorf("want errConnection, have %v", err)
+	}
+	if len(users.identities) != 1 {
+		t.Errorf("expected 1 linked identity, got %d", len(users.identities))
+	}
+}
+
+var errConnection = errors.New("connection lost")
+
+type failingIdentities struct {
+	memoryIdentities
+}
+
+func (fi failingIdentities) FindOne(provider, subject string) (*models.User, error) {
+	return nil, errConnection
+}
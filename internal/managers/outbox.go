@@ -0,0 +1,155 @@
+package managers
+
+import (
+	"forum/internal/models"
+	"log/slog"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	// на это время запись outbox закрепляется за relay, который ее публикует
+	outboxLease = 30 * time.Second
+)
+
+type outboxRepo interface {
+	Claim(time.Time, time.Duration) (*models.OutboxEntry, error)
+	MarkPublished(primitive.ObjectID, time.Time) error
+}
+
+/*
+Публикует события, сохраненные в outbox вместе с изменениями постов. Событие отмечается
+опубликованным только после публикации, поэтому при падении сервера оно будет опубликовано
+повторно: доставка хотя бы один раз, получатели отбрасывают дубли по Event.Key.
+*/
+type OutboxManager struct {
+	storage outboxRepo
+	events  eventPublisher
+}
+
+func NewOutboxManager(storage outboxRepo, events eventPublisher) *OutboxManager {
+	return &OutboxManager{
+		storage: storage,
+		events:  events,
+	}
+}
+
+/*
+Публикует все записи outbox, доступные к now, и возвращает их количество.
+При ошибке публикации запись остается в outbox и будет забрана снова по истечении outboxLease.
+*/
+func (om *OutboxManager) Relay(now time.Time) (int, error) {
+	published := 0
+	for {
+		entry, err := om.storage.Claim(now, outboxLease)
+		if err != nil {
+			return published, err
+		}
+		if entry == nil {
+			return published, nil
+		}
+		err = om.events.Publish(entry.Event)
+		if err != nil {
+			return published, err
+		}
+		err = om.storage.MarkPublished(entry.ID, now)
+		if err != nil {
+			return published, err
+		}
+		published++
+	}
+}
+
+/*
+Раз в interval публикует события из outbox. Ошибки пишутся в logger и не прерывают работу,
+события опубликуются в следующий раз.
+*/
+func (om *OutboxManager) RunRelay(interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		_, err := om.Relay(time.Now())
+		if err != nil {
+			logger.Error("relay outbox events", "task", "outbox.Relay", "err", err)
+		}
+	}
+}
+
+// Возвращает true, если о посте сообщается всем: он опубликован, не удален, не в теневом бане и не задержан
+func isAnnounced(post *models.Post) bool {
+	return post.IsPublished() && !post.AuthorOnly() && post.DeletedAt == nil
+}
+
+func newEvents(eventType string, post *models.Post, data any) ([]*models.Event, error) {
+	event, err := models.NewEvent(eventType, post, data)
+	if err != nil {
+		return nil, err
+	}
+	return []*models.Event{event}, nil
+}
+
+// Событие post.created о посте, который видят все
+func postCreatedEvents(post *models.Post) ([]*models.Event, error) {
+	return visiblePostEvents(models.EventPostCreated, post)
+}
+
+// Событие post.restored о восстановленном из корзины посте, если его видят все
+func postRestoredEvents(post *models.Post) ([]*models.Event, error) {
+	return visiblePostEvents(models.EventPostRestored, post)
+}
+
+/*
+Событие eventType с постом, каким его видят все. Для черновиков, удаленных постов
+и постов в теневом бане или задержанных фильтрами событий нет.
+*/
+func visiblePostEvents(eventType string, post *models.Post) ([]*models.Event, error) {
+	if !isAnnounced(post) {
+		return nil, nil
+	}
+	view := *post
+	view.Comments = hideHeld(hideShadowed(hideDeleted(post.Comments), primitive.NilObjectID), primitive.NilObjectID)
+	return newEvents(eventType, post, &view)
+}
+
+// Событие post.deleted. О черновике никто, кроме автора, не знал.
+func postDeletedEvents(post *models.Post) ([]*models.Event, error) {
+	if !post.IsPublished() {
+		return nil, nil
+	}
+	return newEvents(models.EventPostDeleted, post, nil)
+}
+
+// Событие comment.created о комментарии commentID, если его видят все
+func commentCreatedEvents(commentID primitive.ObjectID) models.OutboxEvents {
+	return visibleCommentEvents(models.EventCommentCreated, commentID)
+}
+
+// Событие comment.restored о восстановленном из корзины комментарии commentID, если его видят все
+func commentRestoredEvents(commentID primitive.ObjectID) models.OutboxEvents {
+	return visibleCommentEvents(models.EventCommentRestored, commentID)
+}
+
+func visibleCommentEvents(eventType string, commentID primitive.ObjectID) models.OutboxEvents {
+	return func(post *models.Post) ([]*models.Event, error) {
+		comment := findComment(post, commentID)
+		if comment == nil || comment.AuthorOnly() || comment.DeletedAt != nil || post.DeletedAt != nil {
+			return nil, nil
+		}
+		return newEvents(eventType, post, comment)
+	}
+}
+
+/*
+Событие comment.deleted об удалении комментария commentID. О комментариях в теневом бане
+и задержанных фильтрами не сообщалось при создании, поэтому не сообщается и об удалении.
+*/
+func commentDeletedEvents(commentID primitive.ObjectID) models.OutboxEvents {
+	return func(post *models.Post) ([]*models.Event, error) {
+		comment := findComment(post, commentID)
+		if comment == nil || comment.AuthorOnly() {
+			return nil, nil
+		}
+		return newEvents(models.EventCommentDeleted, post, models.CommentDeletedData{CommentID: commentID})
+	}
+}
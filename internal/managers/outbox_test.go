@@ -0,0 +1,156 @@
+package managers
+
+import (
+	"errors"
+	"forum/internal/filter"
+	"forum/internal/models"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// outbox в памяти: фейковые хранилища постов сохраняют сюда события, как транзакция mongo
+type memoryOutbox struct {
+	mu      sync.Mutex
+	entries []*models.OutboxEntry
+}
+
+func (mo *memoryOutbox) save(post *models.Post, events models.OutboxEvents) error {
+	if events == nil {
+		return nil
+	}
+	list, err := events(post)
+	if err != nil {
+		return err
+	}
+	mo.mu.Lock()
+	defer mo.mu.Unlock()
+	now := time.Now()
+	for _, event := range list {
+		entry := &models.OutboxEntry{ID: primitive.NewObjectID(), Event: event, Created: now, AvailableAt: now}
+		event.Key = entry.ID.Hex()
+		mo.entries = append(mo.entries, entry)
+	}
+	return nil
+}
+
+// Возвращает все сохраненные события
+func (mo *memoryOutbox) events() []*models.Event {
+	mo.mu.Lock()
+	defer mo.mu.Unlock()
+	events := make([]*models.Event, 0, len(mo.entries))
+	for _, entry := range mo.entries {
+		events = append(events, entry.Event)
+	}
+	return events
+}
+
+func (mo *memoryOutbox) Claim(now time.Time, lease time.Duration) (*models.OutboxEntry, error) {
+	mo.mu.Lock()
+	defer mo.mu.Unlock()
+	for _, entry := range mo.entries {
+		if entry.PublishedAt == nil && !entry.AvailableAt.After(now) {
+			entry.AvailableAt = now.Add(lease)
+			return entry, nil
+		}
+	}
+	return nil, nil
+}
+
+func (mo *memoryOutbox) MarkPublished(id primitive.ObjectID, now time.Time) error {
+	mo.mu.Lock()
+	defer mo.mu.Unlock()
+	for _, entry := range mo.entries {
+		if entry.ID == id {
+			entry.PublishedAt = &now
+		}
+	}
+	return nil
+}
+
+// Публикатор, который отказывает первые failures раз
+type flakyPublisher struct {
+	failures int
+	memoryPublisher
+}
+
+func (fp *flakyPublisher) Publish(event *models.Event) error {
+	if fp.failures > 0 {
+		fp.failures--
+		return errors.New("event storage unavailable")
+	}
+	return fp.memoryPublisher.Publish(event)
+}
+
+func TestOutboxRelay(t *testing.T) {
+	author := &models.Author{ID: primitive.NewObjectID(), Username: "author"}
+	storage := &creatingPost{memoryPost{post: &models.Post{}}}
+	live := &memoryPublisher{}
+	postManager := NewPostManager(storage, nil, nil, nil, nil, live, nil, nil, nil, filter.NewPipeline(), &memoryCategories{}, testArchiveMonths)
+
+	// событие о посте сохраняется вместе с ним, а не публикуется сразу
+	post, err := postManager.Create(&models.PostInput{Title: "hello", Type: "text", Category: "music"}, author)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := storage.outbox.events()
+	if len(saved) != 1 || saved[0].Type != models.EventPostCreated || saved[0].PostID != post.ID || len(*live) != 0 {
+		t.Fatalf("unexpected outbox %v, published %v", saved, *live)
+	}
+
+	publisher := &flakyPublisher{failures: 1}
+	outboxManager := NewOutboxManager(&storage.outbox, publisher)
+	now := time.Now()
+
+	// неудачная публикация оставляет событие в outbox до истечения аренды
+	published, err := outboxManager.Relay(now)
+	if err == nil || published != 0 {
+		t.Fatalf("want publish error, have %d, %v", published, err)
+	}
+	published, err = outboxManager.Relay(now)
+	if err != nil || published != 0 {
+		t.Fatalf("want leased entry skipped, have %d, %v", published, err)
+	}
+
+	published, err = outboxManager.Relay(now.Add(outboxLease))
+	if err != nil || published != 1 {
+		t.Fatalf("want 1 published event, have %d, %v", published, err)
+	}
+	entry := storage.outbox.entries[0]
+	if len(publisher.memoryPublisher) != 1 || publisher.memoryPublisher[0].Key != entry.ID.Hex() {
+		t.Errorf("want event with key %s, have %v", entry.ID.Hex(), publisher.memoryPublisher)
+	}
+
+	published, err = outboxManager.Relay(now.Add(2 * outboxLease))
+	if err != nil || published != 0 {
+		t.Errorf("published event relayed again: %d, %v", published, err)
+	}
+}
+
+func TestCommentDeletedEvents(t *testing.T) {
+	visible := models.Comment{ID: primitive.NewObjectID()}
+	shadowed := models.Comment{ID: primitive.NewObjectID(), Shadowed: true}
+	held := models.Comment{ID: primitive.NewObjectID(), Held: "too many links"}
+	post := &models.Post{ID: primitive.NewObjectID(), Comments: []models.Comment{visible, shadowed, held}}
+
+	cases := []struct {
+		name      string
+		commentID primitive.ObjectID
+		announced bool
+	}{
+		{name: "visible", commentID: visible.ID, announced: true},
+		{name: "shadowed", commentID: shadowed.ID},
+		{name: "held", commentID: held.ID},
+	}
+	for _, item := range cases {
+		events, err := commentDeletedEvents(item.commentID)(post)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", item.name, err)
+		}
+		if (len(events) == 1) != item.announced {
+			t.Errorf("%s: want announced %v, have events %v", item.name, item.announced, events)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package managers
+
+import (
+	"errors"
+	"forum/internal/models"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	minPollOptions      = 2
+	maxPollOptions      = 10
+	maxPollOptionLength = 100
+
+	errNoPoll         = errors.New("poll post requires a poll")
+	errNotPoll        = errors.New("only poll posts can have a poll")
+	errBadPollOptions = errors.New("poll must have 2-10 unique non-empty options")
+	errBadPollClose   = errors.New("poll close time must be in the future")
+	errPollClosed     = errors.New("poll is closed")
+	errAlreadyVoted   = errors.New("you have already voted")
+	errBadBallot      = errors.New("bad ballot options")
+)
+
+// Создает опрос для поста типа poll из post
+func newPoll(post *models.PostInput, now time.Time) (*models.Poll, error) {
+	if post.Type != pollPostType {
+		if post.Poll != nil {
+			return nil, errNotPoll
+		}
+		return nil, nil
+	}
+	if post.Poll == nil {
+		return nil, errNoPoll
+	}
+
+	input := post.Poll
+	if len(input.Options) < minPollOptions || len(input.Options) > maxPollOptions {
+		return nil, errBadPollOptions
+	}
+	if input.Closes != nil && !input.Closes.After(now) {
+		return nil, errBadPollClose
+	}
+
+	poll := &models.Poll{
+		Options:  make([]models.PollOption, 0, len(input.Options)),
+		Multiple: input.Multiple,
+		Closes:   input.Closes,
+		Voters:   make([]primitive.ObjectID, 0),
+	}
+	seen := make(map[string]struct{}, len(input.Options))
+	for i, text := range input.Options {
+		text = strings.TrimSpace(text)
+		if text == "" || utf8.RuneCountInString(text) > maxPollOptionLength {
+			return nil, errBadPollOptions
+		}
+		if _, ok := seen[text]; ok {
+			return nil, errBadPollOptions
+		}
+		seen[text] = struct{}{}
+		poll.Options = append(poll.Options, models.PollOption{
+			ID:   i,
+			Text: text,
+		})
+	}
+	return poll, nil
+}
+
+// Проверяет бюллетень: варианты существуют, не повторяются, несколько - только при Multiple
+func checkBallot(poll *models.Poll, ballot *models.BallotInput) error {
+	if len(ballot.Options) == 0 || (!poll.Multiple && len(ballot.Options) > 1) {
+		return errBadBallot
+	}
+	seen := make(map[int]struct{}, len(ballot.Options))
+	for _, option := range ballot.Options {
+		if option < 0 || option >= len(poll.Options) {
+			return errBadBallot
+		}
+		if _, ok := seen[option]; ok {
+			return errBadBallot
+		}
+		seen[option] = struct{}{}
+	}
+	return nil
+}
+
+/*
+Учитывает бюллетень author в опросе поста postID. У каждого пользователя один бюллетень,
+повторное голосование и голосование в закрытом опросе возвращают ошибку.
+*/
+func (pm *PostManager) Vote(postIDStr string, ballot *models.BallotInput, author *models.Author) (*models.Post, error) {
+	postID, err := primitive.ObjectIDFromHex(postIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := pm.storage.FindOne(postID)
+	if err != nil {
+		return nil, err
+	}
+	if isHiddenFrom(post, author.ID) {
+		return nil, errNoPost
+	}
+	if !post.IsPublished() {
+		return nil, errNotPublished
+	}
+	if post.Poll == nil {
+		return nil, errNotPoll
+	}
+	err = checkBallot(post.Poll, ballot)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	err = pm.checkOpen(post, now)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := pm.storage.CastBallot(postID, author.ID, ballot.Options, now)
+	if err != nil {
+		// условие обновления не выполнилось, выясняем почему по актуальному состоянию
+		post, findErr := pm.storage.FindOne(postID)
+		if findErr != nil {
+			return nil, findErr
+		}
+		if post.Poll.IsClosed(now) {
+			return nil, errPollClosed
+		}
+		for _, voter := range post.Poll.Voters {
+			if voter == author.ID {
+				return nil, errAlreadyVoted
+			}
+		}
+		return nil, err
+	}
+
+	pm.preparePost(updated, author.ID)
+	return updated, nil
+}
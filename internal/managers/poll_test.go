@@ -0,0 +1,197 @@
+package managers
+
+import (
+	"errors"
+	"forum/internal/models"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNewPoll(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	cases := []struct {
+		post *models.PostInput
+		err  error
+	}{
+		{
+			post: &models.PostInput{Type: "poll", Poll: &models.PollInput{Options: []string{"yes", " no "}, Closes: &future}},
+		},
+		{
+			post: &models.PostInput{Type: "text"},
+		},
+		{
+			post: &models.PostInput{Type: "text", Poll: &models.PollInput{Options: []string{"yes", "no"}}},
+			err:  errNotPoll,
+		},
+		{
+			post: &models.PostInput{Type: "poll"},
+			err:  errNoPoll,
+		},
+		{
+			post: &models.PostInput{Type: "poll", Poll: &models.PollInput{Options: []string{"yes"}}},
+			err:  errBadPollOptions,
+		},
+		{
+			post: &models.PostInput{Type: "poll", Poll: &models.PollInput{Options: []string{"yes", "yes "}}},
+			err:  errBadPollOptions,
+		},
+		{
+			post: &models.PostInput{Type: "poll", Poll: &models.PollInput{Options: []string{"yes", "  "}}},
+			err:  errBadPollOptions,
+		},
+		{
+			post: &models.PostInput{Type: "poll", Poll: &models.PollInput{Options: []string{"yes", strings.Repeat("a", maxPollOptionLength+1)}}},
+			err:  errBadPollOptions,
+		},
+		{
+			post: &models.PostInput{Type: "poll", Poll: &models.PollInput{Options: []string{"yes", "no"}, Closes: &past}},
+			err:  errBadPollClose,
+		},
+	}
+
+	for i, item := range cases {
+		poll, err := newPoll(item.post, now)
+		if err != item.err {
+			t.Errorf("case %d: want %v, have %v", i, item.err, err)
+			continue
+		}
+		if err != nil || item.post.Type != pollPostType {
+			continue
+		}
+		if len(poll.Options) != 2 || poll.Options[1].Text != "no" || poll.Options[1].ID != 1 {
+			t.Errorf("case %d: unexpected options %v", i, poll.Options)
+		}
+	}
+}
+
+func TestCheckBallot(t *testing.T) {
+	single := &models.Poll{Options: make([]models.PollOption, 3)}
+	multiple := &models.Poll{Options: make([]models.PollOption, 3), Multiple: true}
+
+	cases := []struct {
+		poll    *models.Poll
+		options []int
+		valid   bool
+	}{
+		{single, []int{2}, true},
+		{single, []int{0, 1}, false},
+		{single, []int{}, false},
+		{single, []int{3}, false},
+		{single, []int{-1}, false},
+		{multiple, []int{0, 2}, true},
+		{multiple, []int{1, 1}, false},
+	}
+
+	for _, item := range cases {
+		err := checkBallot(item.poll, &models.BallotInput{Options: item.options})
+		if (err == nil) != item.valid {
+			t.Errorf("multiple=%v %v: want valid=%v, have %v", item.poll.Multiple, item.options, item.valid, err)
+		}
+	}
+}
+
+func TestPollView(t *testing.T) {
+	voter := primitive.NewObjectID()
+	newTestPoll := func() *models.Poll {
+		return &models.Poll{
+			Options:     []models.PollOption{{ID: 0, Text: "yes", Votes: 1}, {ID: 1, Text: "no"}},
+			Voters:      []primitive.ObjectID{voter},
+			TotalVoters: 1,
+		}
+	}
+	now := time.Now()
+
+	poll := newTestPoll()
+	poll.View(primitive.NewObjectID(), now)
+	if !poll.ResultsHidden || poll.TotalVoters != 0 || poll.Options[0].Votes != 0 {
+		t.Errorf("results must be hidden before voting: %+v", poll)
+	}
+
+	poll = newTestPoll()
+	poll.View(voter, now)
+	if poll.ResultsHidden || !poll.Voted || poll.Options[0].Votes != 1 {
+		t.Errorf("results must be visible after voting: %+v", poll)
+	}
+
+	poll = newTestPoll()
+	closed := now.Add(-time.Minute)
+	poll.Closes = &closed
+	poll.View(primitive.NewObjectID(), now)
+	if poll.ResultsHidden || !poll.Closed || poll.TotalVoters != 1 {
+		t.Errorf("results must be visible after close: %+v", poll)
+	}
+}
+
+// Хранилище с одним постом, CastBallot повторяет условие фильтра mongo
+type memoryPoll struct {
+	postRepo
+	post *models.Post
+}
+
+func (mp *memoryPoll) FindOne(postID primitive.ObjectID) (*models.Post, error) {
+	return mp.post, nil
+}
+
+func (mp *memoryPoll) CastBallot(postID, userID primitive.ObjectID, choices []int, now time.Time) (*models.Post, error) {
+	poll := mp.post.Poll
+	if poll.IsClosed(now) {
+		return nil, errors.New("no documents in result")
+	}
+	for _, voter := range poll.Voters {
+		if voter == userID {
+			return nil, errors.New("no documents in result")
+		}
+	}
+	poll.Voters = append(poll.Voters, userID)
+	poll.TotalVoters++
+	for _, choice := range choices {
+		poll.Options[choice].Votes++
+	}
+	return mp.post, nil
+}
+
+func TestVote(t *testing.T) {
+	author := &models.Author{ID: primitive.NewObjectID(), Username: "voter"}
+	post := &models.Post{
+		ID:      primitive.NewObjectID(),
+		Type:    pollPostType,
+		Created: time.Now(),
+		Poll: &models.Poll{
+			Options: []models.PollOption{{ID: 0, Text: "yes"}, {ID: 1, Text: "no"}},
+			Voters:  make([]primitive.ObjectID, 0),
+		},
+	}
+	postManager := NewPostManager(&memoryPoll{post: post}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, testArchiveMonths)
+
+	_, err := postManager.Vote(post.ID.Hex(), &models.BallotInput{Options: []int{0, 1}}, author)
+	if err != errBadBallot {
+		t.Errorf("want errBadBallot, have %v", err)
+	}
+
+	updated, err := postManager.Vote(post.ID.Hex(), &models.BallotInput{Options: []int{1}}, author)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !updated.Poll.Voted || updated.Poll.Options[1].Votes != 1 {
+		t.Errorf("ballot not counted: %+v", updated.Poll)
+	}
+
+	_, err = postManager.Vote(post.ID.Hex(), &models.BallotInput{Options: []int{0}}, author)
+	if err != errAlreadyVoted {
+		t.Errorf("want errAlreadyVoted, have %v", err)
+	}
+
+	closed := time.Now().Add(-time.Minute)
+	post.Poll.Closes = &closed
+	other := &models.Author{ID: primitive.NewObjectID(), Username: "late"}
+	_, err = postManager.Vote(post.ID.Hex(), &models.BallotInput{Options: []int{0}}, other)
+	if err != errPollClosed {
+		t.Errorf("want errPollClosed, have %v", err)
+	}
+}
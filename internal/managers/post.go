@@ -1,8 +1,12 @@
 package managers
 
 import (
+	"context"
 	"errors"
+	"forum/internal/markdown"
+	"forum/internal/metrics"
 	"forum/internal/models"
+	"forum/internal/tracing"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -10,68 +14,361 @@ import (
 )
 
 var (
-	errBadAction = errors.New("bad action")
+	errBadAction    = errors.New("bad action")
+	errBlocked      = errors.New("post author has blocked you")
+	errBlockedReply = errors.New("comment author has blocked you")
+	errNoParent     = errors.New("parent comment not found")
+	errNoImage      = errors.New("image post requires an uploaded image")
+	errNotImage     = errors.New("only image posts can have an image")
 
 	upvoteAction   = "upvote"
 	downvoteAction = "downvote"
 	unvoteAction   = "unvote"
+
+	linkPostType  = "link"
+	imagePostType = "image"
+	pollPostType  = "poll"
 )
 
 type postRepo interface {
 	Find(bson.M) ([]*models.Post, error)
+	FindPage(bson.M, models.Page) ([]*models.Post, error)
 	FindOne(primitive.ObjectID) (*models.Post, error)
 	UpdateOne(primitive.ObjectID, bson.M) (*models.Post, error)
-	AddComment(primitive.ObjectID, *models.Comment) (*models.Post, error)
-	DeleteComment(primitive.ObjectID, primitive.ObjectID) (*models.Post, error)
-	Delete(primitive.ObjectID) error
-	Create(*models.Post) error
+	View(context.Context, primitive.ObjectID) (*models.Post, error)
+	AddComment(primitive.ObjectID, *models.Comment, *models.Report, models.OutboxEvents) (*models.Post, error)
+	DeleteComment(primitive.ObjectID, primitive.ObjectID, primitive.ObjectID, time.Time, models.OutboxEvents) (*models.Post, error)
+	Delete(primitive.ObjectID, primitive.ObjectID, time.Time, models.OutboxEvents) error
+	Restore(primitive.ObjectID, models.OutboxEvents) (*models.Post, error)
+	RestoreComment(primitive.ObjectID, primitive.ObjectID, models.OutboxEvents) (*models.Post, error)
+	Purge(time.Time) (int64, int64, error)
+	Create(*models.Post, *models.Report, models.OutboxEvents) error
+	CastBallot(primitive.ObjectID, primitive.ObjectID, []int, time.Time) (*models.Post, error)
+	Publish(primitive.ObjectID, primitive.ObjectID, time.Time, models.OutboxEvents) (*models.Post, error)
+	PublishDue(time.Time, models.OutboxEvents) (*models.Post, error)
+	Release(primitive.ObjectID, models.OutboxEvents) (*models.Post, error)
+	ReleaseComment(primitive.ObjectID, primitive.ObjectID, models.OutboxEvents) (*models.Post, error)
+}
+
+type postRelationRepo interface {
+	Following(primitive.ObjectID) ([]primitive.ObjectID, error)
+	Subscriptions(primitive.ObjectID) ([]string, error)
+	Blocked(primitive.ObjectID) ([]primitive.ObjectID, error)
+	IsBlocked(primitive.ObjectID, primitive.ObjectID) (bool, error)
+	Muted(primitive.ObjectID) ([]string, error)
+}
+
+type savedRepo interface {
+	Save(*models.SavedPost) error
+	Delete(primitive.ObjectID, primitive.ObjectID) error
+	Find(primitive.ObjectID, string, models.Page) ([]*models.SavedPost, error)
+}
+
+type eventPublisher interface {
+	Publish(*models.Event) error
+}
+
+type notifier interface {
+	Notify(*models.Notification) error
+}
+
+type unfurlQueue interface {
+	Enqueue(*models.Post)
 }
 
 type PostManager struct {
-	storage postRepo
+	storage       postRepo
+	users         mentionUserRepo
+	relations     postRelationRepo
+	saved         savedRepo
+	attachments   attachmentRepo
+	events        eventPublisher
+	notifications notifier
+	unfurls       unfurlQueue
+	audit         auditRepo
+	filter        contentFilter
+	categories    categoryRepo
+
+	// через сколько месяцев пост архивируется и перестает принимать комментарии и голоса
+	archiveAfterMonths int
 }
 
-func NewPostManager(storage postRepo) *PostManager {
+func NewPostManager(storage postRepo, users mentionUserRepo, relations postRelationRepo, saved savedRepo, attachments attachmentRepo, events eventPublisher, notifications notifier, unfurls unfurlQueue, audit auditRepo, filter contentFilter, categories categoryRepo, archiveAfterMonths int) *PostManager {
 	return &PostManager{
-		storage: storage,
+		storage:       storage,
+		users:         users,
+		relations:     relations,
+		saved:         saved,
+		attachments:   attachments,
+		events:        events,
+		notifications: notifications,
+		unfurls:       unfurls,
+		audit:         audit,
+		filter:        filter,
+		categories:    categories,
+
+		archiveAfterMonths: archiveAfterMonths,
 	}
 }
 
-// Возвращает все имеющиеся посты по category
-func (pm *PostManager) GetAllByCategory(category string) ([]*models.Post, error) {
+/*
+Публикует событие об изменении post сразу, минуя outbox. Так публикуются только события с полным
+текущим состоянием (голоса, закрепление): потерянное событие заменит следующее. Ошибка не возвращается:
+изменение уже сохранено, а клиент увидит актуальное состояние при следующем запросе поста.
+О постах, которые видят не все, события не публикуются, как и при создании.
+*/
+func (pm *PostManager) publish(eventType string, post *models.Post, data any) {
+	if !isAnnounced(post) {
+		return
+	}
+	event, err := models.NewEvent(eventType, post, data)
+	if err != nil {
+		return
+	}
+	pm.events.Publish(event)
+}
+
+// Создает уведомление. Как и события, ошибка не возвращается, чтобы не отменять уже сохраненное действие.
+func (pm *PostManager) notify(notification *models.Notification) {
+	pm.notifications.Notify(notification)
+}
+
+// Возвращает все имеющиеся посты по category, закрепленные - первыми.
+// viewer - текущий пользователь, nil для анонимного.
+func (pm *PostManager) GetAllByCategory(category string, viewer *models.Author) ([]*models.Post, error) {
 	filter := bson.M{"category": category}
-	return pm.storage.Find(filter)
+	posts, err := pm.find(filter, viewer)
+	if err != nil {
+		return nil, err
+	}
+	pinnedFirst(posts)
+	return posts, nil
 }
 
 // Возвращает все имеющиеся посты по username
-func (pm *PostManager) GetAllByUser(username string) ([]*models.Post, error) {
+func (pm *PostManager) GetAllByUser(username string, viewer *models.Author) ([]*models.Post, error) {
 	filter := bson.M{"author.username": username}
-	return pm.storage.Find(filter)
+	return pm.find(filter, viewer)
 }
 
-// Возвращает все имеющиеся посты
-func (pm *PostManager) GetAll() ([]*models.Post, error) {
+// Возвращает все имеющиеся посты, кроме категорий, скрытых viewer
+func (pm *PostManager) GetAll(viewer *models.Author) ([]*models.Post, error) {
 	filter := bson.M{}
-	return pm.storage.Find(filter)
+	if viewer != nil {
+		muted, err := pm.relations.Muted(viewer.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(muted) > 0 {
+			filter["category"] = bson.M{"$nin": muted}
+		}
+	}
+	return pm.find(filter, viewer)
+}
+
+/*
+Выполняет поиск опубликованных и не удаленных постов по filter и скрывает посты и комментарии
+пользователей, заблокированных viewer, и пользователей в теневом бане
+*/
+func (pm *PostManager) find(filter bson.M, viewer *models.Author) ([]*models.Post, error) {
+	posts, err := pm.storage.Find(listable(filter, viewerID(viewer)))
+	if err != nil {
+		return nil, err
+	}
+	pm.preparePosts(posts, viewerID(viewer))
+	return pm.hideBlocked(posts, viewer)
 }
 
-// Возвращает пост по postID.
-// Используется UpdateOne, а не FindOne, т.к. при запросе поста необходимо увеличивать кол-во просмотров.
-func (pm *PostManager) FindOne(postIDStr string) (*models.Post, error) {
+// Убирает из posts посты и комментарии пользователей, заблокированных viewer
+func (pm *PostManager) hideBlocked(posts []*models.Post, viewer *models.Author) ([]*models.Post, error) {
+	blocked, err := pm.blockedSet(viewer)
+	if err != nil {
+		return nil, err
+	}
+	if len(blocked) == 0 {
+		return posts, nil
+	}
+
+	visible := make([]*models.Post, 0, len(posts))
+	for _, post := range posts {
+		if _, ok := blocked[post.Author.ID]; ok {
+			continue
+		}
+		post.Comments = hideComments(post.Comments, blocked)
+		visible = append(visible, post)
+	}
+	return visible, nil
+}
+
+// Возвращает множество id пользователей, заблокированных viewer
+func (pm *PostManager) blockedSet(viewer *models.Author) (map[primitive.ObjectID]struct{}, error) {
+	if viewer == nil {
+		return nil, nil
+	}
+	blockedList, err := pm.relations.Blocked(viewer.ID)
+	if err != nil {
+		return nil, err
+	}
+	blocked := make(map[primitive.ObjectID]struct{}, len(blockedList))
+	for _, id := range blockedList {
+		blocked[id] = struct{}{}
+	}
+	return blocked, nil
+}
+
+// Готовит посты к выдаче пользователю viewerID
+func (pm *PostManager) preparePosts(posts []*models.Post, viewerID primitive.ObjectID) {
+	for _, post := range posts {
+		pm.preparePost(post, viewerID)
+	}
+}
+
+// Готовит пост к выдаче пользователю viewerID, удаленные комментарии скрываются
+func (pm *PostManager) preparePost(post *models.Post, viewerID primitive.ObjectID) {
+	pm.preparePostView(post, viewerID, false)
+}
+
+/*
+Отрисовывает markdown, если пост сохранен без HTML или старой версией рендера,
+скрывает результаты опроса, если viewerID еще не голосовал, скрывает чужие комментарии
+пользователей в теневом бане и отмечает старые посты архивными.
+Удаленные комментарии остаются только для модератора, withDeleted == true,
+ему же видны чужие комментарии, задержанные фильтрами.
+*/
+func (pm *PostManager) preparePostView(post *models.Post, viewerID primitive.ObjectID, withDeleted bool) {
+	now := time.Now()
+	post.Comments = hideShadowed(post.Comments, viewerID)
+	if !withDeleted {
+		post.Comments = hideHeld(hideDeleted(post.Comments), viewerID)
+	}
+	markdown.RenderPost(post)
+	if post.Poll != nil {
+		post.Poll.View(viewerID, now)
+	}
+	post.Archived = pm.isArchived(post, now)
+}
+
+// Возвращает id пользователя, для анонимного - NilObjectID
+func viewerID(viewer *models.Author) primitive.ObjectID {
+	if viewer == nil {
+		return primitive.NilObjectID
+	}
+	return viewer.ID
+}
+
+func hideComments(comments []models.Comment, blocked map[primitive.ObjectID]struct{}) []models.Comment {
+	visible := make([]models.Comment, 0, len(comments))
+	for _, comment := range comments {
+		if _, ok := blocked[comment.Author.ID]; ok {
+			continue
+		}
+		visible = append(visible, comment)
+	}
+	return visible
+}
+
+/*
+Возвращает ленту author: посты пользователей, на которых он подписан, и посты из категорий,
+на которые он подписан. Лента выбирается одним запросом постранично, новые первыми,
+посты заблокированных пользователей исключаются в запросе, чтобы не укорачивать страницу.
+*/
+func (pm *PostManager) GetFeed(author *models.Author, page models.Page) ([]*models.Post, error) {
+	following, err := pm.relations.Following(author.ID)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := pm.relations.Subscriptions(author.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	filters := make([]bson.M, 0, 2)
+	if len(following) > 0 {
+		filters = append(filters, bson.M{"author.id": bson.M{"$in": following}})
+	}
+	if len(categories) > 0 {
+		filters = append(filters, bson.M{"category": bson.M{"$in": categories}})
+	}
+
+	if len(filters) == 0 {
+		return make([]*models.Post, 0), nil
+	}
+
+	blocked, err := pm.blockedSet(author)
+	if err != nil {
+		return nil, err
+	}
+	// $or подписок вложен в $and, т.к. свой $or добавляет visibleTo
+	filter := bson.M{"$and": bson.A{bson.M{"$or": filters}}}
+	if len(blocked) > 0 {
+		blockedIDs := make([]primitive.ObjectID, 0, len(blocked))
+		for id := range blocked {
+			blockedIDs = append(blockedIDs, id)
+		}
+		filter["author.id"] = bson.M{"$nin": blockedIDs}
+	}
+	feed, err := pm.storage.FindPage(listable(filter, author.ID), page)
+	if err != nil {
+		return nil, err
+	}
+	pm.preparePosts(feed, author.ID)
+	for _, post := range feed {
+		post.Comments = hideComments(post.Comments, blocked)
+	}
+	return feed, nil
+}
+
+// Возвращает пост по postID, комментарии пользователей, заблокированных viewer, скрываются.
+// Используется View, а не FindOne, т.к. при запросе поста необходимо увеличивать кол-во просмотров.
+// Черновик и пост в теневом бане видны только автору, просмотры черновика обнуляются при публикации.
+// Удаленный пост и удаленные комментарии видны только модераторам.
+func (pm *PostManager) FindOne(ctx context.Context, postIDStr string, viewer *models.Author) (_ *models.Post, err error) {
+	ctx, span := tracing.Start(ctx, "PostManager.FindOne")
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+
 	postID, err := primitive.ObjectIDFromHex(postIDStr)
 	if err != nil {
 		return nil, err
 	}
-	update := bson.M{
-		"$inc": bson.M{
-			"views": 1,
-		},
+	post, err := pm.storage.View(ctx, postID)
+	if err != nil {
+		return nil, err
 	}
-	return pm.storage.UpdateOne(postID, update)
+	moderator := false
+	if post.DeletedAt != nil || post.Held != "" || hasDeletedComments(post) || hasHeldComments(post) {
+		// хранилища пользователей и категорий не принимают контекст, проверка отмечается отдельным спаном
+		_, moderatorSpan := tracing.Start(ctx, "PostManager.canModerate")
+		moderator = pm.canModerate(viewer, post.Category)
+		moderatorSpan.End()
+	}
+	if post.DeletedAt != nil && !moderator {
+		return nil, errNoPost
+	}
+	// задержанный фильтрами пост виден модератору, который его проверяет
+	if post.Held != "" && !moderator && !isOwnedBy(post.Author.ID, viewerID(viewer)) {
+		return nil, errNoPost
+	}
+	if (!post.IsPublished() || post.Shadowed) && !isOwnedBy(post.Author.ID, viewerID(viewer)) {
+		return nil, errNoPost
+	}
+	pm.preparePostView(post, viewerID(viewer), moderator)
+
+	_, blockedSpan := tracing.Start(ctx, "PostManager.blockedSet")
+	blocked, err := pm.blockedSet(viewer)
+	blockedSpan.End()
+	if err != nil {
+		return nil, err
+	}
+	post.Comments = hideComments(post.Comments, blocked)
+	return post, nil
 }
 
 /*
 Выполняет действие пользователя action (upvote|downvote|unvote) и перерасчет score, percentUpvote.
+В закрытом или архивном посте голосовать нельзя.
 */
 func (pm *PostManager) UpdateVotes(postIDStr, action string, authorID primitive.ObjectID) (*models.Post, error) {
 	postID, err := primitive.ObjectIDFromHex(postIDStr)
@@ -83,6 +380,18 @@ func (pm *PostManager) UpdateVotes(postIDStr, action string, authorID primitive.
 	if err != nil {
 		return nil, err
 	}
+	if isHiddenFrom(post, authorID) {
+		return nil, errNoPost
+	}
+	if !post.IsPublished() {
+		return nil, errNotPublished
+	}
+	err = pm.checkOpen(post, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	scoreBefore := post.Score
 
 	votePos := -1
 	for i, vote := range post.Votes {
@@ -128,11 +437,33 @@ func (pm *PostManager) UpdateVotes(postIDStr, action string, authorID primitive.
 		},
 	}
 
-	return pm.storage.UpdateOne(postID, update)
+	post, err = pm.storage.UpdateOne(postID, update)
+	if err != nil {
+		return nil, err
+	}
+	metrics.Voted(action)
+	pm.preparePost(post, authorID)
+
+	pm.publish(models.EventPostVotes, post, models.VotesData{
+		Score:            post.Score,
+		UpvotePercentage: post.UpvotePercentage,
+		Votes:            len(post.Votes),
+	})
+	for _, milestone := range reachedMilestones(scoreBefore, post.Score) {
+		pm.notify(&models.Notification{
+			UserID: post.Author.ID,
+			Type:   models.NotificationMilestone,
+			PostID: post.ID,
+			Score:  milestone,
+		})
+	}
+	return post, nil
 }
 
 /*
 Создает новый комментарий на основе commentIn к посту с postID.
+Пользователь, заблокированный автором поста, комментировать не может, как и все - закрытый или архивный пост.
+Автор поста и автор комментария, на который дан ответ, получают уведомления.
 */
 func (pm *PostManager) AddComment(postIDStr string, commentIn *models.CommentInput, author *models.Author) (*models.Post, error) {
 	postID, err := primitive.ObjectIDFromHex(postIDStr)
@@ -140,20 +471,123 @@ func (pm *PostManager) AddComment(postIDStr string, commentIn *models.CommentInp
 		return nil, err
 	}
 
+	post, err := pm.storage.FindOne(postID)
+	if err != nil {
+		return nil, err
+	}
+	if isHiddenFrom(post, author.ID) {
+		return nil, errNoPost
+	}
+	if !post.IsPublished() {
+		return nil, errNotPublished
+	}
+	err = pm.checkOpen(post, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	blocked, err := pm.relations.IsBlocked(post.Author.ID, author.ID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, errBlocked
+	}
+
+	var parent *models.Comment
+	if commentIn.ParentID != "" {
+		parentID, err := primitive.ObjectIDFromHex(commentIn.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		for i := range post.Comments {
+			if post.Comments[i].ID == parentID {
+				parent = &post.Comments[i]
+				break
+			}
+		}
+		if parent == nil || parent.DeletedAt != nil || (parent.AuthorOnly() && parent.Author.ID != author.ID) {
+			return nil, errNoParent
+		}
+		// заблокированный автором комментария не может ответить ему и прислать уведомление
+		blocked, err := pm.relations.IsBlocked(parent.Author.ID, author.ID)
+		if err != nil {
+			return nil, err
+		}
+		if blocked {
+			return nil, errBlockedReply
+		}
+	}
+
 	newComment := &models.Comment{
-		ID:      primitive.NewObjectID(),
-		Author:  *author,
-		Created: time.Now(),
-		Body:    commentIn.Body,
+		ID:       primitive.NewObjectID(),
+		Author:   *author,
+		Created:  time.Now(),
+		Body:     commentIn.Body,
+		Shadowed: author.Shadowbanned,
+	}
+	if parent != nil {
+		newComment.ParentID = &parent.ID
+	}
+	newComment.Mentions, newComment.PostLinks, err = pm.resolveReferences(newComment.Body)
+	if err != nil {
+		return nil, err
+	}
+	markdown.RenderComment(newComment)
+	newComment.Held, err = pm.screen(models.CommentContent(newComment))
+	if err != nil {
+		return nil, err
 	}
 
-	return pm.storage.AddComment(postID, newComment)
+	post, err = pm.storage.AddComment(postID, newComment, filterReport(post, newComment, newComment.Held), commentCreatedEvents(newComment.ID))
+	if err != nil {
+		return nil, err
+	}
+	metrics.CommentCreated()
+	pm.preparePost(post, author.ID)
+	// комментарий в теневом бане или на проверке никому, кроме автора, не виден, поэтому о нем не сообщается
+	if newComment.AuthorOnly() {
+		return post, nil
+	}
+	pm.announceComment(post, newComment, parent)
+	return post, nil
+}
+
+/*
+Сообщает о новом комментарии comment уведомлениями об ответе, комментарии и упоминаниях.
+Событие comment.created сохраняется в outbox вместе с комментарием.
+*/
+func (pm *PostManager) announceComment(post *models.Post, comment, parent *models.Comment) {
+	author := &comment.Author
+	notified := make(map[primitive.ObjectID]struct{})
+	if parent != nil {
+		notified[parent.Author.ID] = struct{}{}
+		pm.notify(&models.Notification{
+			UserID:    parent.Author.ID,
+			Type:      models.NotificationReply,
+			Actor:     author,
+			PostID:    post.ID,
+			CommentID: &comment.ID,
+		})
+	}
+	// автор поста, которому ответили на комментарий, уже получил уведомление об ответе
+	if parent == nil || parent.Author.ID != post.Author.ID {
+		notified[post.Author.ID] = struct{}{}
+		pm.notify(&models.Notification{
+			UserID:    post.Author.ID,
+			Type:      models.NotificationComment,
+			Actor:     author,
+			PostID:    post.ID,
+			CommentID: &comment.ID,
+		})
+	}
+	pm.notifyMentions(comment.Mentions, author, post.ID, &comment.ID, notified)
 }
 
 /*
-Удаляет комментарий с id commentID у поста с postID
+Удаляет комментарий с id commentID у поста с postID. Удалить комментарий может его автор
+или модератор категории поста. Копия комментария, actor и reason сохраняются в журнал модерации.
 */
-func (pm *PostManager) DeleteComment(postIDStr, commentIDStr string) (*models.Post, error) {
+func (pm *PostManager) DeleteComment(postIDStr, commentIDStr string, actor *models.Author, reason string) (*models.Post, error) {
 	postID, err := primitive.ObjectIDFromHex(postIDStr)
 	if err != nil {
 		return nil, err
@@ -164,20 +598,78 @@ func (pm *PostManager) DeleteComment(postIDStr, commentIDStr string) (*models.Po
 		return nil, err
 	}
 
-	return pm.storage.DeleteComment(postID, commentID)
+	post, err := pm.storage.FindOne(postID)
+	if err != nil {
+		return nil, err
+	}
+	comment := findComment(post, commentID)
+	if comment == nil || comment.DeletedAt != nil {
+		return nil, errNoComment
+	}
+	if comment.Author.ID != actor.ID {
+		err = checkCategoryModerator(pm.users, pm.categories, actor, post.Category)
+		if err != nil {
+			return nil, err
+		}
+	}
+	before := *comment
+
+	post, err = pm.storage.DeleteComment(postID, commentID, actor.ID, time.Now(), commentDeletedEvents(commentID))
+	if err != nil {
+		return nil, err
+	}
+	pm.preparePost(post, primitive.NilObjectID)
+
+	err = recordAudit(pm.audit, actor, models.AuditCommentDelete, models.AuditTargetComment, commentIDStr, reason, before)
+	if err != nil {
+		return nil, err
+	}
+	return post, nil
 }
 
-// Удаляет пост
-func (pm *PostManager) Delete(postIDStr string) error {
+/*
+Удаляет пост. Удалить пост может его автор или модератор категории поста.
+actor и reason сохраняются в журнал модерации вместе с копией поста.
+*/
+func (pm *PostManager) Delete(postIDStr string, actor *models.Author, reason string) error {
 	postID, err := primitive.ObjectIDFromHex(postIDStr)
 	if err != nil {
 		return err
 	}
-	return pm.storage.Delete(postID)
+
+	post, err := pm.storage.FindOne(postID)
+	if err != nil {
+		return err
+	}
+	if post.Author.ID != actor.ID {
+		err = checkCategoryModerator(pm.users, pm.categories, actor, post.Category)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = pm.storage.Delete(postID, actor.ID, time.Now(), postDeletedEvents)
+	if err != nil {
+		return err
+	}
+	return recordAudit(pm.audit, actor, models.AuditPostDelete, models.AuditTargetPost, postIDStr, reason, post)
 }
 
-// Создает пост
+// Создает пост. Черновик и отложенный пост сохраняются, но объявляются только при публикации.
 func (pm *PostManager) Create(post *models.PostInput, author *models.Author) (*models.Post, error) {
+	now := time.Now()
+	status, err := postStatus(post, now)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := pm.categories.Rules(post.Category)
+	if err != nil {
+		return nil, err
+	}
+	err = checkRules(rules, post.Type, author, now)
+	if err != nil {
+		return nil, err
+	}
 	vote := models.Vote{
 		Vote:   1,
 		UserID: author.ID,
@@ -188,9 +680,12 @@ func (pm *PostManager) Create(post *models.PostInput, author *models.Author) (*m
 		URL:              post.URL,
 		Type:             post.Type,
 		Category:         post.Category,
+		Status:           status,
+		PublishAt:        post.PublishAt,
 		Author:           *author,
+		Shadowed:         author.Shadowbanned,
 		ID:               primitive.NewObjectID(),
-		Created:          time.Now(),
+		Created:          now,
 		Comments:         make([]models.Comment, 0),
 		Votes:            []models.Vote{vote},
 		UpvotePercentage: 100,
@@ -198,9 +693,138 @@ func (pm *PostManager) Create(post *models.PostInput, author *models.Author) (*m
 		Views:            0,
 	}
 
-	err := pm.storage.Create(newPost)
+	newPost.Image, err = pm.postImage(post, author)
 	if err != nil {
 		return nil, err
 	}
+	newPost.Poll, err = newPoll(post, newPost.Created)
+	if err != nil {
+		return nil, err
+	}
+	newPost.Mentions, newPost.PostLinks, err = pm.resolveReferences(newPost.Text)
+	if err != nil {
+		return nil, err
+	}
+	markdown.RenderPost(newPost)
+	newPost.Held, err = pm.screen(models.PostContent(newPost))
+	if err != nil {
+		return nil, err
+	}
+
+	err = pm.storage.Create(newPost, filterReport(newPost, nil, newPost.Held), postCreatedEvents)
+	if err != nil {
+		return nil, err
+	}
+	metrics.PostCreated()
+
+	if newPost.IsPublished() {
+		pm.announce(newPost)
+	}
+	pm.preparePost(newPost, author.ID)
 	return newPost, nil
 }
+
+// Добавляет пост в закладки author в папку folder
+func (pm *PostManager) Save(postIDStr, folder string, author *models.Author) error {
+	postID, err := primitive.ObjectIDFromHex(postIDStr)
+	if err != nil {
+		return err
+	}
+
+	post, err := pm.storage.FindOne(postID)
+	if err != nil {
+		return err
+	}
+	if isHiddenFrom(post, author.ID) {
+		return errNoPost
+	}
+	if !post.IsPublished() {
+		return errNotPublished
+	}
+
+	item := &models.SavedPost{
+		ID:      primitive.NewObjectID(),
+		UserID:  author.ID,
+		PostID:  postID,
+		Folder:  folder,
+		Created: time.Now(),
+	}
+	return pm.saved.Save(item)
+}
+
+// Удаляет пост из закладок author
+func (pm *PostManager) Unsave(postIDStr string, author *models.Author) error {
+	postID, err := primitive.ObjectIDFromHex(postIDStr)
+	if err != nil {
+		return err
+	}
+	return pm.saved.Delete(author.ID, postID)
+}
+
+/*
+Возвращает страницу закладок author из папки folder (пустая - все папки).
+Посты подгружаются актуальные, для удаленных постов возвращается заглушка с Deleted.
+*/
+func (pm *PostManager) GetSaved(author *models.Author, folder string, page models.Page) ([]*models.SavedItem, error) {
+	savedPosts, err := pm.saved.Find(author.ID, folder, page)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*models.SavedItem, 0, len(savedPosts))
+	if len(savedPosts) == 0 {
+		return items, nil
+	}
+
+	postIDs := make([]primitive.ObjectID, 0, len(savedPosts))
+	for _, savedPost := range savedPosts {
+		postIDs = append(postIDs, savedPost.PostID)
+	}
+	posts, err := pm.storage.Find(notDeleted(visibleTo(bson.M{"_id": bson.M{"$in": postIDs}}, author.ID)))
+	if err != nil {
+		return nil, err
+	}
+	pm.preparePosts(posts, author.ID)
+	postsByID := make(map[primitive.ObjectID]*models.Post, len(posts))
+	for _, post := range posts {
+		postsByID[post.ID] = post
+	}
+
+	for _, savedPost := range savedPosts {
+		post, ok := postsByID[savedPost.PostID]
+		items = append(items, &models.SavedItem{
+			PostID:  savedPost.PostID,
+			Folder:  savedPost.Folder,
+			Saved:   savedPost.Created,
+			Deleted: !ok,
+			Post:    post,
+		})
+	}
+	return items, nil
+}
+
+// Возвращает изображение для поста типа image, загруженное author
+func (pm *PostManager) postImage(post *models.PostInput, author *models.Author) (*models.Image, error) {
+	if post.Type != imagePostType {
+		if post.Image != "" {
+			return nil, errNotImage
+		}
+		return nil, nil
+	}
+	if post.Image == "" {
+		return nil, errNoImage
+	}
+	attachmentID, err := primitive.ObjectIDFromHex(post.Image)
+	if err != nil {
+		return nil, err
+	}
+	attachment, err := pm.attachments.FindOne(attachmentID)
+	if err != nil {
+		return nil, err
+	}
+	// чужое изображение использовать нельзя
+	if attachment.OwnerID != author.ID {
+		return nil, errNoImage
+	}
+	return attachment.Image(), nil
+}
@@ -0,0 +1,156 @@
+package managers
+
+import (
+	"context"
+	"forum/internal/filter"
+	"forum/internal/models"
+	"forum/internal/tracing"
+	"forum/internal/tracing/tracingtest"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestFindOneSpans(t *testing.T) {
+	recorder := tracingtest.NewRecorder()
+	moderator := &models.Author{ID: primitive.NewObjectID(), Username: "moderator"}
+	users := &memoryUsers{users: map[string]*models.User{
+		"moderator": {ID: moderator.ID.Hex(), Username: "moderator", Role: models.RoleModerator},
+	}}
+	deletedAt := time.Now()
+	post := &models.Post{ID: primitive.NewObjectID(), Created: time.Now(), DeletedAt: &deletedAt, DeletedBy: &moderator.ID}
+	storage := &trashPost{memoryPost: memoryPost{post: post}}
+	postManager := NewPostManager(storage, users, noBlocks{}, nil, nil, &memoryPublisher{}, nil, nil, &memoryAudit{}, nil, &memoryCategories{}, testArchiveMonths)
+
+	ctx, request := tracing.Start(context.Background(), "request")
+	_, err := postManager.FindOne(ctx, post.ID.Hex(), moderator)
+	request.End()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// спаны менеджера вложены в спан запроса, проверки доступа вложены в FindOne
+	parents := make(map[string]string)
+	spans := recorder.Ended()
+	names := make(map[string]string, len(spans))
+	for _, span := range spans {
+		names[span.SpanContext().SpanID().String()] = span.Name()
+	}
+	for _, span := range spans {
+		parents[span.Name()] = names[span.Parent().SpanID().String()]
+	}
+	expected := map[string]string{
+		"PostManager.FindOne":     "request",
+		"PostManager.canModerate": "PostManager.FindOne",
+		"PostManager.blockedSet":  "PostManager.FindOne",
+	}
+	for name, parent := range expected {
+		if parents[name] != parent {
+			t.Errorf("want %s to be a child of %s, have parent %q", name, parent, parents[name])
+		}
+	}
+}
+
+// Запоминает запрос страницы постов
+type feedPosts struct {
+	postRepo
+	filter bson.M
+	page   models.Page
+	posts  []*models.Post
+}
+
+func (fp *feedPosts) FindPage(filter bson.M, page models.Page) ([]*models.Post, error) {
+	fp.filter, fp.page = filter, page
+	return fp.posts, nil
+}
+
+type feedRelations struct {
+	postRelationRepo
+	following []primitive.ObjectID
+	blocked   []primitive.ObjectID
+}
+
+func (fr feedRelations) Following(userID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	return fr.following, nil
+}
+
+func (fr feedRelations) Subscriptions(userID primitive.ObjectID) ([]string, error) {
+	return []string{"music"}, nil
+}
+
+func (fr feedRelations) Blocked(userID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	return fr.blocked, nil
+}
+
+func TestGetFeed(t *testing.T) {
+	reader := &models.Author{ID: primitive.NewObjectID(), Username: "reader"}
+	followed := primitive.NewObjectID()
+	blocked := &models.Author{ID: primitive.NewObjectID(), Username: "blocked"}
+	post := &models.Post{
+		ID:       primitive.NewObjectID(),
+		Author:   models.Author{ID: followed},
+		Comments: []models.Comment{{ID: primitive.NewObjectID(), Author: *blocked, Body: "hidden"}},
+	}
+	storage := &feedPosts{posts: []*models.Post{post}}
+	relations := feedRelations{following: []primitive.ObjectID{followed}, blocked: []primitive.ObjectID{blocked.ID}}
+	postManager := NewPostManager(storage, &memoryUsers{}, relations, nil, nil, &memoryPublisher{}, nil, nil, &memoryAudit{}, nil, &memoryCategories{}, testArchiveMonths)
+
+	page := models.Page{Number: 3, Size: 10}
+	feed, err := postManager.GetFeed(reader, page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// подписки и блокировки уходят в один запрос страницы
+	if storage.page != page {
+		t.Errorf("want page %v, have %v", page, storage.page)
+	}
+	subscriptions := storage.filter["$and"].(bson.A)[0].(bson.M)["$or"].([]bson.M)
+	if len(subscriptions) != 2 {
+		t.Errorf("want author and category subscriptions, have %v", subscriptions)
+	}
+	if excluded := storage.filter["author.id"].(bson.M)["$nin"].([]primitive.ObjectID); len(excluded) != 1 || excluded[0] != blocked.ID {
+		t.Errorf("blocked authors are not excluded: %v", storage.filter)
+	}
+	if len(feed) != 1 || len(feed[0].Comments) != 0 {
+		t.Errorf("comments of blocked users are not hidden: %+v", feed)
+	}
+}
+
+type blockRelations struct {
+	postRelationRepo
+	blocks memoryBlocks
+}
+
+func (br blockRelations) IsBlocked(blocker, blocked primitive.ObjectID) (bool, error) {
+	return br.blocks.IsBlocked(blocker, blocked)
+}
+
+func TestReplyBlocked(t *testing.T) {
+	author := models.Author{ID: primitive.NewObjectID(), Username: "author"}
+	commenter := models.Author{ID: primitive.NewObjectID(), Username: "commenter"}
+	replier := &models.Author{ID: primitive.NewObjectID(), Username: "replier"}
+	comment := models.Comment{ID: primitive.NewObjectID(), Author: commenter, Body: "first"}
+	post := &models.Post{ID: primitive.NewObjectID(), Author: author, Created: time.Now(), Comments: []models.Comment{comment}}
+
+	storage := &heldPost{memoryPost: memoryPost{post: post}}
+	notifications := &sentNotifications{}
+	relations := blockRelations{blocks: memoryBlocks{commenter.ID: replier.ID}}
+	postManager := NewPostManager(storage, &memoryUsers{}, relations, nil, nil, &memoryPublisher{}, notifications, nil, &memoryAudit{}, filter.NewPipeline(), &memoryCategories{}, testArchiveMonths)
+
+	// автор комментария заблокировал отвечающего: ответ и уведомление о нем запрещены
+	_, err := postManager.AddComment(post.ID.Hex(), &models.CommentInput{Body: "reply", ParentID: comment.ID.Hex()}, replier)
+	if err != errBlockedReply {
+		t.Errorf("want errBlockedReply, have %v", err)
+	}
+	if len(storage.post.Comments) != 1 || len(*notifications) != 0 {
+		t.Errorf("reply saved: comments %v, notifications %v", storage.post.Comments, *notifications)
+	}
+
+	// комментировать сам пост можно, его автор не блокировал
+	_, err = postManager.AddComment(post.ID.Hex(), &models.CommentInput{Body: "top level"}, replier)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
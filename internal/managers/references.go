@@ -0,0 +1,129 @@
+package managers
+
+import (
+	"database/sql"
+	"errors"
+	"forum/internal/models"
+	"regexp"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	// @username, перед @ не должно быть буквы или цифры, чтобы не находить почтовые адреса
+	mentionRegexp = regexp.MustCompile(`(?:^|[^\w@])@([\w-]{1,32})`)
+	// #<id поста>
+	postLinkRegexp = regexp.MustCompile(`(?:^|[^\w#&])#([0-9a-fA-F]{24})\b`)
+
+	// сколько упоминаний и ссылок из одного текста разрешается
+	maxMentions  = 10
+	maxPostLinks = 10
+)
+
+type mentionUserRepo interface {
+	FindOne(string) (*models.User, error)
+}
+
+// Возвращает уникальные имена из упоминаний @username и id из ссылок #<id> в порядке появления в text
+func parseReferences(text string) ([]string, []primitive.ObjectID) {
+	usernames := make([]string, 0)
+	seenUsernames := make(map[string]struct{})
+	for _, match := range mentionRegexp.FindAllStringSubmatch(text, -1) {
+		if len(usernames) == maxMentions {
+			break
+		}
+		if _, ok := seenUsernames[match[1]]; ok {
+			continue
+		}
+		seenUsernames[match[1]] = struct{}{}
+		usernames = append(usernames, match[1])
+	}
+
+	postIDs := make([]primitive.ObjectID, 0)
+	seenPosts := make(map[primitive.ObjectID]struct{})
+	for _, match := range postLinkRegexp.FindAllStringSubmatch(text, -1) {
+		if len(postIDs) == maxPostLinks {
+			break
+		}
+		postID, err := primitive.ObjectIDFromHex(match[1])
+		if err != nil {
+			continue
+		}
+		if _, ok := seenPosts[postID]; ok {
+			continue
+		}
+		seenPosts[postID] = struct{}{}
+		postIDs = append(postIDs, postID)
+	}
+	return usernames, postIDs
+}
+
+/*
+Находит упоминания и ссылки на посты в text и сопоставляет их с существующими пользователями и постами.
+Несуществующие остаются обычным текстом. Заголовки ссылок сохраняются в тексте и видны всем читателям,
+поэтому ссылка разрешается, только если пост виден анонимному: черновики, удаленные, задержанные
+и скрытые теневым баном посты, в том числе самого автора, остаются обычным текстом.
+*/
+func (pm *PostManager) resolveReferences(text string) ([]models.Author, []models.PostLink, error) {
+	usernames, postIDs := parseReferences(text)
+
+	var mentions []models.Author
+	for _, username := range usernames {
+		user, err := pm.users.FindOne(username)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		userID, err := primitive.ObjectIDFromHex(user.ID)
+		if err != nil {
+			continue
+		}
+		mentions = append(mentions, models.Author{
+			ID:       userID,
+			Username: user.Username,
+		})
+	}
+
+	if len(postIDs) == 0 {
+		return mentions, nil, nil
+	}
+	posts, err := pm.storage.Find(listable(bson.M{"_id": bson.M{"$in": postIDs}}, primitive.NilObjectID))
+	if err != nil {
+		return nil, nil, err
+	}
+	titles := make(map[primitive.ObjectID]string, len(posts))
+	for _, post := range posts {
+		titles[post.ID] = post.Title
+	}
+	var links []models.PostLink
+	for _, postID := range postIDs {
+		title, ok := titles[postID]
+		if !ok {
+			continue
+		}
+		links = append(links, models.PostLink{
+			ID:    postID,
+			Title: title,
+		})
+	}
+	return mentions, links, nil
+}
+
+// Уведомляет упомянутых пользователей, кроме тех, кто уже получил уведомление (notified)
+func (pm *PostManager) notifyMentions(mentions []models.Author, author *models.Author, postID primitive.ObjectID, commentID *primitive.ObjectID, notified map[primitive.ObjectID]struct{}) {
+	for _, mention := range mentions {
+		if _, ok := notified[mention.ID]; ok {
+			continue
+		}
+		pm.notify(&models.Notification{
+			UserID:    mention.ID,
+			Type:      models.NotificationMention,
+			Actor:     author,
+			PostID:    postID,
+			CommentID: commentID,
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package managers
+
+import (
+	"errors"
+	"forum/internal/models"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseReferences(t *testing.T) {
+	postID := primitive.NewObjectID()
+	otherID := primitive.NewObjectID()
+
+	cases := []struct {
+		text      string
+		usernames []string
+		postIDs   []primitive.ObjectID
+	}{
+		{
+			text:      "@alice and @bob_2, see #" + postID.Hex() + ".",
+			usernames: []string{"alice", "bob_2"},
+			postIDs:   []primitive.ObjectID{postID},
+		},
+		{
+			text:      "mail me at user@example.com, @alice @alice",
+			usernames: []string{"alice"},
+			postIDs:   []primitive.ObjectID{},
+		},
+		{
+			text:      "#" + postID.Hex() + "ff is not a link, (#" + otherID.Hex() + ") is, &#" + postID.Hex() + " is not",
+			usernames: []string{},
+			postIDs:   []primitive.ObjectID{otherID},
+		},
+		{
+			text:      "no references here",
+			usernames: []string{},
+			postIDs:   []primitive.ObjectID{},
+		},
+	}
+
+	for _, item := range cases {
+		usernames, postIDs := parseReferences(item.text)
+		if !reflect.DeepEqual(usernames, item.usernames) {
+			t.Errorf("%q: want usernames %v, have %v", item.text, item.usernames, usernames)
+		}
+		if !reflect.DeepEqual(postIDs, item.postIDs) {
+			t.Errorf("%q: want posts %v, have %v", item.text, item.postIDs, postIDs)
+		}
+	}
+}
+
+type memoryPosts struct {
+	postRepo
+	posts  []*models.Post
+	filter bson.M
+}
+
+func (mp *memoryPosts) Find(filter bson.M) ([]*models.Post, error) {
+	mp.filter = filter
+	return mp.posts, nil
+}
+
+func TestResolveReferences(t *testing.T) {
+	alice := &models.User{ID: primitive.NewObjectID().Hex(), Username: "alice"}
+	post := &models.Post{ID: primitive.NewObjectID(), Title: "linked"}
+
+	storage := &memoryPosts{posts: []*models.Post{post}}
+	postManager := NewPostManager(
+		storage,
+		&memoryUsers{users: map[string]*models.User{"alice": alice}},
+		nil, nil, nil, nil, nil, nil, nil, nil, nil,
+		testArchiveMonths,
+	)
+
+	mentions, links, err := postManager.resolveReferences(
+		"@alice @ghost #" + post.ID.Hex() + " #" + primitive.NewObjectID().Hex(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	aliceID, _ := primitive.ObjectIDFromHex(alice.ID)
+	expectedMentions := []models.Author{{ID: aliceID, Username: "alice"}}
+	if !reflect.DeepEqual(mentions, expectedMentions) {
+		t.Errorf("\nwant: %v\nhave: %v", expectedMentions, mentions)
+	}
+	expectedLinks := []models.PostLink{{ID: post.ID, Title: "linked"}}
+	if !reflect.DeepEqual(links, expectedLinks) {
+		t.Errorf("\nwant: %v\nhave: %v", expectedLinks, links)
+	}
+
+	// заголовки видны всем читателям, поэтому разрешаются только посты, видные анонимному
+	expectedFilter := listable(bson.M{"_id": storage.filter["_id"]}, primitive.NilObjectID)
+	if !reflect.DeepEqual(storage.filter, expectedFilter) {
+		t.Errorf("references resolved without visibility filter: %v", storage.filter)
+	}
+}
+
+// Пользователи, поиск которых завершается ошибкой базы
+type failingUsers struct{}
+
+func (failingUsers) FindOne(username string) (*models.User, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestResolveReferencesError(t *testing.T) {
+	postManager := NewPostManager(&memoryPosts{}, failingUsers{}, nil, nil, nil, nil, nil, nil, nil, nil, nil, testArchiveMonths)
+	_, _, err := postManager.resolveReferences("@alice")
+	if err == nil {
+		t.Error("expected error, but was nil")
+	}
+}
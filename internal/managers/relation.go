@@ -0,0 +1,115 @@
+package managers
+
+import (
+	"errors"
+	"forum/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	errSelfFollow  = errors.New("cant follow yourself")
+	errSelfBlock   = errors.New("cant block yourself")
+	errBadCategory = errors.New("unknown category")
+)
+
+type relationRepo interface {
+	Follow(primitive.ObjectID, primitive.ObjectID) error
+	Unfollow(primitive.ObjectID, primitive.ObjectID) error
+	Following(primitive.ObjectID) ([]primitive.ObjectID, error)
+	Subscribe(primitive.ObjectID, string) error
+	Unsubscribe(primitive.ObjectID, string) error
+	Subscriptions(primitive.ObjectID) ([]string, error)
+	Block(primitive.ObjectID, primitive.ObjectID) error
+	Unblock(primitive.ObjectID, primitive.ObjectID) error
+	Mute(primitive.ObjectID, string) error
+	Unmute(primitive.ObjectID, string) error
+}
+
+type RelationManager struct {
+	users     userRepo
+	relations relationRepo
+}
+
+func NewRelationManager(users userRepo, relations relationRepo) *RelationManager {
+	return &RelationManager{
+		users:     users,
+		relations: relations,
+	}
+}
+
+// Подписывает author на пользователя username
+func (rm *RelationManager) Follow(author *models.Author, username string) error {
+	followeeID, err := rm.findUserID(username)
+	if err != nil {
+		return err
+	}
+	if followeeID == author.ID {
+		return errSelfFollow
+	}
+	return rm.relations.Follow(author.ID, followeeID)
+}
+
+// Отписывает author от пользователя username
+func (rm *RelationManager) Unfollow(author *models.Author, username string) error {
+	followeeID, err := rm.findUserID(username)
+	if err != nil {
+		return err
+	}
+	return rm.relations.Unfollow(author.ID, followeeID)
+}
+
+// Подписывает author на категорию category
+func (rm *RelationManager) Subscribe(author *models.Author, category string) error {
+	if !models.IsCategory(category) {
+		return errBadCategory
+	}
+	return rm.relations.Subscribe(author.ID, category)
+}
+
+// Отписывает author от категории category
+func (rm *RelationManager) Unsubscribe(author *models.Author, category string) error {
+	return rm.relations.Unsubscribe(author.ID, category)
+}
+
+// Блокирует пользователя username для author
+func (rm *RelationManager) Block(author *models.Author, username string) error {
+	blockedID, err := rm.findUserID(username)
+	if err != nil {
+		return err
+	}
+	if blockedID == author.ID {
+		return errSelfBlock
+	}
+	return rm.relations.Block(author.ID, blockedID)
+}
+
+// Снимает блокировку пользователя username для author
+func (rm *RelationManager) Unblock(author *models.Author, username string) error {
+	blockedID, err := rm.findUserID(username)
+	if err != nil {
+		return err
+	}
+	return rm.relations.Unblock(author.ID, blockedID)
+}
+
+// Скрывает категорию category из общей выдачи author
+func (rm *RelationManager) Mute(author *models.Author, category string) error {
+	if !models.IsCategory(category) {
+		return errBadCategory
+	}
+	return rm.relations.Mute(author.ID, category)
+}
+
+// Возвращает категорию category в общую выдачу author
+func (rm *RelationManager) Unmute(author *models.Author, category string) error {
+	return rm.relations.Unmute(author.ID, category)
+}
+
+func (rm *RelationManager) findUserID(username string) (primitive.ObjectID, error) {
+	user, err := rm.users.FindOne(username)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	return primitive.ObjectIDFromHex(user.ID)
+}
@@ -0,0 +1,289 @@
+package managers
+
+import (
+	"errors"
+	"forum/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	errNoComment       = errors.New("comment not found")
+	errOwnContent      = errors.New("you cant report your own content")
+	errAlreadyReported = errors.New("you have already reported this")
+	errNoReports       = errors.New("no open reports found")
+
+	resolveAuditActions = map[string]string{
+		models.ResolveDismiss: models.AuditReportDismiss,
+		models.ResolveRemove:  models.AuditReportRemove,
+		models.ResolveBan:     models.AuditReportBan,
+	}
+)
+
+type reportRepo interface {
+	Create(*models.Report) (bool, error)
+	Queue([]string, models.Page) ([]*models.ReportGroup, error)
+	FindOpen(primitive.ObjectID, *primitive.ObjectID) ([]*models.Report, error)
+	Resolve(*models.ReportDecision) error
+	Decisions(models.Page) ([]*models.ReportDecision, error)
+}
+
+type reportPostRepo interface {
+	FindOne(primitive.ObjectID) (*models.Post, error)
+}
+
+/*
+Удаление содержимого через менеджер постов, чтобы клиенты получили события об удалении.
+Release публикует содержимое, задержанное фильтрами, если модератор отклонил системную жалобу.
+*/
+type contentRemover interface {
+	Delete(string, *models.Author, string) error
+	DeleteComment(string, string, *models.Author, string) (*models.Post, error)
+	Release(string, string) error
+}
+
+type banRepo interface {
+	moderatorRepo
+	SetBanned(string, bool) error
+}
+
+type ReportManager struct {
+	storage    reportRepo
+	posts      reportPostRepo
+	remover    contentRemover
+	users      banRepo
+	sessions   accountSessionRepo
+	audit      auditRepo
+	categories categoryRepo
+}
+
+func NewReportManager(storage reportRepo, posts reportPostRepo, remover contentRemover, users banRepo, sessions accountSessionRepo, audit auditRepo, categories categoryRepo) *ReportManager {
+	return &ReportManager{
+		storage:    storage,
+		posts:      posts,
+		remover:    remover,
+		users:      users,
+		sessions:   sessions,
+		audit:      audit,
+		categories: categories,
+	}
+}
+
+/*
+Создает жалобу reporter на пост postID или его комментарий commentID (пустой - жалоба на пост).
+Пока жалоба открыта, повторная жалоба того же пользователя на то же содержимое не принимается.
+*/
+func (rm *ReportManager) Report(postIDStr, commentIDStr string, input *models.ReportInput, reporter *models.Author) error {
+	postID, err := primitive.ObjectIDFromHex(postIDStr)
+	if err != nil {
+		return err
+	}
+
+	post, err := rm.posts.FindOne(postID)
+	if err != nil {
+		return err
+	}
+	if isHiddenFrom(post, reporter.ID) {
+		return errNoPost
+	}
+	if !post.IsPublished() {
+		return errNotPublished
+	}
+
+	report := &models.Report{
+		ID:         primitive.NewObjectID(),
+		ReporterID: reporter.ID,
+		PostID:     postID,
+		Category:   post.Category,
+		AuthorID:   post.Author.ID,
+		Reason:     input.Reason,
+		Details:    input.Details,
+		Status:     models.ReportOpen,
+		Created:    time.Now(),
+	}
+	if commentIDStr != "" {
+		commentID, err := primitive.ObjectIDFromHex(commentIDStr)
+		if err != nil {
+			return err
+		}
+		comment := findComment(post, commentID)
+		if comment == nil || comment.DeletedAt != nil || (comment.AuthorOnly() && comment.Author.ID != reporter.ID) {
+			return errNoComment
+		}
+		report.CommentID = &comment.ID
+		report.AuthorID = comment.Author.ID
+	}
+	if report.AuthorID == reporter.ID {
+		return errOwnContent
+	}
+
+	created, err := rm.storage.Create(report)
+	if err != nil {
+		return err
+	}
+	if !created {
+		return errAlreadyReported
+	}
+	return nil
+}
+
+// Возвращает страницу очереди модерации по категориям moderator, самые частые жалобы первыми
+func (rm *ReportManager) Queue(moderator *models.Author, page models.Page) ([]*models.ReportGroup, error) {
+	categories, err := moderatedCategories(rm.users, rm.categories, moderator)
+	if err != nil {
+		return nil, err
+	}
+	return rm.storage.Queue(categories, page)
+}
+
+// Возвращает страницу истории решений по жалобам
+func (rm *ReportManager) Decisions(moderator *models.Author, page models.Page) ([]*models.ReportDecision, error) {
+	err := checkModerator(rm.users, moderator)
+	if err != nil {
+		return nil, err
+	}
+	return rm.storage.Decisions(page)
+}
+
+/*
+Выносит решение по открытым жалобам на пост или комментарий: dismiss - отклонить,
+remove - удалить содержимое, ban - удалить содержимое и заблокировать автора с отзывом его сессий.
+Отклонять и удалять может модератор категории поста, блокировка действует на весь сайт
+и доступна только модераторам сайта. Отклонение жалоб на задержанное фильтрами содержимое публикует его.
+Уже удаленное содержимое считается удаленным, жалобы на него все равно закрываются.
+Жалобы закрываются, решение сохраняется в историю.
+*/
+func (rm *ReportManager) Resolve(input *models.ResolveInput, moderator *models.Author) (*models.ReportDecision, error) {
+	postID, err := primitive.ObjectIDFromHex(input.PostID)
+	if err != nil {
+		return nil, err
+	}
+	post, err := rm.posts.FindOne(postID)
+	if err != nil {
+		return nil, err
+	}
+	if input.Action == models.ResolveBan {
+		err = checkModerator(rm.users, moderator)
+	} else {
+		err = checkCategoryModerator(rm.users, rm.categories, moderator, post.Category)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var commentID *primitive.ObjectID
+	if input.CommentID != "" {
+		id, err := primitive.ObjectIDFromHex(input.CommentID)
+		if err != nil {
+			return nil, err
+		}
+		commentID = &id
+	}
+
+	reports, err := rm.storage.FindOpen(postID, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if len(reports) == 0 {
+		return nil, errNoReports
+	}
+	authorID := reports[0].AuthorID
+
+	switch input.Action {
+	case models.ResolveRemove, models.ResolveBan:
+		if isRemoved(post, commentID) {
+			break
+		}
+		reason := "report: " + reports[0].Reason
+		if commentID != nil {
+			_, err = rm.remover.DeleteComment(input.PostID, input.CommentID, moderator, reason)
+		} else {
+			err = rm.remover.Delete(input.PostID, moderator, reason)
+		}
+		if err != nil {
+			return nil, err
+		}
+	case models.ResolveDismiss:
+		if isHeld(reports) {
+			err = rm.remover.Release(input.PostID, input.CommentID)
+			if err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, errBadAction
+	}
+
+	if input.Action == models.ResolveBan {
+		err = rm.users.SetBanned(authorID.Hex(), true)
+		if err != nil {
+			return nil, err
+		}
+		err = rm.sessions.DeleteAll(authorID)
+		if err != nil {
+			return nil, err
+		}
+		err = recordAudit(rm.audit, moderator, models.AuditUserBan, models.AuditTargetUser, authorID.Hex(), input.Note, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	decision := &models.ReportDecision{
+		ID:          primitive.NewObjectID(),
+		PostID:      postID,
+		CommentID:   commentID,
+		AuthorID:    authorID,
+		Action:      input.Action,
+		Note:        input.Note,
+		ModeratorID: moderator.ID,
+		Created:     time.Now(),
+	}
+	err = rm.storage.Resolve(decision)
+	if err != nil {
+		return nil, err
+	}
+
+	targetType, targetID := models.AuditTargetPost, input.PostID
+	if commentID != nil {
+		targetType, targetID = models.AuditTargetComment, input.CommentID
+	}
+	err = recordAudit(rm.audit, moderator, resolveAuditActions[input.Action], targetType, targetID, input.Note, nil)
+	if err != nil {
+		return nil, err
+	}
+	return decision, nil
+}
+
+// Возвращает true, если пост или его комментарий commentID (nil - сам пост) уже удален
+func isRemoved(post *models.Post, commentID *primitive.ObjectID) bool {
+	if post.DeletedAt != nil || commentID == nil {
+		return post.DeletedAt != nil
+	}
+	for _, comment := range post.Comments {
+		if comment.ID == *commentID {
+			return comment.DeletedAt != nil
+		}
+	}
+	return true
+}
+
+// Возвращает true, если среди жалоб есть системная жалоба фильтров, т.е. содержимое задержано
+func isHeld(reports []*models.Report) bool {
+	for _, report := range reports {
+		if report.Reason == models.ReportFilter {
+			return true
+		}
+	}
+	return false
+}
+
+// Возвращает комментарий commentID поста post, nil - такого нет
+func findComment(post *models.Post, commentID primitive.ObjectID) *models.Comment {
+	for i := range post.Comments {
+		if post.Comments[i].ID == commentID {
+			return &post.Comments[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package managers
+
+import (
+	"forum/internal/models"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryReports struct {
+	reports    []*models.Report
+	decisions  []*models.ReportDecision
+	categories []string
+}
+
+func sameItem(report *models.Report, postID primitive.ObjectID, commentID *primitive.ObjectID) bool {
+	if report.PostID != postID || (report.CommentID == nil) != (commentID == nil) {
+		return false
+	}
+	return commentID == nil || *report.CommentID == *commentID
+}
+
+func (mr *memoryReports) Create(report *models.Report) (bool, error) {
+	for _, item := range mr.reports {
+		if item.ReporterID == report.ReporterID && item.Status == models.ReportOpen && sameItem(item, report.PostID, report.CommentID) {
+			return false, nil
+		}
+	}
+	mr.reports = append(mr.reports, report)
+	return true, nil
+}
+
+func (mr *memoryReports) Queue(categories []string, page models.Page) ([]*models.ReportGroup, error) {
+	mr.categories = categories
+	return nil, nil
+}
+
+func (mr *memoryReports) FindOpen(postID primitive.ObjectID, commentID *primitive.ObjectID) ([]*models.Report, error) {
+	reports := make([]*models.Report, 0)
+	for _, item := range mr.reports {
+		if item.Status == models.ReportOpen && sameItem(item, postID, commentID) {
+			reports = append(reports, item)
+		}
+	}
+	return reports, nil
+}
+
+func (mr *memoryReports) Resolve(decision *models.ReportDecision) error {
+	reports, _ := mr.FindOpen(decision.PostID, decision.CommentID)
+	for _, item := range reports {
+		item.Status = models.ReportResolved
+		item.DecisionID = &decision.ID
+	}
+	decision.Reports = len(reports)
+	mr.decisions = append(mr.decisions, decision)
+	return nil
+}
+
+func (mr *memoryReports) Decisions(page models.Page) ([]*models.ReportDecision, error) {
+	return mr.decisions, nil
+}
+
+type memoryRemover struct {
+	deletedPosts    []string
+	deletedComments []string
+}
+
+func (mr *memoryRemover) Delete(postID string, actor *models.Author, reason string) error {
+	mr.deletedPosts = append(mr.deletedPosts, postID)
+	return nil
+}
+
+func (mr *memoryRemover) DeleteComment(postID, commentID string, actor *models.Author, reason string) (*models.Post, error) {
+	mr.deletedComments = append(mr.deletedComments, commentID)
+	return nil, nil
+}
+
+func (mr *memoryRemover) Release(postID, commentID string) error {
+	return nil
+}
+
+type memoryBans struct {
+	*memoryUsers
+	banned map[string]bool
+}
+
+func (mb *memoryBans) SetBanned(userID string, banned bool) error {
+	mb.banned[userID] = banned
+	return nil
+}
+
+type revokedSessions map[primitive.ObjectID]bool
+
+func (rs revokedSessions) DeleteAll(userID primitive.ObjectID) error {
+	rs[userID] = true
+	return nil
+}
+
+func TestReport(t *testing.T) {
+	author := &models.Author{ID: primitive.NewObjectID(), Username: "author"}
+	reporter := &models.Author{ID: primitive.NewObjectID(), Username: "reporter"}
+	moderator := &models.Author{ID: primitive.NewObjectID(), Username: "moderator"}
+	comment := models.Comment{ID: primitive.NewObjectID(), Author: *reporter}
+	post := &models.Post{
+		ID:       primitive.NewObjectID(),
+		Author:   *author,
+		Created:  time.Now(),
+		Comments: []models.Comment{comment},
+	}
+
+	storage := &memoryReports{}
+	remover := &memoryRemover{}
+	users := &memoryBans{
+		memoryUsers: &memoryUsers{users: map[string]*models.User{
+			"moderator": {ID: moderator.ID.Hex(), Username: "moderator", Role: models.RoleModerator},
+			"reporter":  {ID: reporter.ID.Hex(), Username: "reporter", Role: models.RoleUser},
+		}},
+		banned: make(map[string]bool),
+	}
+	sessions := revokedSessions{}
+	audit := &memoryAudit{}
+	reportManager := NewReportManager(storage, &memoryPost{post: post}, remover, users, sessions, audit, &memoryCategories{})
+
+	input := &models.ReportInput{Reason: "spam"}
+	err := reportManager.Report(post.ID.Hex(), "", input, reporter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = reportManager.Report(post.ID.Hex(), "", input, reporter)
+	if err != errAlreadyReported {
+		t.Errorf("want errAlreadyReported, have %v", err)
+	}
+	err = reportManager.Report(post.ID.Hex(), comment.ID.Hex(), input, reporter)
+	if err != errOwnContent {
+		t.Errorf("want errOwnContent, have %v", err)
+	}
+	err = reportManager.Report(post.ID.Hex(), primitive.NewObjectID().Hex(), input, reporter)
+	if err != errNoComment {
+		t.Errorf("want errNoComment, have %v", err)
+	}
+
+	resolve := &models.ResolveInput{PostID: post.ID.Hex(), Action: models.ResolveBan}
+	_, err = reportManager.Resolve(resolve, reporter)
+	if err != models.ErrForbidden {
+		t.Errorf("want ErrForbidden, have %v", err)
+	}
+
+	decision, err := reportManager.Resolve(resolve, moderator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decision.Reports != 1 || decision.AuthorID != author.ID || decision.ModeratorID != moderator.ID {
+		t.Errorf("unexpected decision %+v", decision)
+	}
+	if len(remover.deletedPosts) != 1 || !users.banned[author.ID.Hex()] || !sessions[author.ID] {
+		t.Errorf("ban not applied: removed %v, banned %v, sessions %v", remover.deletedPosts, users.banned, sessions)
+	}
+	actions := make([]string, 0)
+	for _, entry := range audit.entries {
+		actions = append(actions, entry.Action)
+	}
+	if !reflect.DeepEqual(actions, []string{models.AuditUserBan, models.AuditReportBan}) {
+		t.Errorf("unexpected audit actions %v", actions)
+	}
+
+	// после решения жалоба закрыта, повторно решить нельзя, а пожаловаться снова можно
+	_, err = reportManager.Resolve(resolve, moderator)
+	if err != errNoReports {
+		t.Errorf("want errNoReports, have %v", err)
+	}
+	err = reportManager.Report(post.ID.Hex(), "", input, reporter)
+	if err != nil {
+		t.Errorf("want new report after resolve, have %v", err)
+	}
+}
+
+// Удаление уже удаленного содержимого завершается ошибкой, как в хранилище
+type deletedRemover struct {
+	memoryRemover
+}
+
+func (dr *deletedRemover) Delete(postID string, actor *models.Author, reason string) error {
+	return errNoPost
+}
+
+func (dr *deletedRemover) DeleteComment(postID, commentID string, actor *models.Author, reason string) (*models.Post, error) {
+	return nil, errNoComment
+}
+
+func TestResolveDeleted(t *testing.T) {
+	author := &models.Author{ID: primitive.NewObjectID(), Username: "author"}
+	moderator := &models.Author{ID: primitive.NewObjectID(), Username: "moderator"}
+	deletedAt := time.Now()
+	comment := models.Comment{ID: primitive.NewObjectID(), Author: *author, DeletedAt: &deletedAt}
+	post := &models.Post{ID: primitive.NewObjectID(), Author: *author, Created: time.Now(), Comments: []models.Comment{comment}}
+
+	storage := &memoryReports{reports: []*models.Report{
+		{ID: primitive.NewObjectID(), PostID: post.ID, CommentID: &comment.ID, AuthorID: author.ID, Status: models.ReportOpen},
+		{ID: primitive.NewObjectID(), PostID: post.ID, AuthorID: author.ID, Status: models.ReportOpen},
+	}}
+	users := &memoryBans{
+		memoryUsers: &memoryUsers{users: map[string]*models.User{
+			"moderator": {ID: moderator.ID.Hex(), Username: "moderator", Role: models.RoleModerator},
+		}},
+		banned: make(map[string]bool),
+	}
+	postStorage := &memoryPost{post: post}
+	reportManager := NewReportManager(storage, postStorage, &deletedRemover{}, users, revokedSessions{}, &memoryAudit{}, &memoryCategories{})
+
+	// комментарий уже удален, жалобы на него закрываются без повторного удаления
+	_, err := reportManager.Resolve(&models.ResolveInput{PostID: post.ID.Hex(), CommentID: comment.ID.Hex(), Action: models.ResolveRemove}, moderator)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// пост удален после жалобы, блокировка автора все равно применяется
+	post.DeletedAt = &deletedAt
+	_, err = reportManager.Resolve(&models.ResolveInput{PostID: post.ID.Hex(), Action: models.ResolveBan}, moderator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !users.banned[author.ID.Hex()] {
+		t.Error("author of deleted post is not banned")
+	}
+	for _, report := range storage.reports {
+		if report.Status != models.ReportResolved {
+			t.Errorf("report %v is not resolved", report.ID)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package managers
+
+import (
+	"errors"
+	"forum/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	errBadUntil      = errors.New("suspension must end in the future")
+	errSuspendAdmin  = errors.New("admins cant be suspended")
+	errNotSuspended  = errors.New("user is not suspended")
+	errShadowbanSame = errors.New("shadowban is already in this state")
+)
+
+/*
+Добавляет в filter условие, что пост не в теневом бане и не задержан фильтрами либо его автор - viewerID.
+Посты без поля shadowed созданы до теневых банов и видны всем.
+*/
+func visibleTo(filter bson.M, viewerID primitive.ObjectID) bson.M {
+	visible := bson.A{
+		bson.M{"shadowed": bson.M{"$ne": true}, "held": bson.M{"$exists": false}},
+	}
+	if !viewerID.IsZero() {
+		visible = append(visible, bson.M{"author.id": viewerID})
+	}
+	filter["$or"] = visible
+	return filter
+}
+
+/*
+Возвращает true, если viewerID - автор с authorID. У анонимного пользователя и у обезличенного
+автора удаленного аккаунта id нулевой, поэтому анонимный никогда не считается автором.
+*/
+func isOwnedBy(authorID, viewerID primitive.ObjectID) bool {
+	return !viewerID.IsZero() && authorID == viewerID
+}
+
+// Возвращает true, если post в теневом бане или задержан фильтрами и viewerID - не его автор
+func isShadowedFrom(post *models.Post, viewerID primitive.ObjectID) bool {
+	return post.AuthorOnly() && !isOwnedBy(post.Author.ID, viewerID)
+}
+
+// Убирает комментарии в теневом бане, кроме комментариев самого viewerID
+func hideShadowed(comments []models.Comment, viewerID primitive.ObjectID) []models.Comment {
+	visible := make([]models.Comment, 0, len(comments))
+	for _, comment := range comments {
+		if comment.Shadowed && !isOwnedBy(comment.Author.ID, viewerID) {
+			continue
+		}
+		visible = append(visible, comment)
+	}
+	return visible
+}
+
+type suspensionRepo interface {
+	FindOne(string) (*models.User, error)
+	SetSuspension(string, bool, *time.Time, string) error
+	SetShadowbanned(string, bool) error
+}
+
+// Проверяет по базе, что author - администратор
+func checkAdmin(users moderatorRepo, author *models.Author) error {
+	user, err := users.FindOne(author.Username)
+	if err != nil {
+		return err
+	}
+	if user.Role != models.RoleAdmin {
+		return models.ErrForbidden
+	}
+	return nil
+}
+
+type SuspensionManager struct {
+	users suspensionRepo
+	audit auditRepo
+}
+
+func NewSuspensionManager(users suspensionRepo, audit auditRepo) *SuspensionManager {
+	return &SuspensionManager{
+		users: users,
+		audit: audit,
+	}
+}
+
+// Возвращает пользователя username, которого admin может ограничить
+func (sm *SuspensionManager) target(username string, admin *models.Author) (*models.User, error) {
+	err := checkAdmin(sm.users, admin)
+	if err != nil {
+		return nil, err
+	}
+	user, err := sm.users.FindOne(username)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == models.RoleAdmin {
+		return nil, errSuspendAdmin
+	}
+	return user, nil
+}
+
+/*
+Блокирует пользователя username до input.Until, без Until - бессрочно.
+Сессии не удаляются: проверка токена отклоняет их, пока блокировка действует.
+*/
+func (sm *SuspensionManager) Suspend(username string, input *models.SuspendInput, admin *models.Author) error {
+	if input.Until != nil && !input.Until.After(time.Now()) {
+		return errBadUntil
+	}
+	user, err := sm.target(username, admin)
+	if err != nil {
+		return err
+	}
+
+	err = sm.users.SetSuspension(user.ID, input.Until == nil, input.Until, input.Reason)
+	if err != nil {
+		return err
+	}
+	return recordAudit(sm.audit, admin, models.AuditUserSuspend, models.AuditTargetUser, user.ID, input.Reason, user.Status())
+}
+
+// Снимает временную или бессрочную блокировку пользователя username
+func (sm *SuspensionManager) Unsuspend(username string, admin *models.Author) error {
+	user, err := sm.target(username, admin)
+	if err != nil {
+		return err
+	}
+	if user.Suspension(time.Now()) == nil {
+		return errNotSuspended
+	}
+
+	err = sm.users.SetSuspension(user.ID, false, nil, "")
+	if err != nil {
+		return err
+	}
+	return recordAudit(sm.audit, admin, models.AuditUserUnsuspend, models.AuditTargetUser, user.ID, "", user.Status())
+}
+
+/*
+Включает или выключает теневой бан пользователя username: его новые посты и комментарии
+видны только ему самому. Уже созданное содержимое не меняется.
+*/
+func (sm *SuspensionManager) SetShadowban(username string, shadowbanned bool, admin *models.Author) error {
+	user, err := sm.target(username, admin)
+	if err != nil {
+		return err
+	}
+	if user.Shadowbanned == shadowbanned {
+		return errShadowbanSame
+	}
+
+	err = sm.users.SetShadowbanned(user.ID, shadowbanned)
+	if err != nil {
+		return err
+	}
+	action := models.AuditUserShadowban
+	if !shadowbanned {
+		action = models.AuditUserUnshadowban
+	}
+	return recordAudit(sm.audit, admin, action, models.AuditTargetUser, user.ID, "", user.Status())
+}
@@ -0,0 +1,199 @@
+package managers
+
+import (
+	"context"
+	"errors"
+	"forum/internal/filter"
+	"forum/internal/models"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type memorySuspensions struct {
+	*memoryUsers
+}
+
+func (ms memorySuspensions) byID(userID string) *models.User {
+	for _, user := range ms.users {
+		if user.ID == userID {
+			return user
+		}
+	}
+	return nil
+}
+
+func (ms memorySuspensions) SetSuspension(userID string, banned bool, until *time.Time, reason string) error {
+	user := ms.byID(userID)
+	user.Banned, user.SuspendedUntil, user.SuspendReason = banned, until, reason
+	return nil
+}
+
+func (ms memorySuspensions) SetShadowbanned(userID string, shadowbanned bool) error {
+	ms.byID(userID).Shadowbanned = shadowbanned
+	return nil
+}
+
+func TestSuspension(t *testing.T) {
+	password, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	admin := &models.Author{ID: primitive.NewObjectID(), Username: "admin"}
+	user := &models.Author{ID: primitive.NewObjectID(), Username: "user"}
+	users := memorySuspensions{&memoryUsers{users: map[string]*models.User{
+		"admin": {ID: admin.ID.Hex(), Username: "admin", Role: models.RoleAdmin},
+		"user":  {ID: user.ID.Hex(), Username: "user", Role: models.RoleUser, Password: string(password)},
+	}}}
+	audit := &memoryAudit{}
+	suspensionManager := NewSuspensionManager(users, audit)
+	authManager := NewSeesionManager(users, memorySessions{})
+
+	token, err := authManager.generateToken(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	until := time.Now().Add(time.Hour)
+	input := &models.SuspendInput{Until: &until, Reason: "spam"}
+	err = suspensionManager.Suspend("admin", input, user)
+	if err != models.ErrForbidden {
+		t.Errorf("want ErrForbidden, have %v", err)
+	}
+	err = suspensionManager.Suspend("admin", input, admin)
+	if err != errSuspendAdmin {
+		t.Errorf("want errSuspendAdmin, have %v", err)
+	}
+	past := time.Now().Add(-time.Hour)
+	err = suspensionManager.Suspend("user", &models.SuspendInput{Until: &past, Reason: "spam"}, admin)
+	if err != errBadUntil {
+		t.Errorf("want errBadUntil, have %v", err)
+	}
+
+	err = suspensionManager.Suspend("user", input, admin)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// выданная сессия отклоняется, а новый вход сообщает причину и срок
+	suspended := &models.SuspendedError{}
+	_, err = authManager.Check(context.Background(), token)
+	if !errors.As(err, &suspended) || suspended.Reason != "spam" || !suspended.Until.Equal(until) {
+		t.Errorf("want suspension until %v, have %v", until, err)
+	}
+	_, err = authManager.Login(&models.User{Username: "user", Password: "password"})
+	if !errors.As(err, &suspended) {
+		t.Errorf("want SuspendedError, have %v", err)
+	}
+
+	err = suspensionManager.Unsuspend("user", admin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = suspensionManager.Unsuspend("user", admin)
+	if err != errNotSuspended {
+		t.Errorf("want errNotSuspended, have %v", err)
+	}
+
+	err = suspensionManager.SetShadowban("user", true, admin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	author, err := authManager.Check(context.Background(), token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if author.ID != user.ID || !author.Shadowbanned {
+		t.Errorf("want shadowbanned %v, have %+v", user, author)
+	}
+
+	actions := make([]string, 0)
+	for _, entry := range audit.entries {
+		actions = append(actions, entry.Action)
+	}
+	expected := []string{models.AuditUserSuspend, models.AuditUserUnsuspend, models.AuditUserShadowban}
+	if len(actions) != len(expected) || actions[0] != expected[0] || actions[1] != expected[1] || actions[2] != expected[2] {
+		t.Errorf("want audit actions %v, have %v", expected, actions)
+	}
+}
+
+// Хранилище, сохраняющее созданный пост вместо имеющегося
+type creatingPost struct {
+	memoryPost
+}
+
+func (cp *creatingPost) Create(post *models.Post, report *models.Report, events models.OutboxEvents) error {
+	cp.post = post
+	return cp.outbox.save(post, events)
+}
+
+func TestShadowedContent(t *testing.T) {
+	shadowed := &models.Author{ID: primitive.NewObjectID(), Username: "shadowed", Shadowbanned: true}
+	viewer := &models.Author{ID: primitive.NewObjectID(), Username: "viewer"}
+	post := &models.Post{
+		ID:      primitive.NewObjectID(),
+		Author:  *viewer,
+		Created: time.Now(),
+	}
+	storage := &creatingPost{memoryPost{post: post}}
+	events := &memoryPublisher{}
+	postManager := NewPostManager(storage, nil, nil, nil, nil, events, nil, nil, nil, filter.NewPipeline(), &memoryCategories{}, testArchiveMonths)
+
+	created, err := postManager.Create(&models.PostInput{Title: "hidden", Type: "text", Category: "music"}, shadowed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !created.Shadowed || len(storage.outbox.events()) != 0 {
+		t.Errorf("shadowed post announced: %+v, events %v", created, storage.outbox.events())
+	}
+	if !isShadowedFrom(created, viewer.ID) || isShadowedFrom(created, shadowed.ID) {
+		t.Error("shadowed post must be visible only to its author")
+	}
+
+	comments := []models.Comment{
+		{ID: primitive.NewObjectID(), Author: *shadowed, Shadowed: true},
+		{ID: primitive.NewObjectID(), Author: *viewer},
+	}
+	if visible := hideShadowed(comments, viewer.ID); len(visible) != 1 || visible[0].Author.ID != viewer.ID {
+		t.Errorf("unexpected comments for viewer %v", visible)
+	}
+	if visible := hideShadowed(comments, shadowed.ID); len(visible) != 2 {
+		t.Errorf("unexpected comments for author %v", visible)
+	}
+}
+
+func TestAnonymizedAuthorHidden(t *testing.T) {
+	// у обезличенного автора удаленного аккаунта и у анонимного пользователя одинаковый нулевой id
+	deleted := models.Author{Username: models.DeletedUsername}
+	posts := map[string]*models.Post{
+		"shadowed": {Shadowed: true},
+		"held":     {Held: "too many links"},
+		"draft":    {Status: models.PostDraft},
+	}
+	for name, post := range posts {
+		post.ID = primitive.NewObjectID()
+		post.Author = deleted
+		post.Created = time.Now()
+		storage := &trashPost{memoryPost: memoryPost{post: post}}
+		postManager := NewPostManager(storage, &memoryUsers{}, noBlocks{}, nil, nil, &memoryPublisher{}, nil, nil, &memoryAudit{}, nil, &memoryCategories{}, testArchiveMonths)
+
+		_, err := postManager.FindOne(context.Background(), post.ID.Hex(), nil)
+		if err != errNoPost {
+			t.Errorf("%s post of deleted author visible to anonymous: %v", name, err)
+		}
+		if !isShadowedFrom(post, primitive.NilObjectID) && post.AuthorOnly() {
+			t.Errorf("%s post of deleted author is not hidden from anonymous", name)
+		}
+	}
+
+	comments := []models.Comment{{Author: deleted, Shadowed: true}, {Author: deleted, Held: "spam"}}
+	if visible := hideHeld(hideShadowed(comments, primitive.NilObjectID), primitive.NilObjectID); len(visible) != 0 {
+		t.Errorf("comments of deleted author visible to anonymous: %v", visible)
+	}
+	if filter := visibleTo(bson.M{}, primitive.NilObjectID); len(filter["$or"].(bson.A)) != 1 {
+		t.Errorf("anonymous filter matches own posts: %v", filter)
+	}
+}
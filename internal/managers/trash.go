@@ -0,0 +1,182 @@
+package managers
+
+import (
+	"forum/internal/models"
+	"log/slog"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Добавляет в filter условие, что пост не удален
+func notDeleted(filter bson.M) bson.M {
+	filter["deletedAt"] = bson.M{"$exists": false}
+	return filter
+}
+
+// Добавляет в filter условия, при которых пост попадает в списки viewerID: опубликован, не удален и не скрыт теневым баном
+func listable(filter bson.M, viewerID primitive.ObjectID) bson.M {
+	return notDeleted(visibleTo(publishedOnly(filter), viewerID))
+}
+
+// Возвращает true, если viewerID не может видеть post: пост удален или в теневом бане
+func isHiddenFrom(post *models.Post, viewerID primitive.ObjectID) bool {
+	return post.DeletedAt != nil || isShadowedFrom(post, viewerID)
+}
+
+// Убирает удаленные комментарии
+func hideDeleted(comments []models.Comment) []models.Comment {
+	visible := make([]models.Comment, 0, len(comments))
+	for _, comment := range comments {
+		if comment.DeletedAt != nil {
+			continue
+		}
+		visible = append(visible, comment)
+	}
+	return visible
+}
+
+func hasDeletedComments(post *models.Post) bool {
+	for _, comment := range post.Comments {
+		if comment.DeletedAt != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Возвращает время последнего удаления в посте: самого поста или одного из комментариев
+func lastDeleted(post *models.Post) time.Time {
+	last := time.Time{}
+	if post.DeletedAt != nil {
+		last = *post.DeletedAt
+	}
+	for _, comment := range post.Comments {
+		if comment.DeletedAt != nil && comment.DeletedAt.After(last) {
+			last = *comment.DeletedAt
+		}
+	}
+	return last
+}
+
+// Возвращает true, если viewer - модератор категории category. Для анонимного и при ошибке поиска - false.
+func (pm *PostManager) canModerate(viewer *models.Author, category string) bool {
+	return viewer != nil && checkCategoryModerator(pm.users, pm.categories, viewer, category) == nil
+}
+
+/*
+Возвращает страницу корзины для модератора: удаленные посты и посты с удаленными комментариями
+из категорий, которые он модерирует, последние удаления первыми. Удаленные комментарии в них не скрываются.
+*/
+func (pm *PostManager) GetDeleted(moderator *models.Author, page models.Page) ([]*models.Post, error) {
+	categories, err := moderatedCategories(pm.users, pm.categories, moderator)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{
+		"$or": bson.A{
+			bson.M{"deletedAt": bson.M{"$exists": true}},
+			bson.M{"comments.deletedAt": bson.M{"$exists": true}},
+		},
+	}
+	if categories != nil {
+		filter["category"] = bson.M{"$in": categories}
+	}
+	posts, err := pm.storage.Find(filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, post := range posts {
+		pm.preparePostView(post, moderator.ID, true)
+	}
+
+	sort.SliceStable(posts, func(i, j int) bool {
+		return lastDeleted(posts[i]).After(lastDeleted(posts[j]))
+	})
+	start, end := page.Bounds(len(posts))
+	return posts[start:end], nil
+}
+
+// Восстанавливает удаленный пост postID. Доступно только модераторам категории поста.
+func (pm *PostManager) Restore(postIDStr string, moderator *models.Author, reason string) (*models.Post, error) {
+	postID, err := primitive.ObjectIDFromHex(postIDStr)
+	if err != nil {
+		return nil, err
+	}
+	err = pm.checkPostModerator(postID, moderator)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := pm.storage.Restore(postID, postRestoredEvents)
+	if err != nil {
+		return nil, err
+	}
+	pm.preparePost(post, moderator.ID)
+
+	err = recordAudit(pm.audit, moderator, models.AuditPostRestore, models.AuditTargetPost, postIDStr, reason, nil)
+	if err != nil {
+		return nil, err
+	}
+	return post, nil
+}
+
+// Восстанавливает удаленный комментарий commentID к посту postID. Доступно только модераторам категории поста.
+func (pm *PostManager) RestoreComment(postIDStr, commentIDStr string, moderator *models.Author, reason string) (*models.Post, error) {
+	postID, err := primitive.ObjectIDFromHex(postIDStr)
+	if err != nil {
+		return nil, err
+	}
+	commentID, err := primitive.ObjectIDFromHex(commentIDStr)
+	if err != nil {
+		return nil, err
+	}
+	err = pm.checkPostModerator(postID, moderator)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := pm.storage.RestoreComment(postID, commentID, commentRestoredEvents(commentID))
+	if err != nil {
+		return nil, err
+	}
+	pm.preparePost(post, moderator.ID)
+
+	err = recordAudit(pm.audit, moderator, models.AuditCommentRestore, models.AuditTargetComment, commentIDStr, reason, nil)
+	if err != nil {
+		return nil, err
+	}
+	return post, nil
+}
+
+// Проверяет, что moderator может модерировать категорию поста postID
+func (pm *PostManager) checkPostModerator(postID primitive.ObjectID, moderator *models.Author) error {
+	post, err := pm.storage.FindOne(postID)
+	if err != nil {
+		return err
+	}
+	return checkCategoryModerator(pm.users, pm.categories, moderator, post.Category)
+}
+
+// Окончательно удаляет посты и комментарии, удаленные раньше, чем retention назад от now
+func (pm *PostManager) PurgeDeleted(now time.Time, retention time.Duration) (int64, int64, error) {
+	return pm.storage.Purge(now.Add(-retention))
+}
+
+/*
+Раз в interval окончательно удаляет посты и комментарии старше retention.
+Ошибка пишется в logger и не прерывает работу.
+*/
+func (pm *PostManager) RunPurge(interval, retention time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		_, _, err := pm.PurgeDeleted(time.Now(), retention)
+		if err != nil {
+			logger.Error("purge deleted posts", "task", "post.PurgeDeleted", "err", err)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package managers
+
+import (
+	"context"
+	"forum/internal/models"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Хранилище с корзиной: отдает копию поста, чтобы подготовка к выдаче не меняла его
+type trashPost struct {
+	memoryPost
+	purgedBefore time.Time
+}
+
+func (tp *trashPost) copyPost() *models.Post {
+	post := *tp.post
+	post.Comments = append([]models.Comment(nil), tp.post.Comments...)
+	return &post
+}
+
+func (tp *trashPost) View(ctx context.Context, postID primitive.ObjectID) (*models.Post, error) {
+	return tp.copyPost(), nil
+}
+
+func (tp *trashPost) Restore(postID primitive.ObjectID, events models.OutboxEvents) (*models.Post, error) {
+	tp.post.DeletedAt, tp.post.DeletedBy = nil, nil
+	return tp.copyPost(), tp.outbox.save(tp.post, events)
+}
+
+func (tp *trashPost) RestoreComment(postID, commentID primitive.ObjectID, events models.OutboxEvents) (*models.Post, error) {
+	for i := range tp.post.Comments {
+		if tp.post.Comments[i].ID == commentID {
+			tp.post.Comments[i].DeletedAt, tp.post.Comments[i].DeletedBy = nil, nil
+		}
+	}
+	return tp.copyPost(), tp.outbox.save(tp.post, events)
+}
+
+func (tp *trashPost) Purge(before time.Time) (int64, int64, error) {
+	tp.purgedBefore = before
+	return 1, 0, nil
+}
+
+type noBlocks struct {
+	postRelationRepo
+}
+
+func (nb noBlocks) Blocked(userID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	return nil, nil
+}
+
+func (nb noBlocks) IsBlocked(blocker, blocked primitive.ObjectID) (bool, error) {
+	return false, nil
+}
+
+func TestSoftDelete(t *testing.T) {
+	moderator := &models.Author{ID: primitive.NewObjectID(), Username: "moderator"}
+	user := &models.Author{ID: primitive.NewObjectID(), Username: "user"}
+	users := &memoryUsers{users: map[string]*models.User{
+		"moderator": {ID: moderator.ID.Hex(), Username: "moderator", Role: models.RoleModerator},
+		"user":      {ID: user.ID.Hex(), Username: "user", Role: models.RoleUser},
+	}}
+	deletedAt := time.Now().Add(-time.Hour)
+	comment := models.Comment{ID: primitive.NewObjectID(), Author: *user, Body: "deleted", DeletedAt: &deletedAt, DeletedBy: &moderator.ID}
+	post := &models.Post{
+		ID:        primitive.NewObjectID(),
+		Author:    *user,
+		Created:   time.Now(),
+		Comments:  []models.Comment{comment, {ID: primitive.NewObjectID(), Author: *user, Body: "visible"}},
+		DeletedAt: &deletedAt,
+		DeletedBy: &moderator.ID,
+	}
+	storage := &trashPost{memoryPost: memoryPost{post: post}}
+	events := &memoryPublisher{}
+	audit := &memoryAudit{}
+	postManager := NewPostManager(storage, users, noBlocks{}, nil, nil, events, nil, nil, audit, nil, &memoryCategories{}, testArchiveMonths)
+
+	// удаленный пост виден только модератору, вместе с удаленными комментариями
+	_, err := postManager.FindOne(context.Background(), post.ID.Hex(), user)
+	if err != errNoPost {
+		t.Errorf("want errNoPost, have %v", err)
+	}
+	found, err := postManager.FindOne(context.Background(), post.ID.Hex(), moderator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found.Comments) != 2 {
+		t.Errorf("moderator must see deleted comments, have %v", found.Comments)
+	}
+
+	_, err = postManager.Restore(post.ID.Hex(), user, "mistake")
+	if err != models.ErrForbidden {
+		t.Errorf("want ErrForbidden, have %v", err)
+	}
+	restored, err := postManager.Restore(post.ID.Hex(), moderator, "mistake")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.DeletedAt != nil || len(restored.Comments) != 1 {
+		t.Errorf("unexpected restored post %+v", restored)
+	}
+	if saved := storage.outbox.events(); len(saved) != 1 || saved[0].Type != models.EventPostRestored {
+		t.Errorf("restored post not announced, events %v", saved)
+	}
+
+	// после восстановления пост доступен, удаленный комментарий скрыт от пользователя
+	found, err = postManager.FindOne(context.Background(), post.ID.Hex(), user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found.Comments) != 1 || found.Comments[0].Body != "visible" {
+		t.Errorf("deleted comment visible to user: %v", found.Comments)
+	}
+
+	_, err = postManager.RestoreComment(post.ID.Hex(), comment.ID.Hex(), moderator, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved := storage.outbox.events(); len(saved) != 2 || saved[1].Type != models.EventCommentRestored {
+		t.Errorf("restored comment not announced, events %v", saved)
+	}
+
+	actions := make([]string, 0)
+	for _, entry := range audit.entries {
+		actions = append(actions, entry.Action)
+	}
+	if len(actions) != 2 || actions[0] != models.AuditPostRestore || actions[1] != models.AuditCommentRestore {
+		t.Errorf("unexpected audit actions %v", actions)
+	}
+	if audit.entries[0].Reason != "mistake" || audit.entries[0].TargetID != post.ID.Hex() {
+		t.Errorf("unexpected audit entry %+v", audit.entries[0])
+	}
+
+	now := time.Now()
+	_, _, err = postManager.PurgeDeleted(now, 30*24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !storage.purgedBefore.Equal(now.Add(-30 * 24 * time.Hour)) {
+		t.Errorf("want cutoff %v, have %v", now.Add(-30*24*time.Hour), storage.purgedBefore)
+	}
+}
+
+func TestListable(t *testing.T) {
+	viewer := primitive.NewObjectID()
+	filter := listable(bson.M{"category": "music"}, viewer)
+	if filter["category"] != "music" {
+		t.Errorf("filter lost its conditions: %v", filter)
+	}
+	if deleted, ok := filter["deletedAt"].(bson.M); !ok || deleted["$exists"] != false {
+		t.Errorf("deleted posts are listed: %v", filter)
+	}
+	if _, ok := filter["$or"]; !ok {
+		t.Errorf("shadowed posts are listed: %v", filter)
+	}
+
+	deletedAt := time.Now()
+	post := &models.Post{Author: models.Author{ID: viewer}, DeletedAt: &deletedAt}
+	if !isHiddenFrom(post, viewer) {
+		t.Error("deleted post must be hidden even from its author")
+	}
+}
@@ -0,0 +1,80 @@
+package managers
+
+import (
+	"forum/internal/models"
+	"log/slog"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	// сколько ссылок может ждать загрузки, остальные пропускаются
+	unfurlQueueSize = 100
+)
+
+type linkFetcher interface {
+	Fetch(string) (*models.LinkPreview, error)
+}
+
+type previewRepo interface {
+	UpdateOne(primitive.ObjectID, bson.M) (*models.Post, error)
+}
+
+// Загружает превью ссылок из постов типа link в фоне
+type UnfurlManager struct {
+	fetcher linkFetcher
+	storage previewRepo
+	events  eventPublisher
+	jobs    chan *models.Post
+}
+
+func NewUnfurlManager(fetcher linkFetcher, storage previewRepo, events eventPublisher) *UnfurlManager {
+	return &UnfurlManager{
+		fetcher: fetcher,
+		storage: storage,
+		events:  events,
+		jobs:    make(chan *models.Post, unfurlQueueSize),
+	}
+}
+
+// Ставит пост в очередь на загрузку превью. Не блокирует: если очередь заполнена, пост остается без превью.
+func (um *UnfurlManager) Enqueue(post *models.Post) {
+	select {
+	case um.jobs <- post:
+	default:
+	}
+}
+
+// Обрабатывает очередь, можно запускать в нескольких горутинах. Ошибки пишутся в logger, пост остается без превью.
+func (um *UnfurlManager) Run(logger *slog.Logger) {
+	for post := range um.jobs {
+		err := um.unfurl(post)
+		if err != nil {
+			logger.Error("unfurl link preview", "task", "unfurl", "post", post.ID.Hex(), "err", err)
+		}
+	}
+}
+
+// Загружает превью и сохраняет его в пост, клиенты получают событие post.preview
+func (um *UnfurlManager) unfurl(post *models.Post) error {
+	preview, err := um.fetcher.Fetch(post.URL)
+	if err != nil {
+		return err
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"preview": preview,
+		},
+	}
+	post, err = um.storage.UpdateOne(post.ID, update)
+	if err != nil {
+		return err
+	}
+
+	event, err := models.NewEvent(models.EventPostPreview, post, preview)
+	if err != nil {
+		return err
+	}
+	return um.events.Publish(event)
+}
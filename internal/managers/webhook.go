@@ -0,0 +1,416 @@
+package managers
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"forum/internal/models"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	errBadWebhookEvent = errors.New("webhook requires known events")
+	errBadWebhookURL   = errors.New("webhook url must be http or https")
+	errNoThreshold     = errors.New("post.threshold webhook requires a positive threshold")
+
+	// задержка перед повторной попыткой удваивается от webhookBackoff до webhookMaxBackoff
+	webhookMaxAttempts = 8
+	webhookBackoff     = time.Minute
+	webhookMaxBackoff  = 6 * time.Hour
+	// после стольких неудачных попыток подряд вебхук выключается
+	webhookDisableAfter = 10
+	// на это время доставка закрепляется за экземпляром сервера, который ее отправляет
+	webhookLease = time.Minute
+	// пауза перед повторной постановкой события в очередь доставки после ошибки
+	dispatchRetryDelay = 5 * time.Second
+
+	webhookSignatureHeader = "X-Forum-Signature"
+	webhookEventHeader     = "X-Forum-Event"
+	webhookDeliveryHeader  = "X-Forum-Delivery"
+)
+
+type webhookRepo interface {
+	Create(*models.Webhook) error
+	Find() ([]*models.Webhook, error)
+	Active(string) ([]*models.Webhook, error)
+	FindOne(primitive.ObjectID) (*models.Webhook, error)
+	Delete(primitive.ObjectID) error
+	SetActive(primitive.ObjectID, bool) error
+	RecordAttempt(primitive.ObjectID, bool, int) (*models.Webhook, error)
+	Enqueue(*models.WebhookDelivery) (bool, error)
+	ClaimDue(time.Time, time.Duration) (*models.WebhookDelivery, error)
+	UpdateDelivery(*models.WebhookDelivery) error
+	Deliveries(primitive.ObjectID, models.Page) ([]*models.WebhookDelivery, error)
+	Redeliver(primitive.ObjectID, primitive.ObjectID, time.Time) (*models.WebhookDelivery, error)
+}
+
+type eventSubscriber interface {
+	Subscribe(func(*models.Event) bool, string) (<-chan *models.Event, func(), error)
+}
+
+/*
+Отправляет события форума на вебхуки, зарегистрированные администраторами.
+Каждое событие сначала сохраняется доставкой в очереди, поэтому переживает перезапуск сервера
+и отправляется повторно, пока адрес не ответит 2xx или не кончатся попытки.
+*/
+type WebhookManager struct {
+	storage webhookRepo
+	users   moderatorRepo
+	client  *http.Client
+}
+
+func NewWebhookManager(storage webhookRepo, users moderatorRepo, client *http.Client) *WebhookManager {
+	return &WebhookManager{
+		storage: storage,
+		users:   users,
+		client:  client,
+	}
+}
+
+// Возвращает подпись body ключом secret, которую получатель проверяет по заголовку X-Forum-Signature
+func webhookSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Регистрирует вебхук, в ответе единственный раз возвращается ключ подписи
+func (wm *WebhookManager) Create(input *models.WebhookInput, admin *models.Author) (*models.Webhook, error) {
+	target, err := url.Parse(input.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") {
+		return nil, errBadWebhookURL
+	}
+	if len(input.Events) == 0 {
+		return nil, errBadWebhookEvent
+	}
+	for _, event := range input.Events {
+		if !slices.Contains(models.WebhookEvents, event) {
+			return nil, errBadWebhookEvent
+		}
+	}
+	if slices.Contains(input.Events, models.WebhookVoteThreshold) && input.Threshold <= 0 {
+		return nil, errNoThreshold
+	}
+	err = checkAdmin(wm.users, admin)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+	webhook := &models.Webhook{
+		ID:        primitive.NewObjectID(),
+		URL:       input.URL,
+		Secret:    secret,
+		Events:    input.Events,
+		Threshold: input.Threshold,
+		Active:    true,
+		CreatedBy: admin.ID,
+		Created:   time.Now(),
+	}
+	err = wm.storage.Create(webhook)
+	if err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// Возвращает все вебхуки без ключей подписи
+func (wm *WebhookManager) Find(admin *models.Author) ([]*models.Webhook, error) {
+	err := checkAdmin(wm.users, admin)
+	if err != nil {
+		return nil, err
+	}
+	webhooks, err := wm.storage.Find()
+	if err != nil {
+		return nil, err
+	}
+	for _, webhook := range webhooks {
+		webhook.Secret = ""
+	}
+	return webhooks, nil
+}
+
+// Удаляет вебхук webhookID вместе с журналом доставок
+func (wm *WebhookManager) Delete(webhookIDStr string, admin *models.Author) error {
+	webhookID, err := wm.target(webhookIDStr, admin)
+	if err != nil {
+		return err
+	}
+	return wm.storage.Delete(webhookID)
+}
+
+// Включает или выключает вебхук webhookID. Выключенному вебхуку события не отправляются.
+func (wm *WebhookManager) SetActive(webhookIDStr string, active bool, admin *models.Author) error {
+	webhookID, err := wm.target(webhookIDStr, admin)
+	if err != nil {
+		return err
+	}
+	return wm.storage.SetActive(webhookID, active)
+}
+
+// Возвращает страницу журнала доставок вебхука webhookID
+func (wm *WebhookManager) Deliveries(webhookIDStr string, admin *models.Author, page models.Page) ([]*models.WebhookDelivery, error) {
+	webhookID, err := wm.target(webhookIDStr, admin)
+	if err != nil {
+		return nil, err
+	}
+	return wm.storage.Deliveries(webhookID, page)
+}
+
+// Отправляет доставку deliveryID вебхука webhookID заново, попытки считаются с начала
+func (wm *WebhookManager) Redeliver(webhookIDStr, deliveryIDStr string, admin *models.Author) (*models.WebhookDelivery, error) {
+	webhookID, err := wm.target(webhookIDStr, admin)
+	if err != nil {
+		return nil, err
+	}
+	deliveryID, err := primitive.ObjectIDFromHex(deliveryIDStr)
+	if err != nil {
+		return nil, err
+	}
+	return wm.storage.Redeliver(webhookID, deliveryID, time.Now())
+}
+
+// Проверяет права admin и возвращает id вебхука
+func (wm *WebhookManager) target(webhookIDStr string, admin *models.Author) (primitive.ObjectID, error) {
+	webhookID, err := primitive.ObjectIDFromHex(webhookIDStr)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	return webhookID, checkAdmin(wm.users, admin)
+}
+
+/*
+Ставит в очередь доставки event всем включенным вебхукам, подписанным на него.
+post.votes превращается в post.threshold для вебхуков, порог которых рейтинг поста достиг,
+такое событие доставляется один раз на пост. Повторно полученное событие не дублируется:
+доставка определяется ключом события из outbox, а для событий без него - id в потоке событий.
+*/
+func (wm *WebhookManager) Dispatch(event *models.Event, now time.Time) error {
+	if event.Type == models.EventPostVotes {
+		return wm.dispatchThreshold(event, now)
+	}
+
+	webhooks, err := wm.storage.Active(event.Type)
+	if err != nil {
+		return err
+	}
+	for _, webhook := range webhooks {
+		err = wm.enqueue(webhook, eventKey(event), event, now)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Возвращает ключ, одинаковый у всех публикаций события
+func eventKey(event *models.Event) string {
+	if event.Key != "" {
+		return event.Key
+	}
+	return event.ID
+}
+
+func (wm *WebhookManager) dispatchThreshold(event *models.Event, now time.Time) error {
+	votes := &models.VotesData{}
+	err := json.Unmarshal(event.Data, votes)
+	if err != nil {
+		return err
+	}
+	webhooks, err := wm.storage.Active(models.WebhookVoteThreshold)
+	if err != nil {
+		return err
+	}
+
+	thresholdEvent := *event
+	thresholdEvent.Type = models.WebhookVoteThreshold
+	for _, webhook := range webhooks {
+		if webhook.Threshold <= 0 || votes.Score < webhook.Threshold {
+			continue
+		}
+		key := fmt.Sprintf("%s:%s:%d", models.WebhookVoteThreshold, event.PostID.Hex(), webhook.Threshold)
+		err = wm.enqueue(webhook, key, &thresholdEvent, now)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (wm *WebhookManager) enqueue(webhook *models.Webhook, key string, event *models.Event, now time.Time) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = wm.storage.Enqueue(&models.WebhookDelivery{
+		ID:          primitive.NewObjectID(),
+		WebhookID:   webhook.ID,
+		Key:         key,
+		Event:       event.Type,
+		Payload:     payload,
+		Status:      models.DeliveryPending,
+		NextAttempt: now,
+		Created:     now,
+	})
+	return err
+}
+
+/*
+Отправляет все доставки, время попытки которых наступило к now. Ошибка одной доставки
+не останавливает остальные, ошибки возвращаются вместе. Возвращает количество попыток.
+*/
+func (wm *WebhookManager) DeliverDue(now time.Time) (int, error) {
+	attempts := 0
+	errs := make([]error, 0)
+	for {
+		delivery, err := wm.storage.ClaimDue(now, webhookLease)
+		if err != nil {
+			return attempts, errors.Join(append(errs, err)...)
+		}
+		if delivery == nil {
+			return attempts, errors.Join(errs...)
+		}
+		err = wm.deliver(delivery, now)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		attempts++
+	}
+}
+
+/*
+Выполняет одну попытку доставки. При неудаче следующая попытка откладывается с экспоненциальной задержкой,
+после webhookMaxAttempts попыток доставка считается неудавшейся и ее можно только отправить заново.
+*/
+func (wm *WebhookManager) deliver(delivery *models.WebhookDelivery, now time.Time) error {
+	webhook, err := wm.storage.FindOne(delivery.WebhookID)
+	if err != nil {
+		// вебхук удален или не загрузился, попытка считается неудачной, чтобы доставка не занимала очередь
+		delivery.Attempts++
+		setAttemptResult(delivery, now, err)
+		return errors.Join(err, wm.storage.UpdateDelivery(delivery))
+	}
+	if !webhook.Active {
+		delivery.Status = models.DeliveryFailed
+		delivery.Error = "webhook is disabled"
+		return wm.storage.UpdateDelivery(delivery)
+	}
+
+	delivery.Attempts++
+	delivery.StatusCode, err = wm.send(webhook, delivery)
+	ok := err == nil
+	setAttemptResult(delivery, now, err)
+
+	err = wm.storage.UpdateDelivery(delivery)
+	if err != nil {
+		return err
+	}
+	_, err = wm.storage.RecordAttempt(webhook.ID, ok, webhookDisableAfter)
+	return err
+}
+
+// Записывает в delivery результат попытки, err == nil - доставлено
+func setAttemptResult(delivery *models.WebhookDelivery, now time.Time, err error) {
+	switch {
+	case err == nil:
+		delivery.Status = models.DeliveryDelivered
+		delivery.Error = ""
+		delivery.Delivered = &now
+	case delivery.Attempts >= webhookMaxAttempts:
+		delivery.Status = models.DeliveryFailed
+		delivery.Error = err.Error()
+	default:
+		delivery.Error = err.Error()
+		delivery.NextAttempt = now.Add(retryDelay(delivery.Attempts))
+	}
+}
+
+// Отправляет подписанное тело доставки, ответ не 2xx считается ошибкой
+func (wm *WebhookManager) send(webhook *models.Webhook, delivery *models.WebhookDelivery) (int, error) {
+	request, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(webhookSignatureHeader, webhookSignature(webhook.Secret, delivery.Payload))
+	request.Header.Set(webhookEventHeader, delivery.Event)
+	request.Header.Set(webhookDeliveryHeader, delivery.ID.Hex())
+
+	response, err := wm.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return response.StatusCode, fmt.Errorf("unexpected status %d", response.StatusCode)
+	}
+	return response.StatusCode, nil
+}
+
+// Задержка перед попыткой после attempts неудачных
+func retryDelay(attempts int) time.Duration {
+	delay := webhookBackoff
+	for i := 1; i < attempts && delay < webhookMaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, webhookMaxBackoff)
+}
+
+/*
+Получает события форума и ставит их в очередь доставки. Если подписка закрылась из-за того,
+что очередь не успевала за событиями, подписывается снова с последнего события,
+пропущенные события отдаст история. Событие, которое не удалось поставить в очередь,
+повторяется через dispatchRetryDelay, ошибки пишутся в logger.
+Возвращает ошибку, только если подписаться не удалось.
+*/
+func (wm *WebhookManager) Run(events eventSubscriber, logger *slog.Logger) error {
+	lastEventID := ""
+	for {
+		subscription, cancel, err := events.Subscribe(isWebhookEvent, lastEventID)
+		if err != nil {
+			return err
+		}
+		for event := range subscription {
+			for {
+				err = wm.Dispatch(event, time.Now())
+				if err == nil {
+					break
+				}
+				logger.Error("dispatch webhook event", "task", "webhook.Dispatch", "err", err)
+				time.Sleep(dispatchRetryDelay)
+			}
+			lastEventID = event.ID
+		}
+		cancel()
+	}
+}
+
+// Раз в interval отправляет доставки, время которых наступило. Ошибки пишутся в logger и не прерывают работу.
+func (wm *WebhookManager) RunDelivery(interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		_, err := wm.DeliverDue(time.Now())
+		if err != nil {
+			logger.Error("deliver webhooks", "task", "webhook.DeliverDue", "err", err)
+		}
+	}
+}
+
+func isWebhookEvent(event *models.Event) bool {
+	return event.Type == models.EventPostVotes || slices.Contains(models.WebhookEvents, event.Type)
+}
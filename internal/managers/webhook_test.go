@@ -0,0 +1,423 @@
+package managers
+
+import (
+	"encoding/json"
+	"errors"
+	"forum/internal/models"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Вебхуки и очередь доставок в памяти, ClaimDue отдает доставки по порядку создания
+type memoryWebhooks struct {
+	webhooks   map[primitive.ObjectID]*models.Webhook
+	deliveries []*models.WebhookDelivery
+}
+
+func (mw *memoryWebhooks) Create(webhook *models.Webhook) error {
+	mw.webhooks[webhook.ID] = webhook
+	return nil
+}
+
+func (mw *memoryWebhooks) Find() ([]*models.Webhook, error) {
+	webhooks := make([]*models.Webhook, 0)
+	for _, webhook := range mw.webhooks {
+		copied := *webhook
+		webhooks = append(webhooks, &copied)
+	}
+	return webhooks, nil
+}
+
+func (mw *memoryWebhooks) Active(eventType string) ([]*models.Webhook, error) {
+	webhooks := make([]*models.Webhook, 0)
+	for _, webhook := range mw.webhooks {
+		for _, event := range webhook.Events {
+			if webhook.Active && event == eventType {
+				webhooks = append(webhooks, webhook)
+			}
+		}
+	}
+	return webhooks, nil
+}
+
+var errNoWebhook = errors.New("webhook not found")
+
+func (mw *memoryWebhooks) FindOne(webhookID primitive.ObjectID) (*models.Webhook, error) {
+	webhook, ok := mw.webhooks[webhookID]
+	if !ok {
+		return nil, errNoWebhook
+	}
+	return webhook, nil
+}
+
+func (mw *memoryWebhooks) Delete(webhookID primitive.ObjectID) error {
+	delete(mw.webhooks, webhookID)
+	return nil
+}
+
+func (mw *memoryWebhooks) SetActive(webhookID primitive.ObjectID, active bool) error {
+	mw.webhooks[webhookID].Active = active
+	mw.webhooks[webhookID].Failures = 0
+	return nil
+}
+
+func (mw *memoryWebhooks) RecordAttempt(webhookID primitive.ObjectID, ok bool, disableAfter int) (*models.Webhook, error) {
+	webhook := mw.webhooks[webhookID]
+	if ok {
+		webhook.Failures = 0
+		return webhook, nil
+	}
+	webhook.Failures++
+	if webhook.Failures >= disableAfter {
+		webhook.Active = false
+	}
+	return webhook, nil
+}
+
+func (mw *memoryWebhooks) Enqueue(delivery *models.WebhookDelivery) (bool, error) {
+	for _, item := range mw.deliveries {
+		if item.WebhookID == delivery.WebhookID && item.Key == delivery.Key {
+			return false, nil
+		}
+	}
+	mw.deliveries = append(mw.deliveries, delivery)
+	return true, nil
+}
+
+func (mw *memoryWebhooks) ClaimDue(now time.Time, lease time.Duration) (*models.WebhookDelivery, error) {
+	for _, item := range mw.deliveries {
+		if item.Status == models.DeliveryPending && !item.NextAttempt.After(now) {
+			item.NextAttempt = now.Add(lease)
+			copied := *item
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (mw *memoryWebhooks) UpdateDelivery(delivery *models.WebhookDelivery) error {
+	for i, item := range mw.deliveries {
+		if item.ID == delivery.ID {
+			mw.deliveries[i] = delivery
+		}
+	}
+	return nil
+}
+
+func (mw *memoryWebhooks) Deliveries(webhookID primitive.ObjectID, page models.Page) ([]*models.WebhookDelivery, error) {
+	return mw.deliveries, nil
+}
+
+func (mw *memoryWebhooks) Redeliver(webhookID, deliveryID primitive.ObjectID, now time.Time) (*models.WebhookDelivery, error) {
+	for _, item := range mw.deliveries {
+		if item.ID == deliveryID {
+			item.Status, item.Attempts, item.NextAttempt = models.DeliveryPending, 0, now
+			return item, nil
+		}
+	}
+	return nil, errNoPost
+}
+
+// Получатель вебхуков: запоминает запросы и отвечает status
+type webhookReceiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (wr *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+	wr.requests = append(wr.requests, r)
+	wr.bodies = append(wr.bodies, body)
+	w.WriteHeader(wr.status)
+}
+
+func newWebhookTest(t *testing.T, status int, input *models.WebhookInput) (*WebhookManager, *memoryWebhooks, *webhookReceiver, *models.Webhook) {
+	receiver := &webhookReceiver{status: status}
+	server := httptest.NewServer(receiver)
+	t.Cleanup(server.Close)
+
+	admin := &models.Author{ID: primitive.NewObjectID(), Username: "admin"}
+	users := &memoryUsers{users: map[string]*models.User{
+		"admin": {ID: admin.ID.Hex(), Username: "admin", Role: models.RoleAdmin},
+	}}
+	storage := &memoryWebhooks{webhooks: make(map[primitive.ObjectID]*models.Webhook)}
+	webhookManager := NewWebhookManager(storage, users, server.Client())
+
+	input.URL = server.URL
+	webhook, err := webhookManager.Create(input, admin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return webhookManager, storage, receiver, webhook
+}
+
+func TestWebhookDelivery(t *testing.T) {
+	input := &models.WebhookInput{Events: []string{models.EventPostCreated}}
+	webhookManager, storage, receiver, webhook := newWebhookTest(t, http.StatusNoContent, input)
+	if webhook.Secret == "" {
+		t.Fatal("secret not generated")
+	}
+
+	now := time.Now()
+	post := &models.Post{ID: primitive.NewObjectID(), Category: "music"}
+	event, _ := models.NewEvent(models.EventPostCreated, post, nil)
+	event.ID = "1-0"
+	other, _ := models.NewEvent(models.EventCommentCreated, post, nil)
+	other.ID = "2-0"
+
+	// событие, полученное дважды, и событие без подписки дают одну доставку
+	for _, item := range []*models.Event{event, event, other} {
+		err := webhookManager.Dispatch(item, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	sent, err := webhookManager.DeliverDue(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != 1 || len(receiver.requests) != 1 {
+		t.Fatalf("want one delivery, have %d sent, %d received", sent, len(receiver.requests))
+	}
+
+	request, body := receiver.requests[0], receiver.bodies[0]
+	if request.Header.Get(webhookSignatureHeader) != webhookSignature(webhook.Secret, body) {
+		t.Errorf("bad signature %q", request.Header.Get(webhookSignatureHeader))
+	}
+	if request.Header.Get(webhookEventHeader) != models.EventPostCreated {
+		t.Errorf("bad event header %q", request.Header.Get(webhookEventHeader))
+	}
+	received := &models.Event{}
+	err = json.Unmarshal(body, received)
+	if err != nil || received.ID != event.ID || received.PostID != post.ID {
+		t.Errorf("unexpected payload %s, %v", body, err)
+	}
+
+	delivery := storage.deliveries[0]
+	if delivery.Status != models.DeliveryDelivered || delivery.Attempts != 1 || delivery.StatusCode != http.StatusNoContent {
+		t.Errorf("unexpected delivery %+v", delivery)
+	}
+}
+
+func TestWebhookRetry(t *testing.T) {
+	input := &models.WebhookInput{Events: []string{models.EventPostDeleted}}
+	webhookManager, storage, receiver, webhook := newWebhookTest(t, http.StatusInternalServerError, input)
+
+	now := time.Now()
+	event, _ := models.NewEvent(models.EventPostDeleted, &models.Post{ID: primitive.NewObjectID()}, nil)
+	event.ID = "1-0"
+	err := webhookManager.Dispatch(event, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// задержка удваивается после каждой неудачной попытки
+	for attempt := 1; attempt <= 3; attempt++ {
+		sent, err := webhookManager.DeliverDue(now)
+		if err != nil || sent != 1 {
+			t.Fatalf("attempt %d: sent %d, %v", attempt, sent, err)
+		}
+		delivery := storage.deliveries[0]
+		delay := delivery.NextAttempt.Sub(now)
+		if delivery.Status != models.DeliveryPending || delay != retryDelay(attempt) {
+			t.Errorf("attempt %d: unexpected delivery %+v", attempt, delivery)
+		}
+		sent, _ = webhookManager.DeliverDue(now)
+		if sent != 0 {
+			t.Errorf("attempt %d: retried before backoff", attempt)
+		}
+		now = delivery.NextAttempt
+	}
+	if retryDelay(2) != 2*webhookBackoff || retryDelay(100) != webhookMaxBackoff {
+		t.Errorf("unexpected backoff %v, %v", retryDelay(2), retryDelay(100))
+	}
+
+	// после последней попытки доставка неудачна, повторить ее можно вручную
+	for attempt := 4; attempt <= webhookMaxAttempts; attempt++ {
+		webhookManager.DeliverDue(now)
+		now = storage.deliveries[0].NextAttempt
+	}
+	delivery := storage.deliveries[0]
+	if delivery.Status != models.DeliveryFailed || delivery.Attempts != webhookMaxAttempts || delivery.StatusCode != http.StatusInternalServerError {
+		t.Errorf("unexpected delivery %+v", delivery)
+	}
+	if len(receiver.requests) != webhookMaxAttempts {
+		t.Errorf("want %d requests, have %d", webhookMaxAttempts, len(receiver.requests))
+	}
+
+	receiver.status = http.StatusOK
+	admin := &models.Author{Username: "admin"}
+	_, err = webhookManager.Redeliver(webhook.ID.Hex(), delivery.ID.Hex(), admin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	webhookManager.DeliverDue(time.Now())
+	if storage.deliveries[0].Status != models.DeliveryDelivered || webhook.Failures != 0 {
+		t.Errorf("redelivery failed: %+v, failures %d", storage.deliveries[0], webhook.Failures)
+	}
+}
+
+func TestWebhookDisable(t *testing.T) {
+	input := &models.WebhookInput{Events: []string{models.EventPostCreated}}
+	webhookManager, storage, _, webhook := newWebhookTest(t, http.StatusBadGateway, input)
+
+	// неудачные попытки разных доставок подряд выключают вебхук
+	now := time.Now()
+	for i := 0; i < webhookDisableAfter; i++ {
+		event, _ := models.NewEvent(models.EventPostCreated, &models.Post{ID: primitive.NewObjectID()}, nil)
+		event.ID = primitive.NewObjectID().Hex()
+		webhookManager.Dispatch(event, now)
+	}
+	webhookManager.DeliverDue(now)
+	if webhook.Active || webhook.Failures != webhookDisableAfter {
+		t.Fatalf("webhook not disabled: %+v", webhook)
+	}
+
+	// выключенному вебхуку новые события не ставятся, ожидающие доставки не отправляются
+	event, _ := models.NewEvent(models.EventPostCreated, &models.Post{ID: primitive.NewObjectID()}, nil)
+	event.ID = "new"
+	webhookManager.Dispatch(event, now)
+	if len(storage.deliveries) != webhookDisableAfter {
+		t.Errorf("event queued for disabled webhook")
+	}
+	sent, _ := webhookManager.DeliverDue(now.Add(webhookMaxBackoff))
+	if sent != webhookDisableAfter {
+		t.Errorf("want %d pending deliveries closed, have %d", webhookDisableAfter, sent)
+	}
+	for _, delivery := range storage.deliveries {
+		if delivery.Status != models.DeliveryFailed {
+			t.Errorf("delivery of disabled webhook not failed: %+v", delivery)
+		}
+	}
+}
+
+func TestWebhookThreshold(t *testing.T) {
+	input := &models.WebhookInput{Events: []string{models.WebhookVoteThreshold}, Threshold: 10}
+	webhookManager, storage, receiver, _ := newWebhookTest(t, http.StatusOK, input)
+
+	post := &models.Post{ID: primitive.NewObjectID()}
+	for i, score := range []int{9, 10, 11, 12} {
+		event, _ := models.NewEvent(models.EventPostVotes, post, models.VotesData{Score: score})
+		event.ID = primitive.NewObjectID().Hex()
+		err := webhookManager.Dispatch(event, time.Now())
+		if err != nil {
+			t.Fatalf("case %d: %v", i, err)
+		}
+	}
+	webhookManager.DeliverDue(time.Now())
+
+	if len(storage.deliveries) != 1 || len(receiver.requests) != 1 {
+		t.Fatalf("want one threshold delivery, have %d", len(storage.deliveries))
+	}
+	received := &models.Event{}
+	json.Unmarshal(receiver.bodies[0], received)
+	votes := &models.VotesData{}
+	json.Unmarshal(received.Data, votes)
+	if received.Type != models.WebhookVoteThreshold || votes.Score != 10 {
+		t.Errorf("unexpected payload %s", receiver.bodies[0])
+	}
+
+	_, err := webhookManager.Create(&models.WebhookInput{URL: "http://example.com", Events: []string{models.WebhookVoteThreshold}}, &models.Author{Username: "admin"})
+	if err != errNoThreshold {
+		t.Errorf("want errNoThreshold, have %v", err)
+	}
+	_, err = webhookManager.Create(&models.WebhookInput{URL: "ftp://example.com", Events: []string{models.EventPostCreated}}, &models.Author{Username: "admin"})
+	if err != errBadWebhookURL {
+		t.Errorf("want errBadWebhookURL, have %v", err)
+	}
+}
+
+// Очередь доставок, которая отказывает в постановке failures раз
+type failingWebhooks struct {
+	*memoryWebhooks
+	failures int
+}
+
+func (fw *failingWebhooks) Enqueue(delivery *models.WebhookDelivery) (bool, error) {
+	if fw.failures > 0 {
+		fw.failures--
+		return false, errors.New("enqueue failed")
+	}
+	return fw.memoryWebhooks.Enqueue(delivery)
+}
+
+// Отдает события первой подписке, следующие подписки завершаются ошибкой
+type replaySubscriber struct {
+	events  []*models.Event
+	lastIDs []string
+}
+
+var errSubscriptionClosed = errors.New("subscription closed")
+
+func (rs *replaySubscriber) Subscribe(filter func(*models.Event) bool, lastEventID string) (<-chan *models.Event, func(), error) {
+	rs.lastIDs = append(rs.lastIDs, lastEventID)
+	if len(rs.lastIDs) > 1 {
+		return nil, nil, errSubscriptionClosed
+	}
+	subscription := make(chan *models.Event, len(rs.events))
+	for _, event := range rs.events {
+		subscription <- event
+	}
+	close(subscription)
+	return subscription, func() {}, nil
+}
+
+func TestWebhookRun(t *testing.T) {
+	defer func(delay time.Duration) { dispatchRetryDelay = delay }(dispatchRetryDelay)
+	dispatchRetryDelay = 0
+
+	input := &models.WebhookInput{Events: []string{models.EventPostCreated}}
+	webhookManager, storage, _, _ := newWebhookTest(t, http.StatusNoContent, input)
+	webhookManager.storage = &failingWebhooks{memoryWebhooks: storage, failures: 2}
+
+	post := &models.Post{ID: primitive.NewObjectID(), Category: "music"}
+	event, _ := models.NewEvent(models.EventPostCreated, post, nil)
+	event.ID = "1-0"
+	events := &replaySubscriber{events: []*models.Event{event}}
+
+	// событие, которое не удалось поставить в очередь, не теряется
+	err := webhookManager.Run(events, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != errSubscriptionClosed {
+		t.Fatalf("want errSubscriptionClosed, have %v", err)
+	}
+	if len(storage.deliveries) != 1 {
+		t.Fatalf("want one delivery, have %d", len(storage.deliveries))
+	}
+	if events.lastIDs[1] != event.ID {
+		t.Errorf("want resubscribe from %q, have %q", event.ID, events.lastIDs[1])
+	}
+}
+
+func TestWebhookDeliverAfterError(t *testing.T) {
+	input := &models.WebhookInput{Events: []string{models.EventPostCreated}}
+	webhookManager, storage, receiver, webhook := newWebhookTest(t, http.StatusOK, input)
+
+	// первая доставка принадлежит удаленному вебхуку и не должна задерживать вторую
+	now := time.Now()
+	orphan := &models.WebhookDelivery{ID: primitive.NewObjectID(), WebhookID: primitive.NewObjectID(), Status: models.DeliveryPending, NextAttempt: now}
+	delivery := &models.WebhookDelivery{ID: primitive.NewObjectID(), WebhookID: webhook.ID, Status: models.DeliveryPending, NextAttempt: now}
+	storage.deliveries = append(storage.deliveries, orphan, delivery)
+
+	sent, err := webhookManager.DeliverDue(now)
+	if !errors.Is(err, errNoWebhook) {
+		t.Errorf("want errNoWebhook, have %v", err)
+	}
+	if sent != 1 || len(receiver.requests) != 1 || storage.deliveries[1].Status != models.DeliveryDelivered {
+		t.Fatalf("second delivery not sent: sent %d, %+v", sent, storage.deliveries[1])
+	}
+	failed := storage.deliveries[0]
+	if failed.Attempts != 1 || failed.Error != errNoWebhook.Error() || failed.NextAttempt != now.Add(retryDelay(1)) {
+		t.Errorf("failed delivery not rescheduled: %+v", failed)
+	}
+}
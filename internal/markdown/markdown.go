@@ -0,0 +1,89 @@
+package markdown
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"forum/internal/models"
+	"sync"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+)
+
+/*
+Версия рендера, сохраняется вместе с HTML. При изменении рендера или политики очистки
+версия увеличивается, и сохраненный HTML старой версии перерисовывается при чтении.
+*/
+const Version = 1
+
+var (
+	// CommonMark, сырой HTML из исходника goldmark не пропускает
+	renderer = goldmark.New()
+	// allowlist для пользовательского контента: без скриптов, обработчиков событий и javascript: ссылок
+	policy = bluemonday.UGCPolicy().RequireNoFollowOnLinks(true)
+
+	// сколько отрисованных текстов хранится в памяти
+	cacheSize = 10000
+	cache     = &renderCache{items: make(map[[sha256.Size]byte]string)}
+)
+
+type renderCache struct {
+	mu    sync.Mutex
+	items map[[sha256.Size]byte]string
+}
+
+func (rc *renderCache) get(key [sha256.Size]byte) (string, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	html, ok := rc.items[key]
+	return html, ok
+}
+
+func (rc *renderCache) set(key [sha256.Size]byte, html string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	// кэш просто очищается при переполнении, тексты рендерятся заново при следующем чтении
+	if len(rc.items) >= cacheSize {
+		rc.items = make(map[[sha256.Size]byte]string)
+	}
+	rc.items[key] = html
+}
+
+// Рендерит source из CommonMark в очищенный HTML. Результат кэшируется по содержимому source.
+func Render(source string) string {
+	key := sha256.Sum256([]byte(source))
+	if html, ok := cache.get(key); ok {
+		return html
+	}
+
+	buf := &bytes.Buffer{}
+	err := renderer.Convert([]byte(source), buf)
+	if err != nil {
+		// исходник всегда можно показать как текст
+		buf.Reset()
+		buf.WriteString("<p>" + bluemonday.StrictPolicy().Sanitize(source) + "</p>")
+	}
+	html := policy.Sanitize(buf.String())
+
+	cache.set(key, html)
+	return html
+}
+
+// Заполняет HTML текста поста и его комментариев, если он отсутствует или отрисован старой версией
+func RenderPost(post *models.Post) {
+	if post.Text != "" && (post.TextHTML == "" || post.RenderVersion != Version) {
+		post.TextHTML = Render(post.Text)
+		post.RenderVersion = Version
+	}
+	for i := range post.Comments {
+		RenderComment(&post.Comments[i])
+	}
+}
+
+// Заполняет HTML комментария, если он отсутствует или отрисован старой версией
+func RenderComment(comment *models.Comment) {
+	if comment.Body != "" && (comment.BodyHTML == "" || comment.RenderVersion != Version) {
+		comment.BodyHTML = Render(comment.Body)
+		comment.RenderVersion = Version
+	}
+}
@@ -0,0 +1,78 @@
+package markdown
+
+import (
+	"forum/internal/models"
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	cases := []struct {
+		name      string
+		source    string
+		contains  []string
+		forbidden []string
+	}{
+		{
+			name:     "commonmark",
+			source:   "# Title\n\n*emphasis* and `code`\n\n- item",
+			contains: []string{"<h1>Title</h1>", "<em>emphasis</em>", "<code>code</code>", "<li>item</li>"},
+		},
+		{
+			name:      "raw script",
+			source:    "hello <script>alert(1)</script>",
+			forbidden: []string{"<script", "alert(1)</script>"},
+		},
+		{
+			name:      "event handler",
+			source:    `<img src="x.png" onerror="alert(1)">`,
+			forbidden: []string{"onerror"},
+		},
+		{
+			name:      "javascript url",
+			source:    "[click](javascript:alert(1))",
+			contains:  []string{"click"},
+			forbidden: []string{"javascript:"},
+		},
+		{
+			name:     "safe link",
+			source:   "[forum](https://example.com)",
+			contains: []string{`href="https://example.com"`, `rel="nofollow"`},
+		},
+	}
+
+	for _, item := range cases {
+		html := Render(item.source)
+		for _, part := range item.contains {
+			if !strings.Contains(html, part) {
+				t.Errorf("%s: want %q in %q", item.name, part, html)
+			}
+		}
+		for _, part := range item.forbidden {
+			if strings.Contains(html, part) {
+				t.Errorf("%s: unexpected %q in %q", item.name, part, html)
+			}
+		}
+	}
+}
+
+func TestRenderPost(t *testing.T) {
+	post := &models.Post{
+		Text: "**bold**",
+		Comments: []models.Comment{
+			{Body: "_old_", BodyHTML: "<p>stale</p>", RenderVersion: Version - 1},
+			{Body: "_current_", BodyHTML: "<p>kept</p>", RenderVersion: Version},
+		},
+	}
+	RenderPost(post)
+
+	if post.TextHTML != "<p><strong>bold</strong></p>\n" || post.RenderVersion != Version {
+		t.Errorf("unexpected post html %q", post.TextHTML)
+	}
+	if post.Comments[0].BodyHTML != "<p><em>old</em></p>\n" {
+		t.Errorf("old version must be rerendered, have %q", post.Comments[0].BodyHTML)
+	}
+	if post.Comments[1].BodyHTML != "<p>kept</p>" {
+		t.Errorf("current version must be kept, have %q", post.Comments[1].BodyHTML)
+	}
+}
@@ -0,0 +1,144 @@
+/*
+Метрики Prometheus: запросы к API, обращения к хранилищам, сессии, ошибки аутентификации
+и бизнес-счетчики. Отдаются хендлером Handler на /metrics.
+*/
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "forum"
+
+// Хранилища для метки store
+const (
+	MySQL = "mysql"
+	Mongo = "mongo"
+	Redis = "redis"
+)
+
+// Причины ошибок аутентификации для метки reason
+const (
+	AuthLogin = "login"
+	AuthToken = "token"
+)
+
+var (
+	registry = prometheus.NewRegistry()
+
+	requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route template, method and status.",
+	}, []string{"route", "method", "status"})
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route template, method and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	dbDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_call_duration_seconds",
+		Help:      "Storage call latency by store and storage method.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"store", "method"})
+
+	authFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "auth_failures_total",
+		Help:      "Failed logins and rejected session tokens.",
+	}, []string{"reason"})
+
+	postsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "posts_created_total",
+		Help:      "Created posts, including drafts.",
+	})
+	votes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "votes_total",
+		Help:      "Votes on posts by action: upvote, downvote or unvote.",
+	}, []string{"action"})
+	commentsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "comments_created_total",
+		Help:      "Created comments.",
+	})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		requests,
+		requestDuration,
+		dbDuration,
+		authFailures,
+		postsCreated,
+		votes,
+		commentsCreated,
+	)
+}
+
+// Хендлер /metrics
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// Учитывает запрос к пути с шаблоном route, завершившийся со статусом status за duration
+func ObserveRequest(route, method string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	requests.WithLabelValues(route, method, code).Inc()
+	requestDuration.WithLabelValues(route, method, code).Observe(duration.Seconds())
+}
+
+/*
+Учитывает время обращения к хранилищу store методом method, начатого в start.
+Вызывается отложенно в начале метода хранилища: defer metrics.ObserveDB(metrics.Mongo, "post.FindOne", time.Now())
+*/
+func ObserveDB(store, method string, start time.Time) {
+	dbDuration.WithLabelValues(store, method).Observe(time.Since(start).Seconds())
+}
+
+/*
+Регистрирует метрику активных сессий, count вызывается при каждом сборе метрик.
+Если count вернул ошибку, значение метрики - 0.
+*/
+func RegisterSessions(count func() (int64, error)) {
+	registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active_sessions",
+		Help:      "Sessions that have not expired.",
+	}, func() float64 {
+		sessions, err := count()
+		if err != nil {
+			return 0
+		}
+		return float64(sessions)
+	}))
+}
+
+// Учитывает ошибку аутентификации по причине reason
+func AuthFailure(reason string) {
+	authFailures.WithLabelValues(reason).Inc()
+}
+
+func PostCreated() {
+	postsCreated.Inc()
+}
+
+func Voted(action string) {
+	votes.WithLabelValues(action).Inc()
+}
+
+func CommentCreated() {
+	commentsCreated.Inc()
+}
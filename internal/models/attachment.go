@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Путь, по которому отдаются загруженные файлы
+const AttachmentPath = "/api/attachments/"
+
+// Загруженное изображение. Key и ThumbKey - ключи файлов в хранилище.
+type Attachment struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id"`
+	OwnerID     primitive.ObjectID `json:"-" bson:"ownerID"`
+	Key         string             `json:"-" bson:"key"`
+	ThumbKey    string             `json:"-" bson:"thumbKey"`
+	ContentType string             `json:"contentType" bson:"contentType"`
+	Size        int                `json:"size" bson:"size"`
+	Width       int                `json:"width" bson:"width"`
+	Height      int                `json:"height" bson:"height"`
+	Created     time.Time          `json:"created" bson:"created"`
+}
+
+// Изображение поста типа image
+type Image struct {
+	ID           primitive.ObjectID `json:"id" bson:"id"`
+	URL          string             `json:"url" bson:"url"`
+	ThumbnailURL string             `json:"thumbnailUrl" bson:"thumbnailUrl"`
+	Width        int                `json:"width" bson:"width"`
+	Height       int                `json:"height" bson:"height"`
+}
+
+// Возвращает описание изображения для поста
+func (a *Attachment) Image() *Image {
+	return &Image{
+		ID:           a.ID,
+		URL:          AttachmentPath + a.Key,
+		ThumbnailURL: AttachmentPath + a.ThumbKey,
+		Width:        a.Width,
+		Height:       a.Height,
+	}
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Действия модераторов и администраторов, которые попадают в журнал
+const (
+	AuditPostDelete      = "post.delete"
+	AuditCommentDelete   = "comment.delete"
+	AuditPostRestore     = "post.restore"
+	AuditCommentRestore  = "comment.restore"
+	AuditPostPin         = "post.pin"
+	AuditPostUnpin       = "post.unpin"
+	AuditPostLock        = "post.lock"
+	AuditPostUnlock      = "post.unlock"
+	AuditReportDismiss   = "report.dismiss"
+	AuditReportRemove    = "report.remove"
+	AuditReportBan       = "report.ban"
+	AuditUserBan         = "user.ban"
+	AuditUserSuspend     = "user.suspend"
+	AuditUserUnsuspend   = "user.unsuspend"
+	AuditUserShadowban   = "user.shadowban"
+	AuditUserUnshadowban = "user.unshadowban"
+
+	AuditCategoryModeratorAdd    = "category.moderator.add"
+	AuditCategoryModeratorRemove = "category.moderator.remove"
+	AuditCategoryRules           = "category.rules"
+)
+
+// Типы объектов действия
+const (
+	AuditTargetPost     = "post"
+	AuditTargetComment  = "comment"
+	AuditTargetUser     = "user"
+	AuditTargetCategory = "category"
+)
+
+/*
+Запись журнала модерации. Before - состояние объекта до действия в JSON,
+чтобы удаленное содержимое можно было восстановить вручную. Записи только добавляются.
+*/
+type AuditEntry struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id"`
+	ActorID    primitive.ObjectID `json:"actorID" bson:"actorID"`
+	ActorName  string             `json:"actorName" bson:"actorName"`
+	Action     string             `json:"action" bson:"action"`
+	TargetType string             `json:"targetType" bson:"targetType"`
+	TargetID   string             `json:"targetID" bson:"targetID"`
+	Reason     string             `json:"reason,omitempty" bson:"reason,omitempty"`
+	Before     string             `json:"before,omitempty" bson:"before,omitempty"`
+	Created    time.Time          `json:"created" bson:"created"`
+}
+
+// Условия выборки журнала, пустые поля не учитываются
+type AuditFilter struct {
+	ActorID    *primitive.ObjectID
+	Action     string
+	TargetType string
+	TargetID   string
+	From       *time.Time
+	To         *time.Time
+}
+
+// Создает запись о действии actor над объектом targetType с targetID, before сериализуется в JSON
+func NewAuditEntry(actor *Author, action, targetType, targetID, reason string, before any) (*AuditEntry, error) {
+	entry := &AuditEntry{
+		ID:         primitive.NewObjectID(),
+		ActorID:    actor.ID,
+		ActorName:  actor.Username,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Reason:     reason,
+		Created:    time.Now(),
+	}
+	if before == nil {
+		return entry, nil
+	}
+	raw, err := json.Marshal(before)
+	if err != nil {
+		return nil, err
+	}
+	entry.Before = string(raw)
+	return entry, nil
+}
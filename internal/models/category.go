@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+/*
+Правила публикации постов в категории: PostTypes - разрешенные типы постов (пустой - все),
+MinAccountDays - сколько дней должно пройти с регистрации автора.
+*/
+type CategoryRules struct {
+	Category       string   `json:"category"`
+	PostTypes      []string `json:"postTypes"`
+	MinAccountDays int      `json:"minAccountDays"`
+}
+
+func (cr *CategoryRules) AllowsType(postType string) bool {
+	if len(cr.PostTypes) == 0 {
+		return true
+	}
+	for _, item := range cr.PostTypes {
+		if item == postType {
+			return true
+		}
+	}
+	return false
+}
+
+// Момент, начиная с которого аккаунт, созданный в created, может публиковать посты в категории
+func (cr *CategoryRules) AllowedFrom(created time.Time) time.Time {
+	return created.AddDate(0, 0, cr.MinAccountDays)
+}
+
+type CategoryRulesInput struct {
+	PostTypes      []string `json:"postTypes" valid:"-"`
+	MinAccountDays int      `json:"minAccountDays" valid:"range(0|3650),optional"`
+}
@@ -6,13 +6,33 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+/*
+ParentID - комментарий, на который это ответ, nil для комментария к посту.
+Mentions и PostLinks - найденные в Body упоминания пользователей и ссылки на посты.
+BodyHTML - Body, отрисованный из markdown версией рендера RenderVersion.
+*/
 type Comment struct {
-	ID      primitive.ObjectID `json:"id" bson:"id"`
-	Author  Author             `json:"author" bson:"author"`
-	Body    string             `json:"body" bson:"body"`
-	Created time.Time          `json:"created" bson:"created"`
+	ID            primitive.ObjectID  `json:"id" bson:"id"`
+	Author        Author              `json:"author" bson:"author"`
+	Body          string              `json:"body" bson:"body"`
+	BodyHTML      string              `json:"bodyHtml,omitempty" bson:"bodyHtml,omitempty"`
+	RenderVersion int                 `json:"-" bson:"renderVersion,omitempty"`
+	Created       time.Time           `json:"created" bson:"created"`
+	ParentID      *primitive.ObjectID `json:"parentID,omitempty" bson:"parentID,omitempty"`
+	Mentions      []Author            `json:"mentions,omitempty" bson:"mentions,omitempty"`
+	PostLinks     []PostLink          `json:"postLinks,omitempty" bson:"postLinks,omitempty"`
+	Shadowed      bool                `json:"-" bson:"shadowed,omitempty"`
+	Held          string              `json:"held,omitempty" bson:"held,omitempty"`
+	DeletedAt     *time.Time          `json:"deletedAt,omitempty" bson:"deletedAt,omitempty"`
+	DeletedBy     *primitive.ObjectID `json:"deletedBy,omitempty" bson:"deletedBy,omitempty"`
+}
+
+// Комментарий в теневом бане или задержанный фильтрами (Held - причина) виден только автору
+func (c *Comment) AuthorOnly() bool {
+	return c.Shadowed || c.Held != ""
 }
 
 type CommentInput struct {
-	Body string `json:"comment" valid:"minstringlength(1)"`
+	Body     string `json:"comment" valid:"minstringlength(1)"`
+	ParentID string `json:"parentID" valid:"hexadecimal,optional"`
 }
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Ошибки, по которым хендлеры выбирают статус ответа
+var (
+	ErrForbidden    = errors.New("not enough rights")
+	ErrPostLocked   = errors.New("post is locked")
+	ErrPostArchived = errors.New("post is archived")
+)
+
+// Ошибка входа заблокированного пользователя, Until == nil - блокировка бессрочная
+type SuspendedError struct {
+	Reason string
+	Until  *time.Time
+}
+
+func (e *SuspendedError) Error() string {
+	if e.Until == nil {
+		return fmt.Sprintf("user is banned: %s", e.Reason)
+	}
+	return fmt.Sprintf("user is suspended until %s: %s", e.Until.UTC().Format(time.RFC3339), e.Reason)
+}
+
+// Содержимое отклонено фильтрами, Reason возвращается клиенту
+type RejectedError struct {
+	Reason string
+}
+
+func (e *RejectedError) Error() string {
+	return fmt.Sprintf("content rejected: %s", e.Reason)
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Типы событий, которые рассылаются клиентам
+const (
+	EventPostCreated     = "post.created"
+	EventPostDeleted     = "post.deleted"
+	EventPostRestored    = "post.restored"
+	EventPostVotes       = "post.votes"
+	EventPostPreview     = "post.preview"
+	EventPostState       = "post.state"
+	EventCommentCreated  = "comment.created"
+	EventCommentDeleted  = "comment.deleted"
+	EventCommentRestored = "comment.restored"
+	EventNotification    = "notification.created"
+)
+
+/*
+Событие об изменении поста. ID назначает хранилище событий при публикации,
+по нему клиент может продолжить получение событий после переподключения.
+Key - id записи outbox, одинаковый у повторных публикаций одного события, по нему получатели
+отбрасывают дубли. UserID - пользователь, которому адресовано событие (автор поста).
+*/
+type Event struct {
+	ID       string             `json:"id"`
+	Key      string             `json:"key,omitempty"`
+	Type     string             `json:"type"`
+	PostID   primitive.ObjectID `json:"postID"`
+	Category string             `json:"category,omitempty"`
+	UserID   string             `json:"userID,omitempty"`
+	Data     json.RawMessage    `json:"data,omitempty"`
+}
+
+// Возвращает false для событий, адресованных одному пользователю: они не рассылаются в общие потоки
+func (e *Event) Public() bool {
+	return e.Type != EventNotification
+}
+
+// Данные события post.votes
+type VotesData struct {
+	Score            int `json:"score"`
+	UpvotePercentage int `json:"upvotePercentage"`
+	Votes            int `json:"votes"`
+}
+
+// Данные события post.state
+type PostStateData struct {
+	Pinned   bool `json:"pinned"`
+	Locked   bool `json:"locked"`
+	Archived bool `json:"archived"`
+}
+
+// Данные события comment.deleted
+type CommentDeletedData struct {
+	CommentID primitive.ObjectID `json:"commentID"`
+}
+
+// Создает событие типа eventType с сериализованными data
+func NewEvent(eventType string, post *Post, data any) (*Event, error) {
+	event := &Event{
+		Type:     eventType,
+		PostID:   post.ID,
+		Category: post.Category,
+		UserID:   post.Author.ID.Hex(),
+	}
+	if data == nil {
+		return event, nil
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	event.Data = raw
+	return event, nil
+}
+
+// Сравнивает id событий вида "<ms>-<seq>", возвращает true, если a было раньше b
+func EventBefore(a, b string) bool {
+	aMs, aSeq := splitEventID(a)
+	bMs, bSeq := splitEventID(b)
+	if aMs != bMs {
+		return aMs < bMs
+	}
+	return aSeq < bSeq
+}
+
+func splitEventID(id string) (uint64, uint64) {
+	msStr, seqStr, _ := strings.Cut(id, "-")
+	ms, _ := strconv.ParseUint(msStr, 10, 64)
+	seq, _ := strconv.ParseUint(seqStr, 10, 64)
+	return ms, seq
+}
@@ -0,0 +1,27 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// Данные пользователя, собранные из всех хранилищ для выгрузки
+type UserExport struct {
+	User     Author          `json:"user"`
+	Posts    []*Post         `json:"posts"`
+	Comments []ExportComment `json:"comments"`
+	Votes    []ExportVote    `json:"votes"`
+	Ballots  []ExportBallot  `json:"ballots"`
+}
+
+type ExportComment struct {
+	PostID  primitive.ObjectID `json:"postID"`
+	Comment Comment            `json:"comment"`
+}
+
+type ExportVote struct {
+	PostID primitive.ObjectID `json:"postID"`
+	Vote   int                `json:"vote"`
+}
+
+// Участие в опросе поста PostID. Выбранные варианты не хранятся, поэтому не выгружаются.
+type ExportBallot struct {
+	PostID primitive.ObjectID `json:"postID"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Решения фильтров содержимого: пропустить, задержать до проверки модератором или отклонить
+const (
+	FilterAllow  = "allow"
+	FilterHold   = "hold"
+	FilterReject = "reject"
+)
+
+type FilterVerdict struct {
+	Action string `json:"action"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Текст поста или комментария, который проверяют фильтры
+type Content struct {
+	AuthorID primitive.ObjectID
+	Text     string
+	Created  time.Time
+}
+
+// Заголовок, текст и ссылка поста проверяются вместе
+func PostContent(post *Post) *Content {
+	text := post.Title
+	for _, part := range []string{post.Text, post.URL} {
+		if part != "" {
+			text += "\n" + part
+		}
+	}
+	return &Content{
+		AuthorID: post.Author.ID,
+		Text:     text,
+		Created:  post.Created,
+	}
+}
+
+func CommentContent(comment *Comment) *Content {
+	return &Content{
+		AuthorID: comment.Author.ID,
+		Text:     comment.Body,
+		Created:  comment.Created,
+	}
+}
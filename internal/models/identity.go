@@ -0,0 +1,15 @@
+package models
+
+// Данные пользователя, полученные от внешнего провайдера (OIDC)
+type ExternalIdentity struct {
+	Provider string
+	Subject  string
+	Username string
+	Email    string
+}
+
+// Состояние незавершенного входа через внешнего провайдера, хранится по параметру state
+type OAuthState struct {
+	Verifier string `json:"verifier"`
+	Nonce    string `json:"nonce"`
+}
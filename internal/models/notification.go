@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Типы уведомлений
+const (
+	NotificationComment   = "comment"
+	NotificationReply     = "reply"
+	NotificationMention   = "mention"
+	NotificationMilestone = "milestone"
+)
+
+/*
+Уведомление пользователя UserID. Actor - кто вызвал уведомление, nil для milestone.
+Для milestone в Score лежит достигнутый рейтинг поста.
+*/
+type Notification struct {
+	ID        primitive.ObjectID  `json:"id" bson:"_id"`
+	UserID    primitive.ObjectID  `json:"-" bson:"userID"`
+	Type      string              `json:"type" bson:"type"`
+	Actor     *Author             `json:"actor,omitempty" bson:"actor,omitempty"`
+	PostID    primitive.ObjectID  `json:"postID" bson:"postID"`
+	CommentID *primitive.ObjectID `json:"commentID,omitempty" bson:"commentID,omitempty"`
+	Score     int                 `json:"score,omitempty" bson:"score,omitempty"`
+	Read      bool                `json:"read" bson:"read"`
+	Created   time.Time           `json:"created" bson:"created"`
+}
+
+// Какие уведомления получает пользователь UserID
+type NotificationPreferences struct {
+	UserID    primitive.ObjectID `json:"-" bson:"_id"`
+	Comment   bool               `json:"comment" bson:"comment"`
+	Reply     bool               `json:"reply" bson:"reply"`
+	Mention   bool               `json:"mention" bson:"mention"`
+	Milestone bool               `json:"milestone" bson:"milestone"`
+}
+
+// Настройки по умолчанию - все уведомления включены
+func DefaultNotificationPreferences(userID primitive.ObjectID) *NotificationPreferences {
+	return &NotificationPreferences{
+		UserID:    userID,
+		Comment:   true,
+		Reply:     true,
+		Mention:   true,
+		Milestone: true,
+	}
+}
+
+// Возвращает true, если уведомления типа notificationType включены
+func (np *NotificationPreferences) Enabled(notificationType string) bool {
+	switch notificationType {
+	case NotificationComment:
+		return np.Comment
+	case NotificationReply:
+		return np.Reply
+	case NotificationMention:
+		return np.Mention
+	case NotificationMilestone:
+		return np.Milestone
+	}
+	return false
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+/*
+Строит события об изменении поста по его состоянию после изменения. События сохраняются в outbox
+в одной транзакции с изменением и публикуются отдельно, поэтому не теряются, если процесс упадет после записи.
+*/
+type OutboxEvents func(*Post) ([]*Event, error)
+
+/*
+Событие, ожидающее публикации. Пока relay публикует событие, AvailableAt сдвигается вперед,
+чтобы его не забрал relay другого экземпляра сервера. После публикации заполняется PublishedAt.
+*/
+type OutboxEntry struct {
+	ID          primitive.ObjectID `bson:"_id"`
+	Event       *Event             `bson:"event"`
+	Created     time.Time          `bson:"created"`
+	AvailableAt time.Time          `bson:"availableAt"`
+	PublishedAt *time.Time         `bson:"publishedAt,omitempty"`
+}
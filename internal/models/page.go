@@ -0,0 +1,20 @@
+package models
+
+// Параметры постраничной выдачи, Number начинается с 1
+type Page struct {
+	Number int
+	Size   int
+}
+
+// Возвращает границы страницы для списка длины total
+func (p Page) Bounds(total int) (int, int) {
+	start := (p.Number - 1) * p.Size
+	if start > total {
+		start = total
+	}
+	end := start + p.Size
+	if end > total {
+		end = total
+	}
+	return start, end
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+/*
+Опрос поста типа poll. Voters - проголосовавшие пользователи, по ним проверяется,
+что у каждого один бюллетень. Пока пользователь не проголосовал и опрос открыт,
+результаты скрываются (ResultsHidden), Voted и Closed вычисляются для текущего пользователя.
+*/
+type Poll struct {
+	Options       []PollOption         `json:"options" bson:"options"`
+	Multiple      bool                 `json:"multiple" bson:"multiple"`
+	Closes        *time.Time           `json:"closes,omitempty" bson:"closes,omitempty"`
+	Voters        []primitive.ObjectID `json:"-" bson:"voters"`
+	TotalVoters   int                  `json:"totalVoters" bson:"totalVoters"`
+	Voted         bool                 `json:"voted" bson:"-"`
+	Closed        bool                 `json:"closed" bson:"-"`
+	ResultsHidden bool                 `json:"resultsHidden" bson:"-"`
+}
+
+type PollOption struct {
+	ID    int    `json:"id" bson:"id"`
+	Text  string `json:"text" bson:"text"`
+	Votes int    `json:"votes" bson:"votes"`
+}
+
+type PollInput struct {
+	Options  []string   `json:"options" valid:"-"`
+	Multiple bool       `json:"multiple" valid:"-"`
+	Closes   *time.Time `json:"closes" valid:"-"`
+}
+
+// Бюллетень - id выбранных вариантов
+type BallotInput struct {
+	Options []int `json:"options" valid:"-"`
+}
+
+// Возвращает true, если опрос закрыт к моменту now
+func (p *Poll) IsClosed(now time.Time) bool {
+	return p.Closes != nil && !now.Before(*p.Closes)
+}
+
+// Заполняет поля для пользователя viewerID и скрывает результаты, если он их еще не должен видеть
+func (p *Poll) View(viewerID primitive.ObjectID, now time.Time) {
+	p.Closed = p.IsClosed(now)
+	p.Voted = false
+	for _, voter := range p.Voters {
+		if voter == viewerID {
+			p.Voted = true
+			break
+		}
+	}
+	if p.Voted || p.Closed {
+		return
+	}
+	p.ResultsHidden = true
+	p.TotalVoters = 0
+	for i := range p.Options {
+		p.Options[i].Votes = 0
+	}
+}
@@ -6,26 +6,81 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Состояния поста. У постов, созданных до появления черновиков, состояние пустое - они опубликованы.
+const (
+	PostPublished = "published"
+	PostDraft     = "draft"
+	PostScheduled = "scheduled"
+)
+
+// Допустимые категории, должны совпадать с тегом valid у PostInput.Category
+var Categories = []string{"music", "funny", "videos", "programming", "news", "fashion"}
+
+func IsCategory(category string) bool {
+	for _, item := range Categories {
+		if item == category {
+			return true
+		}
+	}
+	return false
+}
+
 type Post struct {
-	ID               primitive.ObjectID `json:"id" bson:"_id"`
-	Author           Author             `json:"author" bson:"author"`
-	Title            string             `json:"title" bson:"title"`
-	Text             string             `json:"text" bson:"text,omitempty" binding:"-"`
-	URL              string             `json:"url" bson:"url,omitempty" binding:"-"`
-	Type             string             `json:"type" bson:"type"`
-	Category         string             `json:"category" bson:"category"`
-	Created          time.Time          `json:"created" bson:"created"`
-	Score            int                `json:"score" bson:"score"`
-	Views            int                `json:"views" bson:"views"`
-	UpvotePercentage int                `json:"upvotePercentage" bson:"upvotePercentage"`
-	Votes            []Vote             `json:"votes" bson:"votes"`
-	Comments         []Comment          `json:"comments" bson:"comments"`
+	ID               primitive.ObjectID  `json:"id" bson:"_id"`
+	Author           Author              `json:"author" bson:"author"`
+	Title            string              `json:"title" bson:"title"`
+	Text             string              `json:"text" bson:"text,omitempty" binding:"-"`
+	TextHTML         string              `json:"textHtml,omitempty" bson:"textHtml,omitempty"`
+	RenderVersion    int                 `json:"-" bson:"renderVersion,omitempty"`
+	URL              string              `json:"url" bson:"url,omitempty" binding:"-"`
+	Preview          *LinkPreview        `json:"preview,omitempty" bson:"preview,omitempty"`
+	Image            *Image              `json:"image,omitempty" bson:"image,omitempty"`
+	Poll             *Poll               `json:"poll,omitempty" bson:"poll,omitempty"`
+	Type             string              `json:"type" bson:"type"`
+	Category         string              `json:"category" bson:"category"`
+	Status           string              `json:"status,omitempty" bson:"status,omitempty"`
+	PublishAt        *time.Time          `json:"publishAt,omitempty" bson:"publishAt,omitempty"`
+	Pinned           bool                `json:"pinned" bson:"pinned,omitempty"`
+	Locked           bool                `json:"locked" bson:"locked,omitempty"`
+	Archived         bool                `json:"archived" bson:"-"`
+	Shadowed         bool                `json:"-" bson:"shadowed,omitempty"`
+	Held             string              `json:"held,omitempty" bson:"held,omitempty"`
+	DeletedAt        *time.Time          `json:"deletedAt,omitempty" bson:"deletedAt,omitempty"`
+	DeletedBy        *primitive.ObjectID `json:"deletedBy,omitempty" bson:"deletedBy,omitempty"`
+	Created          time.Time           `json:"created" bson:"created"`
+	Score            int                 `json:"score" bson:"score"`
+	Views            int                 `json:"views" bson:"views"`
+	UpvotePercentage int                 `json:"upvotePercentage" bson:"upvotePercentage"`
+	Votes            []Vote              `json:"votes" bson:"votes"`
+	Comments         []Comment           `json:"comments" bson:"comments"`
+	Mentions         []Author            `json:"mentions,omitempty" bson:"mentions,omitempty"`
+	PostLinks        []PostLink          `json:"postLinks,omitempty" bson:"postLinks,omitempty"`
+}
+
+// Ссылка на другой пост из текста, Title нужен клиенту для отображения ссылки
+type PostLink struct {
+	ID    primitive.ObjectID `json:"id" bson:"id"`
+	Title string             `json:"title" bson:"title"`
 }
 
 type PostInput struct {
-	Title    string `json:"title" valid:"minstringlength(1)"`
-	Text     string `json:"text" valid:"minstringlength(4),optional"`
-	URL      string `json:"url" valid:"url,optional"`
-	Type     string `json:"type" valid:"in(link|text)"`
-	Category string `json:"category" valid:"in(music|funny|videos|programming|news|fashion)"`
+	Title     string     `json:"title" valid:"minstringlength(1)"`
+	Text      string     `json:"text" valid:"minstringlength(4),optional"`
+	URL       string     `json:"url" valid:"url,optional"`
+	Image     string     `json:"image" valid:"hexadecimal,optional"`
+	Poll      *PollInput `json:"poll" valid:"optional"`
+	Type      string     `json:"type" valid:"in(link|text|image|poll)"`
+	Category  string     `json:"category" valid:"in(music|funny|videos|programming|news|fashion)"`
+	Status    string     `json:"status" valid:"in(published|draft|scheduled),optional"`
+	PublishAt *time.Time `json:"publishAt" valid:"-"`
+}
+
+// Черновики и отложенные посты видны только автору
+func (p *Post) IsPublished() bool {
+	return p.Status == "" || p.Status == PostPublished
+}
+
+// Пост в теневом бане или задержанный фильтрами (Held - причина) виден только автору
+func (p *Post) AuthorOnly() bool {
+	return p.Shadowed || p.Held != ""
 }
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// Метаданные страницы по ссылке из поста типа link (OpenGraph, Twitter card, oEmbed)
+type LinkPreview struct {
+	Title       string    `json:"title,omitempty" bson:"title,omitempty"`
+	Description string    `json:"description,omitempty" bson:"description,omitempty"`
+	Image       string    `json:"image,omitempty" bson:"image,omitempty"`
+	SiteName    string    `json:"siteName,omitempty" bson:"siteName,omitempty"`
+	Fetched     time.Time `json:"fetched" bson:"fetched"`
+}
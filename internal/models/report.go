@@ -0,0 +1,77 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Состояния жалобы и решения модератора по ней
+const (
+	ReportOpen     = "open"
+	ReportResolved = "resolved"
+
+	// причина системной жалобы на содержимое, задержанное фильтрами
+	ReportFilter = "filter"
+
+	ResolveDismiss = "dismiss"
+	ResolveRemove  = "remove"
+	ResolveBan     = "ban"
+)
+
+/*
+Жалоба пользователя ReporterID на пост или комментарий (CommentID не nil).
+AuthorID - автор содержимого, нужен для блокировки, Category - категория поста,
+по ней жалоба попадает в очередь модераторов категории. После решения модератора
+жалоба получает состояние resolved и ссылку на решение DecisionID.
+*/
+type Report struct {
+	ID         primitive.ObjectID  `json:"id" bson:"_id"`
+	ReporterID primitive.ObjectID  `json:"reporterID" bson:"reporterID"`
+	PostID     primitive.ObjectID  `json:"postID" bson:"postID"`
+	CommentID  *primitive.ObjectID `json:"commentID,omitempty" bson:"commentID"`
+	Category   string              `json:"category" bson:"category"`
+	AuthorID   primitive.ObjectID  `json:"authorID" bson:"authorID"`
+	Reason     string              `json:"reason" bson:"reason"`
+	Details    string              `json:"details,omitempty" bson:"details,omitempty"`
+	Status     string              `json:"status" bson:"status"`
+	DecisionID *primitive.ObjectID `json:"decisionID,omitempty" bson:"decisionID,omitempty"`
+	Created    time.Time           `json:"created" bson:"created"`
+}
+
+type ReportInput struct {
+	Reason  string `json:"reason" valid:"in(spam|abuse|harassment|misinformation|other)"`
+	Details string `json:"details" valid:"maxstringlength(500),optional"`
+}
+
+// Элемент очереди модерации: открытые жалобы на один пост или комментарий
+type ReportGroup struct {
+	PostID    primitive.ObjectID  `json:"postID" bson:"postID"`
+	CommentID *primitive.ObjectID `json:"commentID,omitempty" bson:"commentID"`
+	Category  string              `json:"category" bson:"category"`
+	AuthorID  primitive.ObjectID  `json:"authorID" bson:"authorID"`
+	Count     int                 `json:"count" bson:"count"`
+	Reasons   []string            `json:"reasons" bson:"reasons"`
+	First     time.Time           `json:"first" bson:"first"`
+	Last      time.Time           `json:"last" bson:"last"`
+}
+
+type ResolveInput struct {
+	PostID    string `json:"postID" valid:"hexadecimal"`
+	CommentID string `json:"commentID" valid:"hexadecimal,optional"`
+	Action    string `json:"action" valid:"in(dismiss|remove|ban)"`
+	Note      string `json:"note" valid:"maxstringlength(500),optional"`
+}
+
+// Решение модератора по жалобам на пост или комментарий, история решений не удаляется
+type ReportDecision struct {
+	ID          primitive.ObjectID  `json:"id" bson:"_id"`
+	PostID      primitive.ObjectID  `json:"postID" bson:"postID"`
+	CommentID   *primitive.ObjectID `json:"commentID,omitempty" bson:"commentID"`
+	AuthorID    primitive.ObjectID  `json:"authorID" bson:"authorID"`
+	Action      string              `json:"action" bson:"action"`
+	Note        string              `json:"note,omitempty" bson:"note,omitempty"`
+	ModeratorID primitive.ObjectID  `json:"moderatorID" bson:"moderatorID"`
+	Reports     int                 `json:"reports" bson:"reports"`
+	Created     time.Time           `json:"created" bson:"created"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Закладка пользователя на пост, хранит только ссылку, поэтому переживает редактирование поста
+type SavedPost struct {
+	ID      primitive.ObjectID `json:"id" bson:"_id"`
+	UserID  primitive.ObjectID `json:"userID" bson:"userID"`
+	PostID  primitive.ObjectID `json:"postID" bson:"postID"`
+	Folder  string             `json:"folder" bson:"folder"`
+	Created time.Time          `json:"created" bson:"created"`
+}
+
+// Элемент списка закладок. Если пост удален, то Post пустой, а Deleted - true
+type SavedItem struct {
+	PostID  primitive.ObjectID `json:"postID"`
+	Folder  string             `json:"folder"`
+	Saved   time.Time          `json:"saved"`
+	Deleted bool               `json:"deleted"`
+	Post    *Post              `json:"post"`
+}
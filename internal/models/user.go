@@ -1,14 +1,80 @@
 package models
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"time"
 
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Имя, которое показывается вместо автора удаленного аккаунта
+const DeletedUsername = "[deleted]"
+
+// Роли пользователей, модераторы и администраторы могут закреплять и закрывать посты
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+/*
+Role, блокировка и теневой бан не читаются из запроса, они меняются только модераторами и администраторами.
+Banned - бессрочная блокировка, SuspendedUntil - временная, SuspendReason - причина любой из них.
+*/
 type User struct {
-	ID       string `json:"id" valid:"-"`
-	Password string `json:"password" valid:"minstringlength(8)"`
-	Username string `json:"username" valid:"-"`
+	ID             string     `json:"id" valid:"-"`
+	Password       string     `json:"password" valid:"minstringlength(8)"`
+	Username       string     `json:"username" valid:"-"`
+	Role           string     `json:"-" valid:"-"`
+	Banned         bool       `json:"-" valid:"-"`
+	SuspendedUntil *time.Time `json:"-" valid:"-"`
+	SuspendReason  string     `json:"-" valid:"-"`
+	Shadowbanned   bool       `json:"-" valid:"-"`
+}
+
+func (u *User) IsModerator() bool {
+	return u.Role == RoleModerator || u.Role == RoleAdmin
+}
+
+// Возвращает *SuspendedError, если к now пользователь заблокирован бессрочно или временно
+func (u *User) Suspension(now time.Time) error {
+	if u.Banned {
+		return &SuspendedError{Reason: u.SuspendReason}
+	}
+	if u.SuspendedUntil != nil && now.Before(*u.SuspendedUntil) {
+		return &SuspendedError{Reason: u.SuspendReason, Until: u.SuspendedUntil}
+	}
+	return nil
+}
+
+// Ограничения пользователя, сохраняются в журнал модерации как состояние до изменения
+type UserStatus struct {
+	Banned         bool       `json:"banned"`
+	SuspendedUntil *time.Time `json:"suspendedUntil,omitempty"`
+	SuspendReason  string     `json:"suspendReason,omitempty"`
+	Shadowbanned   bool       `json:"shadowbanned"`
+}
+
+func (u *User) Status() UserStatus {
+	return UserStatus{
+		Banned:         u.Banned,
+		SuspendedUntil: u.SuspendedUntil,
+		SuspendReason:  u.SuspendReason,
+		Shadowbanned:   u.Shadowbanned,
+	}
+}
+
+// Временная или бессрочная (Until не задан) блокировка пользователя
+type SuspendInput struct {
+	Until  *time.Time `json:"until" valid:"-"`
+	Reason string     `json:"reason" valid:"minstringlength(1),maxstringlength(255)"`
 }
 
+/*
+Shadowbanned не хранится ни в сессии, ни в постах: его выставляет проверка токена по базе,
+чтобы новое содержимое пользователя было видно только ему самому.
+*/
 type Author struct {
-	ID       primitive.ObjectID `json:"id" bson:"id"`
-	Username string             `json:"username" bson:"username"`
+	ID           primitive.ObjectID `json:"id" bson:"id"`
+	Username     string             `json:"username" bson:"username"`
+	Shadowbanned bool               `json:"-" bson:"-"`
 }
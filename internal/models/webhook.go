@@ -0,0 +1,68 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+/*
+Событие вебхука о том, что рейтинг поста достиг порога Threshold.
+Отправляется один раз на пост и вебхук.
+*/
+const WebhookVoteThreshold = "post.threshold"
+
+// События, на которые можно подписать вебхук
+var WebhookEvents = []string{EventPostCreated, EventCommentCreated, EventPostDeleted, WebhookVoteThreshold}
+
+// Состояния доставки
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+/*
+Адрес, на который отправляются события Events. Тело запроса подписывается HMAC-SHA256 с ключом Secret,
+ключ показывается только при создании. Failures - число неудачных попыток подряд,
+после нескольких неудач вебхук выключается (Active == false) до включения администратором.
+*/
+type Webhook struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id"`
+	URL       string             `json:"url" bson:"url"`
+	Secret    string             `json:"secret,omitempty" bson:"secret"`
+	Events    []string           `json:"events" bson:"events"`
+	Threshold int                `json:"threshold,omitempty" bson:"threshold,omitempty"`
+	Active    bool               `json:"active" bson:"active"`
+	Failures  int                `json:"failures" bson:"failures"`
+	CreatedBy primitive.ObjectID `json:"createdBy" bson:"createdBy"`
+	Created   time.Time          `json:"created" bson:"created"`
+}
+
+// Threshold обязателен, только если вебхук подписан на post.threshold
+type WebhookInput struct {
+	URL       string   `json:"url" valid:"requrl"`
+	Events    []string `json:"events" valid:"-"`
+	Threshold int      `json:"threshold" valid:"range(0|1000000),optional"`
+}
+
+/*
+Доставка события вебхуку. Key - ключ события (id события или порог для поста),
+по нему одно событие не доставляется дважды, даже если его получили несколько экземпляров сервера.
+NextAttempt - время следующей попытки для ожидающей доставки.
+*/
+type WebhookDelivery struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id"`
+	WebhookID   primitive.ObjectID `json:"webhookID" bson:"webhookID"`
+	Key         string             `json:"key" bson:"key"`
+	Event       string             `json:"event" bson:"event"`
+	Payload     json.RawMessage    `json:"payload" bson:"payload"`
+	Status      string             `json:"status" bson:"status"`
+	Attempts    int                `json:"attempts" bson:"attempts"`
+	NextAttempt time.Time          `json:"nextAttempt" bson:"nextAttempt"`
+	StatusCode  int                `json:"statusCode,omitempty" bson:"statusCode,omitempty"`
+	Error       string             `json:"error,omitempty" bson:"error,omitempty"`
+	Created     time.Time          `json:"created" bson:"created"`
+	Delivered   *time.Time         `json:"delivered,omitempty" bson:"delivered,omitempty"`
+}
@@ -0,0 +1,87 @@
+package local
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	errBadKey = errors.New("bad blob key")
+	errNoBlob = errors.New("no blob found")
+)
+
+// Хранит файлы в каталоге на диске, ключ - имя файла
+type blobStorage struct {
+	dir string
+}
+
+func NewBlobStorage(dir string) (*blobStorage, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+	return &blobStorage{
+		dir: dir,
+	}, nil
+}
+
+// Ключ не может содержать путь, чтобы не выйти за пределы каталога
+func (bs *blobStorage) path(key string) (string, error) {
+	if key == "" || key != filepath.Base(key) || strings.HasPrefix(key, ".") {
+		return "", errBadKey
+	}
+	return filepath.Join(bs.dir, key), nil
+}
+
+// Сохраняет data под ключом key. Файл пишется во временный и переименовывается, чтобы не отдавать недописанный.
+func (bs *blobStorage) Put(key string, data io.Reader, size int64, contentType string) error {
+	path, err := bs.path(key)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(bs.dir, ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, data)
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	err = tmp.Close()
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Открывает файл с ключом key
+func (bs *blobStorage) Get(key string) (io.ReadCloser, error) {
+	path, err := bs.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, errNoBlob
+	}
+	return file, err
+}
+
+// Удаляет файл с ключом key
+func (bs *blobStorage) Delete(key string) error {
+	path, err := bs.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return errNoBlob
+	}
+	return err
+}
@@ -0,0 +1,45 @@
+package local
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestBlobStorage(t *testing.T) {
+	storage, err := NewBlobStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = storage.Put("image.png", strings.NewReader("data"), 4, "image/png")
+	if err != nil {
+		t.Fatalf("want error nil, but have %v", err)
+	}
+
+	file, err := storage.Get("image.png")
+	if err != nil {
+		t.Fatalf("want error nil, but have %v", err)
+	}
+	data, _ := io.ReadAll(file)
+	file.Close()
+	if string(data) != "data" {
+		t.Errorf("want data, have %s", data)
+	}
+
+	for _, key := range []string{"../image.png", "dir/image.png", ".hidden", ""} {
+		_, err = storage.Get(key)
+		if err != errBadKey {
+			t.Errorf("%q: want errBadKey, have %v", key, err)
+		}
+	}
+
+	err = storage.Delete("image.png")
+	if err != nil {
+		t.Fatalf("want error nil, but have %v", err)
+	}
+	_, err = storage.Get("image.png")
+	if err != errNoBlob {
+		t.Errorf("want errNoBlob, have %v", err)
+	}
+}
@@ -0,0 +1,32 @@
+db = db.getSiblingDB("forum");
+
+db.saved.createIndex({ userID: 1, postID: 1 }, { unique: true });
+db.saved.createIndex({ userID: 1, folder: 1, created: -1 });
+
+db.notifications.createIndex({ userID: 1, created: -1 });
+db.notifications.createIndex({ userID: 1, read: 1 });
+db.notifications.createIndex({ userID: 1, type: 1, postID: 1, score: 1 });
+
+db.post.createIndex({ status: 1, publishAt: 1 }, { partialFilterExpression: { status: "scheduled" } });
+db.post.createIndex({ "author.id": 1, status: 1 });
+
+db.reports.createIndex({ reporterID: 1, postID: 1, commentID: 1 }, { unique: true, partialFilterExpression: { status: "open" } });
+db.reports.createIndex({ status: 1, postID: 1, commentID: 1 });
+db.report_decisions.createIndex({ created: -1 });
+db.audit_log.createIndex({ created: -1 });
+db.audit_log.createIndex({ actorID: 1, created: -1 });
+db.audit_log.createIndex({ targetType: 1, targetID: 1, created: -1 });
+
+db.post.createIndex({ deletedAt: 1 }, { partialFilterExpression: { deletedAt: { $exists: true } } });
+db.post.createIndex({ "comments.deletedAt": 1 }, { partialFilterExpression: { "comments.deletedAt": { $exists: true } } });
+
+db.post.createIndex({ "author.id": 1, created: -1 });
+db.post.createIndex({ "comments.author.id": 1, "comments.created": -1 });
+
+db.webhooks.createIndex({ active: 1, events: 1 });
+db.webhook_deliveries.createIndex({ webhookID: 1, key: 1 }, { unique: true });
+db.webhook_deliveries.createIndex({ status: 1, nextAttempt: 1 }, { partialFilterExpression: { status: "pending" } });
+db.webhook_deliveries.createIndex({ webhookID: 1, created: -1 });
+
+db.outbox.createIndex({ availableAt: 1, created: 1 });
+db.outbox.createIndex({ publishedAt: 1 }, { expireAfterSeconds: 86400 });
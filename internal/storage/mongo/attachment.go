@@ -0,0 +1,50 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"forum/internal/metrics"
+	"forum/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	errNoAttachment = errors.New("no attachment found")
+)
+
+type attachmentStorage struct {
+	attachments *mongo.Collection
+}
+
+func NewAttachmentStorage(db *mongo.Database, collectionName string) *attachmentStorage {
+	return &attachmentStorage{
+		attachments: db.Collection(collectionName),
+	}
+}
+
+// Сохраняет описание загруженного файла
+func (as *attachmentStorage) Create(attachment *models.Attachment) error {
+	defer metrics.ObserveDB(metrics.Mongo, "attachment.Create", time.Now())
+	ctx := context.Background()
+	_, err := as.attachments.InsertOne(ctx, attachment)
+	return err
+}
+
+// Возвращает описание файла по id
+func (as *attachmentStorage) FindOne(id primitive.ObjectID) (*models.Attachment, error) {
+	defer metrics.ObserveDB(metrics.Mongo, "attachment.FindOne", time.Now())
+	ctx := context.Background()
+	attachment := &models.Attachment{}
+	err := as.attachments.FindOne(ctx, bson.M{"_id": id}).Decode(attachment)
+	if err == mongo.ErrNoDocuments {
+		return nil, errNoAttachment
+	}
+	if err != nil {
+		return nil, err
+	}
+	return attachment, nil
+}
@@ -0,0 +1,69 @@
+package mongo
+
+import (
+	"forum/internal/models"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+const attachmentCollectionName = "attachments"
+
+func TestAttachments(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	attachment := &models.Attachment{
+		ID:          primitive.NewObjectID(),
+		OwnerID:     primitive.NewObjectID(),
+		Key:         "image.png",
+		ThumbKey:    "image_thumb.png",
+		ContentType: "image/png",
+		Size:        100,
+		Width:       10,
+		Height:      10,
+		Created:     time.Now().In(time.UTC).Round(time.Millisecond),
+	}
+
+	mt.Run("Create", func(mt *mtest.T) {
+		storage := NewAttachmentStorage(mt.DB, attachmentCollectionName)
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		err := storage.Create(attachment)
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	mt.Run("FindOne", func(mt *mtest.T) {
+		storage := NewAttachmentStorage(mt.DB, attachmentCollectionName)
+
+		data, err := bson.Marshal(attachment)
+		if err != nil {
+			t.Fatal(err)
+		}
+		attachmentBson := bson.D{}
+		err = bson.Unmarshal(data, &attachmentBson)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.attachments", mtest.FirstBatch, attachmentBson))
+		found, err := storage.FindOne(attachment.ID)
+		if err != nil {
+			t.Error(err)
+		}
+		if !reflect.DeepEqual(found, attachment) {
+			t.Errorf("\nwant: %v\nhave: %v", attachment, found)
+		}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.attachments", mtest.FirstBatch))
+		_, err = storage.FindOne(attachment.ID)
+		if err != errNoAttachment {
+			t.Errorf("want errNoAttachment, have %v", err)
+		}
+	})
+}
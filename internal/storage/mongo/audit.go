@@ -0,0 +1,99 @@
+package mongo
+
+import (
+	"context"
+	"forum/internal/metrics"
+	"forum/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Журнал модерации. Методов изменения и удаления записей нет намеренно.
+type auditStorage struct {
+	entries *mongo.Collection
+}
+
+func NewAuditStorage(db *mongo.Database, collectionName string) *auditStorage {
+	return &auditStorage{
+		entries: db.Collection(collectionName),
+	}
+}
+
+// Добавляет запись в журнал
+func (as *auditStorage) Append(entry *models.AuditEntry) error {
+	defer metrics.ObserveDB(metrics.Mongo, "audit.Append", time.Now())
+	ctx := context.Background()
+	_, err := as.entries.InsertOne(ctx, entry)
+	return err
+}
+
+// Возвращает страницу записей, подходящих под filter, новые первыми
+func (as *auditStorage) Find(filter models.AuditFilter, page models.Page) ([]*models.AuditEntry, error) {
+	defer metrics.ObserveDB(metrics.Mongo, "audit.Find", time.Now())
+	ctx := context.Background()
+	options := options.Find().
+		SetSort(bson.D{{Key: "created", Value: -1}}).
+		SetSkip(int64((page.Number - 1) * page.Size)).
+		SetLimit(int64(page.Size))
+	cursor, err := as.entries.Find(ctx, auditQuery(filter), options)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]*models.AuditEntry, 0)
+	err = cursor.All(ctx, &entries)
+	return entries, err
+}
+
+// Передает в fn все записи, подходящие под filter, от старых к новым, не загружая их в память целиком
+func (as *auditStorage) Each(filter models.AuditFilter, fn func(*models.AuditEntry) error) error {
+	defer metrics.ObserveDB(metrics.Mongo, "audit.Each", time.Now())
+	ctx := context.Background()
+	options := options.Find().SetSort(bson.D{{Key: "created", Value: 1}})
+	cursor, err := as.entries.Find(ctx, auditQuery(filter), options)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		entry := &models.AuditEntry{}
+		err = cursor.Decode(entry)
+		if err != nil {
+			return err
+		}
+		err = fn(entry)
+		if err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+func auditQuery(filter models.AuditFilter) bson.M {
+	query := bson.M{}
+	if filter.ActorID != nil {
+		query["actorID"] = *filter.ActorID
+	}
+	if filter.Action != "" {
+		query["action"] = filter.Action
+	}
+	if filter.TargetType != "" {
+		query["targetType"] = filter.TargetType
+	}
+	if filter.TargetID != "" {
+		query["targetID"] = filter.TargetID
+	}
+	created := bson.M{}
+	if filter.From != nil {
+		created["$gte"] = *filter.From
+	}
+	if filter.To != nil {
+		created["$lt"] = *filter.To
+	}
+	if len(created) > 0 {
+		query["created"] = created
+	}
+	return query
+}
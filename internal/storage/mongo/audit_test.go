@@ -0,0 +1,129 @@
+package mongo
+
+import (
+	"forum/internal/models"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+const auditCollectionName = "audit_log"
+
+func TestAudit(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("Append", func(mt *mtest.T) {
+		storage := NewAuditStorage(mt.DB, auditCollectionName)
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		err := storage.Append(newTestAuditEntry())
+		if err != nil {
+			t.Error(err)
+		}
+
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Index:   0,
+			Code:    11000,
+			Message: "duplicate key error",
+		}))
+		err = storage.Append(newTestAuditEntry())
+		if err == nil {
+			t.Error("expected error, but was nil")
+		}
+	})
+
+	mt.Run("Find", func(mt *mtest.T) {
+		storage := NewAuditStorage(mt.DB, auditCollectionName)
+		entry := newTestAuditEntry()
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.audit_log", mtest.FirstBatch, auditEntryBson(t, entry)))
+		entries, err := storage.Find(models.AuditFilter{Action: entry.Action}, models.Page{Number: 1, Size: 20})
+		if err != nil {
+			t.Error(err)
+		}
+		expected := []*models.AuditEntry{entry}
+		if !reflect.DeepEqual(entries, expected) {
+			t.Errorf("\nwant: %v\nhave: %v", expected, entries)
+		}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(primitive.E{Key: "ok", Value: 0}))
+		_, err = storage.Find(models.AuditFilter{}, models.Page{Number: 1, Size: 20})
+		if err == nil {
+			t.Error("expected error, but was nil")
+		}
+	})
+
+	mt.Run("Each", func(mt *mtest.T) {
+		storage := NewAuditStorage(mt.DB, auditCollectionName)
+		first := newTestAuditEntry()
+		second := newTestAuditEntry()
+
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(1, "foo.audit_log", mtest.FirstBatch, auditEntryBson(t, first)),
+			mtest.CreateCursorResponse(0, "foo.audit_log", mtest.NextBatch, auditEntryBson(t, second)),
+		)
+		entries := make([]*models.AuditEntry, 0)
+		err := storage.Each(models.AuditFilter{}, func(entry *models.AuditEntry) error {
+			entries = append(entries, entry)
+			return nil
+		})
+		if err != nil {
+			t.Error(err)
+		}
+		expected := []*models.AuditEntry{first, second}
+		if !reflect.DeepEqual(entries, expected) {
+			t.Errorf("\nwant: %v\nhave: %v", expected, entries)
+		}
+	})
+}
+
+func TestAuditQuery(t *testing.T) {
+	actorID := primitive.NewObjectID()
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	filter := models.AuditFilter{
+		ActorID:    &actorID,
+		TargetType: models.AuditTargetPost,
+		From:       &from,
+	}
+
+	expected := bson.M{
+		"actorID":    actorID,
+		"targetType": models.AuditTargetPost,
+		"created":    bson.M{"$gte": from},
+	}
+	query := auditQuery(filter)
+	if !reflect.DeepEqual(query, expected) {
+		t.Errorf("\nwant: %v\nhave: %v", expected, query)
+	}
+}
+
+func newTestAuditEntry() *models.AuditEntry {
+	return &models.AuditEntry{
+		ID:         primitive.NewObjectID(),
+		ActorID:    primitive.NewObjectID(),
+		ActorName:  "moderator",
+		Action:     models.AuditPostDelete,
+		TargetType: models.AuditTargetPost,
+		TargetID:   primitive.NewObjectID().Hex(),
+		Reason:     "spam",
+		Before:     `{"title":"spam"}`,
+		Created:    time.Now().In(time.UTC).Round(time.Millisecond),
+	}
+}
+
+func auditEntryBson(t *testing.T, entry *models.AuditEntry) bson.D {
+	entryBson := bson.D{}
+	data, err := bson.Marshal(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = bson.Unmarshal(data, &entryBson)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entryBson
+}
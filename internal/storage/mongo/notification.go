@@ -0,0 +1,155 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"forum/internal/metrics"
+	"forum/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	errNoNotification = errors.New("no notification found")
+)
+
+type notificationStorage struct {
+	notifications *mongo.Collection
+	preferences   *mongo.Collection
+}
+
+func NewNotificationStorage(db *mongo.Database, notificationCollection, preferenceCollection string) *notificationStorage {
+	return &notificationStorage{
+		notifications: db.Collection(notificationCollection),
+		preferences:   db.Collection(preferenceCollection),
+	}
+}
+
+// Сохраняет уведомление
+func (ns *notificationStorage) Create(notification *models.Notification) error {
+	defer metrics.ObserveDB(metrics.Mongo, "notification.Create", time.Now())
+	ctx := context.Background()
+	_, err := ns.notifications.InsertOne(ctx, notification)
+	return err
+}
+
+/*
+Сохраняет уведомление, только если у пользователя еще нет уведомления того же типа
+с тем же постом и рейтингом. Возвращает true, если уведомление создано.
+*/
+func (ns *notificationStorage) CreateOnce(notification *models.Notification) (bool, error) {
+	defer metrics.ObserveDB(metrics.Mongo, "notification.CreateOnce", time.Now())
+	ctx := context.Background()
+	filter := bson.M{
+		"userID": notification.UserID,
+		"type":   notification.Type,
+		"postID": notification.PostID,
+		"score":  notification.Score,
+	}
+	update := bson.M{
+		"$setOnInsert": bson.M{
+			"_id":     notification.ID,
+			"read":    notification.Read,
+			"created": notification.Created,
+		},
+	}
+	options := options.Update().SetUpsert(true)
+	result, err := ns.notifications.UpdateOne(ctx, filter, update, options)
+	if err != nil {
+		return false, err
+	}
+	return result.UpsertedCount > 0, nil
+}
+
+// Возвращает страницу уведомлений пользователя userID, новые первыми
+func (ns *notificationStorage) Find(userID primitive.ObjectID, unreadOnly bool, page models.Page) ([]*models.Notification, error) {
+	defer metrics.ObserveDB(metrics.Mongo, "notification.Find", time.Now())
+	ctx := context.Background()
+	filter := bson.M{"userID": userID}
+	if unreadOnly {
+		filter["read"] = false
+	}
+	options := options.Find().
+		SetSort(bson.D{{Key: "created", Value: -1}}).
+		SetSkip(int64((page.Number - 1) * page.Size)).
+		SetLimit(int64(page.Size))
+	cursor, err := ns.notifications.Find(ctx, filter, options)
+	if err != nil {
+		return nil, err
+	}
+	notifications := make([]*models.Notification, 0)
+	err = cursor.All(ctx, &notifications)
+	return notifications, err
+}
+
+// Возвращает количество непрочитанных уведомлений пользователя userID
+func (ns *notificationStorage) CountUnread(userID primitive.ObjectID) (int, error) {
+	defer metrics.ObserveDB(metrics.Mongo, "notification.CountUnread", time.Now())
+	ctx := context.Background()
+	filter := bson.M{
+		"userID": userID,
+		"read":   false,
+	}
+	count, err := ns.notifications.CountDocuments(ctx, filter)
+	return int(count), err
+}
+
+// Отмечает прочитанным уведомление id пользователя userID
+func (ns *notificationStorage) MarkRead(userID, id primitive.ObjectID) error {
+	defer metrics.ObserveDB(metrics.Mongo, "notification.MarkRead", time.Now())
+	ctx := context.Background()
+	filter := bson.M{
+		"_id":    id,
+		"userID": userID,
+	}
+	update := bson.M{"$set": bson.M{"read": true}}
+	result, err := ns.notifications.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errNoNotification
+	}
+	return nil
+}
+
+// Отмечает прочитанными все уведомления пользователя userID
+func (ns *notificationStorage) MarkAllRead(userID primitive.ObjectID) error {
+	defer metrics.ObserveDB(metrics.Mongo, "notification.MarkAllRead", time.Now())
+	ctx := context.Background()
+	filter := bson.M{
+		"userID": userID,
+		"read":   false,
+	}
+	update := bson.M{"$set": bson.M{"read": true}}
+	_, err := ns.notifications.UpdateMany(ctx, filter, update)
+	return err
+}
+
+// Возвращает настройки уведомлений пользователя userID, если их нет, то настройки по умолчанию
+func (ns *notificationStorage) Preferences(userID primitive.ObjectID) (*models.NotificationPreferences, error) {
+	defer metrics.ObserveDB(metrics.Mongo, "notification.Preferences", time.Now())
+	ctx := context.Background()
+	preferences := &models.NotificationPreferences{}
+	err := ns.preferences.FindOne(ctx, bson.M{"_id": userID}).Decode(preferences)
+	if err == mongo.ErrNoDocuments {
+		return models.DefaultNotificationPreferences(userID), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return preferences, nil
+}
+
+// Сохраняет настройки уведомлений
+func (ns *notificationStorage) SetPreferences(preferences *models.NotificationPreferences) error {
+	defer metrics.ObserveDB(metrics.Mongo, "notification.SetPreferences", time.Now())
+	ctx := context.Background()
+	options := options.Replace().SetUpsert(true)
+	_, err := ns.preferences.ReplaceOne(ctx, bson.M{"_id": preferences.UserID}, preferences, options)
+	return err
+}
@@ -0,0 +1,151 @@
+package mongo
+
+import (
+	"forum/internal/models"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+const (
+	notificationCollectionName = "notifications"
+	preferenceCollectionName   = "notification_preferences"
+)
+
+func TestNotifications(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("Create", func(mt *mtest.T) {
+		storage := NewNotificationStorage(mt.DB, notificationCollectionName, preferenceCollectionName)
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		err := storage.Create(newTestNotification())
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	mt.Run("CreateOnce", func(mt *mtest.T) {
+		storage := NewNotificationStorage(mt.DB, notificationCollectionName, preferenceCollectionName)
+		notification := newTestNotification()
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(
+			bson.E{Key: "n", Value: 1},
+			bson.E{Key: "upserted", Value: bson.A{bson.D{{Key: "index", Value: 0}, {Key: "_id", Value: notification.ID}}}},
+		))
+		created, err := storage.CreateOnce(notification)
+		if err != nil {
+			t.Error(err)
+		}
+		if !created {
+			t.Error("want created notification")
+		}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+		created, err = storage.CreateOnce(notification)
+		if err != nil {
+			t.Error(err)
+		}
+		if created {
+			t.Error("want existing notification")
+		}
+	})
+
+	mt.Run("Find", func(mt *mtest.T) {
+		storage := NewNotificationStorage(mt.DB, notificationCollectionName, preferenceCollectionName)
+		notification := newTestNotification()
+
+		data, err := bson.Marshal(notification)
+		if err != nil {
+			t.Fatal(err)
+		}
+		notificationBson := bson.D{}
+		err = bson.Unmarshal(data, &notificationBson)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.notifications", mtest.FirstBatch, notificationBson))
+		notifications, err := storage.Find(notification.UserID, true, models.Page{Number: 1, Size: 20})
+		if err != nil {
+			t.Error(err)
+		}
+		expected := []*models.Notification{notification}
+		if !reflect.DeepEqual(notifications, expected) {
+			t.Errorf("\nwant: %v\nhave: %v", expected, notifications)
+		}
+	})
+
+	mt.Run("MarkRead", func(mt *mtest.T) {
+		storage := NewNotificationStorage(mt.DB, notificationCollectionName, preferenceCollectionName)
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+		err := storage.MarkRead(primitive.NewObjectID(), primitive.NewObjectID())
+		if err != nil {
+			t.Error(err)
+		}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}))
+		err = storage.MarkRead(primitive.NewObjectID(), primitive.NewObjectID())
+		if err != errNoNotification {
+			t.Errorf("want errNoNotification, have %v", err)
+		}
+	})
+
+	mt.Run("Preferences", func(mt *mtest.T) {
+		storage := NewNotificationStorage(mt.DB, notificationCollectionName, preferenceCollectionName)
+		userID := primitive.NewObjectID()
+
+		// настроек нет - по умолчанию все включено
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.notification_preferences", mtest.FirstBatch))
+		preferences, err := storage.Preferences(userID)
+		if err != nil {
+			t.Error(err)
+		}
+		if !reflect.DeepEqual(preferences, models.DefaultNotificationPreferences(userID)) {
+			t.Errorf("want default preferences, have %v", preferences)
+		}
+
+		stored := bson.D{
+			{Key: "_id", Value: userID},
+			{Key: "comment", Value: false},
+			{Key: "reply", Value: true},
+			{Key: "mention", Value: true},
+			{Key: "milestone", Value: false},
+		}
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.notification_preferences", mtest.FirstBatch, stored))
+		preferences, err = storage.Preferences(userID)
+		if err != nil {
+			t.Error(err)
+		}
+		if preferences.Enabled(models.NotificationComment) || !preferences.Enabled(models.NotificationReply) {
+			t.Errorf("unexpected preferences %v", preferences)
+		}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+		err = storage.SetPreferences(preferences)
+		if err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func newTestNotification() *models.Notification {
+	commentID := primitive.NewObjectID()
+	return &models.Notification{
+		ID:     primitive.NewObjectID(),
+		UserID: primitive.NewObjectID(),
+		Type:   models.NotificationComment,
+		Actor: &models.Author{
+			ID:       primitive.NewObjectID(),
+			Username: "actor",
+		},
+		PostID:    primitive.NewObjectID(),
+		CommentID: &commentID,
+		Created:   time.Now().In(time.UTC).Round(time.Millisecond),
+	}
+}
@@ -0,0 +1,68 @@
+package mongo
+
+import (
+	"context"
+	"forum/internal/metrics"
+	"forum/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Записи outbox пишет postStorage в транзакциях с изменениями постов, здесь они только забираются relay
+type outboxStorage struct {
+	outbox *mongo.Collection
+}
+
+func NewOutboxStorage(db *mongo.Database, collectionName string) *outboxStorage {
+	return &outboxStorage{
+		outbox: db.Collection(collectionName),
+	}
+}
+
+/*
+Забирает самую старую неопубликованную запись, доступную к now, и откладывает ее на lease.
+Если relay упадет, не отметив запись опубликованной, по истечении lease ее заберет снова
+этот или другой экземпляр сервера. Если таких записей нет, возвращает nil.
+*/
+func (o *outboxStorage) Claim(now time.Time, lease time.Duration) (*models.OutboxEntry, error) {
+	defer metrics.ObserveDB(metrics.Mongo, "outbox.Claim", time.Now())
+	ctx := context.Background()
+	filter := bson.M{
+		"publishedAt": bson.M{"$exists": false},
+		"availableAt": bson.M{"$lte": now},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"availableAt": now.Add(lease),
+		},
+	}
+	options := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "created", Value: 1}, {Key: "_id", Value: 1}}).
+		SetReturnDocument(options.After)
+	entry := &models.OutboxEntry{}
+	err := o.outbox.FindOneAndUpdate(ctx, filter, update, options).Decode(entry)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// Отмечает запись id опубликованной в момент now. Опубликованные записи удаляет TTL-индекс.
+func (o *outboxStorage) MarkPublished(id primitive.ObjectID, now time.Time) error {
+	defer metrics.ObserveDB(metrics.Mongo, "outbox.MarkPublished", time.Now())
+	ctx := context.Background()
+	update := bson.M{
+		"$set": bson.M{
+			"publishedAt": now,
+		},
+	}
+	_, err := o.outbox.UpdateByID(ctx, id, update)
+	return err
+}
@@ -0,0 +1,58 @@
+package mongo
+
+import (
+	"forum/internal/models"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestOutbox(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("Claim", func(mt *mtest.T) {
+		storage := NewOutboxStorage(mt.DB, outboxCollectionName)
+		now := time.Now().In(time.UTC).Round(time.Millisecond)
+		entry := &models.OutboxEntry{
+			ID:          primitive.NewObjectID(),
+			Event:       &models.Event{Key: "key", Type: models.EventPostCreated, PostID: primitive.NewObjectID()},
+			Created:     now,
+			AvailableAt: now.Add(time.Minute),
+		}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "value", Value: toBsonD(t, entry)}))
+		claimed, err := storage.Claim(now, time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(claimed, entry) {
+			t.Errorf("\nwant: %v\nhave: %v", entry, claimed)
+		}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "value", Value: nil}))
+		claimed, err = storage.Claim(now, time.Minute)
+		if err != nil || claimed != nil {
+			t.Errorf("want nothing to relay, have %v, %v", claimed, err)
+		}
+	})
+
+	mt.Run("MarkPublished", func(mt *mtest.T) {
+		storage := NewOutboxStorage(mt.DB, outboxCollectionName)
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+		err := storage.MarkPublished(primitive.NewObjectID(), time.Now())
+		if err != nil {
+			t.Error(err)
+		}
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "update failed"}))
+		err = storage.MarkPublished(primitive.NewObjectID(), time.Now())
+		if err == nil {
+			t.Error("expected error, but was nil")
+		}
+	})
+}
@@ -3,7 +3,11 @@ package mongo
 import (
 	"context"
 	"errors"
+	"fmt"
+	"forum/internal/metrics"
 	"forum/internal/models"
+	"forum/internal/tracing"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -16,19 +20,94 @@ var (
 )
 
 type postStorage struct {
-	posts *mongo.Collection
+	posts   *mongo.Collection
+	outbox  *mongo.Collection
+	reports *mongo.Collection
 }
 
-func NewPostStorage(db *mongo.Database, collectionName string) *postStorage {
+func NewPostStorage(db *mongo.Database, collectionName, outboxCollectionName, reportCollectionName string) *postStorage {
 	collection := db.Collection(collectionName)
 
 	return &postStorage{
-		posts: collection,
+		posts:   collection,
+		outbox:  db.Collection(outboxCollectionName),
+		reports: db.Collection(reportCollectionName),
 	}
 }
 
+/*
+Выполняет write и сохраняет в outbox события, построенные events по его результату, в одной транзакции:
+либо сохраняется и изменение, и события, либо ничего. Если write не нашел пост (вернул nil)
+или events равен nil, в outbox ничего не пишется.
+*/
+func (p *postStorage) withOutbox(events models.OutboxEvents, write func(context.Context) (*models.Post, error)) (*models.Post, error) {
+	ctx := context.Background()
+	session, err := p.posts.Database().Client().StartSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.EndSession(ctx)
+
+	result, err := session.WithTransaction(ctx, func(ctx mongo.SessionContext) (interface{}, error) {
+		post, err := write(ctx)
+		if err != nil || post == nil || events == nil {
+			return post, err
+		}
+		list, err := events(post)
+		if err != nil || len(list) == 0 {
+			return post, err
+		}
+		now := time.Now()
+		entries := make([]interface{}, 0, len(list))
+		for _, event := range list {
+			entry := &models.OutboxEntry{
+				ID:          primitive.NewObjectID(),
+				Event:       event,
+				Created:     now,
+				AvailableAt: now,
+			}
+			event.Key = entry.ID.Hex()
+			entries = append(entries, entry)
+		}
+		_, err = p.outbox.InsertMany(ctx, entries)
+		return post, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	post, _ := result.(*models.Post)
+	return post, nil
+}
+
+// Обновляет пост по фильтру filter в транзакции withOutbox и возвращает его после обновления
+func (p *postStorage) updateWithOutbox(filter, update bson.M, events models.OutboxEvents) (*models.Post, error) {
+	return p.withOutbox(events, func(ctx context.Context) (*models.Post, error) {
+		return p.findOneAndUpdate(ctx, filter, update)
+	})
+}
+
+func (p *postStorage) findOneAndUpdate(ctx context.Context, filter, update bson.M) (*models.Post, error) {
+	options := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	post := &models.Post{}
+	err := p.posts.FindOneAndUpdate(ctx, filter, update, options).Decode(post)
+	if err != nil {
+		return nil, err
+	}
+	return post, nil
+}
+
+// Сохраняет жалобу фильтров на задержанное содержимое, nil - содержимое не задержано
+func (p *postStorage) insertReport(ctx context.Context, report *models.Report) error {
+	if report == nil {
+		return nil
+	}
+	_, err := p.reports.InsertOne(ctx, report)
+	return err
+}
+
 // Обновляет пост по  его postID
 func (p *postStorage) UpdateOne(postID primitive.ObjectID, update bson.M) (*models.Post, error) {
+	defer metrics.ObserveDB(metrics.Mongo, "post.UpdateOne", time.Now())
 	ctx := context.Background()
 	filter := bson.M{"_id": postID}
 	options := options.FindOneAndUpdate().SetReturnDocument(options.After)
@@ -37,26 +116,88 @@ func (p *postStorage) UpdateOne(postID primitive.ObjectID, update bson.M) (*mode
 	return post, err
 }
 
-// Создает новый пост
-func (p *postStorage) Create(post *models.Post) error {
-	ctx := context.Background()
-	_, err := p.posts.InsertOne(ctx, post)
+// Увеличивает счетчик просмотров поста postID и возвращает пост после обновления
+func (p *postStorage) View(ctx context.Context, postID primitive.ObjectID) (_ *models.Post, err error) {
+	defer metrics.ObserveDB(metrics.Mongo, "post.View", time.Now())
+	ctx, span := tracing.StartDB(ctx, tracing.Mongo, "post.View")
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+
+	filter := bson.M{"_id": postID}
+	update := bson.M{
+		"$inc": bson.M{
+			"views": 1,
+		},
+	}
+	options := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	post := &models.Post{}
+	err = p.posts.FindOneAndUpdate(ctx, filter, update, options).Decode(post)
+	if err != nil {
+		return nil, err
+	}
+	return post, nil
+}
+
+/*
+Создает новый пост, события о нем сохраняются в outbox вместе с постом. Жалоба фильтров report
+на задержанный пост сохраняется в той же транзакции, чтобы пост не остался задержанным вне очереди модерации.
+*/
+func (p *postStorage) Create(post *models.Post, report *models.Report, events models.OutboxEvents) error {
+	defer metrics.ObserveDB(metrics.Mongo, "post.Create", time.Now())
+	_, err := p.withOutbox(events, func(ctx context.Context) (*models.Post, error) {
+		_, err := p.posts.InsertOne(ctx, post)
+		if err != nil {
+			return nil, err
+		}
+		return post, p.insertReport(ctx, report)
+	})
 	return err
 }
 
-// Удаляет пост по его ID
-func (p *postStorage) Delete(postID primitive.ObjectID) error {
-	ctx := context.Background()
-	filter := bson.M{"_id": postID}
-	result, err := p.posts.DeleteOne(ctx, filter)
-	if result.DeletedCount == 0 {
+/*
+Помечает пост postID удаленным пользователем deletedBy в момент now. Документ остается в базе,
+пока его не удалит Purge, и может быть восстановлен.
+*/
+func (p *postStorage) Delete(postID, deletedBy primitive.ObjectID, now time.Time, events models.OutboxEvents) error {
+	defer metrics.ObserveDB(metrics.Mongo, "post.Delete", time.Now())
+	filter := bson.M{
+		"_id":       postID,
+		"deletedAt": bson.M{"$exists": false},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"deletedAt": now,
+			"deletedBy": deletedBy,
+		},
+	}
+	_, err := p.updateWithOutbox(filter, update, events)
+	if err == mongo.ErrNoDocuments {
 		return errNoPost
 	}
 	return err
 }
 
+// Снимает пометку об удалении с поста postID
+func (p *postStorage) Restore(postID primitive.ObjectID, events models.OutboxEvents) (*models.Post, error) {
+	defer metrics.ObserveDB(metrics.Mongo, "post.Restore", time.Now())
+	filter := bson.M{
+		"_id":       postID,
+		"deletedAt": bson.M{"$exists": true},
+	}
+	update := bson.M{
+		"$unset": bson.M{
+			"deletedAt": "",
+			"deletedBy": "",
+		},
+	}
+	return p.updateWithOutbox(filter, update, events)
+}
+
 // Возвращает все посты, удовлетворяющие filter.
 func (p *postStorage) Find(filter bson.M) ([]*models.Post, error) {
+	defer metrics.ObserveDB(metrics.Mongo, "post.Find", time.Now())
 	ctx := context.Background()
 	cursor, err := p.posts.Find(ctx, filter)
 	if err != nil {
@@ -67,8 +208,26 @@ func (p *postStorage) Find(filter bson.M) ([]*models.Post, error) {
 	return posts, err
 }
 
+// Возвращает страницу постов, удовлетворяющих filter, новые первыми
+func (p *postStorage) FindPage(filter bson.M, page models.Page) ([]*models.Post, error) {
+	defer metrics.ObserveDB(metrics.Mongo, "post.FindPage", time.Now())
+	ctx := context.Background()
+	options := options.Find().
+		SetSort(bson.D{{Key: "created", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64((page.Number - 1) * page.Size)).
+		SetLimit(int64(page.Size))
+	cursor, err := p.posts.Find(ctx, filter, options)
+	if err != nil {
+		return nil, err
+	}
+	posts := make([]*models.Post, 0)
+	err = cursor.All(ctx, &posts)
+	return posts, err
+}
+
 // Возвращает элемент по postID
 func (p *postStorage) FindOne(postID primitive.ObjectID) (*models.Post, error) {
+	defer metrics.ObserveDB(metrics.Mongo, "post.FindOne", time.Now())
 	ctx := context.Background()
 	filter := bson.M{"_id": postID}
 	post := &models.Post{}
@@ -78,33 +237,216 @@ func (p *postStorage) FindOne(postID primitive.ObjectID) (*models.Post, error) {
 	return post, nil
 }
 
-// Добавляет комментарий comment к посту с postID
-func (p *postStorage) AddComment(postID primitive.ObjectID, comment *models.Comment) (*models.Post, error) {
-	ctx := context.Background()
+// Добавляет комментарий comment к посту с postID, жалоба фильтров report сохраняется в той же транзакции
+func (p *postStorage) AddComment(postID primitive.ObjectID, comment *models.Comment, report *models.Report, events models.OutboxEvents) (*models.Post, error) {
+	defer metrics.ObserveDB(metrics.Mongo, "post.AddComment", time.Now())
 	filter := bson.M{"_id": postID}
 	update := bson.M{
 		"$push": bson.M{
 			"comments": comment,
 		},
 	}
-	options := options.FindOneAndUpdate().SetReturnDocument(options.After)
-	post := &models.Post{}
-	err := p.posts.FindOneAndUpdate(ctx, filter, update, options).Decode(post)
+	return p.withOutbox(events, func(ctx context.Context) (*models.Post, error) {
+		post, err := p.findOneAndUpdate(ctx, filter, update)
+		if err != nil {
+			return nil, err
+		}
+		return post, p.insertReport(ctx, report)
+	})
+}
+
+// Помечает комментарий commentID к посту с postID удаленным пользователем deletedBy в момент now
+func (p *postStorage) DeleteComment(postID, commentID, deletedBy primitive.ObjectID, now time.Time, events models.OutboxEvents) (*models.Post, error) {
+	defer metrics.ObserveDB(metrics.Mongo, "post.DeleteComment", time.Now())
+	filter := bson.M{
+		"_id": postID,
+		"comments": bson.M{"$elemMatch": bson.M{
+			"id":        commentID,
+			"deletedAt": bson.M{"$exists": false},
+		}},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"comments.$.deletedAt": now,
+			"comments.$.deletedBy": deletedBy,
+		},
+	}
+	return p.updateWithOutbox(filter, update, events)
+}
+
+// Снимает пометку об удалении с комментария commentID к посту с postID
+func (p *postStorage) RestoreComment(postID, commentID primitive.ObjectID, events models.OutboxEvents) (*models.Post, error) {
+	defer metrics.ObserveDB(metrics.Mongo, "post.RestoreComment", time.Now())
+	filter := bson.M{
+		"_id": postID,
+		"comments": bson.M{"$elemMatch": bson.M{
+			"id":        commentID,
+			"deletedAt": bson.M{"$exists": true},
+		}},
+	}
+	update := bson.M{
+		"$unset": bson.M{
+			"comments.$.deletedAt": "",
+			"comments.$.deletedBy": "",
+		},
+	}
+	return p.updateWithOutbox(filter, update, events)
+}
+
+// Снимает задержку фильтров с поста postID
+func (p *postStorage) Release(postID primitive.ObjectID, events models.OutboxEvents) (*models.Post, error) {
+	defer metrics.ObserveDB(metrics.Mongo, "post.Release", time.Now())
+	filter := bson.M{"_id": postID}
+	update := bson.M{
+		"$unset": bson.M{
+			"held": "",
+		},
+	}
+	return p.updateWithOutbox(filter, update, events)
+}
+
+// Снимает задержку фильтров с комментария commentID к посту с postID
+func (p *postStorage) ReleaseComment(postID, commentID primitive.ObjectID, events models.OutboxEvents) (*models.Post, error) {
+	defer metrics.ObserveDB(metrics.Mongo, "post.ReleaseComment", time.Now())
+	filter := bson.M{
+		"_id": postID,
+		"comments": bson.M{"$elemMatch": bson.M{
+			"id":   commentID,
+			"held": bson.M{"$exists": true},
+		}},
+	}
+	update := bson.M{
+		"$unset": bson.M{
+			"comments.$.held": "",
+		},
+	}
+	return p.updateWithOutbox(filter, update, events)
+}
+
+// Возвращает посты и комментарии автора authorID, созданные не раньше since, для фильтров содержимого
+func (p *postStorage) Recent(authorID primitive.ObjectID, since time.Time) ([]*models.Content, error) {
+	defer metrics.ObserveDB(metrics.Mongo, "post.Recent", time.Now())
+	created := bson.M{"$gte": since}
+	filter := bson.M{
+		"$or": bson.A{
+			bson.M{"author.id": authorID, "created": created},
+			bson.M{"comments": bson.M{"$elemMatch": bson.M{"author.id": authorID, "created": created}}},
+		},
+	}
+	posts, err := p.Find(filter)
 	if err != nil {
 		return nil, err
 	}
-	return post, nil
+
+	contents := make([]*models.Content, 0)
+	for _, post := range posts {
+		if post.Author.ID == authorID && !post.Created.Before(since) {
+			contents = append(contents, models.PostContent(post))
+		}
+		for i := range post.Comments {
+			comment := &post.Comments[i]
+			if comment.Author.ID == authorID && !comment.Created.Before(since) {
+				contents = append(contents, models.CommentContent(comment))
+			}
+		}
+	}
+	return contents, nil
 }
 
-// Удаляет комментарий comment к посту с postID
-func (p *postStorage) DeleteComment(postID, commentID primitive.ObjectID) (*models.Post, error) {
+/*
+Окончательно удаляет посты и комментарии, помеченные удаленными раньше before.
+Возвращает количество удаленных постов и постов, из которых удалены комментарии.
+*/
+func (p *postStorage) Purge(before time.Time) (int64, int64, error) {
+	defer metrics.ObserveDB(metrics.Mongo, "post.Purge", time.Now())
 	ctx := context.Background()
-	filter := bson.M{"_id": postID}
+	expired := bson.M{"$lt": before}
+
+	deleted, err := p.posts.DeleteMany(ctx, bson.M{"deletedAt": expired})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	filter := bson.M{"comments.deletedAt": expired}
 	update := bson.M{
 		"$pull": bson.M{
-			"comments": bson.M{"id": commentID},
+			"comments": bson.M{"deletedAt": expired},
 		},
 	}
+	updated, err := p.posts.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return deleted.DeletedCount, 0, err
+	}
+	return deleted.DeletedCount, updated.ModifiedCount, nil
+}
+
+// Удаляет черновики и отложенные посты автора authorID
+func (p *postStorage) DeleteUnpublished(authorID primitive.ObjectID) error {
+	defer metrics.ObserveDB(metrics.Mongo, "post.DeleteUnpublished", time.Now())
+	ctx := context.Background()
+	filter := bson.M{
+		"author.id": authorID,
+		"status":    bson.M{"$in": bson.A{models.PostDraft, models.PostScheduled}},
+	}
+	_, err := p.posts.DeleteMany(ctx, filter)
+	return err
+}
+
+// Заменяет автора у всех постов и комментариев пользователя с authorID на удаленного
+func (p *postStorage) AnonymizeAuthor(authorID primitive.ObjectID) error {
+	defer metrics.ObserveDB(metrics.Mongo, "post.AnonymizeAuthor", time.Now())
+	ctx := context.Background()
+	anonymous := models.Author{Username: models.DeletedUsername}
+
+	filter := bson.M{"author.id": authorID}
+	update := bson.M{
+		"$set": bson.M{
+			"author": anonymous,
+		},
+	}
+	_, err := p.posts.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	filter = bson.M{"comments.author.id": authorID}
+	update = bson.M{
+		"$set": bson.M{
+			"comments.$[comment].author": anonymous,
+		},
+	}
+	options := options.Update().SetArrayFilters(options.ArrayFilters{
+		Filters: []interface{}{bson.M{"comment.author.id": authorID}},
+	})
+	_, err = p.posts.UpdateMany(ctx, filter, update, options)
+	return err
+}
+
+/*
+Атомарно учитывает бюллетень userID за варианты choices. Документ обновляется, только если
+пользователь еще не голосовал и опрос не закрыт к now, поэтому параллельные запросы не портят счетчики.
+Если условие не выполнено, возвращается mongo.ErrNoDocuments.
+*/
+func (p *postStorage) CastBallot(postID, userID primitive.ObjectID, choices []int, now time.Time) (*models.Post, error) {
+	defer metrics.ObserveDB(metrics.Mongo, "post.CastBallot", time.Now())
+	ctx := context.Background()
+	filter := bson.M{
+		"_id":         postID,
+		"poll":        bson.M{"$exists": true},
+		"poll.voters": bson.M{"$ne": userID},
+		"$or": bson.A{
+			bson.M{"poll.closes": bson.M{"$exists": false}},
+			bson.M{"poll.closes": bson.M{"$gt": now}},
+		},
+	}
+	inc := bson.M{"poll.totalVoters": 1}
+	for _, choice := range choices {
+		inc[fmt.Sprintf("poll.options.%d.votes", choice)] = 1
+	}
+	update := bson.M{
+		"$push": bson.M{"poll.voters": userID},
+		"$inc":  inc,
+	}
 	options := options.FindOneAndUpdate().SetReturnDocument(options.After)
 	post := &models.Post{}
 	err := p.posts.FindOneAndUpdate(ctx, filter, update, options).Decode(post)
@@ -113,3 +455,50 @@ func (p *postStorage) DeleteComment(postID, commentID primitive.ObjectID) (*mode
 	}
 	return post, nil
 }
+
+/*
+Публикует черновик или отложенный пост postID автора authorID. Проверка состояния и обновление
+выполняются одной операцией, поэтому пост публикуется ровно один раз, даже если одновременно
+его публикуют автор и планировщик на другом экземпляре сервера.
+Если неопубликованного поста нет, возвращается nil без ошибки.
+*/
+func (p *postStorage) Publish(postID, authorID primitive.ObjectID, now time.Time, events models.OutboxEvents) (*models.Post, error) {
+	defer metrics.ObserveDB(metrics.Mongo, "post.Publish", time.Now())
+	filter := bson.M{
+		"_id":       postID,
+		"author.id": authorID,
+		"status":    bson.M{"$in": bson.A{models.PostDraft, models.PostScheduled}},
+		"deletedAt": bson.M{"$exists": false},
+	}
+	return p.publish(filter, now, events)
+}
+
+// Публикует один отложенный пост, время публикации которого наступило к now. Если таких нет, возвращает nil.
+func (p *postStorage) PublishDue(now time.Time, events models.OutboxEvents) (*models.Post, error) {
+	defer metrics.ObserveDB(metrics.Mongo, "post.PublishDue", time.Now())
+	filter := bson.M{
+		"status":    models.PostScheduled,
+		"publishAt": bson.M{"$lte": now},
+		"deletedAt": bson.M{"$exists": false},
+	}
+	return p.publish(filter, now, events)
+}
+
+// Меняет состояние поста под filter на published, время создания становится временем публикации
+func (p *postStorage) publish(filter bson.M, now time.Time, events models.OutboxEvents) (*models.Post, error) {
+	update := bson.M{
+		"$set": bson.M{
+			"status":  models.PostPublished,
+			"created": now,
+			"views":   0,
+		},
+		"$unset": bson.M{
+			"publishAt": "",
+		},
+	}
+	post, err := p.updateWithOutbox(filter, update, events)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return post, err
+}
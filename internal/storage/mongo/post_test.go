@@ -1,8 +1,11 @@
 package mongo
 
 import (
+	"context"
 	"fmt"
 	"forum/internal/models"
+	"forum/internal/tracing"
+	"forum/internal/tracing/tracingtest"
 	"reflect"
 	"testing"
 	"time"
@@ -12,31 +15,76 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
 )
 
-const collectionName = "posts"
+const (
+	collectionName       = "posts"
+	outboxCollectionName = "outbox"
+)
+
+// Ответ на commitTransaction, которым завершается запись с outbox
+func commitResponse() bson.D {
+	return mtest.CreateSuccessResponse()
+}
 
 func TestCreate(t *testing.T) {
 	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
 
 	mt.Run("Create", func(mt *mtest.T) {
-		storage := NewPostStorage(mt.DB, collectionName)
+		storage := NewPostStorage(mt.DB, collectionName, outboxCollectionName, reportCollectionName)
 
 		post := newTestPost()
 
-		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		mt.AddMockResponses(mtest.CreateSuccessResponse(), commitResponse())
 
-		err := storage.Create(post)
+		err := storage.Create(post, nil, nil)
 		if err != nil {
 			t.Error(err)
 		}
+
+		// события пишутся в outbox в той же транзакции, что и пост
+		var saved []*models.Event
+		events := func(post *models.Post) ([]*models.Event, error) {
+			saved = []*models.Event{{Type: models.EventPostCreated, PostID: post.ID}}
+			return saved, nil
+		}
+		mt.AddMockResponses(mtest.CreateSuccessResponse(), mtest.CreateSuccessResponse(), commitResponse())
+		err = storage.Create(post, nil, events)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(saved) != 1 || saved[0].Key == "" {
+			t.Errorf("outbox event without key: %v", saved)
+		}
+
+		failed := func(*models.Post) ([]*models.Event, error) {
+			return nil, fmt.Errorf("bad event")
+		}
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		err = storage.Create(post, nil, failed)
+		if err == nil {
+			t.Error("expected error, but was nil")
+		}
+
+		// жалоба фильтров пишется в той же транзакции, ее ошибка отменяет создание поста
+		report := &models.Report{ID: primitive.NewObjectID(), PostID: post.ID, Reason: models.ReportFilter, Status: models.ReportOpen}
+		mt.AddMockResponses(mtest.CreateSuccessResponse(), mtest.CreateSuccessResponse(), commitResponse())
+		err = storage.Create(post, report, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mt.AddMockResponses(mtest.CreateSuccessResponse(), mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "insert failed"}))
+		err = storage.Create(post, report, nil)
+		if err == nil {
+			t.Error("expected error, but was nil")
+		}
 	})
 
 	mt.Run("AddComment", func(mt *mtest.T) {
-		storage := NewPostStorage(mt.DB, collectionName)
+		storage := NewPostStorage(mt.DB, collectionName, outboxCollectionName, reportCollectionName)
 
 		post := newTestPost()
 
-		mt.AddMockResponses(mtest.CreateSuccessResponse())
-		err := storage.Create(post)
+		mt.AddMockResponses(mtest.CreateSuccessResponse(), commitResponse())
+		err := storage.Create(post, nil, nil)
 		if err != nil {
 			t.Error(err)
 		}
@@ -62,9 +110,9 @@ func TestCreate(t *testing.T) {
 				Value: postBson,
 			},
 		}
-		mt.AddMockResponses(mtest.CreateSuccessResponse(response...))
+		mt.AddMockResponses(mtest.CreateSuccessResponse(response...), commitResponse())
 
-		postResponse, err := storage.AddComment(post.ID, &comment)
+		postResponse, err := storage.AddComment(post.ID, &comment, nil, nil)
 		if err != nil {
 			t.Error(err)
 		}
@@ -73,7 +121,19 @@ func TestCreate(t *testing.T) {
 		}
 
 		mt.AddMockResponses(mtest.CreateSuccessResponse(primitive.E{Key: "ok", Value: 0}))
-		_, err = storage.AddComment(post.ID, &comment)
+		_, err = storage.AddComment(post.ID, &comment, nil, nil)
+		if err == nil {
+			t.Error("expected error, but was nil")
+		}
+
+		report := &models.Report{ID: primitive.NewObjectID(), PostID: post.ID, CommentID: &comment.ID, Reason: models.ReportFilter, Status: models.ReportOpen}
+		mt.AddMockResponses(mtest.CreateSuccessResponse(response...), mtest.CreateSuccessResponse(), commitResponse())
+		_, err = storage.AddComment(post.ID, &comment, report, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mt.AddMockResponses(mtest.CreateSuccessResponse(response...), mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "insert failed"}))
+		_, err = storage.AddComment(post.ID, &comment, report, nil)
 		if err == nil {
 			t.Error("expected error, but was nil")
 		}
@@ -84,7 +144,7 @@ func TestFind(t *testing.T) {
 	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
 
 	mt.Run("Find", func(mt *mtest.T) {
-		storage := NewPostStorage(mt.DB, collectionName)
+		storage := NewPostStorage(mt.DB, collectionName, outboxCollectionName, reportCollectionName)
 
 		post := newTestPost()
 		postBson, err := postToBSON(post)
@@ -92,8 +152,8 @@ func TestFind(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		mt.AddMockResponses(mtest.CreateSuccessResponse())
-		err = storage.Create(post)
+		mt.AddMockResponses(mtest.CreateSuccessResponse(), commitResponse())
+		err = storage.Create(post, nil, nil)
 		if err != nil {
 			t.Error(err)
 		}
@@ -133,8 +193,38 @@ func TestFind(t *testing.T) {
 		}
 	})
 
+	mt.Run("FindPage", func(mt *mtest.T) {
+		storage := NewPostStorage(mt.DB, collectionName, outboxCollectionName, reportCollectionName)
+
+		post := newTestPost()
+		postBson, err := postToBSON(post)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, postBson))
+
+		posts, err := storage.FindPage(bson.M{"category": "music"}, models.Page{Number: 2, Size: 10})
+		if err != nil {
+			t.Error(err)
+		}
+		if !reflect.DeepEqual(posts, []*models.Post{post}) {
+			t.Errorf("\nwant: %v\nhave: %v", post, posts)
+		}
+		// страница выбирается в базе, а не в памяти
+		command := mt.GetStartedEvent().Command
+		if command.Lookup("skip").Int64() != 10 || command.Lookup("limit").Int64() != 10 {
+			t.Errorf("unexpected find command %v", command)
+		}
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "find failed"}))
+		_, err = storage.FindPage(bson.M{}, models.Page{Number: 1, Size: 10})
+		if err == nil {
+			t.Error("expected error, but was nil")
+		}
+	})
+
 	mt.Run("FindOne", func(mt *mtest.T) {
-		storage := NewPostStorage(mt.DB, collectionName)
+		storage := NewPostStorage(mt.DB, collectionName, outboxCollectionName, reportCollectionName)
 
 		post := newTestPost()
 		postBson, err := postToBSON(post)
@@ -142,8 +232,8 @@ func TestFind(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		mt.AddMockResponses(mtest.CreateSuccessResponse())
-		err = storage.Create(post)
+		mt.AddMockResponses(mtest.CreateSuccessResponse(), commitResponse())
+		err = storage.Create(post, nil, nil)
 		if err != nil {
 			t.Error(err)
 		}
@@ -169,12 +259,12 @@ func TestUpdate(t *testing.T) {
 	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
 
 	mt.Run("UpdateOne", func(mt *mtest.T) {
-		storage := NewPostStorage(mt.DB, collectionName)
+		storage := NewPostStorage(mt.DB, collectionName, outboxCollectionName, reportCollectionName)
 
 		post := newTestPost()
 
-		mt.AddMockResponses(mtest.CreateSuccessResponse())
-		err := storage.Create(post)
+		mt.AddMockResponses(mtest.CreateSuccessResponse(), commitResponse())
+		err := storage.Create(post, nil, nil)
 		if err != nil {
 			t.Error(err)
 		}
@@ -205,13 +295,180 @@ func TestUpdate(t *testing.T) {
 			t.Errorf("\nwant: %v\nhave: %v", post, postResponse)
 		}
 	})
+
+	mt.Run("View", func(mt *mtest.T) {
+		recorder := tracingtest.NewRecorder()
+		storage := NewPostStorage(mt.DB, collectionName, outboxCollectionName, reportCollectionName)
+
+		post := newTestPost()
+		post.Views = 1
+		postBson, err := postToBSON(post)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mt.AddMockResponses(mtest.CreateSuccessResponse(
+			primitive.E{Key: "ok", Value: 1},
+			primitive.E{Key: "value", Value: postBson},
+		))
+
+		ctx, parent := tracing.Start(context.Background(), "request")
+		postResponse, err := storage.View(ctx, post.ID)
+		parent.End()
+		if err != nil {
+			t.Error(err)
+		}
+		if !reflect.DeepEqual(postResponse, post) {
+			t.Errorf("\nwant: %v\nhave: %v", post, postResponse)
+		}
+
+		// обращение к базе отмечается спаном, дочерним к спану запроса
+		spans := recorder.Ended()
+		if len(spans) != 2 || spans[0].Name() != "post.View" || spans[0].Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("unexpected spans %v", spans)
+		}
+	})
+
+	mt.Run("Publish", func(mt *mtest.T) {
+		storage := NewPostStorage(mt.DB, collectionName, outboxCollectionName, reportCollectionName)
+
+		post := newTestPost()
+		post.Status = models.PostPublished
+		postBson, err := postToBSON(post)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mt.AddMockResponses(mtest.CreateSuccessResponse(
+			primitive.E{Key: "ok", Value: 1},
+			primitive.E{Key: "value", Value: postBson},
+		), commitResponse())
+
+		postResponse, err := storage.Publish(post.ID, post.Author.ID, time.Now(), nil)
+		if err != nil {
+			t.Error(err)
+		}
+		if !reflect.DeepEqual(postResponse, post) {
+			t.Errorf("\nwant: %v\nhave: %v", post, postResponse)
+		}
+
+		// уже опубликован
+		mt.AddMockResponses(mtest.CreateSuccessResponse(
+			primitive.E{Key: "ok", Value: 1},
+			primitive.E{Key: "value", Value: nil},
+		))
+		postResponse, err = storage.PublishDue(time.Now(), nil)
+		if err != nil || postResponse != nil {
+			t.Errorf("want nil, nil, have %v, %v", postResponse, err)
+		}
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{
+			Code:    2,
+			Message: "bad value",
+		}))
+		_, err = storage.PublishDue(time.Now(), nil)
+		if err == nil {
+			t.Error("expected error, but was nil")
+		}
+	})
+
+	mt.Run("CastBallot", func(mt *mtest.T) {
+		storage := NewPostStorage(mt.DB, collectionName, outboxCollectionName, reportCollectionName)
+
+		post := newTestPost()
+		post.Type = "poll"
+		voter := primitive.NewObjectID()
+		post.Poll = &models.Poll{
+			Options: []models.PollOption{
+				{ID: 0, Text: "yes", Votes: 1},
+				{ID: 1, Text: "no"},
+			},
+			Voters:      []primitive.ObjectID{voter},
+			TotalVoters: 1,
+		}
+		postBson, err := postToBSON(post)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mt.AddMockResponses(mtest.CreateSuccessResponse(
+			primitive.E{Key: "ok", Value: 1},
+			primitive.E{Key: "value", Value: postBson},
+		))
+
+		postResponse, err := storage.CastBallot(post.ID, voter, []int{0}, time.Now())
+		if err != nil {
+			t.Error(err)
+		}
+		if !reflect.DeepEqual(postResponse, post) {
+			t.Errorf("\nwant: %v\nhave: %v", post, postResponse)
+		}
+
+		// уже голосовал или опрос закрыт - документ под фильтр не попадает
+		mt.AddMockResponses(mtest.CreateSuccessResponse(
+			primitive.E{Key: "ok", Value: 1},
+			primitive.E{Key: "value", Value: nil},
+		))
+		_, err = storage.CastBallot(post.ID, voter, []int{0}, time.Now())
+		if err == nil {
+			t.Error("expected error, but was nil")
+		}
+	})
+
+	mt.Run("AnonymizeAuthor", func(mt *mtest.T) {
+		storage := NewPostStorage(mt.DB, collectionName, outboxCollectionName, reportCollectionName)
+
+		post := newTestPost()
+
+		response := []primitive.E{
+			{
+				Key:   "ok",
+				Value: 1,
+			},
+			{
+				Key:   "n",
+				Value: 1,
+			},
+			{
+				Key:   "nModified",
+				Value: 1,
+			},
+		}
+		mt.AddMockResponses(mtest.CreateSuccessResponse(response...), mtest.CreateSuccessResponse(response...))
+
+		err := storage.AnonymizeAuthor(post.Author.ID)
+		if err != nil {
+			t.Error(err)
+		}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(primitive.E{Key: "ok", Value: 0}))
+		err = storage.AnonymizeAuthor(post.Author.ID)
+		if err == nil {
+			t.Error("expected error, but was nil")
+		}
+	})
+
+	mt.Run("DeleteUnpublished", func(mt *mtest.T) {
+		storage := NewPostStorage(mt.DB, collectionName, outboxCollectionName, reportCollectionName)
+
+		post := newTestPost()
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(primitive.E{Key: "n", Value: 2}))
+		err := storage.DeleteUnpublished(post.Author.ID)
+		if err != nil {
+			t.Error(err)
+		}
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "delete failed"}))
+		err = storage.DeleteUnpublished(post.Author.ID)
+		if err == nil {
+			t.Error("expected error, but was nil")
+		}
+	})
 }
 
 func TestDelete(t *testing.T) {
 	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
 
 	mt.Run("DeleteComment", func(mt *mtest.T) {
-		storage := NewPostStorage(mt.DB, collectionName)
+		storage := NewPostStorage(mt.DB, collectionName, outboxCollectionName, reportCollectionName)
 
 		post := newTestPost()
 		comment := models.Comment{
@@ -222,13 +479,15 @@ func TestDelete(t *testing.T) {
 		}
 		post.Comments = append(post.Comments, comment)
 
-		mt.AddMockResponses(mtest.CreateSuccessResponse())
-		err := storage.Create(post)
+		mt.AddMockResponses(mtest.CreateSuccessResponse(), commitResponse())
+		err := storage.Create(post, nil, nil)
 		if err != nil {
 			t.Error(err)
 		}
 
-		post.Comments = make([]models.Comment, 0)
+		deletedAt := time.Now().In(time.UTC).Round(time.Millisecond)
+		post.Comments[0].DeletedAt = &deletedAt
+		post.Comments[0].DeletedBy = &post.Author.ID
 		postBson, err := postToBSON(post)
 		if err != nil {
 			t.Fatal(err)
@@ -243,9 +502,9 @@ func TestDelete(t *testing.T) {
 				Value: postBson,
 			},
 		}
-		mt.AddMockResponses(mtest.CreateSuccessResponse(response...))
+		mt.AddMockResponses(mtest.CreateSuccessResponse(response...), commitResponse())
 
-		postResponse, err := storage.DeleteComment(post.ID, comment.ID)
+		postResponse, err := storage.DeleteComment(post.ID, comment.ID, post.Author.ID, time.Now(), nil)
 		if err != nil {
 			t.Error(err)
 		}
@@ -254,63 +513,191 @@ func TestDelete(t *testing.T) {
 		}
 
 		mt.AddMockResponses(mtest.CreateSuccessResponse(primitive.E{Key: "ok", Value: 0}))
-		_, err = storage.DeleteComment(post.ID, comment.ID)
+		_, err = storage.DeleteComment(post.ID, comment.ID, post.Author.ID, time.Now(), nil)
 		if err == nil {
 			t.Error("expected error, but was nil")
 		}
 	})
 
 	mt.Run("Delete", func(mt *mtest.T) {
-		storage := NewPostStorage(mt.DB, collectionName)
+		storage := NewPostStorage(mt.DB, collectionName, outboxCollectionName, reportCollectionName)
 
 		post := newTestPost()
+		postBson, err := postToBSON(post)
+		if err != nil {
+			t.Fatal(err)
+		}
 
-		mt.AddMockResponses(mtest.CreateSuccessResponse())
-		err := storage.Create(post)
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "value", Value: postBson}), commitResponse())
+		err = storage.Delete(post.ID, post.Author.ID, time.Now(), nil)
 		if err != nil {
 			t.Error(err)
 		}
 
-		response := []primitive.E{
-			{
-				Key:   "ok",
-				Value: 1,
-			},
-			{
-				Key:   "acknowledged",
-				Value: true,
-			},
-			{
-				Key:   "n",
-				Value: 1,
-			},
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "value", Value: nil}))
+		err = storage.Delete(post.ID, post.Author.ID, time.Now(), nil)
+		if err != errNoPost {
+			t.Error(err)
+		}
+	})
+}
+
+func TestRestore(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("Restore", func(mt *mtest.T) {
+		storage := NewPostStorage(mt.DB, collectionName, outboxCollectionName, reportCollectionName)
+
+		post := newTestPost()
+		postBson, err := postToBSON(post)
+		if err != nil {
+			t.Fatal(err)
 		}
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "value", Value: postBson}), commitResponse())
 
-		mt.AddMockResponses(mtest.CreateSuccessResponse(response...))
-		err = storage.Delete(post.ID)
+		postResponse, err := storage.Restore(post.ID, nil)
 		if err != nil {
 			t.Error(err)
 		}
+		if !reflect.DeepEqual(postResponse, post) {
+			t.Errorf("\nwant: %v\nhave: %v", post, postResponse)
+		}
 
-		response = []primitive.E{
-			{
-				Key:   "ok",
-				Value: 1,
-			},
-			{
-				Key:   "acknowledged",
-				Value: true,
-			},
-			{
-				Key:   "n",
-				Value: 0,
-			},
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "value", Value: nil}))
+		_, err = storage.Restore(post.ID, nil)
+		if err == nil {
+			t.Error("expected error, but was nil")
 		}
-		mt.AddMockResponses(mtest.CreateSuccessResponse(response...))
-		err = storage.Delete(post.ID)
-		if err != errNoPost {
+	})
+
+	mt.Run("RestoreComment", func(mt *mtest.T) {
+		storage := NewPostStorage(mt.DB, collectionName, outboxCollectionName, reportCollectionName)
+
+		post := newTestPost()
+		post.Comments = append(post.Comments, models.Comment{
+			ID:      primitive.NewObjectID(),
+			Author:  post.Author,
+			Body:    "test comment",
+			Created: time.Now().In(time.UTC).Round(time.Millisecond),
+		})
+		postBson, err := postToBSON(post)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "value", Value: postBson}), commitResponse())
+
+		postResponse, err := storage.RestoreComment(post.ID, post.Comments[0].ID, nil)
+		if err != nil {
 			t.Error(err)
 		}
+		if !reflect.DeepEqual(postResponse, post) {
+			t.Errorf("\nwant: %v\nhave: %v", post, postResponse)
+		}
+	})
+
+	mt.Run("Purge", func(mt *mtest.T) {
+		storage := NewPostStorage(mt.DB, collectionName, outboxCollectionName, reportCollectionName)
+
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 2}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 3}, bson.E{Key: "nModified", Value: 3}),
+		)
+		posts, comments, err := storage.Purge(time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if posts != 2 || comments != 3 {
+			t.Errorf("want 2 posts and 3 comments, have %d and %d", posts, comments)
+		}
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "purge failed"}))
+		_, _, err = storage.Purge(time.Now())
+		if err == nil {
+			t.Error("expected error, but was nil")
+		}
+	})
+}
+
+func TestHeld(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("ReleaseComment", func(mt *mtest.T) {
+		storage := NewPostStorage(mt.DB, collectionName, outboxCollectionName, reportCollectionName)
+
+		post := newTestPost()
+		post.Comments = append(post.Comments, models.Comment{
+			ID:      primitive.NewObjectID(),
+			Author:  post.Author,
+			Body:    "test comment",
+			Created: time.Now().In(time.UTC).Round(time.Millisecond),
+		})
+		postBson, err := postToBSON(post)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "value", Value: postBson}), commitResponse())
+
+		postResponse, err := storage.ReleaseComment(post.ID, post.Comments[0].ID, nil)
+		if err != nil {
+			t.Error(err)
+		}
+		if !reflect.DeepEqual(postResponse, post) {
+			t.Errorf("\nwant: %v\nhave: %v", post, postResponse)
+		}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "value", Value: nil}))
+		_, err = storage.ReleaseComment(post.ID, post.Comments[0].ID, nil)
+		if err == nil {
+			t.Error("expected error, but was nil")
+		}
+	})
+
+	mt.Run("Recent", func(mt *mtest.T) {
+		storage := NewPostStorage(mt.DB, collectionName, outboxCollectionName, reportCollectionName)
+
+		since := time.Now().Add(-time.Hour).In(time.UTC).Round(time.Millisecond)
+		author := primitive.NewObjectID()
+
+		// свой свежий пост с чужим и старым своим комментарием
+		own := newTestPost()
+		own.Author.ID = author
+		own.Created = since.Add(time.Minute)
+		own.Comments = []models.Comment{
+			{ID: primitive.NewObjectID(), Author: models.Author{ID: primitive.NewObjectID()}, Body: "other", Created: since.Add(time.Minute)},
+			{ID: primitive.NewObjectID(), Author: models.Author{ID: author}, Body: "old", Created: since.Add(-time.Minute)},
+		}
+		// чужой пост со свежим комментарием автора
+		other := newTestPost()
+		other.Comments = []models.Comment{
+			{ID: primitive.NewObjectID(), Author: models.Author{ID: author}, Body: "fresh", Created: since.Add(time.Minute)},
+		}
+
+		ownBson, err := postToBSON(own)
+		if err != nil {
+			t.Fatal(err)
+		}
+		otherBson, err := postToBSON(other)
+		if err != nil {
+			t.Fatal(err)
+		}
+		first := mtest.CreateCursorResponse(1, "foo.bar", mtest.FirstBatch, ownBson)
+		second := mtest.CreateCursorResponse(1, "foo.bar", mtest.NextBatch, otherBson)
+		killCursors := mtest.CreateCursorResponse(0, "foo.bar", mtest.NextBatch)
+		mt.AddMockResponses(first, second, killCursors)
+
+		contents, err := storage.Recent(author, since)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(contents) != 2 || contents[0].Text != models.PostContent(own).Text || contents[1].Text != "fresh" {
+			t.Errorf("unexpected contents %+v", contents)
+		}
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "find failed"}))
+		_, err = storage.Recent(author, since)
+		if err == nil {
+			t.Error("expected error, but was nil")
+		}
 	})
 }
 
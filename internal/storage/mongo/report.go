@@ -0,0 +1,164 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"forum/internal/metrics"
+	"forum/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	errNoReports = errors.New("no open reports found")
+)
+
+type reportStorage struct {
+	reports   *mongo.Collection
+	decisions *mongo.Collection
+}
+
+func NewReportStorage(db *mongo.Database, reportCollection, decisionCollection string) *reportStorage {
+	return &reportStorage{
+		reports:   db.Collection(reportCollection),
+		decisions: db.Collection(decisionCollection),
+	}
+}
+
+/*
+Сохраняет жалобу, только если у пользователя нет открытой жалобы на тот же пост или комментарий.
+Возвращает true, если жалоба создана.
+*/
+func (rs *reportStorage) Create(report *models.Report) (bool, error) {
+	defer metrics.ObserveDB(metrics.Mongo, "report.Create", time.Now())
+	ctx := context.Background()
+	filter := bson.M{
+		"reporterID": report.ReporterID,
+		"postID":     report.PostID,
+		"commentID":  report.CommentID,
+		"status":     models.ReportOpen,
+	}
+	update := bson.M{
+		"$setOnInsert": bson.M{
+			"_id":      report.ID,
+			"category": report.Category,
+			"authorID": report.AuthorID,
+			"reason":   report.Reason,
+			"details":  report.Details,
+			"created":  report.Created,
+		},
+	}
+	options := options.Update().SetUpsert(true)
+	result, err := rs.reports.UpdateOne(ctx, filter, update, options)
+	if err != nil {
+		return false, err
+	}
+	return result.UpsertedCount > 0, nil
+}
+
+/*
+Возвращает страницу очереди модерации: открытые жалобы, сгруппированные по посту и комментарию.
+Если categories не nil, только жалобы на посты из этих категорий.
+*/
+func (rs *reportStorage) Queue(categories []string, page models.Page) ([]*models.ReportGroup, error) {
+	defer metrics.ObserveDB(metrics.Mongo, "report.Queue", time.Now())
+	ctx := context.Background()
+	match := bson.M{"status": models.ReportOpen}
+	if categories != nil {
+		match["category"] = bson.M{"$in": categories}
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{
+			"_id":       bson.M{"postID": "$postID", "commentID": "$commentID"},
+			"postID":    bson.M{"$first": "$postID"},
+			"commentID": bson.M{"$first": "$commentID"},
+			"category":  bson.M{"$first": "$category"},
+			"authorID":  bson.M{"$first": "$authorID"},
+			"count":     bson.M{"$sum": 1},
+			"reasons":   bson.M{"$addToSet": "$reason"},
+			"first":     bson.M{"$min": "$created"},
+			"last":      bson.M{"$max": "$created"},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "last", Value: -1}}}},
+		{{Key: "$skip", Value: (page.Number - 1) * page.Size}},
+		{{Key: "$limit", Value: page.Size}},
+	}
+	cursor, err := rs.reports.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	groups := make([]*models.ReportGroup, 0)
+	err = cursor.All(ctx, &groups)
+	return groups, err
+}
+
+/*
+Закрывает открытые жалобы на пост или комментарий из decision и сохраняет решение в историю
+с количеством закрытых жалоб. Если открытых жалоб нет, возвращает ошибку и решение не сохраняется.
+*/
+func (rs *reportStorage) Resolve(decision *models.ReportDecision) error {
+	defer metrics.ObserveDB(metrics.Mongo, "report.Resolve", time.Now())
+	ctx := context.Background()
+	filter := bson.M{
+		"postID":    decision.PostID,
+		"commentID": decision.CommentID,
+		"status":    models.ReportOpen,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":     models.ReportResolved,
+			"decisionID": decision.ID,
+		},
+	}
+	result, err := rs.reports.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.ModifiedCount == 0 {
+		return errNoReports
+	}
+
+	decision.Reports = int(result.ModifiedCount)
+	_, err = rs.decisions.InsertOne(ctx, decision)
+	return err
+}
+
+// Возвращает открытые жалобы на пост или комментарий
+func (rs *reportStorage) FindOpen(postID primitive.ObjectID, commentID *primitive.ObjectID) ([]*models.Report, error) {
+	defer metrics.ObserveDB(metrics.Mongo, "report.FindOpen", time.Now())
+	ctx := context.Background()
+	filter := bson.M{
+		"postID":    postID,
+		"commentID": commentID,
+		"status":    models.ReportOpen,
+	}
+	cursor, err := rs.reports.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	reports := make([]*models.Report, 0)
+	err = cursor.All(ctx, &reports)
+	return reports, err
+}
+
+// Возвращает страницу истории решений модераторов, новые первыми
+func (rs *reportStorage) Decisions(page models.Page) ([]*models.ReportDecision, error) {
+	defer metrics.ObserveDB(metrics.Mongo, "report.Decisions", time.Now())
+	ctx := context.Background()
+	options := options.Find().
+		SetSort(bson.D{{Key: "created", Value: -1}}).
+		SetSkip(int64((page.Number - 1) * page.Size)).
+		SetLimit(int64(page.Size))
+	cursor, err := rs.decisions.Find(ctx, bson.M{}, options)
+	if err != nil {
+		return nil, err
+	}
+	decisions := make([]*models.ReportDecision, 0)
+	err = cursor.All(ctx, &decisions)
+	return decisions, err
+}
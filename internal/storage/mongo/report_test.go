@@ -0,0 +1,163 @@
+package mongo
+
+import (
+	"forum/internal/models"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+const (
+	reportCollectionName   = "reports"
+	decisionCollectionName = "report_decisions"
+)
+
+func TestReports(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("Create", func(mt *mtest.T) {
+		storage := NewReportStorage(mt.DB, reportCollectionName, decisionCollectionName)
+		report := newTestReport()
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(
+			bson.E{Key: "n", Value: 1},
+			bson.E{Key: "upserted", Value: bson.A{bson.D{{Key: "index", Value: 0}, {Key: "_id", Value: report.ID}}}},
+		))
+		created, err := storage.Create(report)
+		if err != nil {
+			t.Error(err)
+		}
+		if !created {
+			t.Error("want created report")
+		}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+		created, err = storage.Create(report)
+		if err != nil {
+			t.Error(err)
+		}
+		if created {
+			t.Error("want existing report")
+		}
+	})
+
+	mt.Run("Queue", func(mt *mtest.T) {
+		storage := NewReportStorage(mt.DB, reportCollectionName, decisionCollectionName)
+		report := newTestReport()
+		group := &models.ReportGroup{
+			PostID:    report.PostID,
+			CommentID: report.CommentID,
+			Category:  report.Category,
+			AuthorID:  report.AuthorID,
+			Count:     2,
+			Reasons:   []string{"spam", "abuse"},
+			First:     report.Created,
+			Last:      report.Created,
+		}
+
+		groupBson := bson.D{}
+		data, err := bson.Marshal(group)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = bson.Unmarshal(data, &groupBson)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.reports", mtest.FirstBatch, groupBson))
+		groups, err := storage.Queue([]string{report.Category}, models.Page{Number: 1, Size: 20})
+		if err != nil {
+			t.Error(err)
+		}
+		expected := []*models.ReportGroup{group}
+		if !reflect.DeepEqual(groups, expected) {
+			t.Errorf("\nwant: %v\nhave: %v", expected, groups)
+		}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(primitive.E{Key: "ok", Value: 0}))
+		_, err = storage.Queue(nil, models.Page{Number: 1, Size: 20})
+		if err == nil {
+			t.Error("expected error, but was nil")
+		}
+	})
+
+	mt.Run("Resolve", func(mt *mtest.T) {
+		storage := NewReportStorage(mt.DB, reportCollectionName, decisionCollectionName)
+		decision := newTestDecision()
+
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 2}, bson.E{Key: "nModified", Value: 2}),
+			mtest.CreateSuccessResponse(),
+		)
+		err := storage.Resolve(decision)
+		if err != nil {
+			t.Error(err)
+		}
+		if decision.Reports != 2 {
+			t.Errorf("want 2 resolved reports, have %d", decision.Reports)
+		}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}))
+		err = storage.Resolve(newTestDecision())
+		if err != errNoReports {
+			t.Errorf("want errNoReports, have %v", err)
+		}
+	})
+
+	mt.Run("Decisions", func(mt *mtest.T) {
+		storage := NewReportStorage(mt.DB, reportCollectionName, decisionCollectionName)
+		decision := newTestDecision()
+
+		decisionBson := bson.D{}
+		data, err := bson.Marshal(decision)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = bson.Unmarshal(data, &decisionBson)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.report_decisions", mtest.FirstBatch, decisionBson))
+		decisions, err := storage.Decisions(models.Page{Number: 1, Size: 20})
+		if err != nil {
+			t.Error(err)
+		}
+		expected := []*models.ReportDecision{decision}
+		if !reflect.DeepEqual(decisions, expected) {
+			t.Errorf("\nwant: %v\nhave: %v", expected, decisions)
+		}
+	})
+}
+
+func newTestReport() *models.Report {
+	commentID := primitive.NewObjectID()
+	return &models.Report{
+		ID:         primitive.NewObjectID(),
+		ReporterID: primitive.NewObjectID(),
+		PostID:     primitive.NewObjectID(),
+		CommentID:  &commentID,
+		Category:   "programming",
+		AuthorID:   primitive.NewObjectID(),
+		Reason:     "spam",
+		Status:     models.ReportOpen,
+		Created:    time.Now().In(time.UTC).Round(time.Millisecond),
+	}
+}
+
+func newTestDecision() *models.ReportDecision {
+	return &models.ReportDecision{
+		ID:          primitive.NewObjectID(),
+		PostID:      primitive.NewObjectID(),
+		AuthorID:    primitive.NewObjectID(),
+		Action:      models.ResolveDismiss,
+		ModeratorID: primitive.NewObjectID(),
+		Reports:     1,
+		Created:     time.Now().In(time.UTC).Round(time.Millisecond),
+	}
+}
@@ -0,0 +1,91 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"forum/internal/metrics"
+	"forum/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	errNoSaved = errors.New("no saved post found")
+)
+
+type savedStorage struct {
+	saved *mongo.Collection
+}
+
+func NewSavedStorage(db *mongo.Database, collectionName string) *savedStorage {
+	collection := db.Collection(collectionName)
+
+	return &savedStorage{
+		saved: collection,
+	}
+}
+
+// Сохраняет закладку, если она уже есть, то меняет папку
+func (s *savedStorage) Save(item *models.SavedPost) error {
+	defer metrics.ObserveDB(metrics.Mongo, "saved.Save", time.Now())
+	ctx := context.Background()
+	filter := bson.M{
+		"userID": item.UserID,
+		"postID": item.PostID,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"folder": item.Folder,
+		},
+		"$setOnInsert": bson.M{
+			"_id":     item.ID,
+			"created": item.Created,
+		},
+	}
+	options := options.Update().SetUpsert(true)
+	_, err := s.saved.UpdateOne(ctx, filter, update, options)
+	return err
+}
+
+// Удаляет закладку пользователя userID на пост postID
+func (s *savedStorage) Delete(userID, postID primitive.ObjectID) error {
+	defer metrics.ObserveDB(metrics.Mongo, "saved.Delete", time.Now())
+	ctx := context.Background()
+	filter := bson.M{
+		"userID": userID,
+		"postID": postID,
+	}
+	result, err := s.saved.DeleteOne(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return errNoSaved
+	}
+	return nil
+}
+
+// Возвращает страницу закладок пользователя userID, новые первыми. Пустой folder - все папки.
+func (s *savedStorage) Find(userID primitive.ObjectID, folder string, page models.Page) ([]*models.SavedPost, error) {
+	defer metrics.ObserveDB(metrics.Mongo, "saved.Find", time.Now())
+	ctx := context.Background()
+	filter := bson.M{"userID": userID}
+	if folder != "" {
+		filter["folder"] = folder
+	}
+	options := options.Find().
+		SetSort(bson.D{{Key: "created", Value: -1}}).
+		SetSkip(int64((page.Number - 1) * page.Size)).
+		SetLimit(int64(page.Size))
+	cursor, err := s.saved.Find(ctx, filter, options)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*models.SavedPost, 0)
+	err = cursor.All(ctx, &items)
+	return items, err
+}
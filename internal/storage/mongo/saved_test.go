@@ -0,0 +1,97 @@
+package mongo
+
+import (
+	"forum/internal/models"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+const savedCollectionName = "saved"
+
+func TestSaved(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("Save", func(mt *mtest.T) {
+		storage := NewSavedStorage(mt.DB, savedCollectionName)
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+		err := storage.Save(newTestSaved())
+		if err != nil {
+			t.Error(err)
+		}
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{
+			Code:    11000,
+			Message: "duplicate key error",
+		}))
+		err = storage.Save(newTestSaved())
+		if err == nil {
+			t.Error("expected error, but was nil")
+		}
+	})
+
+	mt.Run("Delete", func(mt *mtest.T) {
+		storage := NewSavedStorage(mt.DB, savedCollectionName)
+		item := newTestSaved()
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+		err := storage.Delete(item.UserID, item.PostID)
+		if err != nil {
+			t.Error(err)
+		}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}))
+		err = storage.Delete(item.UserID, item.PostID)
+		if err != errNoSaved {
+			t.Errorf("want errNoSaved, have %v", err)
+		}
+	})
+
+	mt.Run("Find", func(mt *mtest.T) {
+		storage := NewSavedStorage(mt.DB, savedCollectionName)
+		item := newTestSaved()
+
+		itemBson := bson.D{}
+		data, err := bson.Marshal(item)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = bson.Unmarshal(data, &itemBson)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		first := mtest.CreateCursorResponse(0, "foo.saved", mtest.FirstBatch, itemBson)
+		mt.AddMockResponses(first)
+
+		items, err := storage.Find(item.UserID, item.Folder, models.Page{Number: 1, Size: 20})
+		if err != nil {
+			t.Error(err)
+		}
+		expected := []*models.SavedPost{item}
+		if !reflect.DeepEqual(items, expected) {
+			t.Errorf("\nwant: %v\nhave: %v", expected, items)
+		}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(primitive.E{Key: "ok", Value: 0}))
+		_, err = storage.Find(item.UserID, "", models.Page{Number: 1, Size: 20})
+		if err == nil {
+			t.Error("expected error, but was nil")
+		}
+	})
+}
+
+func newTestSaved() *models.SavedPost {
+	return &models.SavedPost{
+		ID:      primitive.NewObjectID(),
+		UserID:  primitive.NewObjectID(),
+		PostID:  primitive.NewObjectID(),
+		Folder:  "later",
+		Created: time.Now().In(time.UTC).Round(time.Millisecond),
+	}
+}
@@ -0,0 +1,261 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"forum/internal/metrics"
+	"forum/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	errNoWebhook  = errors.New("no webhook found")
+	errNoDelivery = errors.New("no delivery found")
+)
+
+type webhookStorage struct {
+	webhooks   *mongo.Collection
+	deliveries *mongo.Collection
+}
+
+func NewWebhookStorage(db *mongo.Database, webhookCollection, deliveryCollection string) *webhookStorage {
+	return &webhookStorage{
+		webhooks:   db.Collection(webhookCollection),
+		deliveries: db.Collection(deliveryCollection),
+	}
+}
+
+// Сохраняет новый вебхук
+func (ws *webhookStorage) Create(webhook *models.Webhook) error {
+	defer metrics.ObserveDB(metrics.Mongo, "webhook.Create", time.Now())
+	ctx := context.Background()
+	_, err := ws.webhooks.InsertOne(ctx, webhook)
+	return err
+}
+
+// Возвращает все вебхуки, новые первыми
+func (ws *webhookStorage) Find() ([]*models.Webhook, error) {
+	defer metrics.ObserveDB(metrics.Mongo, "webhook.Find", time.Now())
+	ctx := context.Background()
+	options := options.Find().SetSort(bson.D{{Key: "created", Value: -1}})
+	return ws.find(ctx, bson.M{}, options)
+}
+
+// Возвращает включенные вебхуки, подписанные на событие eventType
+func (ws *webhookStorage) Active(eventType string) ([]*models.Webhook, error) {
+	defer metrics.ObserveDB(metrics.Mongo, "webhook.Active", time.Now())
+	ctx := context.Background()
+	return ws.find(ctx, bson.M{"active": true, "events": eventType})
+}
+
+func (ws *webhookStorage) find(ctx context.Context, filter bson.M, opts ...*options.FindOptions) ([]*models.Webhook, error) {
+	cursor, err := ws.webhooks.Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	webhooks := make([]*models.Webhook, 0)
+	err = cursor.All(ctx, &webhooks)
+	return webhooks, err
+}
+
+// Возвращает вебхук по webhookID
+func (ws *webhookStorage) FindOne(webhookID primitive.ObjectID) (*models.Webhook, error) {
+	defer metrics.ObserveDB(metrics.Mongo, "webhook.FindOne", time.Now())
+	ctx := context.Background()
+	webhook := &models.Webhook{}
+	err := ws.webhooks.FindOne(ctx, bson.M{"_id": webhookID}).Decode(webhook)
+	if err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// Удаляет вебхук вместе с журналом его доставок
+func (ws *webhookStorage) Delete(webhookID primitive.ObjectID) error {
+	defer metrics.ObserveDB(metrics.Mongo, "webhook.Delete", time.Now())
+	ctx := context.Background()
+	result, err := ws.webhooks.DeleteOne(ctx, bson.M{"_id": webhookID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return errNoWebhook
+	}
+	_, err = ws.deliveries.DeleteMany(ctx, bson.M{"webhookID": webhookID})
+	return err
+}
+
+// Включает или выключает вебхук, счетчик неудач при этом сбрасывается
+func (ws *webhookStorage) SetActive(webhookID primitive.ObjectID, active bool) error {
+	defer metrics.ObserveDB(metrics.Mongo, "webhook.SetActive", time.Now())
+	ctx := context.Background()
+	update := bson.M{
+		"$set": bson.M{
+			"active":   active,
+			"failures": 0,
+		},
+	}
+	result, err := ws.webhooks.UpdateOne(ctx, bson.M{"_id": webhookID}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errNoWebhook
+	}
+	return nil
+}
+
+/*
+Учитывает результат попытки доставки: успех сбрасывает счетчик неудач, неудача увеличивает его
+и выключает вебхук, если неудач подряд стало не меньше disableAfter. Возвращает обновленный вебхук.
+*/
+func (ws *webhookStorage) RecordAttempt(webhookID primitive.ObjectID, ok bool, disableAfter int) (*models.Webhook, error) {
+	defer metrics.ObserveDB(metrics.Mongo, "webhook.RecordAttempt", time.Now())
+	ctx := context.Background()
+	filter := bson.M{"_id": webhookID}
+	update := bson.M{"$set": bson.M{"failures": 0}}
+	if !ok {
+		update = bson.M{"$inc": bson.M{"failures": 1}}
+	}
+	options := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	webhook := &models.Webhook{}
+	err := ws.webhooks.FindOneAndUpdate(ctx, filter, update, options).Decode(webhook)
+	if err != nil {
+		return nil, err
+	}
+	if ok || webhook.Failures < disableAfter || !webhook.Active {
+		return webhook, nil
+	}
+
+	// выключаем, только если вебхук не включили заново с момента неудачи
+	filter = bson.M{"_id": webhookID, "failures": bson.M{"$gte": disableAfter}}
+	update = bson.M{"$set": bson.M{"active": false}}
+	_, err = ws.webhooks.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return nil, err
+	}
+	webhook.Active = false
+	return webhook, nil
+}
+
+/*
+Ставит доставку в очередь, если доставки с тем же вебхуком и ключом еще нет.
+Возвращает true, если доставка создана.
+*/
+func (ws *webhookStorage) Enqueue(delivery *models.WebhookDelivery) (bool, error) {
+	defer metrics.ObserveDB(metrics.Mongo, "webhook.Enqueue", time.Now())
+	ctx := context.Background()
+	filter := bson.M{
+		"webhookID": delivery.WebhookID,
+		"key":       delivery.Key,
+	}
+	update := bson.M{
+		"$setOnInsert": bson.M{
+			"_id":         delivery.ID,
+			"event":       delivery.Event,
+			"payload":     delivery.Payload,
+			"status":      delivery.Status,
+			"attempts":    delivery.Attempts,
+			"nextAttempt": delivery.NextAttempt,
+			"created":     delivery.Created,
+		},
+	}
+	options := options.Update().SetUpsert(true)
+	result, err := ws.deliveries.UpdateOne(ctx, filter, update, options)
+	if err != nil {
+		return false, err
+	}
+	return result.UpsertedCount > 0, nil
+}
+
+/*
+Забирает одну ожидающую доставку, время попытки которой наступило к now, и откладывает
+ее следующую попытку на lease. Пока попытка не завершена, доставку не заберет другой экземпляр сервера.
+Если таких доставок нет, возвращает nil.
+*/
+func (ws *webhookStorage) ClaimDue(now time.Time, lease time.Duration) (*models.WebhookDelivery, error) {
+	defer metrics.ObserveDB(metrics.Mongo, "webhook.ClaimDue", time.Now())
+	ctx := context.Background()
+	filter := bson.M{
+		"status":      models.DeliveryPending,
+		"nextAttempt": bson.M{"$lte": now},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"nextAttempt": now.Add(lease),
+		},
+	}
+	options := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "nextAttempt", Value: 1}}).
+		SetReturnDocument(options.After)
+	delivery := &models.WebhookDelivery{}
+	err := ws.deliveries.FindOneAndUpdate(ctx, filter, update, options).Decode(delivery)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// Сохраняет результат попытки доставки
+func (ws *webhookStorage) UpdateDelivery(delivery *models.WebhookDelivery) error {
+	defer metrics.ObserveDB(metrics.Mongo, "webhook.UpdateDelivery", time.Now())
+	ctx := context.Background()
+	_, err := ws.deliveries.ReplaceOne(ctx, bson.M{"_id": delivery.ID}, delivery)
+	return err
+}
+
+// Возвращает страницу журнала доставок вебхука webhookID, новые первыми
+func (ws *webhookStorage) Deliveries(webhookID primitive.ObjectID, page models.Page) ([]*models.WebhookDelivery, error) {
+	defer metrics.ObserveDB(metrics.Mongo, "webhook.Deliveries", time.Now())
+	ctx := context.Background()
+	options := options.Find().
+		SetSort(bson.D{{Key: "created", Value: -1}}).
+		SetSkip(int64((page.Number - 1) * page.Size)).
+		SetLimit(int64(page.Size))
+	cursor, err := ws.deliveries.Find(ctx, bson.M{"webhookID": webhookID}, options)
+	if err != nil {
+		return nil, err
+	}
+	deliveries := make([]*models.WebhookDelivery, 0)
+	err = cursor.All(ctx, &deliveries)
+	return deliveries, err
+}
+
+// Ставит доставку deliveryID вебхука webhookID в очередь повторно с первой попытки к now
+func (ws *webhookStorage) Redeliver(webhookID, deliveryID primitive.ObjectID, now time.Time) (*models.WebhookDelivery, error) {
+	defer metrics.ObserveDB(metrics.Mongo, "webhook.Redeliver", time.Now())
+	ctx := context.Background()
+	filter := bson.M{
+		"_id":       deliveryID,
+		"webhookID": webhookID,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":      models.DeliveryPending,
+			"attempts":    0,
+			"nextAttempt": now,
+		},
+		"$unset": bson.M{
+			"error":     "",
+			"delivered": "",
+		},
+	}
+	options := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	delivery := &models.WebhookDelivery{}
+	err := ws.deliveries.FindOneAndUpdate(ctx, filter, update, options).Decode(delivery)
+	if err == mongo.ErrNoDocuments {
+		return nil, errNoDelivery
+	}
+	if err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
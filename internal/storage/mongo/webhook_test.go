@@ -0,0 +1,201 @@
+package mongo
+
+import (
+	"forum/internal/models"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+const (
+	webhookCollectionName  = "webhooks"
+	deliveryCollectionName = "webhook_deliveries"
+)
+
+func TestWebhooks(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("Active", func(mt *mtest.T) {
+		storage := NewWebhookStorage(mt.DB, webhookCollectionName, deliveryCollectionName)
+		webhook := newTestWebhook()
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.webhooks", mtest.FirstBatch, toBsonD(t, webhook)))
+		webhooks, err := storage.Active(models.EventPostCreated)
+		if err != nil {
+			t.Error(err)
+		}
+		expected := []*models.Webhook{webhook}
+		if !reflect.DeepEqual(webhooks, expected) {
+			t.Errorf("\nwant: %v\nhave: %v", expected, webhooks)
+		}
+	})
+
+	mt.Run("RecordAttempt", func(mt *mtest.T) {
+		storage := NewWebhookStorage(mt.DB, webhookCollectionName, deliveryCollectionName)
+		webhook := newTestWebhook()
+		webhook.Failures = 3
+
+		// неудач меньше порога - вебхук остается включенным
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "value", Value: toBsonD(t, webhook)}))
+		updated, err := storage.RecordAttempt(webhook.ID, false, 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !updated.Active {
+			t.Error("webhook disabled before threshold")
+		}
+
+		webhook.Failures = 5
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "value", Value: toBsonD(t, webhook)}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+		)
+		updated, err = storage.RecordAttempt(webhook.ID, false, 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if updated.Active {
+			t.Error("webhook not disabled at threshold")
+		}
+	})
+
+	mt.Run("Delete", func(mt *mtest.T) {
+		storage := NewWebhookStorage(mt.DB, webhookCollectionName, deliveryCollectionName)
+
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 3}),
+		)
+		err := storage.Delete(primitive.NewObjectID())
+		if err != nil {
+			t.Error(err)
+		}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}))
+		err = storage.Delete(primitive.NewObjectID())
+		if err != errNoWebhook {
+			t.Errorf("want errNoWebhook, have %v", err)
+		}
+	})
+}
+
+func TestDeliveries(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("Enqueue", func(mt *mtest.T) {
+		storage := NewWebhookStorage(mt.DB, webhookCollectionName, deliveryCollectionName)
+		delivery := newTestDelivery()
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(
+			bson.E{Key: "n", Value: 1},
+			bson.E{Key: "upserted", Value: bson.A{bson.D{{Key: "index", Value: 0}, {Key: "_id", Value: delivery.ID}}}},
+		))
+		created, err := storage.Enqueue(delivery)
+		if err != nil || !created {
+			t.Errorf("want created delivery, have %v, %v", created, err)
+		}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+		created, err = storage.Enqueue(delivery)
+		if err != nil || created {
+			t.Errorf("want existing delivery, have %v, %v", created, err)
+		}
+	})
+
+	mt.Run("ClaimDue", func(mt *mtest.T) {
+		storage := NewWebhookStorage(mt.DB, webhookCollectionName, deliveryCollectionName)
+		delivery := newTestDelivery()
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "value", Value: toBsonD(t, delivery)}))
+		claimed, err := storage.ClaimDue(time.Now(), time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(claimed, delivery) {
+			t.Errorf("\nwant: %v\nhave: %v", delivery, claimed)
+		}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "value", Value: nil}))
+		claimed, err = storage.ClaimDue(time.Now(), time.Minute)
+		if err != nil || claimed != nil {
+			t.Errorf("want nothing due, have %v, %v", claimed, err)
+		}
+	})
+
+	mt.Run("Redeliver", func(mt *mtest.T) {
+		storage := NewWebhookStorage(mt.DB, webhookCollectionName, deliveryCollectionName)
+		delivery := newTestDelivery()
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "value", Value: toBsonD(t, delivery)}))
+		redelivered, err := storage.Redeliver(delivery.WebhookID, delivery.ID, time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(redelivered, delivery) {
+			t.Errorf("\nwant: %v\nhave: %v", delivery, redelivered)
+		}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "value", Value: nil}))
+		_, err = storage.Redeliver(delivery.WebhookID, primitive.NewObjectID(), time.Now())
+		if err != errNoDelivery {
+			t.Errorf("want errNoDelivery, have %v", err)
+		}
+	})
+
+	mt.Run("Deliveries", func(mt *mtest.T) {
+		storage := NewWebhookStorage(mt.DB, webhookCollectionName, deliveryCollectionName)
+		delivery := newTestDelivery()
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.webhook_deliveries", mtest.FirstBatch, toBsonD(t, delivery)))
+		deliveries, err := storage.Deliveries(delivery.WebhookID, models.Page{Number: 1, Size: 20})
+		if err != nil {
+			t.Error(err)
+		}
+		expected := []*models.WebhookDelivery{delivery}
+		if !reflect.DeepEqual(deliveries, expected) {
+			t.Errorf("\nwant: %v\nhave: %v", expected, deliveries)
+		}
+	})
+}
+
+func newTestWebhook() *models.Webhook {
+	return &models.Webhook{
+		ID:        primitive.NewObjectID(),
+		URL:       "https://example.com/hook",
+		Secret:    "secret",
+		Events:    []string{models.EventPostCreated},
+		Active:    true,
+		CreatedBy: primitive.NewObjectID(),
+		Created:   time.Now().In(time.UTC).Round(time.Millisecond),
+	}
+}
+
+func newTestDelivery() *models.WebhookDelivery {
+	return &models.WebhookDelivery{
+		ID:          primitive.NewObjectID(),
+		WebhookID:   primitive.NewObjectID(),
+		Key:         "1-0",
+		Event:       models.EventPostCreated,
+		Payload:     []byte(`{"type":"post.created"}`),
+		Status:      models.DeliveryPending,
+		NextAttempt: time.Now().In(time.UTC).Round(time.Millisecond),
+		Created:     time.Now().In(time.UTC).Round(time.Millisecond),
+	}
+}
+
+func toBsonD(t *testing.T, value any) bson.D {
+	document := bson.D{}
+	data, err := bson.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = bson.Unmarshal(data, &document)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return document
+}
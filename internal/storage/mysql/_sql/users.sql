@@ -7,5 +7,70 @@ DROP TABLE IF EXISTS `users`;
 CREATE TABLE `users` (
     `username` VARCHAR(255) NOT NULL PRIMARY KEY,
     `id` CHAR(24) NOT NULL,
-    `password` VARCHAR(255) NOT NULL
+    `password` VARCHAR(255) NOT NULL,
+    `role` VARCHAR(16) NOT NULL DEFAULT 'user',
+    `banned` TINYINT(1) NOT NULL DEFAULT 0,
+    `suspended_until` DATETIME NULL,
+    `suspend_reason` VARCHAR(255) NOT NULL DEFAULT '',
+    `shadowbanned` TINYINT(1) NOT NULL DEFAULT 0,
+    UNIQUE KEY `users_id` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+DROP TABLE IF EXISTS `user_identities`;
+CREATE TABLE `user_identities` (
+    `provider` VARCHAR(64) NOT NULL,
+    `subject` VARCHAR(255) NOT NULL,
+    `user_id` CHAR(24) NOT NULL,
+    PRIMARY KEY (`provider`, `subject`),
+    FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+DROP TABLE IF EXISTS `follows`;
+CREATE TABLE `follows` (
+    `follower_id` CHAR(24) NOT NULL,
+    `followee_id` CHAR(24) NOT NULL,
+    PRIMARY KEY (`follower_id`, `followee_id`),
+    FOREIGN KEY (`follower_id`) REFERENCES `users` (`id`) ON DELETE CASCADE,
+    FOREIGN KEY (`followee_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+DROP TABLE IF EXISTS `category_subscriptions`;
+CREATE TABLE `category_subscriptions` (
+    `user_id` CHAR(24) NOT NULL,
+    `category` VARCHAR(64) NOT NULL,
+    PRIMARY KEY (`user_id`, `category`),
+    FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+DROP TABLE IF EXISTS `blocks`;
+CREATE TABLE `blocks` (
+    `blocker_id` CHAR(24) NOT NULL,
+    `blocked_id` CHAR(24) NOT NULL,
+    PRIMARY KEY (`blocker_id`, `blocked_id`),
+    FOREIGN KEY (`blocker_id`) REFERENCES `users` (`id`) ON DELETE CASCADE,
+    FOREIGN KEY (`blocked_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+DROP TABLE IF EXISTS `category_mutes`;
+CREATE TABLE `category_mutes` (
+    `user_id` CHAR(24) NOT NULL,
+    `category` VARCHAR(64) NOT NULL,
+    PRIMARY KEY (`user_id`, `category`),
+    FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+DROP TABLE IF EXISTS `category_moderators`;
+CREATE TABLE `category_moderators` (
+    `category` VARCHAR(64) NOT NULL,
+    `user_id` CHAR(24) NOT NULL,
+    PRIMARY KEY (`category`, `user_id`),
+    KEY `category_moderators_user` (`user_id`),
+    FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+DROP TABLE IF EXISTS `category_rules`;
+CREATE TABLE `category_rules` (
+    `category` VARCHAR(64) NOT NULL PRIMARY KEY,
+    `post_types` VARCHAR(255) NOT NULL DEFAULT '',
+    `min_account_days` INT NOT NULL DEFAULT 0
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8;
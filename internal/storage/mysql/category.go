@@ -0,0 +1,140 @@
+package mysql
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"forum/internal/metrics"
+	"forum/internal/models"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type categoryStorage struct {
+	db             *sql.DB
+	userTable      string
+	moderatorTable string
+	ruleTable      string
+}
+
+func NewCategoryStorage(db *sql.DB, userTable, moderatorTable, ruleTable string) *categoryStorage {
+	return &categoryStorage{
+		db:             db,
+		userTable:      userTable,
+		moderatorTable: moderatorTable,
+		ruleTable:      ruleTable,
+	}
+}
+
+// Назначает пользователя userID модератором категории category
+func (cs *categoryStorage) AddModerator(category, userID string) error {
+	defer metrics.ObserveDB(metrics.MySQL, "category.AddModerator", time.Now())
+	query := fmt.Sprintf("INSERT IGNORE INTO %s (category, user_id) VALUES (?, ?)", cs.moderatorTable)
+	_, err := cs.db.Exec(
+		query,
+		category,
+		userID,
+	)
+	return err
+}
+
+// Снимает пользователя userID с модерации категории category
+func (cs *categoryStorage) RemoveModerator(category, userID string) error {
+	defer metrics.ObserveDB(metrics.MySQL, "category.RemoveModerator", time.Now())
+	query := fmt.Sprintf("DELETE FROM %s WHERE category = ? AND user_id = ?", cs.moderatorTable)
+	_, err := cs.db.Exec(
+		query,
+		category,
+		userID,
+	)
+	return err
+}
+
+// Возвращает модераторов категории category
+func (cs *categoryStorage) Moderators(category string) ([]models.Author, error) {
+	defer metrics.ObserveDB(metrics.MySQL, "category.Moderators", time.Now())
+	query := fmt.Sprintf(
+		"SELECT u.id, u.username FROM %s m JOIN %s u ON u.id = m.user_id WHERE m.category = ? ORDER BY u.username",
+		cs.moderatorTable, cs.userTable,
+	)
+	rows, err := cs.db.Query(query, category)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	moderators := make([]models.Author, 0)
+	for rows.Next() {
+		var id, username string
+		err = rows.Scan(&id, &username)
+		if err != nil {
+			return nil, err
+		}
+		userID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, err
+		}
+		moderators = append(moderators, models.Author{ID: userID, Username: username})
+	}
+	return moderators, rows.Err()
+}
+
+// Возвращает категории, которые модерирует пользователь userID
+func (cs *categoryStorage) ModeratedCategories(userID string) ([]string, error) {
+	defer metrics.ObserveDB(metrics.MySQL, "category.ModeratedCategories", time.Now())
+	query := fmt.Sprintf("SELECT category FROM %s WHERE user_id = ?", cs.moderatorTable)
+	rows, err := cs.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := make([]string, 0)
+	for rows.Next() {
+		var category string
+		err = rows.Scan(&category)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+	return categories, rows.Err()
+}
+
+// Возвращает правила категории category, если они не заданы - правила без ограничений
+func (cs *categoryStorage) Rules(category string) (*models.CategoryRules, error) {
+	defer metrics.ObserveDB(metrics.MySQL, "category.Rules", time.Now())
+	rules := &models.CategoryRules{Category: category}
+	var postTypes string
+	query := fmt.Sprintf("SELECT post_types, min_account_days FROM %s WHERE category = ?", cs.ruleTable)
+	err := cs.db.QueryRow(query, category).Scan(&postTypes, &rules.MinAccountDays)
+	if errors.Is(err, sql.ErrNoRows) {
+		return rules, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if postTypes != "" {
+		rules.PostTypes = strings.Split(postTypes, ",")
+	}
+	return rules, nil
+}
+
+// Сохраняет правила категории, заменяя прежние
+func (cs *categoryStorage) SetRules(rules *models.CategoryRules) error {
+	defer metrics.ObserveDB(metrics.MySQL, "category.SetRules", time.Now())
+	query := fmt.Sprintf(
+		"INSERT INTO %s (category, post_types, min_account_days) VALUES (?, ?, ?) "+
+			"ON DUPLICATE KEY UPDATE post_types = VALUES(post_types), min_account_days = VALUES(min_account_days)",
+		cs.ruleTable,
+	)
+	_, err := cs.db.Exec(
+		query,
+		rules.Category,
+		strings.Join(rules.PostTypes, ","),
+		rules.MinAccountDays,
+	)
+	return err
+}
@@ -0,0 +1,157 @@
+package mysql
+
+import (
+	"forum/internal/models"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestCategoryModerators(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("cant create mock: %s", err)
+	}
+	defer db.Close()
+
+	repoCategory := NewCategoryStorage(db, "users", "category_moderators", "category_rules")
+
+	moderator := models.Author{ID: primitive.NewObjectID(), Username: "moderator"}
+
+	// add
+	mock.
+		ExpectExec("INSERT IGNORE INTO category_moderators").
+		WithArgs("music", moderator.ID.Hex()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repoCategory.AddModerator("music", moderator.ID.Hex())
+	if err != nil {
+		t.Errorf("unexpected err: %s", err)
+		return
+	}
+
+	// moderators
+	rows := sqlmock.NewRows([]string{"id", "username"}).AddRow(moderator.ID.Hex(), moderator.Username)
+	mock.
+		ExpectQuery("SELECT u.id, u.username FROM category_moderators m JOIN users u").
+		WithArgs("music").
+		WillReturnRows(rows)
+
+	moderators, err := repoCategory.Moderators("music")
+	if err != nil {
+		t.Errorf("unexpected err: %s", err)
+		return
+	}
+	if !reflect.DeepEqual(moderators, []models.Author{moderator}) {
+		t.Errorf("results not match, want %v, have %v", moderator, moderators)
+		return
+	}
+
+	// bad id in table
+	rows = sqlmock.NewRows([]string{"id", "username"}).AddRow("not id", moderator.Username)
+	mock.
+		ExpectQuery("SELECT u.id, u.username FROM category_moderators m JOIN users u").
+		WithArgs("music").
+		WillReturnRows(rows)
+
+	_, err = repoCategory.Moderators("music")
+	if err == nil {
+		t.Errorf("expected error, got nil")
+		return
+	}
+
+	// moderated categories
+	rows = sqlmock.NewRows([]string{"category"}).AddRow("music").AddRow("news")
+	mock.
+		ExpectQuery("SELECT category FROM category_moderators WHERE").
+		WithArgs(moderator.ID.Hex()).
+		WillReturnRows(rows)
+
+	categories, err := repoCategory.ModeratedCategories(moderator.ID.Hex())
+	if err != nil {
+		t.Errorf("unexpected err: %s", err)
+		return
+	}
+	if !reflect.DeepEqual(categories, []string{"music", "news"}) {
+		t.Errorf("results not match, want [music news], have %v", categories)
+		return
+	}
+
+	// remove
+	mock.
+		ExpectExec("DELETE FROM category_moderators WHERE").
+		WithArgs("music", moderator.ID.Hex()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repoCategory.RemoveModerator("music", moderator.ID.Hex())
+	if err != nil {
+		t.Errorf("unexpected err: %s", err)
+		return
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestCategoryRules(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("cant create mock: %s", err)
+	}
+	defer db.Close()
+
+	repoCategory := NewCategoryStorage(db, "users", "category_moderators", "category_rules")
+
+	// no rules yet
+	mock.
+		ExpectQuery("SELECT post_types, min_account_days FROM category_rules WHERE").
+		WithArgs("music").
+		WillReturnRows(sqlmock.NewRows([]string{"post_types", "min_account_days"}))
+
+	rules, err := repoCategory.Rules("music")
+	if err != nil {
+		t.Errorf("unexpected err: %s", err)
+		return
+	}
+	expected := &models.CategoryRules{Category: "music"}
+	if !reflect.DeepEqual(rules, expected) {
+		t.Errorf("results not match, want %v, have %v", expected, rules)
+		return
+	}
+
+	// set rules
+	expected = &models.CategoryRules{Category: "music", PostTypes: []string{"text", "link"}, MinAccountDays: 7}
+	mock.
+		ExpectExec("INSERT INTO category_rules").
+		WithArgs("music", "text,link", 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repoCategory.SetRules(expected)
+	if err != nil {
+		t.Errorf("unexpected err: %s", err)
+		return
+	}
+
+	rows := sqlmock.NewRows([]string{"post_types", "min_account_days"}).AddRow("text,link", 7)
+	mock.
+		ExpectQuery("SELECT post_types, min_account_days FROM category_rules WHERE").
+		WithArgs("music").
+		WillReturnRows(rows)
+
+	rules, err = repoCategory.Rules("music")
+	if err != nil {
+		t.Errorf("unexpected err: %s", err)
+		return
+	}
+	if !reflect.DeepEqual(rules, expected) {
+		t.Errorf("results not match, want %v, have %v", expected, rules)
+		return
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
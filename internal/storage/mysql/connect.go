@@ -15,6 +15,8 @@ func GetConnection(dbUser, dbPassword, addr, dbName string) (*sql.DB, error) {
 	// отказываемся от prapared statements
 	// параметры подставляются сразу
 	dsn += "&interpolateParams=true"
+	// DATETIME читается сразу в time.Time
+	dsn += "&parseTime=true"
 
 	db, err := sql.Open("mysql", dsn)
 	if err != nil {
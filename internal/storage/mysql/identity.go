@@ -0,0 +1,82 @@
+package mysql
+
+import (
+	"database/sql"
+	"fmt"
+	"forum/internal/metrics"
+	"forum/internal/models"
+	"time"
+)
+
+type identityStorage struct {
+	db        *sql.DB
+	table     string
+	userTable string
+}
+
+func NewIdentityStorage(db *sql.DB, identityTable, userTable string) *identityStorage {
+	return &identityStorage{
+		db:        db,
+		table:     identityTable,
+		userTable: userTable,
+	}
+}
+
+// Возвращает пользователя, привязанного к subject у провайдера provider
+func (is *identityStorage) FindOne(provider, subject string) (*models.User, error) {
+	defer metrics.ObserveDB(metrics.MySQL, "identity.FindOne", time.Now())
+	user := &models.User{}
+	suspendedUntil := sql.NullTime{}
+	query := fmt.Sprintf(
+		"SELECT u.username, u.id, u.password, u.banned, u.suspended_until, u.suspend_reason FROM %s u JOIN %s i ON i.user_id = u.id WHERE i.provider = ? AND i.subject = ?",
+		is.userTable,
+		is.table,
+	)
+	row := is.db.QueryRow(
+		query,
+		provider,
+		subject,
+	)
+	err := row.Scan(&user.Username, &user.ID, &user.Password, &user.Banned, &suspendedUntil, &user.SuspendReason)
+	if err != nil {
+		return nil, err
+	}
+	if suspendedUntil.Valid {
+		user.SuspendedUntil = &suspendedUntil.Time
+	}
+	return user, nil
+}
+
+// Создает пользователя и привязывает к нему внешнюю учетную запись в одной транзакции
+func (is *identityStorage) Create(identity *models.ExternalIdentity, user *models.User) error {
+	defer metrics.ObserveDB(metrics.MySQL, "identity.Create", time.Now())
+	tx, err := is.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf("INSERT INTO %s (username, id, password) VALUES (?, ?, ?)", is.userTable)
+	_, err = tx.Exec(
+		query,
+		user.Username,
+		user.ID,
+		user.Password,
+	)
+	if err != nil {
+		return errUserExists
+	}
+
+	query = fmt.Sprintf("INSERT INTO %s (provider, subject, user_id) VALUES (?, ?, ?)", is.table)
+	_, err = tx.Exec(
+		query,
+		identity.Provider,
+		identity.Subject,
+		user.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
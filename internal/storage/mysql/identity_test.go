@@ -0,0 +1,116 @@
+package mysql
+
+import (
+	"fmt"
+	"forum/internal/models"
+	"reflect"
+	"testing"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestIdentityFindOne(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("cant create mock: %s", err)
+	}
+	defer db.Close()
+
+	repoIdentity := NewIdentityStorage(db, "user_identities", "users")
+
+	expect := &models.User{
+		ID:       "1",
+		Password: "password",
+		Username: "username",
+	}
+
+	// good query
+	rows := sqlmock.NewRows([]string{"username", "id", "password", "banned", "suspended_until", "suspend_reason"}).
+		AddRow(expect.Username, expect.ID, expect.Password, expect.Banned, nil, expect.SuspendReason)
+	mock.
+		ExpectQuery("SELECT u.username, u.id, u.password, u.banned, u.suspended_until, u.suspend_reason FROM users u JOIN user_identities i").
+		WithArgs("corp", "subject").
+		WillReturnRows(rows)
+
+	item, err := repoIdentity.FindOne("corp", "subject")
+	if err != nil {
+		t.Errorf("unexpected err: %s", err)
+		return
+	}
+	if !reflect.DeepEqual(item, expect) {
+		t.Errorf("results not match, want %v, have %v", expect, item)
+		return
+	}
+
+	// query error
+	mock.
+		ExpectQuery("SELECT u.username, u.id, u.password, u.banned, u.suspended_until, u.suspend_reason FROM users u JOIN user_identities i").
+		WithArgs("corp", "subject").
+		WillReturnError(fmt.Errorf("db_error"))
+
+	_, err = repoIdentity.FindOne("corp", "subject")
+	if err == nil {
+		t.Errorf("expected error, got nil")
+		return
+	}
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestIdentityCreate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("cant create mock: %s", err)
+	}
+	defer db.Close()
+
+	repoIdentity := NewIdentityStorage(db, "user_identities", "users")
+
+	identity := &models.ExternalIdentity{
+		Provider: "corp",
+		Subject:  "subject",
+	}
+	user := &models.User{
+		ID:       "1",
+		Password: "password",
+		Username: "username",
+	}
+
+	// ok query
+	mock.ExpectBegin()
+	mock.
+		ExpectExec("INSERT INTO users").
+		WithArgs(user.Username, user.ID, user.Password).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.
+		ExpectExec("INSERT INTO user_identities").
+		WithArgs(identity.Provider, identity.Subject, user.ID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = repoIdentity.Create(identity, user)
+	if err != nil {
+		t.Errorf("unexpected err: %s", err)
+		return
+	}
+
+	// username exists, transaction rolled back
+	mock.ExpectBegin()
+	mock.
+		ExpectExec("INSERT INTO users").
+		WithArgs(user.Username, user.ID, user.Password).
+		WillReturnError(fmt.Errorf("duplicate"))
+	mock.ExpectRollback()
+
+	err = repoIdentity.Create(identity, user)
+	if err != errUserExists {
+		t.Errorf("want errUserExists, have %v", err)
+	}
+
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,203 @@
+package mysql
+
+import (
+	"database/sql"
+	"fmt"
+	"forum/internal/metrics"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type relationStorage struct {
+	db                *sql.DB
+	followTable       string
+	subscriptionTable string
+	blockTable        string
+	muteTable         string
+}
+
+func NewRelationStorage(db *sql.DB, followTable, subscriptionTable, blockTable, muteTable string) *relationStorage {
+	return &relationStorage{
+		db:                db,
+		followTable:       followTable,
+		subscriptionTable: subscriptionTable,
+		blockTable:        blockTable,
+		muteTable:         muteTable,
+	}
+}
+
+// Подписывает пользователя followerID на пользователя followeeID
+func (rs *relationStorage) Follow(followerID, followeeID primitive.ObjectID) error {
+	defer metrics.ObserveDB(metrics.MySQL, "relation.Follow", time.Now())
+	query := fmt.Sprintf("INSERT IGNORE INTO %s (follower_id, followee_id) VALUES (?, ?)", rs.followTable)
+	_, err := rs.db.Exec(
+		query,
+		followerID.Hex(),
+		followeeID.Hex(),
+	)
+	return err
+}
+
+// Отписывает пользователя followerID от пользователя followeeID
+func (rs *relationStorage) Unfollow(followerID, followeeID primitive.ObjectID) error {
+	defer metrics.ObserveDB(metrics.MySQL, "relation.Unfollow", time.Now())
+	query := fmt.Sprintf("DELETE FROM %s WHERE follower_id = ? AND followee_id = ?", rs.followTable)
+	_, err := rs.db.Exec(
+		query,
+		followerID.Hex(),
+		followeeID.Hex(),
+	)
+	return err
+}
+
+// Возвращает id пользователей, на которых подписан userID
+func (rs *relationStorage) Following(userID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	defer metrics.ObserveDB(metrics.MySQL, "relation.Following", time.Now())
+	query := fmt.Sprintf("SELECT followee_id FROM %s WHERE follower_id = ?", rs.followTable)
+	return rs.selectIDs(query, userID.Hex())
+}
+
+// Подписывает пользователя userID на категорию category
+func (rs *relationStorage) Subscribe(userID primitive.ObjectID, category string) error {
+	defer metrics.ObserveDB(metrics.MySQL, "relation.Subscribe", time.Now())
+	query := fmt.Sprintf("INSERT IGNORE INTO %s (user_id, category) VALUES (?, ?)", rs.subscriptionTable)
+	_, err := rs.db.Exec(
+		query,
+		userID.Hex(),
+		category,
+	)
+	return err
+}
+
+// Отписывает пользователя userID от категории category
+func (rs *relationStorage) Unsubscribe(userID primitive.ObjectID, category string) error {
+	defer metrics.ObserveDB(metrics.MySQL, "relation.Unsubscribe", time.Now())
+	query := fmt.Sprintf("DELETE FROM %s WHERE user_id = ? AND category = ?", rs.subscriptionTable)
+	_, err := rs.db.Exec(
+		query,
+		userID.Hex(),
+		category,
+	)
+	return err
+}
+
+// Возвращает категории, на которые подписан userID
+func (rs *relationStorage) Subscriptions(userID primitive.ObjectID) ([]string, error) {
+	defer metrics.ObserveDB(metrics.MySQL, "relation.Subscriptions", time.Now())
+	query := fmt.Sprintf("SELECT category FROM %s WHERE user_id = ?", rs.subscriptionTable)
+	return rs.selectStrings(query, userID.Hex())
+}
+
+// Блокирует пользователя blockedID для пользователя blockerID
+func (rs *relationStorage) Block(blockerID, blockedID primitive.ObjectID) error {
+	defer metrics.ObserveDB(metrics.MySQL, "relation.Block", time.Now())
+	query := fmt.Sprintf("INSERT IGNORE INTO %s (blocker_id, blocked_id) VALUES (?, ?)", rs.blockTable)
+	_, err := rs.db.Exec(
+		query,
+		blockerID.Hex(),
+		blockedID.Hex(),
+	)
+	return err
+}
+
+// Снимает блокировку пользователя blockedID пользователем blockerID
+func (rs *relationStorage) Unblock(blockerID, blockedID primitive.ObjectID) error {
+	defer metrics.ObserveDB(metrics.MySQL, "relation.Unblock", time.Now())
+	query := fmt.Sprintf("DELETE FROM %s WHERE blocker_id = ? AND blocked_id = ?", rs.blockTable)
+	_, err := rs.db.Exec(
+		query,
+		blockerID.Hex(),
+		blockedID.Hex(),
+	)
+	return err
+}
+
+// Возвращает id пользователей, заблокированных userID
+func (rs *relationStorage) Blocked(userID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	defer metrics.ObserveDB(metrics.MySQL, "relation.Blocked", time.Now())
+	query := fmt.Sprintf("SELECT blocked_id FROM %s WHERE blocker_id = ?", rs.blockTable)
+	return rs.selectIDs(query, userID.Hex())
+}
+
+// Проверяет, заблокировал ли blockerID пользователя blockedID
+func (rs *relationStorage) IsBlocked(blockerID, blockedID primitive.ObjectID) (bool, error) {
+	defer metrics.ObserveDB(metrics.MySQL, "relation.IsBlocked", time.Now())
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE blocker_id = ? AND blocked_id = ?", rs.blockTable)
+	var count int
+	err := rs.db.QueryRow(
+		query,
+		blockerID.Hex(),
+		blockedID.Hex(),
+	).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Скрывает категорию category для пользователя userID
+func (rs *relationStorage) Mute(userID primitive.ObjectID, category string) error {
+	defer metrics.ObserveDB(metrics.MySQL, "relation.Mute", time.Now())
+	query := fmt.Sprintf("INSERT IGNORE INTO %s (user_id, category) VALUES (?, ?)", rs.muteTable)
+	_, err := rs.db.Exec(
+		query,
+		userID.Hex(),
+		category,
+	)
+	return err
+}
+
+// Возвращает категорию category в выдачу пользователя userID
+func (rs *relationStorage) Unmute(userID primitive.ObjectID, category string) error {
+	defer metrics.ObserveDB(metrics.MySQL, "relation.Unmute", time.Now())
+	query := fmt.Sprintf("DELETE FROM %s WHERE user_id = ? AND category = ?", rs.muteTable)
+	_, err := rs.db.Exec(
+		query,
+		userID.Hex(),
+		category,
+	)
+	return err
+}
+
+// Возвращает категории, скрытые пользователем userID
+func (rs *relationStorage) Muted(userID primitive.ObjectID) ([]string, error) {
+	defer metrics.ObserveDB(metrics.MySQL, "relation.Muted", time.Now())
+	query := fmt.Sprintf("SELECT category FROM %s WHERE user_id = ?", rs.muteTable)
+	return rs.selectStrings(query, userID.Hex())
+}
+
+func (rs *relationStorage) selectIDs(query string, args ...any) ([]primitive.ObjectID, error) {
+	values, err := rs.selectStrings(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]primitive.ObjectID, 0, len(values))
+	for _, value := range values {
+		id, err := primitive.ObjectIDFromHex(value)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (rs *relationStorage) selectStrings(query string, args ...any) ([]string, error) {
+	rows, err := rs.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := make([]string, 0)
+	for rows.Next() {
+		var value string
+		err = rows.Scan(&value)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
@@ -0,0 +1,233 @@
+package mysql
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestFollowing(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("cant create mock: %s", err)
+	}
+	defer db.Close()
+
+	repoRelation := NewRelationStorage(db, "follows", "category_subscriptions", "blocks", "category_mutes")
+
+	followerID := primitive.NewObjectID()
+	followeeID := primitive.NewObjectID()
+
+	// follow
+	mock.
+		ExpectExec("INSERT IGNORE INTO follows").
+		WithArgs(followerID.Hex(), followeeID.Hex()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repoRelation.Follow(followerID, followeeID)
+	if err != nil {
+		t.Errorf("unexpected err: %s", err)
+		return
+	}
+
+	// following
+	rows := sqlmock.NewRows([]string{"followee_id"}).AddRow(followeeID.Hex())
+	mock.
+		ExpectQuery("SELECT followee_id FROM follows WHERE").
+		WithArgs(followerID.Hex()).
+		WillReturnRows(rows)
+
+	following, err := repoRelation.Following(followerID)
+	if err != nil {
+		t.Errorf("unexpected err: %s", err)
+		return
+	}
+	expected := []primitive.ObjectID{followeeID}
+	if !reflect.DeepEqual(following, expected) {
+		t.Errorf("results not match, want %v, have %v", expected, following)
+		return
+	}
+
+	// bad id in table
+	rows = sqlmock.NewRows([]string{"followee_id"}).AddRow("not id")
+	mock.
+		ExpectQuery("SELECT followee_id FROM follows WHERE").
+		WithArgs(followerID.Hex()).
+		WillReturnRows(rows)
+
+	_, err = repoRelation.Following(followerID)
+	if err == nil {
+		t.Errorf("expected error, got nil")
+		return
+	}
+
+	// unfollow error
+	mock.
+		ExpectExec("DELETE FROM follows WHERE").
+		WithArgs(followerID.Hex(), followeeID.Hex()).
+		WillReturnError(fmt.Errorf("db_error"))
+
+	err = repoRelation.Unfollow(followerID, followeeID)
+	if err == nil {
+		t.Errorf("expected error, got nil")
+		return
+	}
+
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSubscriptions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("cant create mock: %s", err)
+	}
+	defer db.Close()
+
+	repoRelation := NewRelationStorage(db, "follows", "category_subscriptions", "blocks", "category_mutes")
+
+	userID := primitive.NewObjectID()
+
+	// subscribe
+	mock.
+		ExpectExec("INSERT IGNORE INTO category_subscriptions").
+		WithArgs(userID.Hex(), "music").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repoRelation.Subscribe(userID, "music")
+	if err != nil {
+		t.Errorf("unexpected err: %s", err)
+		return
+	}
+
+	// subscriptions
+	rows := sqlmock.NewRows([]string{"category"}).AddRow("music").AddRow("news")
+	mock.
+		ExpectQuery("SELECT category FROM category_subscriptions WHERE").
+		WithArgs(userID.Hex()).
+		WillReturnRows(rows)
+
+	categories, err := repoRelation.Subscriptions(userID)
+	if err != nil {
+		t.Errorf("unexpected err: %s", err)
+		return
+	}
+	expected := []string{"music", "news"}
+	if !reflect.DeepEqual(categories, expected) {
+		t.Errorf("results not match, want %v, have %v", expected, categories)
+		return
+	}
+
+	// query error
+	mock.
+		ExpectQuery("SELECT category FROM category_subscriptions WHERE").
+		WithArgs(userID.Hex()).
+		WillReturnError(fmt.Errorf("db_error"))
+
+	_, err = repoRelation.Subscriptions(userID)
+	if err == nil {
+		t.Errorf("expected error, got nil")
+		return
+	}
+
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestBlocks(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("cant create mock: %s", err)
+	}
+	defer db.Close()
+
+	repoRelation := NewRelationStorage(db, "follows", "category_subscriptions", "blocks", "category_mutes")
+
+	blockerID := primitive.NewObjectID()
+	blockedID := primitive.NewObjectID()
+
+	// block
+	mock.
+		ExpectExec("INSERT IGNORE INTO blocks").
+		WithArgs(blockerID.Hex(), blockedID.Hex()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repoRelation.Block(blockerID, blockedID)
+	if err != nil {
+		t.Errorf("unexpected err: %s", err)
+		return
+	}
+
+	// is blocked
+	mock.
+		ExpectQuery("SELECT COUNT\\(\\*\\) FROM blocks WHERE").
+		WithArgs(blockerID.Hex(), blockedID.Hex()).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	blocked, err := repoRelation.IsBlocked(blockerID, blockedID)
+	if err != nil {
+		t.Errorf("unexpected err: %s", err)
+		return
+	}
+	if !blocked {
+		t.Errorf("expected blocked, got not blocked")
+		return
+	}
+
+	// is blocked error
+	mock.
+		ExpectQuery("SELECT COUNT\\(\\*\\) FROM blocks WHERE").
+		WithArgs(blockerID.Hex(), blockedID.Hex()).
+		WillReturnError(fmt.Errorf("db_error"))
+
+	_, err = repoRelation.IsBlocked(blockerID, blockedID)
+	if err == nil {
+		t.Errorf("expected error, got nil")
+		return
+	}
+
+	// blocked list
+	mock.
+		ExpectQuery("SELECT blocked_id FROM blocks WHERE").
+		WithArgs(blockerID.Hex()).
+		WillReturnRows(sqlmock.NewRows([]string{"blocked_id"}).AddRow(blockedID.Hex()))
+
+	blockedList, err := repoRelation.Blocked(blockerID)
+	if err != nil {
+		t.Errorf("unexpected err: %s", err)
+		return
+	}
+	expected := []primitive.ObjectID{blockedID}
+	if !reflect.DeepEqual(blockedList, expected) {
+		t.Errorf("results not match, want %v, have %v", expected, blockedList)
+		return
+	}
+
+	// muted list
+	mock.
+		ExpectQuery("SELECT category FROM category_mutes WHERE").
+		WithArgs(blockerID.Hex()).
+		WillReturnRows(sqlmock.NewRows([]string{"category"}).AddRow("music"))
+
+	muted, err := repoRelation.Muted(blockerID)
+	if err != nil {
+		t.Errorf("unexpected err: %s", err)
+		return
+	}
+	if !reflect.DeepEqual(muted, []string{"music"}) {
+		t.Errorf("results not match, want %v, have %v", []string{"music"}, muted)
+		return
+	}
+
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
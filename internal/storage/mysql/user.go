@@ -4,11 +4,14 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"forum/internal/metrics"
 	"forum/internal/models"
+	"time"
 )
 
 var (
 	errUserExists = errors.New("this username already exists")
+	errNoUser     = errors.New("no user found")
 )
 
 type userStorage struct {
@@ -25,21 +28,30 @@ func NewUserStorage(db *sql.DB, userTable string) *userStorage {
 
 // Возрващает первый элемент по username
 func (us *userStorage) FindOne(username string) (*models.User, error) {
+	defer metrics.ObserveDB(metrics.MySQL, "user.FindOne", time.Now())
 	user := &models.User{}
-	query := fmt.Sprintf("SELECT username, id, password FROM %s WHERE username = ?", us.table)
+	suspendedUntil := sql.NullTime{}
+	query := fmt.Sprintf(
+		"SELECT username, id, password, role, banned, suspended_until, suspend_reason, shadowbanned FROM %s WHERE username = ?",
+		us.table,
+	)
 	row := us.db.QueryRow(
 		query,
 		username,
 	)
-	err := row.Scan(&user.Username, &user.ID, &user.Password)
+	err := row.Scan(&user.Username, &user.ID, &user.Password, &user.Role, &user.Banned, &suspendedUntil, &user.SuspendReason, &user.Shadowbanned)
 	if err != nil {
 		return nil, err
 	}
+	if suspendedUntil.Valid {
+		user.SuspendedUntil = &suspendedUntil.Time
+	}
 	return user, nil
 }
 
 // Создает пользователя
 func (us *userStorage) Create(user *models.User) error {
+	defer metrics.ObserveDB(metrics.MySQL, "user.Create", time.Now())
 	query := fmt.Sprintf("INSERT INTO %s (username, id, password) VALUES (?, ?, ?)", us.table)
 	result, err := us.db.Exec(
 		query,
@@ -56,3 +68,65 @@ func (us *userStorage) Create(user *models.User) error {
 	}
 	return err
 }
+
+// Удаляет пользователя по username, связанные записи удаляются каскадно
+func (us *userStorage) Delete(username string) error {
+	defer metrics.ObserveDB(metrics.MySQL, "user.Delete", time.Now())
+	query := fmt.Sprintf("DELETE FROM %s WHERE username = ?", us.table)
+	result, err := us.db.Exec(
+		query,
+		username,
+	)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return errNoUser
+	}
+	return nil
+}
+
+// Блокирует или разблокирует пользователя с userID
+func (us *userStorage) SetBanned(userID string, banned bool) error {
+	defer metrics.ObserveDB(metrics.MySQL, "user.SetBanned", time.Now())
+	query := fmt.Sprintf("UPDATE %s SET banned = ? WHERE id = ?", us.table)
+	_, err := us.db.Exec(
+		query,
+		banned,
+		userID,
+	)
+	return err
+}
+
+/*
+Блокирует пользователя с userID: banned - бессрочно, иначе до until.
+Снятие блокировки - banned == false и until == nil.
+*/
+func (us *userStorage) SetSuspension(userID string, banned bool, until *time.Time, reason string) error {
+	defer metrics.ObserveDB(metrics.MySQL, "user.SetSuspension", time.Now())
+	query := fmt.Sprintf("UPDATE %s SET banned = ?, suspended_until = ?, suspend_reason = ? WHERE id = ?", us.table)
+	_, err := us.db.Exec(
+		query,
+		banned,
+		until,
+		reason,
+		userID,
+	)
+	return err
+}
+
+// Включает или выключает теневой бан пользователя с userID
+func (us *userStorage) SetShadowbanned(userID string, shadowbanned bool) error {
+	defer metrics.ObserveDB(metrics.MySQL, "user.SetShadowbanned", time.Now())
+	query := fmt.Sprintf("UPDATE %s SET shadowbanned = ? WHERE id = ?", us.table)
+	_, err := us.db.Exec(
+		query,
+		shadowbanned,
+		userID,
+	)
+	return err
+}
@@ -5,6 +5,7 @@ import (
 	"forum/internal/models"
 	"reflect"
 	"testing"
+	"time"
 
 	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
 )
@@ -87,20 +88,25 @@ func TestFindOne(t *testing.T) {
 	username := "usertest"
 
 	// good query
-	rows := sqlmock.NewRows([]string{"username", "id", "password"})
+	rows := sqlmock.NewRows([]string{"username", "id", "password", "role", "banned", "suspended_until", "suspend_reason", "shadowbanned"})
+	suspendedUntil := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
 	expect := []*models.User{
 		{
-			ID:       "1",
-			Password: "password",
-			Username: username,
+			ID:             "1",
+			Password:       "password",
+			Username:       username,
+			Role:           models.RoleModerator,
+			SuspendedUntil: &suspendedUntil,
+			SuspendReason:  "spam",
+			Shadowbanned:   true,
 		},
 	}
 	for _, item := range expect {
-		rows = rows.AddRow(item.Username, item.ID, item.Password)
+		rows = rows.AddRow(item.Username, item.ID, item.Password, item.Role, item.Banned, *item.SuspendedUntil, item.SuspendReason, item.Shadowbanned)
 	}
 
 	mock.
-		ExpectQuery(fmt.Sprintf("SELECT username, id, password FROM %s WHERE", table)).
+		ExpectQuery(fmt.Sprintf("SELECT username, id, password, role, banned, suspended_until, suspend_reason, shadowbanned FROM %s WHERE", table)).
 		WithArgs(username).
 		WillReturnRows(rows)
 
@@ -121,7 +127,7 @@ func TestFindOne(t *testing.T) {
 
 	// query error
 	mock.
-		ExpectQuery(fmt.Sprintf("SELECT username, id, password FROM %s WHERE", table)).
+		ExpectQuery(fmt.Sprintf("SELECT username, id, password, role, banned, suspended_until, suspend_reason, shadowbanned FROM %s WHERE", table)).
 		WithArgs(username).
 		WillReturnError(fmt.Errorf("db_error"))
 
@@ -141,7 +147,7 @@ func TestFindOne(t *testing.T) {
 		AddRow("username", 1)
 
 	mock.
-		ExpectQuery(fmt.Sprintf("SELECT username, id, password FROM %s WHERE", table)).
+		ExpectQuery(fmt.Sprintf("SELECT username, id, password, role, banned, suspended_until, suspend_reason, shadowbanned FROM %s WHERE", table)).
 		WithArgs(username).
 		WillReturnRows(rows)
 
@@ -156,3 +162,161 @@ func TestFindOne(t *testing.T) {
 		return
 	}
 }
+
+func TestDelete(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("cant create mock: %s", err)
+	}
+	defer db.Close()
+
+	table := "user"
+	repoUser := NewUserStorage(db, table)
+
+	username := "usertest"
+
+	// ok query
+	mock.
+		ExpectExec(fmt.Sprintf("DELETE FROM %s WHERE", table)).
+		WithArgs(username).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repoUser.Delete(username)
+	if err != nil {
+		t.Errorf("unexpected err: %s", err)
+		return
+	}
+
+	// no user
+	mock.
+		ExpectExec(fmt.Sprintf("DELETE FROM %s WHERE", table)).
+		WithArgs(username).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repoUser.Delete(username)
+	if err != errNoUser {
+		t.Errorf("want errNoUser, have %v", err)
+		return
+	}
+
+	// query error
+	mock.
+		ExpectExec(fmt.Sprintf("DELETE FROM %s WHERE", table)).
+		WithArgs(username).
+		WillReturnError(fmt.Errorf("db_error"))
+
+	err = repoUser.Delete(username)
+	if err == nil {
+		t.Errorf("expected error, got nil")
+		return
+	}
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSetBanned(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("cant create mock: %s", err)
+	}
+	defer db.Close()
+
+	table := "user"
+	repoUser := NewUserStorage(db, table)
+
+	// ok query
+	mock.
+		ExpectExec(fmt.Sprintf("UPDATE %s SET banned = \\? WHERE id = \\?", table)).
+		WithArgs(true, "1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repoUser.SetBanned("1", true)
+	if err != nil {
+		t.Errorf("unexpected err: %s", err)
+		return
+	}
+
+	// query error
+	mock.
+		ExpectExec(fmt.Sprintf("UPDATE %s SET banned = \\? WHERE id = \\?", table)).
+		WithArgs(false, "1").
+		WillReturnError(fmt.Errorf("db_error"))
+
+	err = repoUser.SetBanned("1", false)
+	if err == nil {
+		t.Errorf("expected error, got nil")
+		return
+	}
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSetSuspension(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("cant create mock: %s", err)
+	}
+	defer db.Close()
+
+	table := "user"
+	repoUser := NewUserStorage(db, table)
+	until := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// ok query
+	mock.
+		ExpectExec(fmt.Sprintf("UPDATE %s SET banned = \\?, suspended_until = \\?, suspend_reason = \\? WHERE id = \\?", table)).
+		WithArgs(false, &until, "spam", "1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repoUser.SetSuspension("1", false, &until, "spam")
+	if err != nil {
+		t.Errorf("unexpected err: %s", err)
+		return
+	}
+
+	// query error
+	mock.
+		ExpectExec(fmt.Sprintf("UPDATE %s SET banned = \\?, suspended_until = \\?, suspend_reason = \\? WHERE id = \\?", table)).
+		WithArgs(false, nil, "", "1").
+		WillReturnError(fmt.Errorf("db_error"))
+
+	err = repoUser.SetSuspension("1", false, nil, "")
+	if err == nil {
+		t.Errorf("expected error, got nil")
+		return
+	}
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSetShadowbanned(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("cant create mock: %s", err)
+	}
+	defer db.Close()
+
+	table := "user"
+	repoUser := NewUserStorage(db, table)
+
+	mock.
+		ExpectExec(fmt.Sprintf("UPDATE %s SET shadowbanned = \\? WHERE id = \\?", table)).
+		WithArgs(true, "1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repoUser.SetShadowbanned("1", true)
+	if err != nil {
+		t.Errorf("unexpected err: %s", err)
+		return
+	}
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,111 @@
+package redis
+
+import (
+	"encoding/json"
+	"forum/internal/metrics"
+	"forum/internal/models"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+var (
+	eventStream  = "events"
+	eventChannel = "events"
+
+	// сколько последних событий хранится для продолжения после переподключения
+	eventHistory int64 = 10000
+	replayLimit  int64 = 1000
+)
+
+type eventStorage struct {
+	client *redis.Client
+}
+
+func NewEventStorage(client *redis.Client) *eventStorage {
+	return &eventStorage{
+		client: client,
+	}
+}
+
+/*
+Сохраняет событие в stream, чтобы его можно было получить повторно по Last-Event-ID,
+и рассылает его всем экземплярам сервера через pub/sub. ID события берется из stream.
+*/
+func (es *eventStorage) Publish(event *models.Event) error {
+	defer metrics.ObserveDB(metrics.Redis, "event.Publish", time.Now())
+	serrialized, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	id, err := es.client.XAdd(&redis.XAddArgs{
+		Stream:       eventStream,
+		MaxLenApprox: eventHistory,
+		Values: map[string]interface{}{
+			"event": serrialized,
+		},
+	}).Result()
+	if err != nil {
+		return err
+	}
+
+	event.ID = id
+	serrialized, err = json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return es.client.Publish(eventChannel, serrialized).Err()
+}
+
+// Возвращает события, опубликованные после события с lastID
+func (es *eventStorage) Since(lastID string) ([]*models.Event, error) {
+	defer metrics.ObserveDB(metrics.Redis, "event.Since", time.Now())
+	messages, err := es.client.XRangeN(eventStream, lastID, "+", replayLimit).Result()
+	if err != nil {
+		return nil, err
+	}
+	events := make([]*models.Event, 0, len(messages))
+	for _, message := range messages {
+		if message.ID == lastID {
+			continue
+		}
+		data, ok := message.Values["event"].(string)
+		if !ok {
+			continue
+		}
+		event := &models.Event{}
+		err = json.Unmarshal([]byte(data), event)
+		if err != nil {
+			return nil, err
+		}
+		event.ID = message.ID
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// Подписывается на события всех экземпляров сервера. Второе значение закрывает подписку.
+func (es *eventStorage) Subscribe() (<-chan *models.Event, func() error, error) {
+	defer metrics.ObserveDB(metrics.Redis, "event.Subscribe", time.Now())
+	pubsub := es.client.Subscribe(eventChannel)
+	// ждем подтверждения подписки, чтобы не потерять события, опубликованные сразу после нее
+	_, err := pubsub.Receive()
+	if err != nil {
+		pubsub.Close()
+		return nil, nil, err
+	}
+
+	events := make(chan *models.Event)
+	go func() {
+		defer close(events)
+		for message := range pubsub.Channel() {
+			event := &models.Event{}
+			err := json.Unmarshal([]byte(message.Payload), event)
+			if err != nil {
+				continue
+			}
+			events <- event
+		}
+	}()
+	return events, pubsub.Close, nil
+}
@@ -0,0 +1,71 @@
+package redis
+
+import (
+	"forum/internal/models"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPublishAndSince(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mr.Close()
+
+	client := redis.NewClient(&redis.Options{
+		Addr: mr.Addr(),
+	})
+
+	eventRepo := NewEventStorage(client)
+
+	events, closeSubscription, err := eventRepo.Subscribe()
+	if err != nil {
+		t.Fatalf("want error nil, but have %v", err)
+	}
+	defer closeSubscription()
+
+	postID := primitive.NewObjectID()
+	published := make([]*models.Event, 0)
+	for _, eventType := range []string{models.EventCommentCreated, models.EventPostVotes, models.EventPostDeleted} {
+		event := &models.Event{Type: eventType, PostID: postID}
+		err = eventRepo.Publish(event)
+		if err != nil {
+			t.Fatalf("want error nil, but have %v", err)
+		}
+		if event.ID == "" {
+			t.Fatal("want event id, but have empty")
+		}
+		published = append(published, event)
+	}
+
+	// pub/sub
+	for _, expected := range published {
+		select {
+		case event := <-events:
+			if event.ID != expected.ID || event.Type != expected.Type {
+				t.Errorf("want %v, but have %v", expected, event)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for event")
+		}
+	}
+
+	// replay after first event
+	missed, err := eventRepo.Since(published[0].ID)
+	if err != nil {
+		t.Fatalf("want error nil, but have %v", err)
+	}
+	if len(missed) != 2 {
+		t.Fatalf("want 2 events, but have %d", len(missed))
+	}
+	for i, event := range missed {
+		if event.ID != published[i+1].ID || event.Type != published[i+1].Type {
+			t.Errorf("want %v, but have %v", published[i+1], event)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package redis
+
+import (
+	"encoding/json"
+	"forum/internal/metrics"
+	"forum/internal/models"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+var (
+	stateLifespan = 10 // in minutes
+)
+
+type oauthStateStorage struct {
+	client *redis.Client
+}
+
+func NewOAuthStateStorage(client *redis.Client) *oauthStateStorage {
+	return &oauthStateStorage{
+		client: client,
+	}
+}
+
+// Сохраняет состояние входа через внешнего провайдера по ключу state
+func (ss *oauthStateStorage) Set(state string, oauthState *models.OAuthState) error {
+	defer metrics.ObserveDB(metrics.Redis, "oauthState.Set", time.Now())
+	mkey := "oauth:" + state
+	stateSerrialized, err := json.Marshal(oauthState)
+	if err != nil {
+		return err
+	}
+	return ss.client.Set(mkey, stateSerrialized, time.Minute*time.Duration(stateLifespan)).Err()
+}
+
+// Возвращает и удаляет состояние по ключу state, чтобы его нельзя было использовать повторно
+func (ss *oauthStateStorage) Pop(state string) (*models.OAuthState, error) {
+	defer metrics.ObserveDB(metrics.Redis, "oauthState.Pop", time.Now())
+	mkey := "oauth:" + state
+	var result *redis.StringCmd
+	_, err := ss.client.TxPipelined(func(pipe redis.Pipeliner) error {
+		result = pipe.Get(mkey)
+		pipe.Del(mkey)
+		return nil
+	})
+	if err == redis.Nil {
+		return nil, errNoKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	data, err := result.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	oauthState := &models.OAuthState{}
+	err = json.Unmarshal(data, oauthState)
+	if err != nil {
+		return nil, err
+	}
+	return oauthState, nil
+}
@@ -0,0 +1,46 @@
+package redis
+
+import (
+	"forum/internal/models"
+	"reflect"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis"
+)
+
+func TestOAuthStatePop(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mr.Close()
+
+	client := redis.NewClient(&redis.Options{
+		Addr: mr.Addr(),
+	})
+
+	stateRepo := NewOAuthStateStorage(client)
+	state := &models.OAuthState{
+		Verifier: "verifier",
+		Nonce:    "nonce",
+	}
+
+	err = stateRepo.Set("state", state)
+	if err != nil {
+		t.Errorf("want error nil, but have %v", err)
+	}
+
+	stateStorage, err := stateRepo.Pop("state")
+	if err != nil {
+		t.Errorf("want error nil, but have %v", err)
+	}
+	if !reflect.DeepEqual(state, stateStorage) {
+		t.Errorf("want %v, but have %v", state, stateStorage)
+	}
+
+	_, err = stateRepo.Pop("state")
+	if err != errNoKey {
+		t.Errorf("want errNoKey, but have %v", err)
+	}
+}
@@ -1,12 +1,17 @@
 package redis
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"forum/internal/metrics"
 	"forum/internal/models"
+	"forum/internal/tracing"
+	"strconv"
 	"time"
 
 	"github.com/go-redis/redis"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 var (
@@ -15,6 +20,9 @@ var (
 	errNoKey = errors.New("key does not exists")
 )
 
+// Токены всех сессий с временем истечения в score, по нему считаются активные сессии
+const activeSessionsKey = "sessions:active"
+
 type redisStorage struct {
 	client *redis.Client
 }
@@ -25,20 +33,35 @@ func NewRedisStorage(client *redis.Client) *redisStorage {
 	}
 }
 
+// Сохраняет сессию и запоминает токен в списке сессий пользователя
 func (rs *redisStorage) Set(token string, author *models.Author) error {
+	defer metrics.ObserveDB(metrics.Redis, "session.Set", time.Now())
 	mkey := "token:" + token
 	authorSerrialized, err := json.Marshal(author)
 	if err != nil {
 		return err
 	}
-	err = rs.client.Set(mkey, authorSerrialized, time.Hour*time.Duration(tokenLifespan)).Err()
-	if err != nil {
-		return err
-	}
-	return nil
+	lifespan := time.Hour * time.Duration(tokenLifespan)
+	userKey := "sessions:" + author.ID.Hex()
+	_, err = rs.client.TxPipelined(func(pipe redis.Pipeliner) error {
+		pipe.Set(mkey, authorSerrialized, lifespan)
+		pipe.SAdd(userKey, token)
+		pipe.Expire(userKey, lifespan)
+		pipe.ZAdd(activeSessionsKey, redis.Z{Score: float64(time.Now().Add(lifespan).Unix()), Member: token})
+		return nil
+	})
+	return err
 }
 
-func (rs *redisStorage) Get(token string) (*models.Author, error) {
+// Возвращает автора сессии token, ctx нужен для трассировки
+func (rs *redisStorage) Get(ctx context.Context, token string) (_ *models.Author, err error) {
+	defer metrics.ObserveDB(metrics.Redis, "session.Get", time.Now())
+	_, span := tracing.StartDB(ctx, tracing.Redis, "session.Get")
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+
 	mkey := "token:" + token
 	result := rs.client.Get(mkey)
 	if result.Err() == redis.Nil {
@@ -55,3 +78,38 @@ func (rs *redisStorage) Get(token string) (*models.Author, error) {
 	}
 	return author, nil
 }
+
+// Удаляет все сессии пользователя с userID
+func (rs *redisStorage) DeleteAll(userID primitive.ObjectID) error {
+	defer metrics.ObserveDB(metrics.Redis, "session.DeleteAll", time.Now())
+	userKey := "sessions:" + userID.Hex()
+	tokens, err := rs.client.SMembers(userKey).Result()
+	if err != nil {
+		return err
+	}
+	keys := []string{userKey}
+	members := make([]interface{}, 0, len(tokens))
+	for _, token := range tokens {
+		keys = append(keys, "token:"+token)
+		members = append(members, token)
+	}
+	_, err = rs.client.TxPipelined(func(pipe redis.Pipeliner) error {
+		pipe.Del(keys...)
+		if len(members) > 0 {
+			pipe.ZRem(activeSessionsKey, members...)
+		}
+		return nil
+	})
+	return err
+}
+
+// Возвращает количество неистекших сессий, истекшие при этом удаляются из учета
+func (rs *redisStorage) Count() (int64, error) {
+	defer metrics.ObserveDB(metrics.Redis, "session.Count", time.Now())
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	_, err := rs.client.ZRemRangeByScore(activeSessionsKey, "-inf", "("+now).Result()
+	if err != nil {
+		return 0, err
+	}
+	return rs.client.ZCard(activeSessionsKey).Result()
+}
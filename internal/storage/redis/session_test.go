@@ -1,13 +1,18 @@
 package redis
 
 import (
+	"context"
 	"forum/internal/models"
+	"forum/internal/tracing"
+	"forum/internal/tracing/tracingtest"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/alicebob/miniredis/v2"
 	"github.com/go-redis/redis"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel/codes"
 )
 
 func TestErrNoKey(t *testing.T) {
@@ -24,7 +29,7 @@ func TestErrNoKey(t *testing.T) {
 	sessionRepo := NewRedisStorage(client)
 	token := "token"
 
-	_, err = sessionRepo.Get(token)
+	_, err = sessionRepo.Get(context.Background(), token)
 	if err != errNoKey {
 		t.Errorf("want errNokey, but have %v", err)
 	}
@@ -54,7 +59,7 @@ func TestSetAndGet(t *testing.T) {
 		t.Errorf("want error nil, but have %v", err)
 	}
 
-	authorStorage, err := sessionRepo.Get(token)
+	authorStorage, err := sessionRepo.Get(context.Background(), token)
 	if err != nil {
 		t.Errorf("want error nil, but have %v", err)
 	}
@@ -62,3 +67,130 @@ func TestSetAndGet(t *testing.T) {
 		t.Errorf("want %v, but have %v", author, authorStorage)
 	}
 }
+
+func TestDeleteAll(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mr.Close()
+
+	client := redis.NewClient(&redis.Options{
+		Addr: mr.Addr(),
+	})
+
+	sessionRepo := NewRedisStorage(client)
+
+	author := &models.Author{
+		ID:       primitive.NewObjectID(),
+		Username: "usertest",
+	}
+	other := &models.Author{
+		ID:       primitive.NewObjectID(),
+		Username: "other",
+	}
+
+	for _, token := range []string{"token1", "token2"} {
+		err = sessionRepo.Set(token, author)
+		if err != nil {
+			t.Errorf("want error nil, but have %v", err)
+		}
+	}
+	err = sessionRepo.Set("token3", other)
+	if err != nil {
+		t.Errorf("want error nil, but have %v", err)
+	}
+
+	err = sessionRepo.DeleteAll(author.ID)
+	if err != nil {
+		t.Errorf("want error nil, but have %v", err)
+	}
+
+	for _, token := range []string{"token1", "token2"} {
+		_, err = sessionRepo.Get(context.Background(), token)
+		if err != errNoKey {
+			t.Errorf("want errNoKey, but have %v", err)
+		}
+	}
+	_, err = sessionRepo.Get(context.Background(), "token3")
+	if err != nil {
+		t.Errorf("want error nil, but have %v", err)
+	}
+}
+
+func TestCount(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mr.Close()
+
+	client := redis.NewClient(&redis.Options{
+		Addr: mr.Addr(),
+	})
+
+	sessionRepo := NewRedisStorage(client)
+
+	author := &models.Author{
+		ID:       primitive.NewObjectID(),
+		Username: "usertest",
+	}
+	for _, token := range []string{"token1", "token2"} {
+		err = sessionRepo.Set(token, author)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// истекшая сессия не учитывается
+	err = client.ZAdd(activeSessionsKey, redis.Z{Score: float64(time.Now().Add(-time.Hour).Unix()), Member: "expired"}).Err()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	count, err := sessionRepo.Count()
+	if err != nil || count != 2 {
+		t.Errorf("want 2 sessions, have %d, %v", count, err)
+	}
+
+	err = sessionRepo.DeleteAll(author.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	count, err = sessionRepo.Count()
+	if err != nil || count != 0 {
+		t.Errorf("want 0 sessions, have %d, %v", count, err)
+	}
+}
+
+func TestGetSpan(t *testing.T) {
+	recorder := tracingtest.NewRecorder()
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mr.Close()
+
+	client := redis.NewClient(&redis.Options{
+		Addr: mr.Addr(),
+	})
+	sessionRepo := NewRedisStorage(client)
+
+	ctx, parent := tracing.Start(context.Background(), "request")
+	_, err = sessionRepo.Get(ctx, "token")
+	parent.End()
+	if err != errNoKey {
+		t.Errorf("want errNoKey, but have %v", err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("want 2 spans, have %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "session.Get" || span.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("span %s is not a child of request", span.Name())
+	}
+	if span.Status().Code != codes.Error {
+		t.Errorf("want error status, have %v", span.Status())
+	}
+}
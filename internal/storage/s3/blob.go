@@ -0,0 +1,83 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+var (
+	errNoBlob = errors.New("no blob found")
+)
+
+// Возвращает клиент S3-совместимого хранилища, создает bucket, если его нет
+func GetConnection(endpoint, accessKey, secretKey, bucket string, useSSL bool) (*minio.Client, error) {
+	ctx := context.Background()
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
+		Secure: useSSL,
+	})
+	if err != nil {
+		return nil, err
+	}
+	exists, err := client.BucketExists(ctx, bucket)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		err = client.MakeBucket(ctx, bucket, minio.MakeBucketOptions{})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return client, nil
+}
+
+type blobStorage struct {
+	client *minio.Client
+	bucket string
+}
+
+func NewBlobStorage(client *minio.Client, bucket string) *blobStorage {
+	return &blobStorage{
+		client: client,
+		bucket: bucket,
+	}
+}
+
+// Сохраняет data под ключом key
+func (bs *blobStorage) Put(key string, data io.Reader, size int64, contentType string) error {
+	ctx := context.Background()
+	_, err := bs.client.PutObject(ctx, bs.bucket, key, data, size, minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	return err
+}
+
+// Открывает объект с ключом key
+func (bs *blobStorage) Get(key string) (io.ReadCloser, error) {
+	ctx := context.Background()
+	object, err := bs.client.GetObject(ctx, bs.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	// GetObject ленивый, отсутствие объекта видно только после запроса
+	_, err = object.Stat()
+	if err != nil {
+		object.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, errNoBlob
+		}
+		return nil, err
+	}
+	return object, nil
+}
+
+// Удаляет объект с ключом key
+func (bs *blobStorage) Delete(key string) error {
+	ctx := context.Background()
+	return bs.client.RemoveObject(ctx, bs.bucket, key, minio.RemoveObjectOptions{})
+}
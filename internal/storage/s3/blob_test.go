@@ -0,0 +1,46 @@
+package s3
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/johannesboyne/gofakes3"
+	"github.com/johannesboyne/gofakes3/backend/s3mem"
+)
+
+func TestBlobStorage(t *testing.T) {
+	server := httptest.NewServer(gofakes3.New(s3mem.New()).Server())
+	defer server.Close()
+
+	client, err := GetConnection(strings.TrimPrefix(server.URL, "http://"), "key", "secret", "forum", false)
+	if err != nil {
+		t.Fatalf("want error nil, but have %v", err)
+	}
+	storage := NewBlobStorage(client, "forum")
+
+	err = storage.Put("image.png", strings.NewReader("data"), 4, "image/png")
+	if err != nil {
+		t.Fatalf("want error nil, but have %v", err)
+	}
+
+	object, err := storage.Get("image.png")
+	if err != nil {
+		t.Fatalf("want error nil, but have %v", err)
+	}
+	data, _ := io.ReadAll(object)
+	object.Close()
+	if string(data) != "data" {
+		t.Errorf("want data, have %s", data)
+	}
+
+	err = storage.Delete("image.png")
+	if err != nil {
+		t.Fatalf("want error nil, but have %v", err)
+	}
+	_, err = storage.Get("image.png")
+	if err != errNoBlob {
+		t.Errorf("want errNoBlob, have %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Трассировка OpenTelemetry: спаны от HTTP-запроса через менеджеры до обращений к хранилищам.
+Контекст трассировки передается в заголовках W3C traceparent и tracestate.
+*/
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "forum"
+
+// Хранилища для атрибута db.system
+var (
+	MySQL = semconv.DBSystemMySQL
+	Mongo = semconv.DBSystemMongoDB
+	Redis = semconv.DBSystemRedis
+)
+
+/*
+Настраивает распространение контекста W3C и экспорт спанов по OTLP/HTTP на endpoint
+(например, http://collector:4318), спаны отправляются на путь /v1/traces, как для OTEL_EXPORTER_OTLP_ENDPOINT.
+Если endpoint пустой, экспорт выключен: спаны не записываются, но контекст трассировки
+из входящих заголовков передается дальше.
+Возвращает функцию, отправляющую оставшиеся спаны при остановке сервера.
+*/
+func Setup(endpoint, serviceName string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagator())
+	if endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(context.Background(), otlptracehttp.WithEndpointURL(strings.TrimSuffix(endpoint, "/")+"/v1/traces"))
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(serviceName))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+func propagator() propagation.TextMapPropagator {
+	return propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+}
+
+// Начинает спан name, дочерний к спану из ctx
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// Начинает спан обращения к хранилищу system методом хранилища method, например "post.View"
+func StartDB(ctx context.Context, system attribute.KeyValue, method string) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(system, semconv.DBOperation(method)),
+	)
+}
+
+// Отмечает span завершившимся с ошибкой err, если она есть
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+/*
+Начинает серверный спан запроса r к пути с шаблоном route. Если клиент передал traceparent,
+спан продолжает его трассировку.
+*/
+func StartHTTP(r *http.Request, route string) (context.Context, trace.Span) {
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	return otel.Tracer(tracerName).Start(ctx, r.Method+" "+route,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(r.Method),
+			semconv.HTTPRoute(route),
+			semconv.URLPath(r.URL.Path),
+		),
+	)
+}
+
+// Записывает в серверный спан статус ответа, ответы 5xx отмечаются ошибкой
+func EndHTTP(span trace.Span, status int) {
+	span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+	if status >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(status))
+	}
+	span.End()
+}
@@ -0,0 +1,21 @@
+// Вспомогательные функции для тестов со спанами, в сервер не входят
+package tracingtest
+
+import (
+	"forum/internal/tracing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+/*
+Записывает спаны в память вместо экспорта.
+Провайдер глобальный, поэтому тесты со спанами не должны выполняться параллельно.
+*/
+func NewRecorder() *tracetest.SpanRecorder {
+	tracing.Setup("", "")
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	return recorder
+}
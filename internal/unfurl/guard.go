@@ -0,0 +1,84 @@
+package unfurl
+
+import (
+	"errors"
+	"net"
+	"net/netip"
+	"strings"
+	"syscall"
+)
+
+var (
+	errPrivateAddress = errors.New("address is not public")
+	errDeniedDomain   = errors.New("domain is not allowed")
+)
+
+// Диапазоны, которые не считаются публичными, кроме покрытых методами netip.Addr
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// Возвращает true, если addr - публичный адрес
+func isPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+Проверяет адрес непосредственно перед соединением, т.е. уже после разрешения DNS,
+поэтому подмена DNS-ответа не позволяет обратиться к внутренней сети.
+*/
+func guardControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !isPublic(addr) {
+		return errPrivateAddress
+	}
+	return nil
+}
+
+// Списки доменов. Домен подходит под запись, если совпадает с ней или является ее поддоменом.
+type domainList struct {
+	allow []string
+	deny  []string
+}
+
+// Запрещенные домены имеют приоритет, пустой allow разрешает все остальные
+func (dl domainList) allowed(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if matchDomain(host, dl.deny) {
+		return false
+	}
+	return len(dl.allow) == 0 || matchDomain(host, dl.allow)
+}
+
+func matchDomain(host string, domains []string) bool {
+	for _, domain := range domains {
+		domain = strings.TrimSuffix(strings.ToLower(domain), ".")
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,312 @@
+package unfurl
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"forum/internal/models"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+var (
+	defaultTimeout     = 5 * time.Second
+	defaultMaxBodySize = int64(1 << 20)
+	maxRedirects       = 5
+	// сколько символов описания сохраняется
+	maxDescriptionLength = 300
+
+	userAgent = "forum-unfurler/1.0"
+
+	errBadScheme        = errors.New("only http and https urls are supported")
+	errTooManyRedirects = errors.New("too many redirects")
+	errContentType      = errors.New("unexpected content type")
+	errNoMetadata       = errors.New("no metadata found")
+)
+
+/*
+Загружает страницу по ссылке и достает из нее метаданные для превью.
+Обращения к непубличным адресам запрещены, размер ответа и время ограничены.
+*/
+type Unfurler struct {
+	client      *http.Client
+	domains     domainList
+	maxBodySize int64
+}
+
+// allow - разрешенные домены (пустой - все), deny - запрещенные
+func NewUnfurler(allow, deny []string) *Unfurler {
+	return newUnfurler(allow, deny, false)
+}
+
+// allowPrivate отключает проверку адресов, нужно для тестов на локальном сервере
+func newUnfurler(allow, deny []string, allowPrivate bool) *Unfurler {
+	dialer := &net.Dialer{
+		Timeout: defaultTimeout,
+	}
+	if !allowPrivate {
+		dialer.Control = guardControl
+	}
+	transport := &http.Transport{
+		// прокси из окружения обошел бы проверку адресов
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   defaultTimeout,
+		ResponseHeaderTimeout: defaultTimeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+	u := &Unfurler{
+		domains: domainList{
+			allow: allow,
+			deny:  deny,
+		},
+		maxBodySize: defaultMaxBodySize,
+	}
+	u.client = &http.Client{
+		Transport: transport,
+		Timeout:   2 * defaultTimeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return errTooManyRedirects
+			}
+			return u.checkURL(req.URL)
+		},
+	}
+	return u
+}
+
+func (u *Unfurler) checkURL(target *url.URL) error {
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return errBadScheme
+	}
+	if !u.domains.allowed(target.Hostname()) {
+		return errDeniedDomain
+	}
+	return nil
+}
+
+// Возвращает превью страницы rawURL
+func (u *Unfurler) Fetch(rawURL string) (*models.LinkPreview, error) {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	err = u.checkURL(target)
+	if err != nil {
+		return nil, err
+	}
+
+	body, finalURL, err := u.get(target.String(), "text/html")
+	if err != nil {
+		return nil, err
+	}
+	meta, err := parseHTML(body, finalURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// oEmbed только дополняет то, чего нет в разметке страницы
+	if meta.oembed != "" && (meta.title == "" || meta.image == "") {
+		u.fillOEmbed(meta)
+	}
+
+	if meta.title == "" && meta.description == "" && meta.image == "" {
+		return nil, errNoMetadata
+	}
+	if meta.siteName == "" {
+		meta.siteName = finalURL.Hostname()
+	}
+	return &models.LinkPreview{
+		Title:       meta.title,
+		Description: truncate(meta.description, maxDescriptionLength),
+		Image:       meta.image,
+		SiteName:    meta.siteName,
+		Fetched:     time.Now(),
+	}, nil
+}
+
+// Выполняет GET и читает не больше maxBodySize байт ответа с типом mediaType
+func (u *Unfurler) get(rawURL, mediaType string) (io.Reader, *url.URL, error) {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", mediaType)
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	contentType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || contentType != mediaType {
+		return nil, nil, errContentType
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, u.maxBodySize))
+	if err != nil {
+		return nil, nil, err
+	}
+	return bytes.NewReader(data), resp.Request.URL, nil
+}
+
+type oembedResponse struct {
+	Title        string `json:"title"`
+	ProviderName string `json:"provider_name"`
+	ThumbnailURL string `json:"thumbnail_url"`
+}
+
+// Дополняет meta данными oEmbed, ошибки игнорируются - превью уже есть из разметки
+func (u *Unfurler) fillOEmbed(meta *pageMeta) {
+	target, err := url.Parse(meta.oembed)
+	if err != nil || u.checkURL(target) != nil {
+		return
+	}
+	body, base, err := u.get(target.String(), "application/json")
+	if err != nil {
+		return
+	}
+	oembed := &oembedResponse{}
+	err = json.NewDecoder(body).Decode(oembed)
+	if err != nil {
+		return
+	}
+	if meta.title == "" {
+		meta.title = oembed.Title
+	}
+	if meta.siteName == "" {
+		meta.siteName = oembed.ProviderName
+	}
+	if meta.image == "" {
+		meta.image = resolve(base, oembed.ThumbnailURL)
+	}
+}
+
+type pageMeta struct {
+	title       string
+	description string
+	image       string
+	siteName    string
+	oembed      string
+}
+
+/*
+Достает метаданные из head страницы. OpenGraph имеет приоритет над Twitter card,
+а они - над <title> и <meta name="description">. Относительные ссылки разрешаются от base.
+*/
+func parseHTML(body io.Reader, base *url.URL) (*pageMeta, error) {
+	values := make(map[string]string)
+	var title, oembed string
+
+	tokenizer := html.NewTokenizer(body)
+	inTitle := false
+	for {
+		tokenType := tokenizer.Next()
+		switch tokenType {
+		case html.ErrorToken:
+			if tokenizer.Err() != io.EOF {
+				return nil, tokenizer.Err()
+			}
+			return buildMeta(values, title, oembed, base), nil
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			switch token.Data {
+			case "title":
+				inTitle = title == ""
+			case "meta":
+				key, content := metaAttrs(token)
+				if key != "" && values[key] == "" {
+					values[key] = strings.TrimSpace(content)
+				}
+			case "link":
+				if attr(token, "type") == "application/json+oembed" && oembed == "" {
+					oembed = resolve(base, attr(token, "href"))
+				}
+			case "body":
+				// метаданные есть только в head
+				return buildMeta(values, title, oembed, base), nil
+			}
+		case html.TextToken:
+			if inTitle {
+				title = strings.TrimSpace(string(tokenizer.Text()))
+				inTitle = false
+			}
+		case html.EndTagToken:
+			inTitle = false
+		}
+	}
+}
+
+func buildMeta(values map[string]string, title, oembed string, base *url.URL) *pageMeta {
+	first := func(keys ...string) string {
+		for _, key := range keys {
+			if values[key] != "" {
+				return values[key]
+			}
+		}
+		return ""
+	}
+	meta := &pageMeta{
+		title:       first("og:title", "twitter:title"),
+		description: first("og:description", "twitter:description", "description"),
+		image:       resolve(base, first("og:image", "og:image:url", "twitter:image", "twitter:image:src")),
+		siteName:    first("og:site_name", "twitter:site"),
+		oembed:      oembed,
+	}
+	if meta.title == "" {
+		meta.title = title
+	}
+	return meta
+}
+
+// OpenGraph использует property, Twitter card и description - name
+func metaAttrs(token html.Token) (string, string) {
+	key := attr(token, "property")
+	if key == "" {
+		key = attr(token, "name")
+	}
+	return strings.ToLower(key), attr(token, "content")
+}
+
+func attr(token html.Token, name string) string {
+	for _, a := range token.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// Разрешает ref относительно base, оставляет только http(s) ссылки
+func resolve(base *url.URL, ref string) string {
+	if ref == "" {
+		return ""
+	}
+	target, err := base.Parse(ref)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") {
+		return ""
+	}
+	return target.String()
+}
+
+func truncate(text string, length int) string {
+	runes := []rune(text)
+	if len(runes) <= length {
+		return text
+	}
+	return string(runes[:length]) + "…"
+}
@@ -0,0 +1,174 @@
+package unfurl
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+const ogPage = `<!doctype html>
+<html><head>
+<title>Fallback title</title>
+<meta property="og:title" content="OG title">
+<meta name="twitter:title" content="Twitter title">
+<meta name="description" content="Plain description">
+<meta property="og:image" content="/images/cover.png">
+<meta property="og:site_name" content="Example">
+</head><body><meta property="og:description" content="ignored in body"></body></html>`
+
+const twitterPage = `<html><head>
+<title>Fallback title</title>
+<meta name="twitter:title" content="Twitter title">
+<meta name="twitter:description" content="Twitter description">
+<link rel="alternate" type="application/json+oembed" href="/oembed">
+</head><body></body></html>`
+
+func newTestServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/og", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, ogPage)
+	})
+	mux.HandleFunc("/twitter", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, twitterPage)
+	})
+	mux.HandleFunc("/oembed", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"title": "oEmbed title", "provider_name": "Provider", "thumbnail_url": "https://cdn.example.com/thumb.jpg"}`)
+	})
+	mux.HandleFunc("/script", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, strings.Replace(twitterPage, `href="/oembed"`, `href="/oembed-script"`, 1))
+	})
+	mux.HandleFunc("/oembed-script", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"title": "oEmbed title", "thumbnail_url": "javascript:alert(1)"}`)
+	})
+	mux.HandleFunc("/huge", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, "<html><head><!--"+strings.Repeat("x", 4096)+`--><meta property="og:title" content="too far"></head></html>`)
+	})
+	mux.HandleFunc("/image", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		fmt.Fprint(w, "png")
+	})
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://denied.example.com/og", http.StatusFound)
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestFetch(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	unfurler := newUnfurler(nil, []string{"example.com"}, true)
+	unfurler.maxBodySize = 1024
+
+	// OpenGraph
+	preview, err := unfurler.Fetch(server.URL + "/og")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if preview.Title != "OG title" || preview.Description != "Plain description" ||
+		preview.Image != server.URL+"/images/cover.png" || preview.SiteName != "Example" {
+		t.Errorf("unexpected preview %+v", preview)
+	}
+
+	// Twitter card + oEmbed
+	preview, err = unfurler.Fetch(server.URL + "/twitter")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if preview.Title != "Twitter title" || preview.Description != "Twitter description" ||
+		preview.Image != "https://cdn.example.com/thumb.jpg" || preview.SiteName != "Provider" {
+		t.Errorf("unexpected preview %+v", preview)
+	}
+
+	// картинка из oEmbed проверяется так же, как из разметки
+	preview, err = unfurler.Fetch(server.URL + "/script")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if preview.Image != "" {
+		t.Errorf("want no image, have %q", preview.Image)
+	}
+
+	errorCases := []struct {
+		name     string
+		url      string
+		expected error
+	}{
+		{name: "size limit", url: server.URL + "/huge", expected: errNoMetadata},
+		{name: "content type", url: server.URL + "/image", expected: errContentType},
+		{name: "scheme", url: "file:///etc/passwd", expected: errBadScheme},
+		{name: "denied domain", url: "https://sub.example.com/", expected: errDeniedDomain},
+		{name: "redirect to denied domain", url: server.URL + "/redirect", expected: errDeniedDomain},
+	}
+	for _, item := range errorCases {
+		_, err = unfurler.Fetch(item.url)
+		if !errors.Is(err, item.expected) {
+			t.Errorf("%s: want %v, have %v", item.name, item.expected, err)
+		}
+	}
+}
+
+func TestFetchPrivateAddress(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	unfurler := NewUnfurler(nil, nil)
+	_, err := unfurler.Fetch(server.URL + "/og")
+	if !errors.Is(err, errPrivateAddress) {
+		t.Errorf("want %v, have %v", errPrivateAddress, err)
+	}
+}
+
+func TestIsPublic(t *testing.T) {
+	cases := map[string]bool{
+		"8.8.8.8":         true,
+		"2606:4700::1111": true,
+		"127.0.0.1":       false,
+		"10.1.2.3":        false,
+		"172.16.0.1":      false,
+		"192.168.1.1":     false,
+		"169.254.169.254": false,
+		"100.64.0.1":      false,
+		"0.0.0.0":         false,
+		"::1":             false,
+		"fc00::1":         false,
+		"fe80::1":         false,
+		"::ffff:10.0.0.1": false,
+	}
+	for address, expected := range cases {
+		if isPublic(netip.MustParseAddr(address)) != expected {
+			t.Errorf("%s: want public %v", address, expected)
+		}
+	}
+}
+
+func TestDomainList(t *testing.T) {
+	domains := domainList{
+		allow: []string{"example.com", "news.org"},
+		deny:  []string{"bad.example.com"},
+	}
+	cases := map[string]bool{
+		"example.com":       true,
+		"www.example.com":   true,
+		"bad.example.com":   false,
+		"x.bad.example.com": false,
+		"notexample.com":    false,
+		"NEWS.org.":         true,
+		"other.net":         false,
+	}
+	for host, expected := range cases {
+		if domains.allowed(host) != expected {
+			t.Errorf("%s: want allowed %v", host, expected)
+		}
+	}
+}